@@ -0,0 +1,309 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// These lines surround the armored text.
+const (
+	ArmorBegin = "-----BEGIN GISTFLOW ENCRYPTED FILE-----"
+	ArmorEnd   = "-----END GISTFLOW ENCRYPTED FILE-----"
+)
+
+// Prefixes of the textual representation of the keys.
+const (
+	publicPrefix  = "gistflow-public-"
+	privatePrefix = "gistflow-private-"
+)
+
+// Stanza types in the armor header.
+const (
+	scryptStanza = "scrypt"
+	x25519Stanza = "x25519"
+	lineLength   = 64
+)
+
+// Errors for armored texts.
+var (
+	ErrNotArmored   = errors.New("text is not armored")
+	ErrBadArmor     = errors.New("malformed armored text")
+	ErrNoSecrets    = errors.New("no passphrase or recipients are provided")
+	ErrBadKey       = errors.New("malformed key")
+	ErrNoMatchingID = errors.New("no matching passphrase or identity")
+)
+
+// PublicKey is the key of a recipient.
+type PublicKey [KeySize]byte
+
+// PrivateKey is the identity used for decrypting texts encrypted to its
+// PublicKey.
+type PrivateKey [KeySize]byte
+
+// Keyring holds the secrets for encrypting and decrypting armored texts.
+// Texts are encrypted for the Passphrase (if not empty) and for all
+// Recipients. They are decrypted with the Passphrase or any of Identities.
+type Keyring struct {
+	Passphrase []byte
+	Recipients []PublicKey
+	Identities []PrivateKey
+}
+
+// GenerateKey returns a new identity.
+func GenerateKey() (PrivateKey, error) {
+	var k PrivateKey
+	if _, err := io.ReadFull(rand.Reader, k[:]); err != nil {
+		return k, err
+	}
+	return k, nil
+}
+
+// Public returns the PublicKey of the identity.
+func (k PrivateKey) Public() PublicKey {
+	var p PublicKey
+	pub, _ := curve25519.X25519(k[:], curve25519.Basepoint)
+	copy(p[:], pub)
+	return p
+}
+
+func (k PrivateKey) String() string {
+	return privatePrefix + base64.RawURLEncoding.EncodeToString(k[:])
+}
+
+func (p PublicKey) String() string {
+	return publicPrefix + base64.RawURLEncoding.EncodeToString(p[:])
+}
+
+// ParsePublicKey parses the output of PublicKey.String.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var p PublicKey
+	err := parseKey(p[:], publicPrefix, s)
+	return p, err
+}
+
+// ParsePrivateKey parses the output of PrivateKey.String.
+func ParsePrivateKey(s string) (PrivateKey, error) {
+	var k PrivateKey
+	err := parseKey(k[:], privatePrefix, s)
+	return k, err
+}
+
+func parseKey(dst []byte, prefix, s string) error {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, prefix) {
+		return ErrBadKey
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil || len(b) != len(dst) {
+		return ErrBadKey
+	}
+	copy(dst, b)
+	return nil
+}
+
+// IsArmored returns true if the text is in the armored format.
+func IsArmored(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), ArmorBegin)
+}
+
+// Encrypt encrypts the plain text for the passphrase and the recipients in the
+// keyring and returns the armored text. The text is sealed with a random file
+// key, which is then wrapped for each of them.
+func Encrypt(plain []byte, k *Keyring) (string, error) {
+	if len(k.Passphrase) == 0 && len(k.Recipients) == 0 {
+		return "", ErrNoSecrets
+	}
+	fileKey := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+		return "", err
+	}
+	var stanzas []string
+	if len(k.Passphrase) > 0 {
+		salt, err := NewSalt()
+		if err != nil {
+			return "", err
+		}
+		key, err := DeriveKey(k.Passphrase, salt)
+		if err != nil {
+			return "", err
+		}
+		wrapped, err := Seal(key, fileKey, []byte(scryptStanza))
+		if err != nil {
+			return "", err
+		}
+		stanzas = append(stanzas, stanza(scryptStanza, salt, wrapped))
+	}
+	for _, r := range k.Recipients {
+		ephemeral, err := GenerateKey()
+		if err != nil {
+			return "", err
+		}
+		key, err := wrapKey(ephemeral[:], r[:], ephemeral.Public(), r)
+		if err != nil {
+			return "", err
+		}
+		wrapped, err := Seal(key, fileKey, []byte(x25519Stanza))
+		if err != nil {
+			return "", err
+		}
+		epub := ephemeral.Public()
+		stanzas = append(stanzas, stanza(x25519Stanza, epub[:], wrapped))
+	}
+	header := strings.Join(stanzas, "\n")
+	body, err := Seal(fileKey, plain, []byte(header))
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, ArmorBegin)
+	fmt.Fprintln(buf, header)
+	fmt.Fprintln(buf)
+	encoded := base64.StdEncoding.EncodeToString(body)
+	for len(encoded) > lineLength {
+		fmt.Fprintln(buf, encoded[:lineLength])
+		encoded = encoded[lineLength:]
+	}
+	fmt.Fprintln(buf, encoded)
+	fmt.Fprintln(buf, ArmorEnd)
+	return buf.String(), nil
+}
+
+// Decrypt decrypts the armored text with the passphrase or any of the
+// identities in the keyring. The header is authenticated along with the body.
+func Decrypt(armored string, k *Keyring) ([]byte, error) {
+	stanzas, header, body, err := parseArmor(armored)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stanzas {
+		var fileKey []byte
+		switch s.kind {
+		case scryptStanza:
+			if len(k.Passphrase) == 0 {
+				continue
+			}
+			key, err := DeriveKey(k.Passphrase, s.arg)
+			if err != nil {
+				continue
+			}
+			fileKey, err = Open(key, s.wrapped, []byte(scryptStanza))
+			if err != nil {
+				continue
+			}
+		case x25519Stanza:
+			var epub PublicKey
+			if len(s.arg) != len(epub) {
+				return nil, ErrBadArmor
+			}
+			copy(epub[:], s.arg)
+			for _, id := range k.Identities {
+				key, err := wrapKey(id[:], epub[:], epub, id.Public())
+				if err != nil {
+					continue
+				}
+				if fileKey, err = Open(key, s.wrapped, []byte(x25519Stanza)); err == nil {
+					break
+				}
+			}
+		}
+		if fileKey == nil {
+			continue
+		}
+		return Open(fileKey, body, []byte(header))
+	}
+	return nil, ErrNoMatchingID
+}
+
+// wrapKey derives the key for wrapping the file key for a recipient from the
+// shared secret of the scalar and the point.
+func wrapKey(scalar, point []byte, ephemeral, recipient PublicKey) ([]byte, error) {
+	shared, err := curve25519.X25519(scalar, point)
+	if err != nil {
+		return nil, err
+	}
+	salt := append(ephemeral[:], recipient[:]...)
+	key := make([]byte, KeySize)
+	r := hkdf.New(sha256.New, shared, salt, []byte("gistflow"))
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+type armorStanza struct {
+	kind    string
+	arg     []byte
+	wrapped []byte
+}
+
+func stanza(kind string, arg, wrapped []byte) string {
+	return fmt.Sprintf("%s %s %s",
+		kind,
+		base64.RawStdEncoding.EncodeToString(arg),
+		base64.RawStdEncoding.EncodeToString(wrapped),
+	)
+}
+
+func parseArmor(armored string) (stanzas []armorStanza, header string, body []byte, err error) {
+	if !IsArmored(armored) {
+		return nil, "", nil, ErrNotArmored
+	}
+	var (
+		headers []string
+		encoded strings.Builder
+		inBody  bool
+		ended   bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimSpace(armored)))
+	scanner.Scan() // the begin line.
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == ArmorEnd:
+			ended = true
+		case ended:
+			return nil, "", nil, ErrBadArmor
+		case line == "" && !inBody:
+			inBody = true
+		case inBody:
+			encoded.WriteString(line)
+		default:
+			headers = append(headers, line)
+		}
+	}
+	if !ended || len(headers) == 0 {
+		return nil, "", nil, ErrBadArmor
+	}
+	for _, h := range headers {
+		parts := strings.Fields(h)
+		if len(parts) != 3 {
+			return nil, "", nil, ErrBadArmor
+		}
+		arg, err1 := base64.RawStdEncoding.DecodeString(parts[1])
+		wrapped, err2 := base64.RawStdEncoding.DecodeString(parts[2])
+		if err1 != nil || err2 != nil {
+			return nil, "", nil, ErrBadArmor
+		}
+		stanzas = append(stanzas, armorStanza{parts[0], arg, wrapped})
+	}
+	body, err = base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, "", nil, ErrBadArmor
+	}
+	return stanzas, strings.Join(headers, "\n"), body, nil
+}
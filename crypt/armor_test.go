@@ -0,0 +1,125 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package crypt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/crypt"
+)
+
+func TestKeyStrings(t *testing.T) {
+	k, err := crypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := crypt.ParsePrivateKey(k.String())
+	if err != nil {
+		t.Errorf("crypt.ParsePrivateKey(): err = %v, want nil", err)
+	}
+	if k2 != k {
+		t.Errorf("crypt.ParsePrivateKey() = %v, want %v", k2, k)
+	}
+	p, err := crypt.ParsePublicKey(k.Public().String())
+	if err != nil {
+		t.Errorf("crypt.ParsePublicKey(): err = %v, want nil", err)
+	}
+	if p != k.Public() {
+		t.Errorf("crypt.ParsePublicKey() = %v, want %v", p, k.Public())
+	}
+	for _, s := range []string{"", k.String(), "gistflow-public-AAAA", "gistflow-public-!!"} {
+		if _, err := crypt.ParsePublicKey(s); err != crypt.ErrBadKey {
+			t.Errorf("crypt.ParsePublicKey(%s): err = %v, want %v", s, err, crypt.ErrBadKey)
+		}
+	}
+}
+
+func TestArmorPassphrase(t *testing.T) {
+	plain := "package main\n\nfunc main() {}\n"
+	k := &crypt.Keyring{Passphrase: []byte("pO8ZMlq")}
+	armored, err := crypt.Encrypt([]byte(plain), k)
+	if err != nil {
+		t.Fatalf("crypt.Encrypt(): err = %v, want nil", err)
+	}
+	if !crypt.IsArmored(armored) {
+		t.Errorf("crypt.IsArmored(%s) = false, want true", armored)
+	}
+	if strings.Contains(armored, "package") {
+		t.Error("armored text contains the plain text")
+	}
+	got, err := crypt.Decrypt(armored, k)
+	if err != nil {
+		t.Fatalf("crypt.Decrypt(): err = %v, want nil", err)
+	}
+	if string(got) != plain {
+		t.Errorf("crypt.Decrypt() = %s, want %s", got, plain)
+	}
+
+	_, err = crypt.Decrypt(armored, &crypt.Keyring{Passphrase: []byte("wrong")})
+	if err != crypt.ErrNoMatchingID {
+		t.Errorf("crypt.Decrypt(): err = %v, want %v", err, crypt.ErrNoMatchingID)
+	}
+}
+
+func TestArmorRecipients(t *testing.T) {
+	plain := strings.Repeat("DBzW0Oa3nGw7 ", 30)
+	alice, _ := crypt.GenerateKey()
+	bob, _ := crypt.GenerateKey()
+	eve, _ := crypt.GenerateKey()
+	armored, err := crypt.Encrypt([]byte(plain), &crypt.Keyring{
+		Recipients: []crypt.PublicKey{alice.Public(), bob.Public()},
+	})
+	if err != nil {
+		t.Fatalf("crypt.Encrypt(): err = %v, want nil", err)
+	}
+	for _, id := range []crypt.PrivateKey{alice, bob} {
+		got, err := crypt.Decrypt(armored, &crypt.Keyring{Identities: []crypt.PrivateKey{eve, id}})
+		if err != nil {
+			t.Errorf("crypt.Decrypt(): err = %v, want nil", err)
+		}
+		if string(got) != plain {
+			t.Errorf("crypt.Decrypt() = %s, want %s", got, plain)
+		}
+	}
+	_, err = crypt.Decrypt(armored, &crypt.Keyring{Identities: []crypt.PrivateKey{eve}})
+	if err != crypt.ErrNoMatchingID {
+		t.Errorf("crypt.Decrypt(): err = %v, want %v", err, crypt.ErrNoMatchingID)
+	}
+}
+
+func TestArmorErrors(t *testing.T) {
+	if _, err := crypt.Encrypt([]byte("text"), &crypt.Keyring{}); err != crypt.ErrNoSecrets {
+		t.Errorf("crypt.Encrypt(): err = %v, want %v", err, crypt.ErrNoSecrets)
+	}
+	k := &crypt.Keyring{Passphrase: []byte("WfW0d")}
+	armored, err := crypt.Encrypt([]byte("text"), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(armored, "\n")
+	// the header is authenticated with the body.
+	headerTampered := strings.Replace(armored, "scrypt ", "scrypt  ", 1)
+
+	tcs := []struct {
+		name string
+		text string
+		err  error
+	}{
+		{"plain", "text", crypt.ErrNotArmored},
+		{"no end", strings.Replace(armored, crypt.ArmorEnd, "", 1), crypt.ErrBadArmor},
+		{"no header", crypt.ArmorBegin + "\n\nAAAA\n" + crypt.ArmorEnd, crypt.ErrBadArmor},
+		{"bad body", strings.Replace(armored, lines[3], "!!!", 1), crypt.ErrBadArmor},
+		{"text after end", armored + "more", crypt.ErrBadArmor},
+		{"tampered header", headerTampered, crypt.ErrAuthentication},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := crypt.Decrypt(tc.text, k); err != tc.err {
+				t.Errorf("crypt.Decrypt(): err = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package crypt provides authenticated encryption for the data gistflow keeps
+// or sends. Keys are derived from passphrases with scrypt and data is sealed
+// with AES-256-GCM.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Sizes of the keys and salts.
+const (
+	KeySize  = 32
+	SaltSize = 16
+)
+
+// Errors for crypt.
+var (
+	ErrEmptyPassphrase = errors.New("passphrase cannot be empty")
+	ErrBadKeySize      = errors.New("key should be 32 bytes")
+	ErrBadSaltSize     = errors.New("salt should be 16 bytes")
+	ErrShortCipherText = errors.New("cipher text is too short")
+	ErrAuthentication  = errors.New("message authentication failed")
+)
+
+// NewSalt returns a new random salt.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey returns a key derived from the passphrase and the salt.
+func DeriveKey(passphrase, salt []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	if len(salt) != SaltSize {
+		return nil, ErrBadSaltSize
+	}
+	return scrypt.Key(passphrase, salt, 1<<15, 8, 1, KeySize)
+}
+
+// ReadKeyFile returns the contents of the key file at name, which can be used
+// as a passphrase.
+func ReadKeyFile(name string) ([]byte, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	return b, nil
+}
+
+// Seal encrypts and authenticates the plain text, and authenticates the
+// additional data. The nonce is prepended to the returning cipher text.
+func Seal(key, plain, additional []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+// Open decrypts the cipher text created by Seal. It returns ErrAuthentication
+// if the cipher text or the additional data has been tampered with, or the key
+// is wrong.
+func Open(key, cipherText, additional []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherText) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrShortCipherText
+	}
+	nonce, body := cipherText[:aead.NonceSize()], cipherText[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, body, additional)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return plain, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrBadKeySize
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package crypt_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/arsham/gistflow/crypt"
+)
+
+func testKey(t *testing.T, passphrase string) []byte {
+	salt, err := crypt.NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypt.DeriveKey([]byte(passphrase), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt, err := crypt.NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypt.DeriveKey(nil, salt); err != crypt.ErrEmptyPassphrase {
+		t.Errorf("crypt.DeriveKey(): err = %v, want %v", err, crypt.ErrEmptyPassphrase)
+	}
+	if _, err := crypt.DeriveKey([]byte("Ub4y"), salt[:3]); err != crypt.ErrBadSaltSize {
+		t.Errorf("crypt.DeriveKey(): err = %v, want %v", err, crypt.ErrBadSaltSize)
+	}
+	key1, err := crypt.DeriveKey([]byte("Ub4y"), salt)
+	if err != nil {
+		t.Fatalf("crypt.DeriveKey(): err = %v, want nil", err)
+	}
+	if len(key1) != crypt.KeySize {
+		t.Errorf("len(key) = %d, want %d", len(key1), crypt.KeySize)
+	}
+	key2, _ := crypt.DeriveKey([]byte("Ub4y"), salt)
+	if !bytes.Equal(key1, key2) {
+		t.Error("same passphrase and salt resulted in different keys")
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	var (
+		key   = testKey(t, "MEUcGmJaB6")
+		plain = []byte("lXNEVf3bKGyx0")
+		ad    = []byte("id")
+	)
+	sealed, err := crypt.Seal(key, plain, ad)
+	if err != nil {
+		t.Fatalf("crypt.Seal(): err = %v, want nil", err)
+	}
+	if bytes.Contains(sealed, plain) {
+		t.Error("sealed text contains the plain text")
+	}
+	got, err := crypt.Open(key, sealed, ad)
+	if err != nil {
+		t.Fatalf("crypt.Open(): err = %v, want nil", err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Errorf("crypt.Open() = %s, want %s", got, plain)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	var (
+		key   = testKey(t, "uJ8Jxy")
+		plain = []byte("Hkdn94hGjT")
+		ad    = []byte("a4ZQdq")
+	)
+	sealed, err := crypt.Seal(key, plain, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tcs := []struct {
+		name string
+		key  []byte
+		text []byte
+		ad   []byte
+		err  error
+	}{
+		{"bad key size", key[:10], sealed, ad, crypt.ErrBadKeySize},
+		{"short", key, sealed[:5], ad, crypt.ErrShortCipherText},
+		{"tampered", key, tampered, ad, crypt.ErrAuthentication},
+		{"wrong additional data", key, sealed, []byte("other"), crypt.ErrAuthentication},
+		{"wrong key", testKey(t, "other"), sealed, ad, crypt.ErrAuthentication},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := crypt.Open(tc.key, tc.text, tc.ad); err != tc.err {
+				t.Errorf("crypt.Open(): err = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := crypt.ReadKeyFile(f.Name()); err != crypt.ErrEmptyPassphrase {
+		t.Errorf("crypt.ReadKeyFile(): err = %v, want %v", err, crypt.ErrEmptyPassphrase)
+	}
+	f.Write([]byte("4pUYJ2ZuVh"))
+	f.Close()
+	b, err := crypt.ReadKeyFile(f.Name())
+	if err != nil {
+		t.Errorf("crypt.ReadKeyFile(): err = %v, want nil", err)
+	}
+	if string(b) != "4pUYJ2ZuVh" {
+		t.Errorf("crypt.ReadKeyFile() = %s, want 4pUYJ2ZuVh", b)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package diff compares texts line by line, and groups their differences in
+// hunks that can be shown as unified diffs or reverted one at a time.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Op is what happens to a line going from the old text to the new one.
+type Op int
+
+// Operations on lines.
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Line is a line of either text. Its Text has the newline it ends with, unless
+// it is the last line of a text without one.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Hunk is a group of changes with the equal lines around them. Old and New are
+// the indexes of the first lines of the hunk in the old and the new texts,
+// starting from 0.
+type Hunk struct {
+	Old, OldLines int
+	New, NewLines int
+	Lines         []Line
+}
+
+// maxEdits is the number of changed lines after which texts are treated as
+// completely different, to keep comparing large texts fast.
+const maxEdits = 2000
+
+// lines splits the text after its newlines.
+func lines(text string) []string {
+	l := strings.SplitAfter(text, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+// Compare returns the lines of both texts, with the lines that are only in
+// the old text marked as deleted and the ones only in the new text as
+// inserted. The number of changes is kept to a minimum.
+func Compare(a, b string) []Line {
+	x, y := lines(a), lines(b)
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	var result []Line
+	for _, l := range x[:prefix] {
+		result = append(result, Line{Equal, l})
+	}
+	result = append(result, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, l := range x[len(x)-suffix:] {
+		result = append(result, Line{Equal, l})
+	}
+	return result
+}
+
+// myers returns the shortest edit script of the lines with Myers' algorithm.
+// The state of each round is kept for walking the script back from the end.
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+	offset := total + 1
+	v := make([]int, 2*total+3)
+	var trace [][]int // v[-d:d+1] at the start of each round d.
+	for d := 0; d <= total; d++ {
+		if d > maxEdits {
+			return replace(a, b)
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replace(a, b)
+}
+
+func backtrack(trace [][]int, a, b []string) []Line {
+	var reversed []Line
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		prev := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prev = k + 1
+		}
+		prevX := at(prev)
+		prevY := prevX - prev
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Insert, b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Line{Delete, a[x-1]})
+			x--
+		}
+	}
+	for ; x > 0; x-- {
+		reversed = append(reversed, Line{Equal, a[x-1]})
+	}
+	result := make([]Line, len(reversed))
+	for i, l := range reversed {
+		result[len(reversed)-1-i] = l
+	}
+	return result
+}
+
+// replace returns a script deleting all the lines of a and inserting all the
+// lines of b.
+func replace(a, b []string) []Line {
+	result := make([]Line, 0, len(a)+len(b))
+	for _, l := range a {
+		result = append(result, Line{Delete, l})
+	}
+	for _, l := range b {
+		result = append(result, Line{Insert, l})
+	}
+	return result
+}
+
+// Hunks returns the changes between the texts, with up to context equal lines
+// around them. Changes closer than twice the context are in the same hunk.
+func Hunks(a, b string, context int) []Hunk {
+	script := Compare(a, b)
+	// oldAt[i] and newAt[i] are the numbers of the lines before script[i].
+	oldAt, newAt := make([]int, len(script)+1), make([]int, len(script)+1)
+	for i, l := range script {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if l.Op != Insert {
+			oldAt[i+1]++
+		}
+		if l.Op != Delete {
+			newAt[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(script); {
+		if script[i].Op == Equal {
+			i++
+			continue
+		}
+		end := i + 1
+		for j := end; j < len(script); j++ {
+			if script[j].Op != Equal {
+				end = j + 1
+			} else if j-end+1 > 2*context {
+				break
+			}
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		stop := end + context
+		if stop > len(script) {
+			stop = len(script)
+		}
+		hunks = append(hunks, Hunk{
+			Old:      oldAt[start],
+			OldLines: oldAt[stop] - oldAt[start],
+			New:      newAt[start],
+			NewLines: newAt[stop] - newAt[start],
+			Lines:    script[start:stop],
+		})
+		i = stop
+	}
+	return hunks
+}
+
+// Header returns the header of the hunk in a unified diff, like
+// "@@ -1,3 +1,4 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", span(h.Old, h.OldLines), span(h.New, h.NewLines))
+}
+
+// span returns the range of lines as shown in hunk headers. Empty ranges
+// start at the line before them.
+func span(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// Unified returns the unified diff of the texts with three lines of context,
+// or an empty string if they are the same.
+func Unified(oldName, newName, a, b string) string {
+	hunks := Hunks(a, b, 3)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		buf.WriteString(h.Header() + "\n")
+		for _, l := range h.Lines {
+			buf.WriteString(l.String())
+		}
+	}
+	return buf.String()
+}
+
+var marks = map[Op]string{Equal: " ", Delete: "-", Insert: "+"}
+
+// String returns the line as it is shown in a unified diff.
+func (l Line) String() string {
+	s := marks[l.Op] + l.Text
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n\\ No newline at end of file\n"
+	}
+	return s
+}
+
+// Revert returns the new text b with the lines of the hunk as they were in the
+// old text. The hunk should be one of the hunks of b, and the other hunks are
+// left as they are.
+func Revert(b string, h Hunk) string {
+	l := lines(b)
+	var buf bytes.Buffer
+	for _, s := range l[:h.New] {
+		buf.WriteString(s)
+	}
+	for _, line := range h.Lines {
+		if line.Op != Insert {
+			buf.WriteString(line.Text)
+		}
+	}
+	for _, s := range l[h.New+h.NewLines:] {
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/diff"
+)
+
+// script returns the lines as they are shown in unified diffs, without their
+// newlines.
+func script(lines []diff.Line) []string {
+	var s []string
+	for _, l := range lines {
+		s = append(s, strings.TrimSuffix(l.String(), "\n"))
+	}
+	return s
+}
+
+func TestCompare(t *testing.T) {
+	tcs := []struct {
+		name, a, b string
+		want       []string
+	}{
+		{"same", "a\nb\n", "a\nb\n", []string{" a", " b"}},
+		{"empty", "", "", nil},
+		{"added", "", "a\nb\n", []string{"+a", "+b"}},
+		{"deleted", "a\n", "", []string{"-a"}},
+		{"changed", "a\nb\nc\n", "a\nx\nc\n", []string{" a", "-b", "+x", " c"}},
+		{"inserted", "a\nc\n", "a\nb\nc\n", []string{" a", "+b", " c"}},
+		{"moved", "a\nb\nc\n", "b\nc\na\n", []string{"-a", " b", " c", "+a"}},
+		{"newline", "a\n", "a", []string{"-a", "+a\n\\ No newline at end of file"}},
+		{"repeated", "x\na\nx\nb\nx\n", "x\nb\nx\na\nx\n", []string{" x", "-a", "-x", " b", "+x", "+a", " x"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := script(diff.Compare(tc.a, tc.b)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Compare() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// numbered returns the lines from 1 to n.
+func numbered(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i + 1)
+	}
+	return lines
+}
+
+func TestHunks(t *testing.T) {
+	old := numbered(20)
+	lines := numbered(20)
+	lines[1] = "two"                          // a hunk at the start.
+	lines = append(lines[:10], lines[11:]...) // deleting line 11,
+	lines[11] = "thirteen"                    // close to this change.
+	lines = append(lines, "21")               // adding to the end.
+	a, b := strings.Join(old, "\n")+"\n", strings.Join(lines, "\n")+"\n"
+
+	hunks := diff.Hunks(a, b, 3)
+	var headers []string
+	for _, h := range hunks {
+		headers = append(headers, h.Header())
+	}
+	want := []string{"@@ -1,5 +1,5 @@", "@@ -8,9 +8,8 @@", "@@ -18,3 +17,4 @@"}
+	if !reflect.DeepEqual(headers, want) {
+		t.Fatalf("headers = %q, want %q", headers, want)
+	}
+
+	for i, h := range hunks {
+		got := diff.Revert(b, h)
+		wantHunks := len(hunks) - 1
+		if n := len(diff.Hunks(a, got, 3)); n != wantHunks {
+			t.Errorf("%d: %d hunks after reverting, want %d", i, n, wantHunks)
+		}
+	}
+	reverted := b
+	for i := len(hunks) - 1; i >= 0; i-- {
+		reverted = diff.Revert(reverted, hunks[i])
+	}
+	if reverted != a {
+		t.Errorf("reverting all the hunks = %q, want %q", reverted, a)
+	}
+}
+
+func TestHunkHeader(t *testing.T) {
+	tcs := []struct {
+		a, b, want string
+	}{
+		{"", "a\n", "@@ -0,0 +1 @@"},
+		{"a\n", "", "@@ -1 +0,0 @@"},
+		{"a\nb\n", "a\n", "@@ -1,2 +1 @@"},
+		{"a\nb\n", "b\n", "@@ -1,2 +1 @@"},
+	}
+	for _, tc := range tcs {
+		hunks := diff.Hunks(tc.a, tc.b, 3)
+		if len(hunks) != 1 {
+			t.Errorf("Hunks(%q, %q) = %d hunks, want 1", tc.a, tc.b, len(hunks))
+			continue
+		}
+		if got := hunks[0].Header(); got != tc.want {
+			t.Errorf("Header() = %q, want %q", got, tc.want)
+		}
+		if got := diff.Revert(tc.b, hunks[0]); got != tc.a {
+			t.Errorf("Revert() = %q, want %q", got, tc.a)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	if got := diff.Unified("a.go", "a.go", "x\n", "x\n"); got != "" {
+		t.Errorf("Unified() = %q for the same texts, want empty", got)
+	}
+	want := "--- a.go\n+++ b.go\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"
+	if got := diff.Unified("a.go", "b.go", "a\nb\n", "a\nc\n"); got != want {
+		t.Errorf("Unified() = %q, want %q", got, want)
+	}
+}
+
+func TestCompareLarge(t *testing.T) {
+	a := strings.Join(numbered(10000), "\n")
+	b := strings.Replace(strings.Replace(a, "\n", "\nx\n", -1), "5", "6", -1)
+	lines := diff.Compare(a, b)
+	var x, y []string
+	for _, l := range lines {
+		if l.Op != diff.Insert {
+			x = append(x, l.Text)
+		}
+		if l.Op != diff.Delete {
+			y = append(y, l.Text)
+		}
+	}
+	if strings.Join(x, "") != a || strings.Join(y, "") != b {
+		t.Error("the lines don't make the texts")
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arsham/gistflow/crypt"
+)
+
+// These files are kept inside the cache directory. They start with a dot so
+// they are never mistaken for cache entries.
+const (
+	pinFile   = ".pinned" // list of pinned gist IDs.
+	saltFile  = ".salt"   // salt for deriving the encryption key.
+	checkFile = ".check"  // sealed checkText for verifying the passphrase.
+)
+
+var checkText = []byte("gistflow")
+
+// cacheMu serialises all writes and evictions on cache directories.
+var cacheMu sync.Mutex
+
+// Cache manages the cached gist responses on the disk. Each entry is stored in
+// a file named after the gist ID. When MaxSize is set, the least recently used
+// entries are evicted to keep the directory under MaxSize bytes. Pinned entries
+// are never evicted, so they stay available offline.
+//
+// When the cache is encrypted, entries are sealed with the Key and
+// authenticated against their IDs, therefore swapped or modified entries are
+// detected. The Key is set by unlocking the cache with a passphrase.
+type Cache struct {
+	Dir     string
+	MaxSize int64  // in bytes, zero means no limit.
+	Key     []byte // encryption key, set by Unlock.
+}
+
+// CacheEntry is the information about one cached gist.
+type CacheEntry struct {
+	ID       string
+	Size     int64
+	Accessed time.Time
+	Pinned   bool
+}
+
+// Read returns the contents of the entry identified by id. It marks the entry
+// as recently used.
+func (c *Cache) Read(id string) ([]byte, error) {
+	if c.Dir == "" {
+		return nil, ErrEmptyCacheLoc
+	}
+	name := path.Join(c.Dir, id)
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, ErrCacheNotExists
+	}
+	defer file.Close()
+	now := time.Now()
+	os.Chtimes(name, now, now)
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return c.open(id, contents)
+}
+
+// Write stores contents as the entry for id, and evicts old entries if the
+// cache has grown over its limit.
+func (c *Cache) Write(id string, contents []byte) error {
+	if c.Dir == "" {
+		return ErrEmptyCacheLoc
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	contents, err := c.seal(id, contents)
+	if err != nil {
+		return err
+	}
+	name := path.Join(c.Dir, id)
+	if err := ioutil.WriteFile(name, contents, 0640); err != nil {
+		return err
+	}
+	return c.compact()
+}
+
+// Delete removes the entry for id and its pin.
+func (c *Cache) Delete(id string) error {
+	if c.Dir == "" {
+		return ErrEmptyCacheLoc
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	os.Remove(path.Join(c.Dir, id))
+	pins, err := c.pins()
+	if err != nil {
+		return err
+	}
+	if _, ok := pins[id]; ok {
+		delete(pins, id)
+		return c.savePins(pins)
+	}
+	return nil
+}
+
+// Entries returns all entries in the cache, the least recently used first.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	if c.Dir == "" {
+		return nil, ErrEmptyCacheLoc
+	}
+	files, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := c.pins()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CacheEntry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		_, pinned := pins[f.Name()]
+		entries = append(entries, CacheEntry{
+			ID:       f.Name(),
+			Size:     f.Size(),
+			Accessed: f.ModTime(),
+			Pinned:   pinned,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Accessed.Before(entries[j].Accessed)
+	})
+	return entries, nil
+}
+
+// Size returns the total size of the cache entries in bytes.
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	return size, nil
+}
+
+// Clear removes all entries that are not pinned.
+func (c *Cache) Clear() error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Pinned {
+			continue
+		}
+		if err := os.Remove(path.Join(c.Dir, e.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compact evicts the least recently used entries until the cache is under its
+// limit. It is a no-op if MaxSize is zero.
+func (c *Cache) Compact() error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return c.compact()
+}
+
+func (c *Cache) compact() error {
+	if c.MaxSize <= 0 {
+		return nil
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	for _, e := range entries {
+		if size <= c.MaxSize {
+			break
+		}
+		if e.Pinned {
+			continue
+		}
+		if err := os.Remove(path.Join(c.Dir, e.ID)); err != nil {
+			return err
+		}
+		size -= e.Size
+	}
+	return nil
+}
+
+// Prune removes the entries that are not in ids. This should be called after a
+// full sync to clean up the gists that were deleted elsewhere. Pinned entries
+// and their pins are kept, as they are the offline copies of the gists.
+func (c *Cache) Prune(ids []string) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		keep[id] = struct{}{}
+	}
+	for _, e := range entries {
+		if _, ok := keep[e.ID]; ok || e.Pinned {
+			continue
+		}
+		if err := os.Remove(path.Join(c.Dir, e.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pin protects the entry for id from eviction.
+func (c *Cache) Pin(id string) error { return c.setPin(id, true) }
+
+// Unpin lets the entry for id to be evicted again.
+func (c *Cache) Unpin(id string) error { return c.setPin(id, false) }
+
+// Pinned returns true if the entry for id is pinned.
+func (c *Cache) Pinned(id string) bool {
+	pins, err := c.pins()
+	if err != nil {
+		return false
+	}
+	_, ok := pins[id]
+	return ok
+}
+
+func (c *Cache) setPin(id string, pin bool) error {
+	if c.Dir == "" {
+		return ErrEmptyCacheLoc
+	}
+	if id == "" {
+		return ErrEmptyID
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	pins, err := c.pins()
+	if err != nil {
+		return err
+	}
+	if pin {
+		pins[id] = struct{}{}
+	} else {
+		delete(pins, id)
+	}
+	return c.savePins(pins)
+}
+
+func (c *Cache) pins() (map[string]struct{}, error) {
+	pins := make(map[string]struct{})
+	if c.Dir == "" {
+		return pins, ErrEmptyCacheLoc
+	}
+	b, err := ioutil.ReadFile(path.Join(c.Dir, pinFile))
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return pins, err
+	}
+	var ids []string
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return pins, err
+	}
+	for _, id := range ids {
+		pins[id] = struct{}{}
+	}
+	return pins, nil
+}
+
+func (c *Cache) savePins(pins map[string]struct{}) error {
+	ids := make([]string, 0, len(pins))
+	for id := range pins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(c.Dir, pinFile), b, 0640)
+}
+
+// Encrypted returns true if the cache entries are encrypted.
+func (c *Cache) Encrypted() bool {
+	if c.Dir == "" {
+		return false
+	}
+	_, err := os.Stat(path.Join(c.Dir, checkFile))
+	return err == nil
+}
+
+// Unlock derives the Key from the secret. If the cache is not encrypted yet,
+// all existing entries are encrypted with the new key. It returns
+// ErrBadPassphrase if the secret does not match the one the cache was
+// encrypted with.
+func (c *Cache) Unlock(secret []byte) error {
+	if c.Dir == "" {
+		return ErrEmptyCacheLoc
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if !c.Encrypted() {
+		return c.encrypt(secret)
+	}
+	salt, err := ioutil.ReadFile(path.Join(c.Dir, saltFile))
+	if err != nil {
+		return err
+	}
+	key, err := crypt.DeriveKey(secret, salt)
+	if err != nil {
+		return err
+	}
+	check, err := ioutil.ReadFile(path.Join(c.Dir, checkFile))
+	if err != nil {
+		return err
+	}
+	if _, err := crypt.Open(key, check, nil); err != nil {
+		return ErrBadPassphrase
+	}
+	c.Key = key
+	return nil
+}
+
+// Decrypt turns the cache back to plain text. If the cache is locked, the
+// entries are removed as they cannot be read anymore.
+func (c *Cache) Decrypt() error {
+	if c.Dir == "" {
+		return ErrEmptyCacheLoc
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if !c.Encrypted() {
+		return nil
+	}
+	err := c.convert(func(id string, contents []byte) ([]byte, error) {
+		if c.Key == nil {
+			return nil, ErrCacheLocked
+		}
+		return crypt.Open(c.Key, contents, []byte(id))
+	})
+	if err != nil {
+		return err
+	}
+	c.Key = nil
+	os.Remove(path.Join(c.Dir, saltFile))
+	return os.Remove(path.Join(c.Dir, checkFile))
+}
+
+func (c *Cache) encrypt(secret []byte) error {
+	salt, err := crypt.NewSalt()
+	if err != nil {
+		return err
+	}
+	key, err := crypt.DeriveKey(secret, salt)
+	if err != nil {
+		return err
+	}
+	check, err := crypt.Seal(key, checkText, nil)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(c.Dir, saltFile), salt, 0640); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(c.Dir, checkFile), check, 0640); err != nil {
+		return err
+	}
+	c.Key = key
+	return c.convert(func(id string, contents []byte) ([]byte, error) {
+		return crypt.Seal(key, contents, []byte(id))
+	})
+}
+
+// convert rewrites all entries with the result of fn. Entries that fn fails on
+// are removed.
+func (c *Cache) convert(fn func(id string, contents []byte) ([]byte, error)) error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := path.Join(c.Dir, e.ID)
+		contents, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		contents, err = fn(e.ID, contents)
+		if err != nil {
+			os.Remove(name)
+			continue
+		}
+		if err := ioutil.WriteFile(name, contents, 0640); err != nil {
+			return err
+		}
+		os.Chtimes(name, e.Accessed, e.Accessed)
+	}
+	return nil
+}
+
+func (c *Cache) seal(id string, contents []byte) ([]byte, error) {
+	if c.Key == nil {
+		if c.Encrypted() {
+			return nil, ErrCacheLocked
+		}
+		return contents, nil
+	}
+	return crypt.Seal(c.Key, contents, []byte(id))
+}
+
+func (c *Cache) open(id string, contents []byte) ([]byte, error) {
+	if c.Key == nil {
+		if c.Encrypted() {
+			return nil, ErrCacheLocked
+		}
+		return contents, nil
+	}
+	plain, err := crypt.Open(c.Key, contents, []byte(id))
+	if err != nil {
+		return nil, ErrCacheTampered
+	}
+	return plain, nil
+}
@@ -0,0 +1,314 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func tempCache(t *testing.T, maxSize int64) (*gist.Cache, func()) {
+	loc, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gist.Cache{Dir: loc, MaxSize: maxSize}, func() { os.RemoveAll(loc) }
+}
+
+// age sets the access time of the entry for id to d in the past.
+func age(t *testing.T, c *gist.Cache, id string, d time.Duration) {
+	then := time.Now().Add(-d)
+	if err := os.Chtimes(path.Join(c.Dir, id), then, then); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasEntry(c *gist.Cache, id string) bool {
+	_, err := os.Stat(path.Join(c.Dir, id))
+	return err == nil
+}
+
+func TestCacheEmptyLocation(t *testing.T) {
+	c := &gist.Cache{}
+	if _, err := c.Read("id"); err != gist.ErrEmptyCacheLoc {
+		t.Errorf("c.Read(): err = %v, want %v", err, gist.ErrEmptyCacheLoc)
+	}
+	if err := c.Write("id", nil); err != gist.ErrEmptyCacheLoc {
+		t.Errorf("c.Write(): err = %v, want %v", err, gist.ErrEmptyCacheLoc)
+	}
+	if err := c.Pin("id"); err != gist.ErrEmptyCacheLoc {
+		t.Errorf("c.Pin(): err = %v, want %v", err, gist.ErrEmptyCacheLoc)
+	}
+}
+
+func TestCacheReadWrite(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	id := "tbKdJ1Hr"
+	if _, err := c.Read(id); err != gist.ErrCacheNotExists {
+		t.Errorf("c.Read(): err = %v, want %v", err, gist.ErrCacheNotExists)
+	}
+	if err := c.Write(id, []byte("a long content")); err != nil {
+		t.Fatalf("c.Write(): err = %v, want nil", err)
+	}
+	if err := c.Write(id, []byte("short")); err != nil {
+		t.Fatalf("c.Write(): err = %v, want nil", err)
+	}
+	b, err := c.Read(id)
+	if err != nil {
+		t.Errorf("c.Read(): err = %v, want nil", err)
+	}
+	if string(b) != "short" {
+		t.Errorf("c.Read() = %s, want short", b)
+	}
+	size, err := c.Size()
+	if err != nil {
+		t.Errorf("c.Size(): err = %v, want nil", err)
+	}
+	if size != int64(len("short")) {
+		t.Errorf("c.Size() = %d, want %d", size, len("short"))
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	content := []byte(strings.Repeat("A", 10))
+	for _, id := range []string{"old", "mid", "pinned"} {
+		if err := c.Write(id, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	age(t, c, "pinned", 3*time.Hour)
+	age(t, c, "old", 2*time.Hour)
+	age(t, c, "mid", time.Hour)
+	if err := c.Pin("pinned"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.MaxSize = 30
+	if err := c.Write("new", content); err != nil {
+		t.Fatalf("c.Write(): err = %v, want nil", err)
+	}
+	if hasEntry(c, "old") {
+		t.Error("least recently used entry was not evicted")
+	}
+	for _, id := range []string{"mid", "pinned", "new"} {
+		if !hasEntry(c, id) {
+			t.Errorf("%s was evicted", id)
+		}
+	}
+
+	c.MaxSize = 5
+	if err := c.Compact(); err != nil {
+		t.Fatalf("c.Compact(): err = %v, want nil", err)
+	}
+	if !hasEntry(c, "pinned") {
+		t.Error("pinned entry was evicted")
+	}
+	if hasEntry(c, "mid") || hasEntry(c, "new") {
+		t.Error("expected all unpinned entries to be evicted")
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	c.Write("id1", []byte("content"))
+	c.Write("id2", []byte("content"))
+	c.Pin("id2")
+	if err := c.Clear(); err != nil {
+		t.Fatalf("c.Clear(): err = %v, want nil", err)
+	}
+	if hasEntry(c, "id1") {
+		t.Error("id1 was not cleared")
+	}
+	if !hasEntry(c, "id2") {
+		t.Error("pinned entry was cleared")
+	}
+}
+
+func TestCachePins(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	id := "Npq3mFKj"
+	if c.Pinned(id) {
+		t.Errorf("c.Pinned(%s) = true, want false", id)
+	}
+	if err := c.Pin(id); err != nil {
+		t.Errorf("c.Pin(): err = %v, want nil", err)
+	}
+	if !c.Pinned(id) {
+		t.Errorf("c.Pinned(%s) = false, want true", id)
+	}
+	if err := c.Unpin(id); err != nil {
+		t.Errorf("c.Unpin(): err = %v, want nil", err)
+	}
+	if c.Pinned(id) {
+		t.Errorf("c.Pinned(%s) = true, want false", id)
+	}
+	c.Pin(id)
+	if err := c.Delete(id); err != nil {
+		t.Errorf("c.Delete(): err = %v, want nil", err)
+	}
+	if c.Pinned(id) {
+		t.Error("deleting the entry did not remove the pin")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	for _, id := range []string{"keep", "orphan", "pinnedOrphan"} {
+		c.Write(id, []byte("content"))
+	}
+	c.Pin("pinnedOrphan")
+	if err := c.Prune([]string{"keep", "notCached"}); err != nil {
+		t.Fatalf("c.Prune(): err = %v, want nil", err)
+	}
+	if !hasEntry(c, "keep") {
+		t.Error("keep was removed")
+	}
+	if hasEntry(c, "orphan") {
+		t.Error("orphan was not removed")
+	}
+	if !hasEntry(c, "pinnedOrphan") || !c.Pinned("pinnedOrphan") {
+		t.Error("the pinned entry was removed")
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("len(entries) = %d, want 2", len(entries))
+	}
+}
+
+func TestCacheEncryption(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	var (
+		secret  = []byte("VBxyGnzdbz")
+		plain   = []byte(`{"id":"pinnedID"}`)
+		content = []byte(`{"id":"anotherID"}`)
+	)
+	if err := c.Write("pinnedID", plain); err != nil {
+		t.Fatal(err)
+	}
+	if c.Encrypted() {
+		t.Error("c.Encrypted() = true, want false")
+	}
+	if err := c.Unlock(secret); err != nil {
+		t.Fatalf("c.Unlock(): err = %v, want nil", err)
+	}
+	if !c.Encrypted() {
+		t.Error("c.Encrypted() = false, want true")
+	}
+	raw, err := ioutil.ReadFile(path.Join(c.Dir, "pinnedID"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "pinnedID") {
+		t.Error("existing entry was not encrypted")
+	}
+	if err := c.Write("anotherID", content); err != nil {
+		t.Fatalf("c.Write(): err = %v, want nil", err)
+	}
+
+	locked := &gist.Cache{Dir: c.Dir}
+	if _, err := locked.Read("anotherID"); err != gist.ErrCacheLocked {
+		t.Errorf("locked.Read(): err = %v, want %v", err, gist.ErrCacheLocked)
+	}
+	if err := locked.Write("anotherID", content); err != gist.ErrCacheLocked {
+		t.Errorf("locked.Write(): err = %v, want %v", err, gist.ErrCacheLocked)
+	}
+	if err := locked.Unlock([]byte("wrong")); err != gist.ErrBadPassphrase {
+		t.Errorf("locked.Unlock(): err = %v, want %v", err, gist.ErrBadPassphrase)
+	}
+	if err := locked.Unlock(secret); err != nil {
+		t.Fatalf("locked.Unlock(): err = %v, want nil", err)
+	}
+	b, err := locked.Read("pinnedID")
+	if err != nil {
+		t.Errorf("locked.Read(): err = %v, want nil", err)
+	}
+	if string(b) != string(plain) {
+		t.Errorf("locked.Read() = %s, want %s", b, plain)
+	}
+
+	// swapping two entries should be detected.
+	if err := os.Rename(path.Join(c.Dir, "anotherID"), path.Join(c.Dir, "pinnedID")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locked.Read("pinnedID"); err != gist.ErrCacheTampered {
+		t.Errorf("locked.Read(): err = %v, want %v", err, gist.ErrCacheTampered)
+	}
+}
+
+func TestCacheDecrypt(t *testing.T) {
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	content := []byte("9qBpwAq")
+	c.Unlock([]byte("Gx4k"))
+	c.Write("id", content)
+	if err := c.Decrypt(); err != nil {
+		t.Fatalf("c.Decrypt(): err = %v, want nil", err)
+	}
+	if c.Encrypted() {
+		t.Error("c.Encrypted() = true, want false")
+	}
+	raw, err := ioutil.ReadFile(path.Join(c.Dir, "id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != string(content) {
+		t.Errorf("entry = %s, want %s", raw, content)
+	}
+}
+
+func TestServiceLockedCache(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"id": "zGkqEwJ5"}`))
+	}))
+	defer ts.Close()
+	c, cleanup := tempCache(t, 0)
+	defer cleanup()
+	c.Unlock([]byte("ZRAzwGb"))
+
+	s := &gist.Service{
+		Username: "arsham",
+		Token:    "9iJrm",
+		API:      ts.URL,
+		CacheDir: c.Dir,
+		Logger: &logger{
+			warningFunc: func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := s.Get("zGkqEwJ5"); err != nil {
+			t.Errorf("s.Get(): err = %v, want nil", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	s.CacheKey = c.Key
+	s.Get("zGkqEwJ5")
+	s.Get("zGkqEwJ5")
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Comments fetches all comments of the gist identified by id.
+func (s *Service) Comments(id string) ([]Comment, error) {
+	if id == "" {
+		return nil, ErrEmptyID
+	}
+	url := s.withToken(s.gistURL(id) + "/comments")
+	r, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusNotFound {
+		return nil, ErrGistNotFound
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	var res []Comment
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddComment leaves a comment with the body on the gist identified by id.
+func (s *Service) AddComment(id, body string) (Comment, error) {
+	if id == "" {
+		return Comment{}, ErrEmptyID
+	}
+	b, err := json.Marshal(Comment{Body: body})
+	if err != nil {
+		return Comment{}, err
+	}
+	url := s.withToken(s.gistURL(id) + "/comments")
+	client := &http.Client{}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(b))
+	if err != nil {
+		return Comment{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return Comment{}, err
+	}
+	defer res.Body.Close()
+
+	reply, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Comment{}, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	default:
+		return Comment{}, fmt.Errorf("error adding comment: %s", reply)
+	}
+	var c Comment
+	if err := json.Unmarshal(reply, &c); err != nil {
+		return Comment{}, err
+	}
+	return c, nil
+}
+
+// Clone creates a new gist with the description and the files of g, with the
+// given visibility. The new gist is fetched again and verified against g. If
+// comments is true, the comments of g are copied to the new gist as a single
+// note. If any of the steps fail, the new gist is removed. The original gist
+// is never changed.
+func (s *Service) Clone(g Gist, public, comments bool) (Gist, error) {
+	want := Gist{
+		Description: g.Description,
+		Public:      public,
+		Files:       make(map[string]File, len(g.Files)),
+	}
+	for name, f := range g.Files {
+		// empty files are deletion marks and cannot be created.
+		if f.Content != "" {
+			want.Files[name] = File{Content: f.Content}
+		}
+	}
+	if len(want.Files) == 0 {
+		return Gist{}, ErrNoFiles
+	}
+	var list []Comment
+	if comments && g.ID != "" {
+		var err error
+		if list, err = s.Comments(g.ID); err != nil {
+			return Gist{}, errors.Wrap(err, "reading comments")
+		}
+	}
+
+	created, err := s.createVerified(want)
+	if err != nil {
+		return Gist{}, err
+	}
+	if len(list) > 0 {
+		if _, err := s.AddComment(created.ID, CommentsNote(g, list)); err != nil {
+			return Gist{}, rollback(errors.Wrap(err, "copying comments"), func() error {
+				return s.DeleteGist(created.ID)
+			})
+		}
+	}
+	return created, nil
+}
+
+// createVerified creates the gist, fetches it again and verifies it against
+// want. The new gist is removed if it does not match.
+func (s *Service) createVerified(want Gist) (Gist, error) {
+	created, err := s.Create(want)
+	if err != nil {
+		return Gist{}, err
+	}
+	undo := func() error { return s.DeleteGist(created.ID) }
+	got, err := s.Fetch(created.ID)
+	if err != nil {
+		return Gist{}, rollback(errors.Wrap(err, "verifying the new gist"), undo)
+	}
+	if err := Verify(want, got); err != nil {
+		return Gist{}, rollback(err, undo)
+	}
+	return got, nil
+}
+
+// Verify returns ErrCloneMismatch if got does not have the same description,
+// visibility and files of want.
+func Verify(want, got Gist) error {
+	if want.Description != got.Description {
+		return errors.Wrap(ErrCloneMismatch, "description")
+	}
+	if want.Public != got.Public {
+		return errors.Wrap(ErrCloneMismatch, "visibility")
+	}
+	if len(want.Files) != len(got.Files) {
+		return errors.Wrapf(ErrCloneMismatch, "got %d files, want %d", len(got.Files), len(want.Files))
+	}
+	for name, f := range want.Files {
+		if got.Files[name].Content != f.Content {
+			return errors.Wrapf(ErrCloneMismatch, "file %s", name)
+		}
+	}
+	return nil
+}
+
+// CommentsNote returns the text of a comment that holds the list of comments
+// of g.
+func CommentsNote(g Gist, list []Comment) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "Comments copied from %s\n", g.HTMLURL)
+	for _, c := range list {
+		fmt.Fprintf(buf, "\n**%s** commented on %s:\n", c.User.Login, c.CreatedAt)
+		for _, line := range strings.Split(c.Body, "\n") {
+			fmt.Fprintf(buf, "> %s\n", line)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,237 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/pkg/errors"
+)
+
+// gistServer keeps the gists created on it in memory.
+type gistServer struct {
+	gists    map[string]gist.Gist
+	comments map[string][]gist.Comment
+	deleted  []string
+	tamper   bool   // changes the contents of created gists.
+	fail     string // fails requests with this method and path.
+	created  int
+	url      string
+}
+
+// add stores the gist on the server with the URL pointing to the server.
+func (g *gistServer) add(in gist.Gist) gist.Gist {
+	in.URL = g.url + "/gists/" + in.ID
+	g.gists[in.ID] = in
+	return in
+}
+
+func (g *gistServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if g.fail == r.Method+" "+r.URL.Path {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 1:
+		var in gist.Gist
+		json.NewDecoder(r.Body).Decode(&in)
+		g.created++
+		in.ID = "clone"
+		if g.created > 1 {
+			in.ID = fmt.Sprintf("clone%d", g.created)
+		}
+		if g.tamper {
+			for name := range in.Files {
+				in.Files[name] = gist.File{Content: "tampered"}
+			}
+		}
+		in = g.add(in)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(in)
+	case r.Method == http.MethodPatch && len(parts) == 2:
+		var in gist.Gist
+		json.NewDecoder(r.Body).Decode(&in)
+		res, ok := g.gists[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		files := make(map[string]gist.File, len(res.Files))
+		for name, f := range res.Files {
+			files[name] = f
+		}
+		for name, f := range in.Files {
+			if f.Content == "" {
+				delete(files, name)
+				continue
+			}
+			files[name] = f
+		}
+		res.Files = files
+		g.gists[res.ID] = res
+		json.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodGet && len(parts) == 2:
+		res, ok := g.gists[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodDelete && len(parts) == 2:
+		delete(g.gists, parts[1])
+		g.deleted = append(g.deleted, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && len(parts) == 3:
+		json.NewEncoder(w).Encode(g.comments[parts[1]])
+	case r.Method == http.MethodPost && len(parts) == 3:
+		var c gist.Comment
+		json.NewDecoder(r.Body).Decode(&c)
+		g.comments[parts[1]] = append(g.comments[parts[1]], c)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newGistServer(t *testing.T) (*gistServer, *gist.Service, func()) {
+	g := &gistServer{
+		gists:    make(map[string]gist.Gist),
+		comments: make(map[string][]gist.Comment),
+	}
+	ts := httptest.NewServer(g)
+	g.url = ts.URL
+	loc, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &gist.Service{
+		Username: "arsham",
+		Token:    "Hk3mZ",
+		API:      ts.URL,
+		CacheDir: loc,
+		Logger:   getLogger(),
+	}
+	return g, s, func() {
+		ts.Close()
+		os.RemoveAll(loc)
+	}
+}
+
+func TestClone(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	orig := gist.Gist{
+		ID:          "original",
+		HTMLURL:     "https://gist.github.com/original",
+		Description: "Cw8EAzf",
+		Files: map[string]gist.File{
+			"a.go":    {Content: "package a"},
+			"removed": {},
+		},
+	}
+	srv.comments["original"] = []gist.Comment{
+		{Body: "first\nsecond", User: gist.User{Login: "jane"}, CreatedAt: "2018-05-01"},
+	}
+
+	got, err := s.Clone(orig, true, true)
+	if err != nil {
+		t.Fatalf("s.Clone(): err = %v, want nil", err)
+	}
+	if got.ID != "clone" {
+		t.Errorf("got.ID = %s, want clone", got.ID)
+	}
+	if !got.Public {
+		t.Error("got.Public = false, want true")
+	}
+	if len(got.Files) != 1 || got.Files["a.go"].Content != "package a" {
+		t.Errorf("got.Files = %v", got.Files)
+	}
+	notes := srv.comments["clone"]
+	if len(notes) != 1 {
+		t.Fatalf("len(notes) = %d, want 1", len(notes))
+	}
+	for _, want := range []string{orig.HTMLURL, "**jane**", "> first\n> second"} {
+		if !strings.Contains(notes[0].Body, want) {
+			t.Errorf("note = %q, want it to contain %q", notes[0].Body, want)
+		}
+	}
+	if len(srv.deleted) != 0 {
+		t.Errorf("srv.deleted = %v, want none", srv.deleted)
+	}
+}
+
+func TestCloneWithoutComments(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	srv.comments["original"] = []gist.Comment{{Body: "comment"}}
+	orig := gist.Gist{
+		ID:    "original",
+		Files: map[string]gist.File{"a": {Content: "b"}},
+	}
+	if _, err := s.Clone(orig, false, false); err != nil {
+		t.Fatalf("s.Clone(): err = %v, want nil", err)
+	}
+	if len(srv.comments["clone"]) != 0 {
+		t.Error("comments were copied")
+	}
+}
+
+func TestCloneErrors(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	if _, err := s.Clone(gist.Gist{}, true, false); err != gist.ErrNoFiles {
+		t.Errorf("s.Clone(): err = %v, want %v", err, gist.ErrNoFiles)
+	}
+
+	srv.tamper = true
+	orig := gist.Gist{Files: map[string]gist.File{"a": {Content: "b"}}}
+	_, err := s.Clone(orig, true, false)
+	if errors.Cause(err) != gist.ErrCloneMismatch {
+		t.Errorf("s.Clone(): err = %v, want %v", err, gist.ErrCloneMismatch)
+	}
+	if len(srv.deleted) != 1 || srv.deleted[0] != "clone" {
+		t.Errorf("srv.deleted = %v, want [clone]", srv.deleted)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	want := gist.Gist{
+		Description: "desc",
+		Files:       map[string]gist.File{"a": {Content: "b"}},
+	}
+	tcs := []struct {
+		name string
+		got  gist.Gist
+		ok   bool
+	}{
+		{"same", gist.Gist{Description: "desc", Files: map[string]gist.File{"a": {Content: "b"}}}, true},
+		{"description", gist.Gist{Description: "other", Files: map[string]gist.File{"a": {Content: "b"}}}, false},
+		{"visibility", gist.Gist{Description: "desc", Public: true, Files: map[string]gist.File{"a": {Content: "b"}}}, false},
+		{"content", gist.Gist{Description: "desc", Files: map[string]gist.File{"a": {Content: "c"}}}, false},
+		{"name", gist.Gist{Description: "desc", Files: map[string]gist.File{"c": {Content: "b"}}}, false},
+		{"extra file", gist.Gist{Description: "desc", Files: map[string]gist.File{"a": {Content: "b"}, "c": {}}}, false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := gist.Verify(want, tc.got)
+			if tc.ok && err != nil {
+				t.Errorf("gist.Verify(): err = %v, want nil", err)
+			}
+			if !tc.ok && errors.Cause(err) != gist.ErrCloneMismatch {
+				t.Errorf("gist.Verify(): err = %v, want %v", err, gist.ErrCloneMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/arsham/gistflow/crypt"
+	"github.com/pkg/errors"
+)
+
+// Draft is the unsaved state of a gist, kept on the disk so it can be restored
+// if the application stops before the gist is saved. Drafts of new gists have
+// no GistID.
+type Draft struct {
+	Key         string      `json:"key"` // name of the draft on the disk.
+	GistID      string      `json:"gist_id,omitempty"`
+	Description string      `json:"description"`
+	Public      bool        `json:"public,omitempty"`
+	Encrypted   bool        `json:"encrypted,omitempty"`
+	Files       []DraftFile `json:"files"`
+	Updated     time.Time   `json:"updated"`
+}
+
+// DraftFile is a file of a draft. SavedName is the name of the file in the
+// saved gist, which is empty for new files.
+type DraftFile struct {
+	Name      string `json:"name"`
+	SavedName string `json:"saved_name,omitempty"`
+	Content   string `json:"content"`
+}
+
+// Drafts keeps each draft in a file named after its key. When the Key is set,
+// the drafts are sealed with it like the cache entries, as they can have the
+// contents of encrypted gists.
+type Drafts struct {
+	Dir string
+	Key []byte
+}
+
+// Save writes the draft, replacing the previous one with the same key.
+func (d *Drafts) Save(draft Draft) error {
+	if d.Dir == "" {
+		return ErrEmptyDraftLoc
+	}
+	if !validKey(draft.Key) {
+		return ErrBadDraftKey
+	}
+	if err := os.MkdirAll(d.Dir, 0700); err != nil {
+		return errors.Wrap(err, "creating drafts directory")
+	}
+	contents, err := json.Marshal(draft)
+	if err != nil {
+		return errors.Wrap(err, "encoding draft")
+	}
+	if d.Key != nil {
+		if contents, err = crypt.Seal(d.Key, contents, []byte(draft.Key)); err != nil {
+			return err
+		}
+	}
+	// the draft is renamed in place, so a crash never leaves half of it.
+	name := path.Join(d.Dir, draft.Key)
+	if err := ioutil.WriteFile(name+".tmp", contents, 0600); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// Load returns all the drafts, the most recently updated first. Drafts that
+// can't be read are skipped, and ErrDraftLocked is returned with the rest if
+// any of them are sealed with another key or without a Key.
+func (d *Drafts) Load() ([]Draft, error) {
+	if d.Dir == "" {
+		return nil, ErrEmptyDraftLoc
+	}
+	files, err := ioutil.ReadDir(d.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		drafts []Draft
+		locked bool
+	)
+	for _, f := range files {
+		if f.IsDir() || !validKey(f.Name()) {
+			continue
+		}
+		contents, err := ioutil.ReadFile(path.Join(d.Dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var draft Draft
+		if json.Unmarshal(contents, &draft) != nil {
+			// the draft is sealed.
+			if d.Key == nil {
+				locked = true
+				continue
+			}
+			if contents, err = crypt.Open(d.Key, contents, []byte(f.Name())); err != nil {
+				locked = true
+				continue
+			}
+			if json.Unmarshal(contents, &draft) != nil {
+				continue
+			}
+		}
+		if draft.Key == f.Name() {
+			drafts = append(drafts, draft)
+		}
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].Updated.After(drafts[j].Updated)
+	})
+	if locked {
+		return drafts, ErrDraftLocked
+	}
+	return drafts, nil
+}
+
+// Remove deletes the draft with the key. It is not an error if there is no
+// such draft.
+func (d *Drafts) Remove(key string) error {
+	if d.Dir == "" {
+		return ErrEmptyDraftLoc
+	}
+	if !validKey(key) {
+		return ErrBadDraftKey
+	}
+	err := os.Remove(path.Join(d.Dir, key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// validKey returns true if the key can be the name of a draft file. Keys are
+// made of letters, digits and dashes, so they can't point outside the
+// directory.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func tempDrafts(t *testing.T) (*gist.Drafts, func()) {
+	loc, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gist.Drafts{Dir: path.Join(loc, "drafts")}, func() { os.RemoveAll(loc) }
+}
+
+func TestDraftsEmptyLocation(t *testing.T) {
+	d := &gist.Drafts{}
+	if err := d.Save(gist.Draft{Key: "a"}); err != gist.ErrEmptyDraftLoc {
+		t.Errorf("d.Save(): err = %v, want %v", err, gist.ErrEmptyDraftLoc)
+	}
+	if _, err := d.Load(); err != gist.ErrEmptyDraftLoc {
+		t.Errorf("d.Load(): err = %v, want %v", err, gist.ErrEmptyDraftLoc)
+	}
+	if err := d.Remove("a"); err != gist.ErrEmptyDraftLoc {
+		t.Errorf("d.Remove(): err = %v, want %v", err, gist.ErrEmptyDraftLoc)
+	}
+}
+
+func TestDraftsSaveLoad(t *testing.T) {
+	d, cleanup := tempDrafts(t)
+	defer cleanup()
+	if drafts, err := d.Load(); err != nil || len(drafts) != 0 {
+		t.Errorf("d.Load() = %v, %v, want no drafts before the first save", drafts, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	older := gist.Draft{
+		Key:         "new-1",
+		Description: "scratch",
+		Files:       []gist.DraftFile{{Name: "a.go", Content: "package a"}},
+		Updated:     now.Add(-time.Minute),
+	}
+	newer := gist.Draft{
+		Key:       "x1",
+		GistID:    "x1",
+		Encrypted: true,
+		Files: []gist.DraftFile{
+			{Name: "b.go", SavedName: "b.go", Content: "package b"},
+			{Name: "c.go", Content: ""},
+		},
+		Updated: now,
+	}
+	for _, draft := range []gist.Draft{older, newer} {
+		if err := d.Save(draft); err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := os.Stat(path.Join(d.Dir, "x1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	drafts, err := d.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []gist.Draft{newer, older}; !reflect.DeepEqual(drafts, want) {
+		t.Errorf("d.Load() = %v, want %v", drafts, want)
+	}
+
+	newer.Description = "updated"
+	if err := d.Save(newer); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Remove("new-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Remove("new-1"); err != nil {
+		t.Errorf("d.Remove() = %v for a removed draft, want nil", err)
+	}
+	drafts, err = d.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 1 || drafts[0].Description != "updated" {
+		t.Errorf("d.Load() = %v, want the updated draft", drafts)
+	}
+}
+
+func TestDraftsBadKey(t *testing.T) {
+	d, cleanup := tempDrafts(t)
+	defer cleanup()
+	for _, key := range []string{"", "../a", "a/b", "a.tmp", ".."} {
+		if err := d.Save(gist.Draft{Key: key}); err != gist.ErrBadDraftKey {
+			t.Errorf("d.Save(%q): err = %v, want %v", key, err, gist.ErrBadDraftKey)
+		}
+		if err := d.Remove(key); err != gist.ErrBadDraftKey {
+			t.Errorf("d.Remove(%q): err = %v, want %v", key, err, gist.ErrBadDraftKey)
+		}
+	}
+}
+
+func TestDraftsSealed(t *testing.T) {
+	d, cleanup := tempDrafts(t)
+	defer cleanup()
+	d.Key = make([]byte, 32)
+	draft := gist.Draft{Key: "s1", Files: []gist.DraftFile{{Name: "a", Content: "secret words"}}}
+	if err := d.Save(draft); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadFile(path.Join(d.Dir, "s1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret words") {
+		t.Errorf("the draft is not sealed: %q", raw)
+	}
+	drafts, err := d.Load()
+	if err != nil || len(drafts) != 1 || drafts[0].Files[0].Content != "secret words" {
+		t.Errorf("d.Load() = %v, %v, want the draft", drafts, err)
+	}
+
+	plain := &gist.Drafts{Dir: d.Dir}
+	if err := plain.Save(gist.Draft{Key: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	drafts, err = plain.Load()
+	if err != gist.ErrDraftLocked {
+		t.Errorf("err = %v, want %v", err, gist.ErrDraftLocked)
+	}
+	if len(drafts) != 1 || drafts[0].Key != "p1" {
+		t.Errorf("plain.Load() = %v, want the plain draft", drafts)
+	}
+
+	other := &gist.Drafts{Dir: d.Dir, Key: []byte("01234567890123456789012345678901")}
+	if _, err := other.Load(); err != gist.ErrDraftLocked {
+		t.Errorf("err = %v, want %v", err, gist.ErrDraftLocked)
+	}
+}
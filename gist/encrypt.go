@@ -0,0 +1,90 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"strings"
+
+	"github.com/arsham/gistflow/crypt"
+	"github.com/pkg/errors"
+)
+
+// EncryptedExt is appended to the names of the files of encrypted gists.
+const EncryptedExt = ".enc"
+
+// Encrypted returns true if the gist has files and all of them are encrypted.
+// Only the file names are checked, therefore it works on the gists of a list
+// response too.
+func (g Gist) Encrypted() bool {
+	if len(g.Files) == 0 {
+		return false
+	}
+	for name := range g.Files {
+		if !strings.HasSuffix(name, EncryptedExt) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encrypt returns a copy of the gist with all its files encrypted and
+// EncryptedExt added to their names. Empty files are kept empty, as they mark
+// the files for deletion.
+func Encrypt(g Gist, k *crypt.Keyring) (Gist, error) {
+	files := make(map[string]File, len(g.Files))
+	for name, f := range g.Files {
+		if f.Content == "" {
+			files[name+EncryptedExt] = File{}
+			continue
+		}
+		armored, err := crypt.Encrypt([]byte(f.Content), k)
+		if err != nil {
+			return Gist{}, errors.Wrapf(err, "encrypting %s", name)
+		}
+		files[name+EncryptedExt] = File{Content: armored}
+	}
+	g.Files = files
+	return g, nil
+}
+
+// Decrypt returns a copy of the encrypted gist with its files decrypted and
+// EncryptedExt removed from their names.
+func Decrypt(g Gist, k *crypt.Keyring) (Gist, error) {
+	files := make(map[string]File, len(g.Files))
+	for name, f := range g.Files {
+		plain := strings.TrimSuffix(name, EncryptedExt)
+		if f.Content == "" {
+			files[plain] = File{}
+			continue
+		}
+		b, err := crypt.Decrypt(f.Content, k)
+		if err != nil {
+			return Gist{}, errors.Wrapf(err, "decrypting %s", name)
+		}
+		files[plain] = File{Content: string(b)}
+	}
+	g.Files = files
+	return g, nil
+}
+
+// Patch returns a copy of local that can be sent to the API for updating the
+// remote gist. Files of remote that are not in local are marked for deletion,
+// and deletion marks for files that remote does not have are dropped.
+func Patch(local, remote Gist) Gist {
+	files := make(map[string]File, len(local.Files))
+	for name, f := range local.Files {
+		if _, ok := remote.Files[name]; !ok && f.Content == "" {
+			continue
+		}
+		files[name] = f
+	}
+	for name := range remote.Files {
+		if _, ok := local.Files[name]; !ok {
+			files[name] = File{}
+		}
+	}
+	local.Files = files
+	return local
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/crypt"
+	"github.com/arsham/gistflow/gist"
+)
+
+func TestEncryptGist(t *testing.T) {
+	k := &crypt.Keyring{Passphrase: []byte("c5wEZlIe")}
+	g := gist.Gist{
+		ID:          "lJcD2MQS",
+		Description: "vSyEdIKt",
+		Files: map[string]gist.File{
+			"main.go": gist.File{Content: "package main"},
+			"removed": gist.File{},
+		},
+	}
+	if g.Encrypted() {
+		t.Error("g.Encrypted() = true, want false")
+	}
+	enc, err := gist.Encrypt(g, k)
+	if err != nil {
+		t.Fatalf("gist.Encrypt(): err = %v, want nil", err)
+	}
+	if !enc.Encrypted() {
+		t.Error("enc.Encrypted() = false, want true")
+	}
+	if enc.Description != g.Description {
+		t.Errorf("enc.Description = %s, want %s", enc.Description, g.Description)
+	}
+	f, ok := enc.Files["main.go"+gist.EncryptedExt]
+	if !ok {
+		t.Fatalf("main.go was not renamed: %v", enc.Files)
+	}
+	if !crypt.IsArmored(f.Content) {
+		t.Errorf("content is not armored: %s", f.Content)
+	}
+	if enc.Files["removed"+gist.EncryptedExt] != (gist.File{}) {
+		t.Error("deletion mark was encrypted")
+	}
+	if _, ok := g.Files["main.go"]; !ok {
+		t.Error("the original gist was modified")
+	}
+
+	dec, err := gist.Decrypt(enc, k)
+	if err != nil {
+		t.Fatalf("gist.Decrypt(): err = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(dec, g) {
+		t.Errorf("gist.Decrypt() = %v, want %v", dec, g)
+	}
+	if _, err := gist.Decrypt(enc, &crypt.Keyring{Passphrase: []byte("wrong")}); err == nil {
+		t.Error("gist.Decrypt(): err = nil, want error")
+	}
+}
+
+func TestPatch(t *testing.T) {
+	remote := gist.Gist{
+		Files: map[string]gist.File{
+			"kept":    gist.File{Content: "old"},
+			"renamed": gist.File{Content: "old"},
+		},
+	}
+	local := gist.Gist{
+		Files: map[string]gist.File{
+			"kept":          gist.File{Content: "new"},
+			"renamed.enc":   gist.File{Content: "new"},
+			"never existed": gist.File{},
+		},
+	}
+	want := map[string]gist.File{
+		"kept":        gist.File{Content: "new"},
+		"renamed.enc": gist.File{Content: "new"},
+		"renamed":     gist.File{},
+	}
+	got := gist.Patch(local, remote)
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Errorf("gist.Patch() = %v, want %v", got.Files, want)
+	}
+}
@@ -16,4 +16,19 @@ var (
 	ErrPagination     = errors.New("pagination")
 	ErrEmptyCacheLoc  = errors.New("empty cache location")
 	ErrCacheNotExists = errors.New("cache file does not exists")
+	ErrCacheLocked    = errors.New("cache is locked")
+	ErrCacheTampered  = errors.New("cache entry has been tampered with")
+	ErrBadPassphrase  = errors.New("wrong passphrase")
+	ErrNoFiles        = errors.New("gist has no files")
+	ErrCloneMismatch  = errors.New("cloned gist does not match the original")
+	ErrFileNotFound   = errors.New("file not found")
+	ErrFileExists     = errors.New("file already exists")
+	ErrSameGist       = errors.New("source and destination are the same gist")
+	ErrLastFile       = errors.New("cannot remove the last file of a gist")
+	ErrTooFewGists    = errors.New("at least two gists are needed")
+	ErrTooFewFiles    = errors.New("at least two files are needed")
+	ErrBadFileName    = errors.New("bad file name")
+	ErrEmptyDraftLoc  = errors.New("empty drafts location")
+	ErrBadDraftKey    = errors.New("bad draft key")
+	ErrDraftLocked    = errors.New("some drafts are sealed with another key")
 )
@@ -0,0 +1,40 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Export writes the files of g into a directory named after its ID in dir.
+// Empty files are skipped, and the names are stripped of any directories so
+// the files stay in place. It returns ErrEmptyID if g has no ID, and
+// ErrBadFileName for names that cannot be files.
+func Export(g Gist, dir string) error {
+	if g.ID == "" {
+		return ErrEmptyID
+	}
+	target := filepath.Join(dir, g.ID)
+	if err := os.MkdirAll(target, 0700); err != nil {
+		return errors.Wrap(err, "creating the directory")
+	}
+	for name, f := range g.Files {
+		if f.Content == "" {
+			continue
+		}
+		base := filepath.Base(name)
+		if base == ".." || base == "." || base == string(filepath.Separator) {
+			return errors.Wrapf(ErrBadFileName, "%q", name)
+		}
+		if err := ioutil.WriteFile(filepath.Join(target, base), []byte(f.Content), 0600); err != nil {
+			return errors.Wrapf(err, "writing %s", name)
+		}
+	}
+	return nil
+}
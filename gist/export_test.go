@@ -0,0 +1,53 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/pkg/errors"
+)
+
+func TestExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g := gist.Gist{ID: "a1", Files: map[string]gist.File{
+		"main.go":       {Content: "package main"},
+		"../escape.txt": {Content: "stays in"},
+		"deleted.md":    {},
+	}}
+	if err := gist.Export(g, dir); err != nil {
+		t.Fatalf("Export(): err = %v, want nil", err)
+	}
+	for name, want := range map[string]string{"main.go": "package main", "escape.txt": "stays in"} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, "a1", name))
+		if err != nil {
+			t.Errorf("reading %s: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a1", "deleted.md")); !os.IsNotExist(err) {
+		t.Errorf("empty file was written: %v", err)
+	}
+
+	if err := gist.Export(gist.Gist{}, dir); err != gist.ErrEmptyID {
+		t.Errorf("Export(): err = %v, want %v", err, gist.ErrEmptyID)
+	}
+	bad := gist.Gist{ID: "b2", Files: map[string]gist.File{"..": {Content: "x"}}}
+	if err := gist.Export(bad, dir); errors.Cause(err) != gist.ErrBadFileName {
+		t.Errorf("Export(): err = %v, want %v", err, gist.ErrBadFileName)
+	}
+}
@@ -15,9 +15,25 @@ type Gist struct {
 	CreatedAt   string          `json:"created_at"`
 	UpdatedAt   string          `json:"updated_at"`
 	Files       map[string]File `json:"files"`
+	Comments    int             `json:"comments,omitempty"`
 }
 
 // File is one file in a Gist.
 type File struct {
-	Content string `json:"content"`
+	Content  string `json:"content"`
+	Size     int    `json:"size,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// Comment is a comment left on a Gist.
+type Comment struct {
+	ID        int64  `json:"id,omitempty"`
+	Body      string `json:"body"`
+	User      User   `json:"user,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// User is the owner of a Gist or a Comment.
+type User struct {
+	Login string `json:"login"`
 }
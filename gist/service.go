@@ -14,12 +14,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
-	"path"
 	"strconv"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
+// PageSize is the number of gists Iter lists with each request.
+const PageSize = 40
+
 type boxLogger interface {
 	Warning(msg string)
 	Warningf(format string, a ...interface{})
@@ -27,11 +30,14 @@ type boxLogger interface {
 
 // Service holds the information about the user.
 type Service struct {
-	Username string
-	Token    string
-	API      string
-	CacheDir string
-	Logger   boxLogger
+	Username  string
+	Token     string
+	API       string
+	CacheDir  string
+	CacheSize int64  // maximum size of the cache in bytes, zero means no limit.
+	CacheKey  []byte // the key of an encrypted cache, see Cache.Unlock.
+	DraftDir  string
+	Logger    boxLogger
 }
 
 func (s *Service) api() string {
@@ -41,6 +47,24 @@ func (s *Service) api() string {
 	return s.API
 }
 
+// Cache returns the cache manager for the CacheDir.
+func (s *Service) Cache() *Cache {
+	return &Cache{
+		Dir:     s.CacheDir,
+		MaxSize: s.CacheSize,
+		Key:     s.CacheKey,
+	}
+}
+
+// Drafts returns the drafts manager for the DraftDir. The drafts are sealed
+// with the key of the cache when it is encrypted.
+func (s *Service) Drafts() *Drafts {
+	return &Drafts{
+		Dir: s.DraftDir,
+		Key: s.CacheKey,
+	}
+}
+
 // List fetches all gists for the user.
 func (s *Service) List(perPage, page int) ([]Gist, error) {
 	if s.Token == "" {
@@ -86,48 +110,70 @@ func (s *Service) List(perPage, page int) ([]Gist, error) {
 }
 
 // Iter returns a channel which emits new Gist objects. It will follow the
-// paginations until it's exhausted.
-func (s *Service) Iter() chan Gist {
+// paginations until it's exhausted. When the gists channel is closed, the
+// error channel receives the error that stopped the listing, or nil if all
+// gists were listed.
+func (s *Service) Iter() (chan Gist, chan error) {
 	ch := make(chan Gist)
+	errc := make(chan error, 1)
 	go func() {
-		perPage := 40
+		defer close(errc)
+		defer close(ch)
 		for page := 1; ; page++ {
-			gs, err := s.List(perPage, page)
-			if err != nil || len(gs) == 0 {
-				break
+			gs, err := s.List(PageSize, page)
+			if err != nil {
+				errc <- errors.Wrapf(err, "page %d", page)
+				return
+			}
+			if len(gs) == 0 {
+				errc <- nil
+				return
 			}
 			for _, g := range gs {
 				ch <- g
 			}
 		}
-		close(ch)
 	}()
-	return ch
+	return ch, errc
 }
 
 // Get gets a gist item by its id.
 func (s *Service) Get(id string) (Gist, error) {
-	var g Gist
 	if id == "" {
 		return Gist{}, ErrEmptyID
 	}
-
-	body, err := fromCache(s.CacheDir, id)
+	g, err := s.Cached(id)
 	switch err {
-	case nil, ErrCacheNotExists, ErrEmptyCacheLoc:
+	case nil:
+		return g, nil
+	case ErrCacheNotExists, ErrEmptyCacheLoc, ErrCacheLocked:
 	default:
 		s.Logger.Warningf("reading from cache: %s", err.Error())
 	}
+	return s.Fetch(id)
+}
 
-	gistURL := fmt.Sprintf("%s/gists/%s", s.api(), id)
-	if body != nil {
-		if err = json.Unmarshal(body, &g); err == nil {
-			g.URL = gistURL
-			return g, nil
-		}
-		s.Logger.Warning(err.Error())
+// Cached returns the gist identified by id only if it is in the cache.
+func (s *Service) Cached(id string) (Gist, error) {
+	var g Gist
+	if id == "" {
+		return Gist{}, ErrEmptyID
 	}
+	body, err := s.Cache().Read(id)
+	if err != nil {
+		return Gist{}, err
+	}
+	if err = json.Unmarshal(body, &g); err != nil {
+		return Gist{}, err
+	}
+	g.URL = s.gistURL(id)
+	return g, nil
+}
 
+// Fetch gets the gist from the API, bypassing the cache, and caches it. It
+// should be used before changes that rely on the latest version of the gist.
+func (s *Service) Fetch(id string) (Gist, error) {
+	gistURL := s.gistURL(id)
 	url := s.withToken(gistURL)
 	r, err := http.Get(url)
 	if err != nil {
@@ -139,7 +185,7 @@ func (s *Service) Get(id string) (Gist, error) {
 		return Gist{}, ErrGistNotFound
 	}
 
-	g, err = s.readAndCache(r.Body, id)
+	g, err := s.readAndCache(r.Body, id)
 	if err != nil {
 		return g, err
 	}
@@ -147,6 +193,10 @@ func (s *Service) Get(id string) (Gist, error) {
 	return g, nil
 }
 
+func (s *Service) gistURL(id string) string {
+	return fmt.Sprintf("%s/gists/%s", s.api(), id)
+}
+
 // Update returns an error if the remote API responds other than 200.
 func (s *Service) Update(g Gist) (Gist, error) {
 	b, err := json.Marshal(g)
@@ -232,8 +282,7 @@ func (s *Service) DeleteFile(g Gist, name string) (Gist, error) {
 
 // DeleteGist sends a request to the server to remove a gist.
 func (s *Service) DeleteGist(id string) error {
-	gistURL := fmt.Sprintf("%s/gists/%s", s.api(), id)
-	url := s.withToken(gistURL)
+	url := s.withToken(s.gistURL(id))
 	req, err := http.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
 		return err
@@ -247,7 +296,7 @@ func (s *Service) DeleteGist(id string) error {
 	if res.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("error removing gist: %d", res.StatusCode)
 	}
-	return deleteCache(s.CacheDir, id)
+	return s.Cache().Delete(id)
 }
 
 func (s *Service) withToken(url string) string {
@@ -269,44 +318,8 @@ func (s *Service) readAndCache(r io.ReadCloser, id string) (Gist, error) {
 	if id == "" {
 		id = g.ID
 	}
-	if err = saveCache(s.CacheDir, id, body); err != nil {
+	if err = s.Cache().Write(id, body); err != nil && err != ErrCacheLocked {
 		s.Logger.Warning(err.Error())
 	}
 	return g, nil
 }
-
-func fromCache(location, id string) ([]byte, error) {
-	if location == "" {
-		return nil, ErrEmptyCacheLoc
-	}
-	name := path.Join(location, id)
-	file, err := os.Open(name)
-	if err != nil {
-		return nil, ErrCacheNotExists
-	}
-	defer file.Close()
-	return ioutil.ReadAll(file)
-}
-
-func deleteCache(location, id string) error {
-	if location == "" {
-		return ErrEmptyCacheLoc
-	}
-	name := path.Join(location, id)
-	os.Remove(name)
-	return nil
-}
-
-func saveCache(location, id string, contents []byte) error {
-	if location == "" {
-		return ErrEmptyCacheLoc
-	}
-	name := path.Join(location, id)
-	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0640)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	_, err = file.Write(contents)
-	return err
-}
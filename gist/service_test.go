@@ -109,7 +109,11 @@ func TestIter(t *testing.T) {
 		input <- d
 	}
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Write(<-input)
+		if d, ok := <-input; ok {
+			w.Write(d)
+			return
+		}
+		w.Write([]byte("[]"))
 	}))
 	close(input) // because we don't want the server waiting
 	defer ts.Close()
@@ -121,7 +125,8 @@ func TestIter(t *testing.T) {
 	done := make(chan struct{})
 	go func() {
 		count := 0
-		for r := range s.Iter() {
+		gists, errc := s.Iter()
+		for r := range gists {
 			count++
 			if r.ID != "1b212f0843127d2d061f0d53fb581680" {
 				t.Errorf("r.ID = %s, want %s", r.ID, "1b212f0843127d2d061f0d53fb581680")
@@ -130,6 +135,9 @@ func TestIter(t *testing.T) {
 		if count != size {
 			t.Errorf("got %d iteration, want %d", count, size)
 		}
+		if err := <-errc; err != nil {
+			t.Errorf("err = %v, want nil", err)
+		}
 		close(done)
 	}()
 
@@ -140,6 +148,38 @@ func TestIter(t *testing.T) {
 	}
 }
 
+func TestIterError(t *testing.T) {
+	d, err := ioutil.ReadFile("testdata/gist1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Write(d)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message": "server error"}`))
+	}))
+	defer ts.Close()
+	s := &gist.Service{
+		Username: "arsham",
+		Token:    "QnqPp208",
+		API:      ts.URL,
+	}
+	gists, errc := s.Iter()
+	count := 0
+	for range gists {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("got %d iteration, want 1", count)
+	}
+	if err := <-errc; err == nil {
+		t.Error("err = nil, want the error of the second page")
+	}
+}
+
 func TestGistGetError(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`
@@ -262,6 +302,49 @@ func TestLoadFromCache(t *testing.T) {
 	}
 }
 
+func TestCached(t *testing.T) {
+	var calls int
+	id := "Xb4nLq0rT"
+	loc, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(loc)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(gist.Gist{
+			ID:    id,
+			Files: map[string]gist.File{"file": {Content: "pWq2x"}},
+		})
+	}))
+	defer ts.Close()
+	s := &gist.Service{
+		Username: "arsham",
+		Token:    "Kc8dNw",
+		API:      ts.URL,
+		CacheDir: loc,
+	}
+	if _, err := s.Cached(""); err != gist.ErrEmptyID {
+		t.Errorf("s.Cached(): err = %v, want %v", err, gist.ErrEmptyID)
+	}
+	if _, err := s.Cached(id); err != gist.ErrCacheNotExists {
+		t.Errorf("s.Cached(): err = %v, want %v", err, gist.ErrCacheNotExists)
+	}
+	if _, err := s.Get(id); err != nil {
+		t.Fatal(err)
+	}
+	g, err := s.Cached(id)
+	if err != nil {
+		t.Fatalf("s.Cached(): err = %v, want nil", err)
+	}
+	if g.Files["file"].Content != "pWq2x" {
+		t.Errorf("g.Files = %v", g.Files)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
 func TestGistUpdateError(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "PATCH" {
@@ -0,0 +1,82 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Starred returns the IDs of all gists the user has starred. It follows the
+// paginations until it's exhausted.
+func (s *Service) Starred() ([]string, error) {
+	if s.Token == "" {
+		return nil, ErrEmptyToken
+	}
+	var ids []string
+	perPage := 100
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s&page=%d&per_page=%d", s.withToken(s.api()+"/gists/starred"), page, perPage)
+		r, err := http.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if r.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error getting starred gists: %s", body)
+		}
+		var gs []Gist
+		if err := json.Unmarshal(body, &gs); err != nil {
+			return nil, err
+		}
+		for _, g := range gs {
+			ids = append(ids, g.ID)
+		}
+		if len(gs) < perPage {
+			return ids, nil
+		}
+	}
+}
+
+// Star stars the gist identified by id.
+func (s *Service) Star(id string) error {
+	return s.star(http.MethodPut, id)
+}
+
+// Unstar removes the star of the gist identified by id.
+func (s *Service) Unstar(id string) error {
+	return s.star(http.MethodDelete, id)
+}
+
+func (s *Service) star(method, id string) error {
+	if id == "" {
+		return ErrEmptyID
+	}
+	url := s.withToken(s.gistURL(id) + "/star")
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrGistNotFound
+	}
+	reason, _ := ioutil.ReadAll(res.Body)
+	return fmt.Errorf("error starring gist: %s", reason)
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func TestStarred(t *testing.T) {
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gists/starred" {
+			t.Errorf("path = %s, want /gists/starred", r.URL.Path)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		var gs []gist.Gist
+		if page == "1" {
+			for i := 0; i < perPage; i++ {
+				gs = append(gs, gist.Gist{ID: fmt.Sprintf("id%d", i)})
+			}
+		} else {
+			gs = append(gs, gist.Gist{ID: "last"})
+		}
+		json.NewEncoder(w).Encode(gs)
+	}))
+	defer ts.Close()
+	s := &gist.Service{Token: "Pq9zXw", API: ts.URL}
+	ids, err := s.Starred()
+	if err != nil {
+		t.Fatalf("s.Starred(): err = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Errorf("pages = %v, want [1 2]", pages)
+	}
+	if len(ids) != 101 || ids[0] != "id0" || ids[100] != "last" {
+		t.Errorf("got %d ids: %v", len(ids), ids)
+	}
+}
+
+func TestStarredErrors(t *testing.T) {
+	s := &gist.Service{}
+	if _, err := s.Starred(); err != gist.ErrEmptyToken {
+		t.Errorf("s.Starred(): err = %v, want %v", err, gist.ErrEmptyToken)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("bad credentials"))
+	}))
+	defer ts.Close()
+	s = &gist.Service{Token: "Pq9zXw", API: ts.URL}
+	if _, err := s.Starred(); err == nil {
+		t.Error("s.Starred(): err = nil, want error")
+	}
+}
+
+func TestStar(t *testing.T) {
+	var method, path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		if strings.Contains(path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	s := &gist.Service{Token: "Pq9zXw", API: ts.URL}
+
+	if err := s.Star("Hq1x"); err != nil {
+		t.Errorf("s.Star(): err = %v, want nil", err)
+	}
+	if method != http.MethodPut || path != "/gists/Hq1x/star" {
+		t.Errorf("request = %s %s, want PUT /gists/Hq1x/star", method, path)
+	}
+	if err := s.Unstar("Hq1x"); err != nil {
+		t.Errorf("s.Unstar(): err = %v, want nil", err)
+	}
+	if method != http.MethodDelete {
+		t.Errorf("method = %s, want DELETE", method)
+	}
+	if err := s.Star("missing"); err != gist.ErrGistNotFound {
+		t.Errorf("s.Star(): err = %v, want %v", err, gist.ErrGistNotFound)
+	}
+	if err := s.Star(""); err != gist.ErrEmptyID {
+		t.Errorf("s.Star(): err = %v, want %v", err, gist.ErrEmptyID)
+	}
+}
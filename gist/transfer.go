@@ -0,0 +1,170 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CopyFile adds the file identified by name in src to dst, and returns the
+// updated dst. It returns ErrFileExists if dst already has a file with the
+// same name.
+func (s *Service) CopyFile(src Gist, name string, dst Gist) (Gist, error) {
+	if src.ID != "" && src.ID == dst.ID {
+		return Gist{}, ErrSameGist
+	}
+	f, ok := src.Files[name]
+	if !ok || f.Content == "" {
+		return Gist{}, ErrFileNotFound
+	}
+	if _, ok := dst.Files[name]; ok {
+		return Gist{}, ErrFileExists
+	}
+	return s.Update(Gist{
+		ID:          dst.ID,
+		URL:         dst.URL,
+		Description: dst.Description,
+		Files:       map[string]File{name: {Content: f.Content}},
+	})
+}
+
+// MoveFile copies the file identified by name from src to dst, then removes it
+// from src. If the removal fails, the copy is removed from dst. It returns the
+// updated src and dst.
+func (s *Service) MoveFile(src Gist, name string, dst Gist) (Gist, Gist, error) {
+	if len(contents(src.Files)) < 2 {
+		return Gist{}, Gist{}, ErrLastFile
+	}
+	newDst, err := s.CopyFile(src, name, dst)
+	if err != nil {
+		return Gist{}, Gist{}, err
+	}
+	newSrc, err := s.DeleteFile(src, name)
+	if err != nil {
+		return Gist{}, Gist{}, rollback(errors.Wrap(err, "removing from the source"), func() error {
+			_, err := s.DeleteFile(newDst, name)
+			return err
+		})
+	}
+	return newSrc, newDst, nil
+}
+
+// Merge creates a new gist with the files of all gists. Files with the same
+// names are renamed. The new gist is public only if all gists are public. The
+// original gists are not changed, and the new gist is removed if it cannot be
+// verified.
+func (s *Service) Merge(gists []Gist, description string) (Gist, error) {
+	if len(gists) < 2 {
+		return Gist{}, ErrTooFewGists
+	}
+	want := Gist{
+		Description: description,
+		Public:      true,
+		Files:       make(map[string]File),
+	}
+	for _, g := range gists {
+		want.Public = want.Public && g.Public
+		for _, name := range sortedNames(contents(g.Files)) {
+			want.Files[uniqueName(name, want.Files)] = File{Content: g.Files[name].Content}
+		}
+	}
+	if len(want.Files) == 0 {
+		return Gist{}, ErrNoFiles
+	}
+	return s.createVerified(want)
+}
+
+// Split creates a new gist for each file of g, with the same visibility. If
+// any of them fails, all new gists are removed. The original gist is not
+// changed.
+func (s *Service) Split(g Gist) ([]Gist, error) {
+	files := contents(g.Files)
+	if len(files) < 2 {
+		return nil, ErrTooFewFiles
+	}
+	var created []Gist
+	undo := func() error {
+		var firstErr error
+		for _, c := range created {
+			if err := s.DeleteGist(c.ID); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+	for _, name := range sortedNames(files) {
+		description := strings.TrimSuffix(name, EncryptedExt)
+		if g.Description != "" {
+			description = fmt.Sprintf("%s (%s)", g.Description, description)
+		}
+		got, err := s.createVerified(Gist{
+			Description: description,
+			Public:      g.Public,
+			Files:       map[string]File{name: files[name]},
+		})
+		if err != nil {
+			return nil, rollback(errors.Wrapf(err, "creating gist for %s", name), undo)
+		}
+		created = append(created, got)
+	}
+	return created, nil
+}
+
+// rollback runs the undo steps of a failed operation in reverse order. The
+// returned error has the same cause as err.
+func rollback(err error, undo ...func() error) error {
+	for i := len(undo) - 1; i >= 0; i-- {
+		if e := undo[i](); e != nil {
+			err = errors.Wrapf(err, "rollback failed: %s", e)
+		}
+	}
+	return err
+}
+
+// contents returns the files that are not deletion marks.
+func contents(files map[string]File) map[string]File {
+	res := make(map[string]File, len(files))
+	for name, f := range files {
+		if f.Content != "" {
+			res[name] = f
+		}
+	}
+	return res
+}
+
+func sortedNames(files map[string]File) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uniqueName adds a number to the name if it is already taken, while keeping
+// the file extension and the encrypted suffix.
+func uniqueName(name string, taken map[string]File) string {
+	if _, ok := taken[name]; !ok {
+		return name
+	}
+	suffix := ""
+	if strings.HasSuffix(name, EncryptedExt) {
+		suffix = EncryptedExt
+		name = strings.TrimSuffix(name, EncryptedExt)
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		n := fmt.Sprintf("%s-%d%s%s", base, i, ext, suffix)
+		if _, ok := taken[n]; !ok {
+			return n
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gist_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func TestCopyFile(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	src := srv.add(gist.Gist{ID: "src", Files: map[string]gist.File{"a.go": {Content: "package a"}}})
+	dst := srv.add(gist.Gist{ID: "dst", Description: "kept", Files: map[string]gist.File{"b.go": {Content: "package b"}}})
+
+	got, err := s.CopyFile(src, "a.go", dst)
+	if err != nil {
+		t.Fatalf("s.CopyFile(): err = %v, want nil", err)
+	}
+	if got.Files["a.go"].Content != "package a" || got.Files["b.go"].Content != "package b" {
+		t.Errorf("got.Files = %v", got.Files)
+	}
+	if srv.gists["dst"].Description != "kept" {
+		t.Errorf("description = %s, want kept", srv.gists["dst"].Description)
+	}
+	if _, ok := srv.gists["src"].Files["a.go"]; !ok {
+		t.Error("file was removed from the source")
+	}
+
+	tcs := []struct {
+		name string
+		file string
+		dst  gist.Gist
+		err  error
+	}{
+		{"same gist", "a.go", src, gist.ErrSameGist},
+		{"not found", "c.go", dst, gist.ErrFileNotFound},
+		{"exists", "a.go", got, gist.ErrFileExists},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.CopyFile(src, tc.file, tc.dst); err != tc.err {
+				t.Errorf("s.CopyFile(): err = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	src := srv.add(gist.Gist{ID: "src", Files: map[string]gist.File{
+		"a.go": {Content: "package a"},
+		"b.go": {Content: "package b"},
+	}})
+	dst := srv.add(gist.Gist{ID: "dst", Files: map[string]gist.File{"c.go": {Content: "package c"}}})
+
+	newSrc, newDst, err := s.MoveFile(src, "a.go", dst)
+	if err != nil {
+		t.Fatalf("s.MoveFile(): err = %v, want nil", err)
+	}
+	if _, ok := newSrc.Files["a.go"]; ok {
+		t.Error("file was not removed from the source")
+	}
+	if _, ok := newDst.Files["a.go"]; !ok {
+		t.Error("file was not added to the destination")
+	}
+
+	if _, _, err := s.MoveFile(newSrc, "b.go", newDst); err != gist.ErrLastFile {
+		t.Errorf("s.MoveFile(): err = %v, want %v", err, gist.ErrLastFile)
+	}
+}
+
+func TestMoveFileRollback(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	src := srv.add(gist.Gist{ID: "src", Files: map[string]gist.File{
+		"a.go": {Content: "package a"},
+		"b.go": {Content: "package b"},
+	}})
+	dst := srv.add(gist.Gist{ID: "dst", Files: map[string]gist.File{"c.go": {Content: "package c"}}})
+	srv.fail = http.MethodPatch + " /gists/src"
+
+	if _, _, err := s.MoveFile(src, "a.go", dst); err == nil {
+		t.Fatal("s.MoveFile(): err = nil, want error")
+	}
+	if _, ok := srv.gists["dst"].Files["a.go"]; ok {
+		t.Error("the copy was not removed from the destination")
+	}
+	if _, ok := srv.gists["src"].Files["a.go"]; !ok {
+		t.Error("the file was removed from the source")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	gists := []gist.Gist{
+		{ID: "one", Public: true, Files: map[string]gist.File{
+			"main.go":      {Content: "package one"},
+			"notes.md.enc": {Content: "armored one"},
+		}},
+		{ID: "two", Public: false, Files: map[string]gist.File{
+			"main.go":      {Content: "package two"},
+			"notes.md.enc": {Content: "armored two"},
+			"removed":      {},
+		}},
+	}
+	if _, err := s.Merge(gists[:1], "merged"); err != gist.ErrTooFewGists {
+		t.Errorf("s.Merge(): err = %v, want %v", err, gist.ErrTooFewGists)
+	}
+	got, err := s.Merge(gists, "merged")
+	if err != nil {
+		t.Fatalf("s.Merge(): err = %v, want nil", err)
+	}
+	if got.Public {
+		t.Error("merged gist is public")
+	}
+	if got.Description != "merged" {
+		t.Errorf("got.Description = %s, want merged", got.Description)
+	}
+	want := map[string]string{
+		"main.go":        "package one",
+		"main-2.go":      "package two",
+		"notes.md.enc":   "armored one",
+		"notes-2.md.enc": "armored two",
+	}
+	if len(got.Files) != len(want) {
+		t.Errorf("got.Files = %v, want %v", got.Files, want)
+	}
+	for name, content := range want {
+		if got.Files[name].Content != content {
+			t.Errorf("got.Files[%s] = %s, want %s", name, got.Files[name].Content, content)
+		}
+	}
+	if len(srv.deleted) != 0 {
+		t.Errorf("srv.deleted = %v, want none", srv.deleted)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	g := gist.Gist{
+		ID:          "orig",
+		Description: "snippets",
+		Public:      true,
+		Files: map[string]gist.File{
+			"a.go": {Content: "package a"},
+			"b.go": {Content: "package b"},
+		},
+	}
+	if _, err := s.Split(gist.Gist{Files: map[string]gist.File{"a": {Content: "a"}}}); err != gist.ErrTooFewFiles {
+		t.Errorf("s.Split(): err = %v, want %v", err, gist.ErrTooFewFiles)
+	}
+	got, err := s.Split(g)
+	if err != nil {
+		t.Fatalf("s.Split(): err = %v, want nil", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d, want 2", len(got))
+	}
+	if got[0].Description != "snippets (a.go)" || got[1].Description != "snippets (b.go)" {
+		t.Errorf("descriptions = %s, %s", got[0].Description, got[1].Description)
+	}
+	for _, n := range got {
+		if !n.Public || len(n.Files) != 1 {
+			t.Errorf("new gist = %v", n)
+		}
+	}
+	if len(srv.deleted) != 0 {
+		t.Errorf("srv.deleted = %v, want none", srv.deleted)
+	}
+}
+
+func TestSplitRollback(t *testing.T) {
+	srv, s, cleanup := newGistServer(t)
+	defer cleanup()
+	g := gist.Gist{
+		Files: map[string]gist.File{
+			"a.go": {Content: "package a"},
+			"b.go": {Content: "package b"},
+		},
+	}
+	// the second gist cannot be verified.
+	srv.fail = http.MethodGet + " /gists/clone2"
+	if _, err := s.Split(g); err == nil {
+		t.Fatal("s.Split(): err = nil, want error")
+	}
+	if len(srv.gists) != 0 {
+		t.Errorf("srv.gists = %v, want none", srv.gists)
+	}
+}
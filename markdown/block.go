@@ -0,0 +1,477 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arsham/gistflow/syntax"
+)
+
+var (
+	openFence  = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^ \t`]*)")
+	atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextH1   = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2   = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	rule       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	quote      = regexp.MustCompile(`^ {0,3}> ?`)
+	listItem   = regexp.MustCompile(`^( {0,3})([-+*]|(\d{1,9})[.)])( +|$)`)
+	taskBox    = regexp.MustCompile(`^\[([ xX])\][ \t]`)
+	delimiter  = regexp.MustCompile(`^:?-+:?$`)
+)
+
+// Boxes shown for the items of task lists.
+const (
+	uncheckedBox = "\u2610"
+	checkedBox   = "\u2611"
+)
+
+// blocks writes the blocks of the lines.
+func (r *renderer) blocks(lines []line) {
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		if blank(l.text) {
+			i++
+			continue
+		}
+		r.mark(l.n)
+		switch {
+		case openFence.MatchString(l.text):
+			i = r.fenced(lines, i)
+		case atxHeading.MatchString(l.text):
+			m := atxHeading.FindStringSubmatch(l.text)
+			r.heading(len(m[1]), m[2])
+			i++
+		case rule.MatchString(l.text):
+			r.buf.WriteString("<hr>\n")
+			if r.pending == l.n {
+				r.pending = 0 // rules have no text to hold the anchor.
+			}
+			i++
+		case quote.MatchString(l.text):
+			i = r.quote(lines, i)
+		case listItem.MatchString(l.text):
+			i = r.list(lines, i)
+		case indentation(l.text) >= 4:
+			i = r.indented(lines, i)
+		case tableStart(lines, i):
+			i = r.table(lines, i)
+		default:
+			i = r.paragraph(lines, i)
+		}
+	}
+}
+
+// startsBlock returns true if the text starts a block that ends a paragraph.
+func startsBlock(text string) bool {
+	if openFence.MatchString(text) || atxHeading.MatchString(text) ||
+		rule.MatchString(text) || quote.MatchString(text) {
+		return true
+	}
+	m := listItem.FindStringSubmatch(text)
+	if m == nil || blank(text[len(m[0]):]) {
+		return false
+	}
+	return m[3] == "" || m[3] == "1"
+}
+
+func (r *renderer) heading(level int, text string) {
+	fmt.Fprintf(&r.buf, "<h%d>", level)
+	r.anchor()
+	r.buf.WriteString(r.inline(strings.TrimSpace(text)))
+	fmt.Fprintf(&r.buf, "</h%d>\n", level)
+}
+
+// paragraph writes the lines up to the next blank line or block as a
+// paragraph, or as a heading if they are underlined.
+func (r *renderer) paragraph(lines []line, i int) int {
+	var text []string
+	for ; i < len(lines); i++ {
+		t := lines[i].text
+		if len(text) > 0 {
+			if setextH1.MatchString(t) {
+				r.heading(1, strings.Join(text, "\n"))
+				return i + 1
+			}
+			if setextH2.MatchString(t) {
+				r.heading(2, strings.Join(text, "\n"))
+				return i + 1
+			}
+			if startsBlock(t) || tableStart(lines, i) {
+				break
+			}
+		}
+		if blank(t) {
+			break
+		}
+		text = append(text, t)
+	}
+	// two spaces at the end of a line break it, like a backslash does.
+	for j := range text[:len(text)-1] {
+		if strings.HasSuffix(text[j], "  ") {
+			text[j] = strings.TrimRight(text[j], " ") + `\`
+		}
+	}
+	for j := range text {
+		text[j] = strings.TrimLeft(text[j], " ")
+	}
+	content := strings.TrimRight(strings.Join(text, "\n"), " ")
+	if !r.tight {
+		r.buf.WriteString("<p>")
+	}
+	r.anchor()
+	r.buf.WriteString(r.inline(content))
+	if !r.tight {
+		r.buf.WriteString("</p>")
+	}
+	r.buf.WriteString("\n")
+	return i
+}
+
+// closesFence returns true if the text closes a fence opened with fence.
+func closesFence(text, fence string) bool {
+	t := strings.TrimRight(text, " \t")
+	if indentation(t) > 3 {
+		return false
+	}
+	t = strings.TrimLeft(t, " ")
+	return len(t) >= len(fence) && strings.Trim(t, fence[:1]) == ""
+}
+
+// fenced writes the code up to the closing fence, highlighted with the grammar
+// of the language after the opening fence.
+func (r *renderer) fenced(lines []line, i int) int {
+	m := openFence.FindStringSubmatch(lines[i].text)
+	indent, fence, lang := len(m[1]), m[2], m[3]
+	var code []string
+	for i++; i < len(lines); i++ {
+		t := lines[i].text
+		if closesFence(t, fence) {
+			i++
+			break
+		}
+		code = append(code, t[min(indent, indentation(t)):])
+	}
+	r.code(code, lang)
+	return i
+}
+
+// indented writes the lines indented by four spaces as code.
+func (r *renderer) indented(lines []line, i int) int {
+	var code []string
+	for ; i < len(lines); i++ {
+		t := lines[i].text
+		if !blank(t) && indentation(t) < 4 {
+			break
+		}
+		if len(t) >= 4 {
+			t = t[4:]
+		} else {
+			t = ""
+		}
+		code = append(code, t)
+	}
+	for len(code) > 0 && blank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+	r.code(code, "")
+	return i
+}
+
+// code writes the lines in a pre block.
+func (r *renderer) code(code []string, lang string) {
+	r.buf.WriteString(preTag(lang))
+	r.anchor()
+	writeCode(&r.buf, code, lang)
+	r.buf.WriteString("</pre>\n")
+}
+
+// Code returns the HTML of the code in a pre block, highlighted with the
+// grammar of the language if there is one.
+func Code(code, lang string) string {
+	var buf bytes.Buffer
+	buf.WriteString(preTag(lang))
+	writeCode(&buf, strings.Split(strings.TrimRight(code, "\n"), "\n"), lang)
+	buf.WriteString("</pre>\n")
+	return buf.String()
+}
+
+func preTag(lang string) string {
+	if lang == "" {
+		return "<pre>"
+	}
+	return `<pre class="language-` + html.EscapeString(lang) + `">`
+}
+
+// writeCode writes the lines of the code. The tokens are put in spans with the
+// names of their kinds as their classes.
+func writeCode(buf *bytes.Buffer, code []string, lang string) {
+	var g *syntax.Grammar
+	if lang != "" {
+		if g = syntax.ForLanguage(lang); g == nil {
+			g = syntax.ForFile("code." + lang)
+		}
+	}
+	state := 0
+	for i, text := range code {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if g == nil {
+			buf.WriteString(html.EscapeString(text))
+			continue
+		}
+		var tokens []syntax.Token
+		tokens, state = g.Highlight(text, state)
+		pos := 0
+		for _, t := range tokens {
+			buf.WriteString(html.EscapeString(text[pos:t.Start]))
+			fmt.Fprintf(buf, `<span class="%s">%s</span>`, t.Kind, html.EscapeString(text[t.Start:t.End]))
+			pos = t.End
+		}
+		buf.WriteString(html.EscapeString(text[pos:]))
+	}
+}
+
+// quote writes the lines starting with > as a block quote.
+func (r *renderer) quote(lines []line, i int) int {
+	var inner []line
+	for ; i < len(lines); i++ {
+		loc := quote.FindStringIndex(lines[i].text)
+		if loc == nil {
+			break
+		}
+		inner = append(inner, line{text: lines[i].text[loc[1]:], n: lines[i].n})
+	}
+	r.buf.WriteString("<blockquote>\n")
+	r.blocks(inner)
+	r.buf.WriteString("</blockquote>\n")
+	return i
+}
+
+// marker is the start of a list item.
+type marker struct {
+	kind   string // the bullet, or the delimiter of an ordered list.
+	start  int    // number of an ordered item, or -1.
+	indent int    // where the content of the item starts.
+}
+
+func itemMarker(text string) (marker, bool) {
+	m := listItem.FindStringSubmatch(text)
+	if m == nil || rule.MatchString(text) {
+		return marker{}, false
+	}
+	mk := marker{kind: m[2], start: -1}
+	if m[3] != "" {
+		mk.kind = m[2][len(m[3]):]
+		mk.start, _ = strconv.Atoi(m[3])
+	}
+	spaces := len(m[4])
+	if spaces == 0 || spaces > 4 {
+		spaces = 1 // the content is an indented code block, or empty.
+	}
+	mk.indent = len(m[1]) + len(m[2]) + spaces
+	return mk, true
+}
+
+// list writes the items of a list. The list is loose, with its items in
+// paragraphs, when there are blank lines between or inside the items.
+func (r *renderer) list(lines []line, i int) int {
+	first, _ := itemMarker(lines[i].text)
+	var (
+		items [][]line
+		loose bool
+	)
+	for i < len(lines) {
+		mk, ok := itemMarker(lines[i].text)
+		if !ok || mk.kind != first.kind {
+			break
+		}
+		text := lines[i].text
+		content := ""
+		if len(text) > mk.indent {
+			content = text[mk.indent:]
+		}
+		item := []line{{text: content, n: lines[i].n}}
+		for i++; i < len(lines); i++ {
+			t := lines[i].text
+			if blank(t) {
+				j := i
+				for j < len(lines) && blank(lines[j].text) {
+					j++
+				}
+				if j == len(lines) || indentation(lines[j].text) < mk.indent {
+					break
+				}
+				for ; i < j; i++ {
+					item = append(item, line{n: lines[i].n})
+				}
+				loose = true
+				t = lines[i].text
+			}
+			if indentation(t) >= mk.indent {
+				item = append(item, line{text: t[mk.indent:], n: lines[i].n})
+				continue
+			}
+			// a lazy continuation of the paragraph of the item.
+			if _, isItem := itemMarker(t); !isItem && !startsBlock(t) && !blank(item[len(item)-1].text) {
+				item = append(item, line{text: strings.TrimLeft(t, " "), n: lines[i].n})
+				continue
+			}
+			break
+		}
+		items = append(items, item)
+
+		j := i
+		for j < len(lines) && blank(lines[j].text) {
+			j++
+		}
+		if j == i {
+			continue
+		}
+		if mk, ok := itemMarker(safeText(lines, j)); !ok || mk.kind != first.kind {
+			break
+		}
+		loose = true
+		i = j
+	}
+
+	tag := "ul"
+	if first.start >= 0 {
+		tag = "ol"
+	}
+	if first.start >= 0 && first.start != 1 {
+		fmt.Fprintf(&r.buf, "<%s start=\"%d\">\n", tag, first.start)
+	} else {
+		fmt.Fprintf(&r.buf, "<%s>\n", tag)
+	}
+	tight := r.tight
+	r.tight = !loose
+	for _, item := range items {
+		r.mark(item[0].n)
+		r.buf.WriteString("<li>")
+		if m := taskBox.FindStringSubmatch(item[0].text); m != nil {
+			box := uncheckedBox
+			if m[1] != " " {
+				box = checkedBox
+			}
+			item[0].text = box + " " + item[0].text[len(m[0]):]
+		}
+		r.blocks(item)
+		r.buf.WriteString("</li>\n")
+	}
+	r.tight = tight
+	fmt.Fprintf(&r.buf, "</%s>\n", tag)
+	return i
+}
+
+func safeText(lines []line, i int) string {
+	if i < len(lines) {
+		return lines[i].text
+	}
+	return ""
+}
+
+// cells returns the cells of a table row.
+func cells(text string) []string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "|")
+	if strings.HasSuffix(text, "|") && !strings.HasSuffix(text, `\|`) {
+		text = text[:len(text)-1]
+	}
+	var (
+		row   []string
+		start int
+	)
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '|':
+			row = append(row, text[start:i])
+			start = i + 1
+		}
+	}
+	row = append(row, text[start:])
+	for i, c := range row {
+		row[i] = strings.Replace(strings.TrimSpace(c), `\|`, "|", -1)
+	}
+	return row
+}
+
+// tableStart returns true if a table starts on the line, with its header
+// followed by a delimiter row with the same number of cells.
+func tableStart(lines []line, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i].text, "|") {
+		return false
+	}
+	aligns := cells(lines[i+1].text)
+	for _, a := range aligns {
+		if !delimiter.MatchString(a) {
+			return false
+		}
+	}
+	return len(aligns) == len(cells(lines[i].text))
+}
+
+// table writes the rows of a table up to a blank line or another block.
+func (r *renderer) table(lines []line, i int) int {
+	header := cells(lines[i].text)
+	var aligns []string
+	for _, d := range cells(lines[i+1].text) {
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			aligns = append(aligns, "center")
+		case strings.HasSuffix(d, ":"):
+			aligns = append(aligns, "right")
+		case strings.HasPrefix(d, ":"):
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	row := func(tag string, row []string) {
+		r.buf.WriteString("<tr>")
+		for j, align := range aligns {
+			if align != "" {
+				fmt.Fprintf(&r.buf, `<%s align="%s">`, tag, align)
+			} else {
+				fmt.Fprintf(&r.buf, "<%s>", tag)
+			}
+			r.anchor()
+			if j < len(row) {
+				r.buf.WriteString(r.inline(row[j]))
+			}
+			fmt.Fprintf(&r.buf, "</%s>", tag)
+		}
+		r.buf.WriteString("</tr>\n")
+	}
+
+	r.buf.WriteString(`<table border="1" cellspacing="0" cellpadding="4">` + "\n<thead>")
+	row("th", header)
+	r.buf.WriteString("</thead>\n<tbody>\n")
+	for i += 2; i < len(lines); i++ {
+		t := lines[i].text
+		if blank(t) || startsBlock(t) {
+			break
+		}
+		r.mark(lines[i].n)
+		row("td", cells(t))
+	}
+	r.buf.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
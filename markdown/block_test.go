@@ -0,0 +1,75 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/arsham/gistflow/markdown"
+)
+
+func TestBlocks(t *testing.T) {
+	tcs := []struct {
+		name, src, want string
+	}{
+		{"empty", "", ""},
+		{"paragraphs", "one\ntwo\n\nthree", "<p>one\ntwo</p>\n<p>three</p>\n"},
+		{"hard break", "one  \ntwo\\\nthree", "<p>one<br>\ntwo<br>\nthree</p>\n"},
+		{"atx", "# One #\n###### Six\n####### Seven\n#tag", "<h1>One</h1>\n<h6>Six</h6>\n<p>####### Seven\n#tag</p>\n"},
+		{"setext", "One\n===\nTwo\n---", "<h1>One</h1>\n<h2>Two</h2>\n"},
+		{"rule", "a\n\n***\n- - -", "<p>a</p>\n<hr>\n<hr>\n"},
+		{"quote", "> one\n> # two\n\nthree", "<blockquote>\n<p>one</p>\n<h1>two</h1>\n</blockquote>\n<p>three</p>\n"},
+		{"tight list", "- one\n- two\n  more\n* three", "<ul>\n<li>one\n</li>\n<li>two\nmore\n</li>\n</ul>\n<ul>\n<li>three\n</li>\n</ul>\n"},
+		{"loose list", "1. one\n\n2. two", "<ol>\n<li><p>one</p>\n</li>\n<li><p>two</p>\n</li>\n</ol>\n"},
+		{"ordered start", "3) three\n4) four", "<ol start=\"3\">\n<li>three\n</li>\n<li>four\n</li>\n</ol>\n"},
+		{"nested list", "- one\n  - two\n- three", "<ul>\n<li>one\n<ul>\n<li>two\n</li>\n</ul>\n</li>\n<li>three\n</li>\n</ul>\n"},
+		{"lazy item", "- one\ntwo", "<ul>\n<li>one\ntwo\n</li>\n</ul>\n"},
+		{"task list", "- [ ] todo\n- [x] done\n- [] not", "<ul>\n<li>☐ todo\n</li>\n<li>☑ done\n</li>\n<li>[] not\n</li>\n</ul>\n"},
+		{"paragraph then list", "text\n2. not a list\n- list", "<p>text\n2. not a list</p>\n<ul>\n<li>list\n</li>\n</ul>\n"},
+		{"indented code", "    a < b\n\n    c\n\nd", "<pre>a &lt; b\n\nc</pre>\n<p>d</p>\n"},
+		{"fence", "```\n<a>\n  b\n```\nafter", "<pre>&lt;a&gt;\n  b</pre>\n<p>after</p>\n"},
+		{"long fence", "````\n```\n````", "<pre>```</pre>\n"},
+		{"tilde fence", "~~~\ncode", "<pre>code</pre>\n"},
+		{"indented fence", "  ```\n  a\n b\n  ```", "<pre>a\nb</pre>\n"},
+		{"highlighted", "```go\nfunc main() {}\n```", `<pre class="language-go"><span class="keyword">func</span> <span class="function">main</span>() {}</pre>` + "\n"},
+		{"unknown language", "```cobol\nDISPLAY\n```", `<pre class="language-cobol">DISPLAY</pre>` + "\n"},
+		{"table", "| a | b | c |\n|:--|:-:|--:|\n| 1 | 2 |\n| x \\| y | `\\|` | 3 | 4 |\n\nafter",
+			`<table border="1" cellspacing="0" cellpadding="4">` + "\n" +
+				`<thead><tr><th align="left">a</th><th align="center">b</th><th align="right">c</th></tr>` + "\n" +
+				"</thead>\n<tbody>\n" +
+				`<tr><td align="left">1</td><td align="center">2</td><td align="right"></td></tr>` + "\n" +
+				`<tr><td align="left">x | y</td><td align="center"><code>|</code></td><td align="right">3</td></tr>` + "\n" +
+				"</tbody>\n</table>\n<p>after</p>\n"},
+		{"not a table", "a | b\n--|--|--", "<p>a | b\n--|--|--</p>\n"},
+		{"table after paragraph", "text\na | b\n--|--", "<p>text</p>\n" +
+			`<table border="1" cellspacing="0" cellpadding="4">` + "\n<thead><tr><th>a</th><th>b</th></tr>\n</thead>\n<tbody>\n</tbody>\n</table>\n"},
+		{"html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := render(tc.src); got != tc.want {
+				t.Errorf("render(%q) =\n%q\nwant\n%q", tc.src, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	tcs := []struct {
+		name, code, lang, want string
+	}{
+		{"plain", "a < b\n", "", "<pre>a &lt; b</pre>\n"},
+		{"highlighted", "x = 1\nprint(x)", "python", `<pre class="language-python">x = <span class="number">1</span>` + "\n" + `<span class="builtin">print</span>(x)</pre>` + "\n"},
+		{"by extension", "--", "sql", `<pre class="language-sql"><span class="comment">--</span></pre>` + "\n"},
+		{"unknown", "x", "cobol", `<pre class="language-cobol">x</pre>` + "\n"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := markdown.Code(tc.code, tc.lang); got != tc.want {
+				t.Errorf("Code() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	autolink = regexp.MustCompile(`^<((?:https?|ftp|mailto):[^\s<>]+|[\w.+-]+@[\w-]+(?:\.[\w-]+)+)>`)
+	bareURL  = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*[^\s<?!.,:;*_~'")\]]`)
+)
+
+const punctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// inline returns the HTML of the text of a paragraph, a heading or a cell.
+func (r *renderer) inline(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				b.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+				b.WriteString(html.EscapeString(s[i+1 : i+2]))
+				i += 2
+				continue
+			}
+		case '`':
+			if out, end, ok := codeSpan(s, i); ok {
+				b.WriteString(out)
+				i = end
+				continue
+			}
+			// an unmatched run of backticks is written as it is.
+			n := run(s, i)
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if text, dest, title, end, ok := r.link(s, i+1); ok {
+					b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plain(text)) + `"`)
+					if title != "" {
+						b.WriteString(` title="` + html.EscapeString(title) + `"`)
+					}
+					b.WriteString(">")
+					i = end
+					continue
+				}
+			}
+		case '[':
+			if text, dest, title, end, ok := r.link(s, i); ok {
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">" + r.inline(text) + "</a>")
+				i = end
+				continue
+			}
+		case '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				dest := m[1]
+				if !strings.Contains(dest, ":") {
+					dest = "mailto:" + dest
+				}
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+		case '*', '_', '~':
+			if out, end, ok := r.emphasis(s, i); ok {
+				b.WriteString(out)
+				i = end
+				continue
+			}
+			// a run that is not emphasis is written as it is.
+			n := run(s, i)
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		case 'h', 'w':
+			if i == 0 || strings.IndexByte(" \t\n*_~(", s[i-1]) >= 0 {
+				if url := bareURL.FindString(s[i:]); url != "" {
+					dest := url
+					if strings.HasPrefix(dest, "www.") {
+						dest = "http://" + dest
+					}
+					b.WriteString(`<a href="` + html.EscapeString(dest) + `">` + html.EscapeString(url) + "</a>")
+					i += len(url)
+					continue
+				}
+			}
+		}
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// run returns the number of times the character at i repeats from there.
+func run(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// codeSpan returns the HTML of the code span starting at i, and where it
+// ends. The span ends with a run of backticks as long as the one at i.
+func codeSpan(s string, i int) (string, int, bool) {
+	n := run(s, i)
+	for j := i + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := run(s, j)
+		if m != n {
+			j += m
+			continue
+		}
+		code := strings.Replace(s[i+n:j], "\n", " ", -1)
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		return "<code>" + html.EscapeString(code) + "</code>", j + n, true
+	}
+	return "", 0, false
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' }
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// emphasis returns the HTML of the emphasised text starting at i, and where it
+// ends. One delimiter makes it emphasised, two make it strong, and three make
+// it both. Two tildes strike it through. Underscores inside words are left
+// alone.
+func (r *renderer) emphasis(s string, i int) (string, int, bool) {
+	c := s[i]
+	n := run(s, i)
+	if n > 3 || (c == '~' && n != 2) || i+n >= len(s) || isSpace(s[i+n]) {
+		return "", 0, false
+	}
+	if c == '_' && i > 0 && isAlnum(s[i-1]) {
+		return "", 0, false
+	}
+	open, close := "<em>", "</em>"
+	switch {
+	case c == '~':
+		open, close = "<del>", "</del>"
+	case n == 2:
+		open, close = "<strong>", "</strong>"
+	case n == 3:
+		open, close = "<strong><em>", "</em></strong>"
+	}
+
+	for j := i + n; j < len(s); {
+		if s[j] == '`' {
+			// delimiters in code spans don't close the emphasis.
+			if _, end, ok := codeSpan(s, j); ok {
+				j = end
+				continue
+			}
+		}
+		if s[j] != c {
+			j++
+			continue
+		}
+		m := run(s, j)
+		after := j + m
+		if m == n && !isSpace(s[j-1]) && (c != '_' || after == len(s) || !isAlnum(s[after])) {
+			return open + r.inline(s[i+n:j]) + close, after, true
+		}
+		j = after
+	}
+	return "", 0, false
+}
+
+// link returns the text, the destination and the title of the link starting
+// with the bracket at i, and where it ends. Links are either inline, like
+// [text](dest "title"), or refer to a definition, like [text][label], [label][]
+// or [label].
+func (r *renderer) link(s string, i int) (text, dest, title string, end int, ok bool) {
+	closing := matching(s, i, '[', ']')
+	if closing < 0 {
+		return "", "", "", 0, false
+	}
+	text = s[i+1 : closing]
+	k := closing + 1
+	if k < len(s) && s[k] == '(' {
+		paren := matching(s, k, '(', ')')
+		if paren < 0 {
+			return "", "", "", 0, false
+		}
+		dest, title = destination(strings.TrimSpace(s[k+1 : paren]))
+		return text, dest, title, paren + 1, true
+	}
+
+	label, end := text, k
+	if k < len(s) && s[k] == '[' {
+		if j := strings.IndexByte(s[k:], ']'); j > 0 {
+			if l := s[k+1 : k+j]; l != "" {
+				label = l
+			}
+			end = k + j + 1
+		}
+	}
+	d, found := r.refs[normalize(label)]
+	if !found {
+		return "", "", "", 0, false
+	}
+	return text, d.dest, d.title, end, true
+}
+
+// destination splits the inside of the parentheses of a link.
+func destination(s string) (dest, title string) {
+	if strings.HasPrefix(s, "<") {
+		if j := strings.IndexByte(s, '>'); j > 0 {
+			dest, s = s[1:j], s[j+1:]
+		}
+	} else {
+		fields := strings.SplitN(s, " ", 2)
+		dest, s = fields[0], ""
+		if len(fields) == 2 {
+			s = fields[1]
+		}
+	}
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && strings.IndexByte(`"'(`, s[0]) >= 0 {
+		title = s[1 : len(s)-1]
+	}
+	return dest, title
+}
+
+// matching returns the position of the bracket closing the one at i, or -1.
+// Escaped brackets and the ones in code spans are skipped.
+func matching(s string, i int, open, close byte) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			if _, end, ok := codeSpan(s, j); ok {
+				j = end - 1
+			}
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// plain returns the text without the Markdown markers, for the alternative
+// texts of images.
+func plain(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~", "").Replace(s)
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	tcs := []struct {
+		name, src, want string
+	}{
+		{"escape", `a & b < c \* d \q`, `a &amp; b &lt; c * d \q`},
+		{"code", "a `b * c` ``d ` e`` `f", "a <code>b * c</code> <code>d ` e</code> `f"},
+		{"emphasis", "*a* _b_ **c** __d__ ***e*** ~~f~~", "<em>a</em> <em>b</em> <strong>c</strong> <strong>d</strong> <strong><em>e</em></strong> <del>f</del>"},
+		{"nested emphasis", "**a *b* c**", "<strong>a <em>b</em> c</strong>"},
+		{"not emphasis", "a * b snake_case_name 2*3 ~one~", "a * b snake_case_name 2*3 ~one~"},
+		{"code in emphasis", "*a `*` b*", "<em>a <code>*</code> b</em>"},
+		{"link", `[a *b*](http://x.com/?a=1&b=2 "T")`, `<a href="http://x.com/?a=1&amp;b=2" title="T">a <em>b</em></a>`},
+		{"relative link", "[runbook](./run.md#step-2)", `<a href="./run.md#step-2">runbook</a>`},
+		{"link with parens", "[a](http://x.com/a_(b))", `<a href="http://x.com/a_(b)">a</a>`},
+		{"image", `![a *b*](img.png)`, `<img src="img.png" alt="a b">`},
+		{"reference", "[a][r] [R] [r][] [missing]", `<a href="http://r.com" title="Title">a</a> <a href="http://r.com" title="Title">R</a> <a href="http://r.com" title="Title">r</a> [missing]`},
+		{"autolink", "<https://x.com> <me@x.com>", `<a href="https://x.com">https://x.com</a> <a href="mailto:me@x.com">me@x.com</a>`},
+		{"bare url", "see https://x.com/a. and www.y.com, (https://z.com)", `see <a href="https://x.com/a">https://x.com/a</a>. and <a href="http://www.y.com">www.y.com</a>, (<a href="https://z.com">https://z.com</a>)`},
+		{"unicode", "héllo *wörld*", "héllo <em>wörld</em>"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			src := tc.src + "\n\n[r]: http://r.com \"Title\""
+			got := strings.TrimSuffix(strings.TrimPrefix(render(src), "<p>"), "</p>\n")
+			if got != tc.want {
+				t.Errorf("render(%q) =\n%q\nwant\n%q", tc.src, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package markdown renders GitHub flavoured Markdown to HTML for previews. It
+// supports tables, task lists, strikethrough, autolinks and fenced code, which
+// is highlighted with the grammars of the syntax package. Raw HTML is escaped.
+//
+// Each block gets an anchor named by Anchor with the line it starts on, so a
+// preview can be scrolled to the part of the text being edited.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Document is the result of rendering a Markdown text.
+type Document struct {
+	HTML  string
+	Lines []int // lines of the anchors in HTML, in order.
+}
+
+// Anchor returns the name of the anchor of the block starting on the line.
+// Lines start from 1.
+func Anchor(line int) string { return "L" + strconv.Itoa(line) }
+
+// AnchorFor returns the anchor of the last block starting on or before the
+// line, or an empty string if there is none.
+func (d *Document) AnchorFor(line int) string {
+	i := sort.SearchInts(d.Lines, line+1)
+	if i == 0 {
+		return ""
+	}
+	return Anchor(d.Lines[i-1])
+}
+
+// line is a line of the text with its number, starting from 1.
+type line struct {
+	text string
+	n    int
+}
+
+// ref is the destination of a reference link.
+type ref struct {
+	dest, title string
+}
+
+type renderer struct {
+	buf     bytes.Buffer
+	refs    map[string]ref
+	tight   bool // paragraphs are written without <p> in tight lists.
+	pending int  // the line of the anchor to write before the next text.
+	lines   []int
+}
+
+// Render returns the HTML of the Markdown text.
+func Render(src string) *Document {
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	var lines []line
+	for i, text := range strings.Split(src, "\n") {
+		lines = append(lines, line{text: expandTabs(text), n: i + 1})
+	}
+	r := &renderer{refs: make(map[string]ref)}
+	lines = r.collectRefs(lines)
+	r.blocks(lines)
+	return &Document{HTML: r.buf.String(), Lines: r.lines}
+}
+
+// expandTabs replaces the tabs in the indentation with spaces.
+func expandTabs(text string) string {
+	if !strings.HasPrefix(text, "\t") && !strings.HasPrefix(text, " ") {
+		return text
+	}
+	width := 0
+	for i, c := range text {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return strings.Repeat(" ", width) + text[i:]
+		}
+	}
+	return ""
+}
+
+var refDef = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?(?:\s+["'(](.*)["')])?\s*$`)
+
+// collectRefs records the definitions of the reference links, and returns the
+// rest of the lines. The contents of fenced code are left alone.
+func (r *renderer) collectRefs(lines []line) []line {
+	var (
+		rest  []line
+		fence string
+		prev  = ""
+	)
+	for _, l := range lines {
+		switch {
+		case fence != "":
+			if closesFence(l.text, fence) {
+				fence = ""
+			}
+		case openFence.MatchString(l.text):
+			fence = openFence.FindStringSubmatch(l.text)[2]
+		case blank(prev) || refDef.MatchString(prev):
+			if m := refDef.FindStringSubmatch(l.text); m != nil {
+				label := normalize(m[1])
+				if _, ok := r.refs[label]; !ok {
+					r.refs[label] = ref{dest: m[2], title: m[3]}
+				}
+				prev = l.text
+				continue
+			}
+		}
+		prev = l.text
+		rest = append(rest, l)
+	}
+	return rest
+}
+
+// normalize returns the label of a reference in the form it is looked up.
+func normalize(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func blank(text string) bool { return strings.TrimSpace(text) == "" }
+
+// indentation returns the number of leading spaces of the text.
+func indentation(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " "))
+}
+
+// mark sets the anchor for the block starting on the line, unless an earlier
+// block has not written its anchor yet.
+func (r *renderer) mark(n int) {
+	if r.pending == 0 {
+		r.pending = n
+	}
+}
+
+// anchor writes the pending anchor. It is called where texts start, so the
+// anchors are inside the blocks.
+func (r *renderer) anchor() {
+	if r.pending == 0 {
+		return
+	}
+	r.buf.WriteString(`<a name="` + Anchor(r.pending) + `"></a>`)
+	r.lines = append(r.lines, r.pending)
+	r.pending = 0
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package markdown_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/arsham/gistflow/markdown"
+)
+
+var anchors = regexp.MustCompile(`<a name="L\d+"></a>`)
+
+// render returns the HTML of the text without the anchors.
+func render(src string) string {
+	return anchors.ReplaceAllString(markdown.Render(src).HTML, "")
+}
+
+func TestAnchors(t *testing.T) {
+	src := "# Title\n\nSome text\nmore text\n\n- one\n- two\n\n```\ncode\n```\n\n---\n\nlast"
+	doc := markdown.Render(src)
+	want := []int{1, 3, 6, 7, 9, 15}
+	if !reflect.DeepEqual(doc.Lines, want) {
+		t.Errorf("Lines = %v, want %v", doc.Lines, want)
+	}
+	for _, line := range want {
+		if !regexp.MustCompile(`<a name="` + markdown.Anchor(line) + `">`).MatchString(doc.HTML) {
+			t.Errorf("anchor of line %d is not in the HTML", line)
+		}
+	}
+
+	tcs := []struct {
+		line int
+		want string
+	}{
+		{0, ""},
+		{1, "L1"},
+		{2, "L1"},
+		{4, "L3"},
+		{10, "L9"},
+		{100, "L15"},
+	}
+	for _, tc := range tcs {
+		if got := doc.AnchorFor(tc.line); got != tc.want {
+			t.Errorf("AnchorFor(%d) = %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestRenderCRLF(t *testing.T) {
+	if got, want := render("a\r\nb\r\n\r\nc"), "<p>a\nb</p>\n<p>c</p>\n"; got != want {
+		t.Errorf("render() = %q, want %q", got, want)
+	}
+}
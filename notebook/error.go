@@ -0,0 +1,13 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package notebook
+
+import "errors"
+
+// Errors for notebooks.
+var (
+	ErrNotNotebook = errors.New("not a notebook")
+	ErrOldFormat   = errors.New("notebook format is older than version 4")
+)
@@ -0,0 +1,236 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package notebook renders Jupyter notebooks to HTML for viewing. Markdown
+// cells are rendered with the markdown package, and code cells are highlighted
+// with the language of the notebook. Outputs are shown as text, images or
+// HTML, with scripts and styles removed.
+package notebook
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/arsham/gistflow/markdown"
+	"github.com/pkg/errors"
+)
+
+// StyleSheet styles the prompts and the outputs in the HTML.
+const StyleSheet = `
+.prompt { color: gray; }
+.stderr { background-color: #fdd; }
+.error { color: #c00; }
+`
+
+// Document is a rendered notebook. The sources of the images in HTML are the
+// keys of Images, which are made of the hashes of the images.
+type Document struct {
+	HTML   string
+	Images map[string]Image
+}
+
+// Image is an image output of a cell.
+type Image struct {
+	MIME string // like image/png.
+	Data []byte
+}
+
+// multiline is a text that notebooks store either as a string or as a list of
+// lines.
+type multiline string
+
+func (m *multiline) UnmarshalJSON(b []byte) error {
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err == nil {
+		*m = multiline(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*m = multiline(s)
+	return nil
+}
+
+type notebook struct {
+	Format   int    `json:"nbformat"`
+	Cells    []cell `json:"cells"`
+	Metadata struct {
+		Kernel struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		Language struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type cell struct {
+	Type    string    `json:"cell_type"`
+	Source  multiline `json:"source"`
+	Count   *int      `json:"execution_count"`
+	Outputs []output  `json:"outputs"`
+}
+
+type output struct {
+	Type      string                     `json:"output_type"`
+	Name      string                     `json:"name"` // stdout or stderr.
+	Text      multiline                  `json:"text"`
+	Data      map[string]json.RawMessage `json:"data"`
+	Count     *int                       `json:"execution_count"`
+	EName     string                     `json:"ename"`
+	EValue    string                     `json:"evalue"`
+	Traceback []string                   `json:"traceback"`
+}
+
+// imageTypes are the types of the images that can be shown, in the order they
+// are preferred.
+var imageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/svg+xml"}
+
+var (
+	ansi    = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+	scripts = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+)
+
+type renderer struct {
+	buf    bytes.Buffer
+	lang   string
+	images map[string]Image
+}
+
+// Render returns the HTML of the notebook. Only notebooks of version 4 and
+// later are supported.
+func Render(src []byte) (*Document, error) {
+	var nb notebook
+	if err := json.Unmarshal(src, &nb); err != nil {
+		return nil, errors.Wrap(err, "decoding notebook")
+	}
+	switch {
+	case nb.Format == 0:
+		return nil, ErrNotNotebook
+	case nb.Format < 4:
+		return nil, ErrOldFormat
+	}
+	r := &renderer{
+		lang:   nb.Metadata.Language.Name,
+		images: make(map[string]Image),
+	}
+	if r.lang == "" {
+		r.lang = nb.Metadata.Kernel.Language
+	}
+	if r.lang == "" {
+		r.lang = "python"
+	}
+	for _, c := range nb.Cells {
+		r.cell(c)
+	}
+	return &Document{HTML: r.buf.String(), Images: r.images}, nil
+}
+
+func (r *renderer) cell(c cell) {
+	switch c.Type {
+	case "markdown":
+		r.buf.WriteString(markdown.Render(string(c.Source)).HTML)
+	case "code":
+		r.prompt("In", c.Count)
+		r.buf.WriteString(markdown.Code(string(c.Source), r.lang))
+		for _, o := range c.Outputs {
+			r.output(o)
+		}
+	default:
+		r.pre("", string(c.Source))
+	}
+}
+
+// prompt writes the label of an input or an output with its execution count.
+func (r *renderer) prompt(label string, count *int) {
+	n := " "
+	if count != nil {
+		n = fmt.Sprint(*count)
+	}
+	fmt.Fprintf(&r.buf, "<p class=\"prompt\">%s [%s]:</p>\n", label, n)
+}
+
+func (r *renderer) pre(class, text string) {
+	if class != "" {
+		r.buf.WriteString(`<pre class="` + class + `">`)
+	} else {
+		r.buf.WriteString("<pre>")
+	}
+	text = strings.TrimRight(ansi.ReplaceAllString(text, ""), "\n")
+	r.buf.WriteString(html.EscapeString(text) + "</pre>\n")
+}
+
+func (r *renderer) output(o output) {
+	switch o.Type {
+	case "stream":
+		class := "stream"
+		if o.Name == "stderr" {
+			class = "stderr"
+		}
+		r.pre(class, string(o.Text))
+	case "execute_result":
+		r.prompt("Out", o.Count)
+		r.data(o.Data)
+	case "display_data":
+		r.data(o.Data)
+	case "error":
+		text := o.EName + ": " + o.EValue
+		if len(o.Traceback) > 0 {
+			text = strings.Join(o.Traceback, "\n")
+		}
+		r.pre("error", text)
+	}
+}
+
+// data writes the richest type of the output that can be shown.
+func (r *renderer) data(data map[string]json.RawMessage) {
+	text := func(mime string) (string, bool) {
+		raw, ok := data[mime]
+		if !ok {
+			return "", false
+		}
+		var m multiline
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return "", false
+		}
+		return string(m), true
+	}
+	for _, mime := range imageTypes {
+		s, ok := text(mime)
+		if !ok {
+			continue
+		}
+		img := Image{MIME: mime, Data: []byte(s)}
+		if mime != "image/svg+xml" {
+			b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+			if err != nil {
+				continue
+			}
+			img.Data = b
+		}
+		name := fmt.Sprintf("image-%x", sha256.Sum256(img.Data))[:22]
+		r.images[name] = img
+		r.buf.WriteString(`<p><img src="` + name + `"></p>` + "\n")
+		return
+	}
+	if s, ok := text("text/html"); ok {
+		r.buf.WriteString(scripts.ReplaceAllString(s, "") + "\n")
+		return
+	}
+	if s, ok := text("text/markdown"); ok {
+		r.buf.WriteString(markdown.Render(s).HTML)
+		return
+	}
+	if s, ok := text("text/plain"); ok {
+		r.pre("", s)
+	}
+}
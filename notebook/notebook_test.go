@@ -0,0 +1,109 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package notebook_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/notebook"
+	"github.com/pkg/errors"
+)
+
+const sample = `{
+ "nbformat": 4,
+ "nbformat_minor": 2,
+ "metadata": {"language_info": {"name": "python"}},
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Title\n", "Some *text*."]},
+  {"cell_type": "code", "execution_count": 3, "metadata": {}, "source": "x = 1\nx",
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["a < b\n"]},
+    {"output_type": "stream", "name": "stderr", "text": "warning\n"},
+    {"output_type": "execute_result", "execution_count": 3, "metadata": {},
+     "data": {"text/plain": ["1"], "text/html": "<b>1</b><script>alert(1)</script>"}},
+    {"output_type": "display_data", "metadata": {},
+     "data": {"image/png": "iVBORw0K\nGgo=\n", "text/plain": "<Figure>"}},
+    {"output_type": "error", "ename": "ValueError", "evalue": "bad",
+     "traceback": ["\u001b[0;31mValueError\u001b[0m: bad"]}
+   ]},
+  {"cell_type": "code", "execution_count": null, "metadata": {}, "source": [], "outputs": []},
+  {"cell_type": "raw", "metadata": {}, "source": "<raw>"}
+ ]
+}`
+
+func TestRender(t *testing.T) {
+	doc, err := notebook.Render([]byte(sample))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		`<h1>`, `Title</h1>`, `<em>text</em>`,
+		`<p class="prompt">In [3]:</p>`,
+		`<pre class="language-python">x = <span class="number">1</span>`,
+		`<pre class="stream">a &lt; b</pre>`,
+		`<pre class="stderr">warning</pre>`,
+		`<p class="prompt">Out [3]:</p>`,
+		`<b>1</b>`,
+		`<img src="image-`,
+		`<pre class="error">ValueError: bad</pre>`,
+		`<p class="prompt">In [ ]:</p>`,
+		`<pre>&lt;raw&gt;</pre>`,
+	} {
+		if !strings.Contains(doc.HTML, want) {
+			t.Errorf("HTML doesn't contain %q:\n%s", want, doc.HTML)
+		}
+	}
+	for _, unwanted := range []string{"alert", "&lt;Figure&gt;"} {
+		if strings.Contains(doc.HTML, unwanted) {
+			t.Errorf("HTML contains %q:\n%s", unwanted, doc.HTML)
+		}
+	}
+	if len(doc.Images) != 1 {
+		t.Fatalf("len(Images) = %d, want 1", len(doc.Images))
+	}
+	for name, img := range doc.Images {
+		if !strings.Contains(doc.HTML, `<img src="`+name+`">`) {
+			t.Errorf("HTML doesn't show %s", name)
+		}
+		if img.MIME != "image/png" || string(img.Data) != "\x89PNG\r\n\x1a\n" {
+			t.Errorf("Images[%s] = %q, want the decoded PNG", name, img)
+		}
+	}
+}
+
+func TestRenderLanguage(t *testing.T) {
+	src := `{"nbformat": 4, "metadata": {"kernelspec": {"language": "bash"}},
+		"cells": [{"cell_type": "code", "source": "echo $HOME", "outputs": []}]}`
+	doc, err := notebook.Render([]byte(src))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := `<span class="variable">$HOME</span>`; !strings.Contains(doc.HTML, want) {
+		t.Errorf("HTML doesn't contain %q:\n%s", want, doc.HTML)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tcs := []struct {
+		name, src string
+		want      error
+	}{
+		{"not json", "{", nil},
+		{"not notebook", `{"cells": []}`, notebook.ErrNotNotebook},
+		{"old", `{"nbformat": 3, "worksheets": []}`, notebook.ErrOldFormat},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := notebook.Render([]byte(tc.src))
+			if err == nil {
+				t.Fatal("Render(): want an error")
+			}
+			if tc.want != nil && errors.Cause(err) != tc.want {
+				t.Errorf("Render() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package command keeps the actions of the application, so they can be found
+// and run from the command palette.
+package command
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arsham/gistflow/search"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Command is an action that can be run from the command palette.
+type Command struct {
+	Category string
+	Action   *widgets.QAction
+}
+
+// Name returns the category and the text of the action, without the
+// mnemonics.
+func (c Command) Name() string {
+	text := strings.Replace(c.Action.Text(), "&", "", -1)
+	if c.Category == "" {
+		return text
+	}
+	return c.Category + ": " + text
+}
+
+// Shortcut returns the shortcut of the action as it is shown to the user.
+func (c Command) Shortcut() string {
+	return c.Action.Shortcut().ToString(gui.QKeySequence__NativeText)
+}
+
+// Registry holds the commands in the order they are registered. Features add
+// their actions to the registry instead of being wired to the palette.
+type Registry struct {
+	commands []Command
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the actions under the category. Registering an action again
+// only changes its category.
+func (r *Registry) Register(category string, actions ...*widgets.QAction) {
+	for _, a := range actions {
+		if a == nil || a.Pointer() == nil || a.IsSeparator() {
+			continue
+		}
+		if i := r.indexOf(a); i >= 0 {
+			r.commands[i].Category = category
+			continue
+		}
+		r.commands = append(r.commands, Command{Category: category, Action: a})
+	}
+}
+
+func (r *Registry) indexOf(a *widgets.QAction) int {
+	for i, c := range r.commands {
+		if c.Action.Pointer() == a.Pointer() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Commands returns the commands that are enabled.
+func (r *Registry) Commands() []Command {
+	var res []Command
+	for _, c := range r.commands {
+		if c.Action.IsEnabled() {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Find returns the enabled commands with names that fuzzy match the query, the
+// best matches first. All of them are returned for an empty query.
+func (r *Registry) Find(query string) []Command {
+	query = strings.TrimSpace(query)
+	type scored struct {
+		Command
+		score int
+	}
+	var found []scored
+	for _, c := range r.Commands() {
+		if score, _, ok := search.Fuzzy(query, c.Name()); ok {
+			found = append(found, scored{c, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	res := make([]Command, len(found))
+	for i, f := range found {
+		res[i] = f.Command
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package command
+
+import (
+	"os"
+	"testing"
+
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+var app *widgets.QApplication
+
+func TestMain(m *testing.M) {
+	app = widgets.NewQApplication(len(os.Args), os.Args)
+	go func() { app.Exit(m.Run()) }()
+	app.Exec()
+}
+
+func names(commands []Command) []string {
+	res := make([]string, len(commands))
+	for i, c := range commands {
+		res[i] = c.Name()
+	}
+	return res
+}
+
+func TestRegister(t *testing.T) { tRunner.Run(func() { testRegister(t) }) }
+func testRegister(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	quit := widgets.NewQAction2("&Quit", parent)
+	quit.SetShortcut(gui.NewQKeySequence2("Ctrl+Q", gui.QKeySequence__NativeText))
+	settings := widgets.NewQAction2("Settings", parent)
+	separator := widgets.NewQAction(parent)
+	separator.SetSeparator(true)
+
+	r := NewRegistry()
+	r.Register("Options", quit, nil, separator, settings)
+	r.Register("File", quit)
+
+	commands := r.Commands()
+	if len(commands) != 2 {
+		t.Fatalf("len(r.Commands()) = %d, want 2: %v", len(commands), names(commands))
+	}
+	if got := commands[0].Name(); got != "File: Quit" {
+		t.Errorf("Name() = %q, want File: Quit", got)
+	}
+	if got := commands[0].Shortcut(); got != "Ctrl+Q" {
+		t.Errorf("Shortcut() = %q, want Ctrl+Q", got)
+	}
+	if got := commands[1].Shortcut(); got != "" {
+		t.Errorf("Shortcut() = %q, want empty", got)
+	}
+
+	settings.SetEnabled(false)
+	if got := names(r.Commands()); len(got) != 1 {
+		t.Errorf("r.Commands() = %v, want the disabled action left out", got)
+	}
+}
+
+func TestFind(t *testing.T) { tRunner.Run(func() { testFind(t) }) }
+func testFind(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	r := NewRegistry()
+	r.Register("Window",
+		widgets.NewQAction2("Toolbar", parent),
+		widgets.NewQAction2("Gist List", parent),
+	)
+	r.Register("Options", widgets.NewQAction2("New Gist", parent))
+
+	tcs := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Window: Toolbar", "Window: Gist List", "Options: New Gist"}},
+		{"  ", []string{"Window: Toolbar", "Window: Gist List", "Options: New Gist"}},
+		{"new", []string{"Options: New Gist"}},
+		{"gist", []string{"Window: Gist List", "Options: New Gist"}},
+		{"xyz", nil},
+	}
+	for _, tc := range tcs {
+		got := names(r.Find(tc.query))
+		if len(got) != len(tc.want) {
+			t.Errorf("Find(%q) = %v, want %v", tc.query, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("Find(%q) = %v, want %v", tc.query, got, tc.want)
+				break
+			}
+		}
+	}
+}
@@ -6,6 +6,8 @@ package conf
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/therecipe/qt/core"
 	"github.com/therecipe/qt/widgets"
@@ -13,22 +15,67 @@ import (
 
 // Variable names in settings.
 const (
-	AccessToken = "access_token"
-	Username    = "username"
+	AccessToken   = "access_token"
+	Username      = "username"
+	CacheLimit    = "cache_limit"
+	EncryptCache  = "encrypt_cache"
+	CacheKeyFile  = "cache_key_file"
+	IdentityFile  = "identity_file"
+	Recipients    = "recipients"
+	Allowlist     = "scan_allowlist"
+	TabWidth      = "tab_width"
+	IndentSpaces  = "indent_spaces"
+	AutoSave      = "auto_save"
+	AutoSaveDelay = "auto_save_delay"
+	AutoSaveGists = "auto_save_gists"
 )
 
+// DefaultCacheLimit is the cache size limit in megabytes when it has not been
+// set by the user.
+const DefaultCacheLimit = 100
+
+// DefaultTabWidth is the number of columns of a tab in the editor when it has
+// not been set by the user.
+const DefaultTabWidth = 4
+
+// DefaultAutoSaveDelay is the number of seconds the typing should pause before
+// the gist is auto-saved, when it has not been set by the user.
+const DefaultAutoSaveDelay = 5
+
 // Tab is a tab shown in the tabWidget area that contains the application's
 // settings.
 type Tab struct {
 	widgets.QTabWidget
 
-	_ func() `constructor:"init"`
+	_ func()     `constructor:"init"`
+	_ func()     `signal:"clearCache"`
+	_ func()     `signal:"compactCache"`
+	_ func(bool) `signal:"encryptCache"`
+	_ func()     `signal:"generateIdentity"`
+
+	Name               string // This should be the application name to reflect the right entry.
+	settings           *Settings
+	GridLayout         *widgets.QGridLayout
+	UsernameInput      *widgets.QLineEdit
+	AccessTokenInput   *widgets.QLineEdit
+	CacheLimitInput    *widgets.QSpinBox
+	CacheSizeLabel     *widgets.QLabel
+	ClearCacheButton   *widgets.QPushButton
+	CompactCacheButton *widgets.QPushButton
+	EncryptCacheInput  *widgets.QCheckBox
+	CacheKeyFileInput  *widgets.QLineEdit
+
+	IdentityFileInput      *widgets.QLineEdit
+	GenerateIdentityButton *widgets.QPushButton
+	RecipientsInput        *widgets.QLineEdit
 
-	Name             string // This should be the application name to reflect the right entry.
-	settings         *Settings
-	GridLayout       *widgets.QGridLayout
-	UsernameInput    *widgets.QLineEdit
-	AccessTokenInput *widgets.QLineEdit
+	AllowlistInput *widgets.QPlainTextEdit
+
+	TabWidthInput     *widgets.QSpinBox
+	IndentSpacesInput *widgets.QCheckBox
+
+	AutoSaveInput      *widgets.QCheckBox
+	AutoSaveDelayInput *widgets.QSpinBox
 }
 
 func (t *Tab) init() {
@@ -55,6 +102,140 @@ func (t *Tab) init() {
 	label3.SetTextInteractionFlags(core.Qt__TextBrowserInteraction)
 	label3.SetOpenExternalLinks(true)
 	t.GridLayout.AddWidget3(label3, 2, 0, 1, 2, 0)
+
+	t.cacheSection()
+	t.encryptionSection()
+	t.scanSection()
+	t.editorSection()
+	t.autoSaveSection()
+}
+
+func (t *Tab) cacheSection() {
+	cacheBox := widgets.NewQGroupBox2("Cache", t)
+	cacheBox.SetGeometry(core.NewQRect4(10, 190, 511, 161))
+	cacheLayout := widgets.NewQGridLayout(cacheBox)
+	cacheLayout.SetObjectName("cacheLayout")
+	cacheLayout.SetContentsMargins(0, 0, 0, 0)
+	cacheLayout.SetSpacing(0)
+	label4 := widgets.NewQLabel2("Size limit", cacheBox, core.Qt__Widget)
+	cacheLayout.AddWidget3(label4, 0, 0, 1, 1, 0)
+	t.CacheLimitInput = widgets.NewQSpinBox(cacheBox)
+	t.CacheLimitInput.SetRange(0, 100000)
+	t.CacheLimitInput.SetSuffix(" MB")
+	t.CacheLimitInput.SetSpecialValueText("No limit")
+	t.CacheLimitInput.SetToolTip("The least recently used gists are removed from the cache when it grows over this size")
+	cacheLayout.AddWidget3(t.CacheLimitInput, 0, 1, 1, 2, 0)
+	t.CacheSizeLabel = widgets.NewQLabel2("", cacheBox, core.Qt__Widget)
+	cacheLayout.AddWidget3(t.CacheSizeLabel, 1, 0, 1, 1, 0)
+	t.CompactCacheButton = widgets.NewQPushButton2("Compact", cacheBox)
+	t.CompactCacheButton.SetToolTip("Removes the least recently used gists until the cache is under its limit")
+	cacheLayout.AddWidget3(t.CompactCacheButton, 1, 1, 1, 1, 0)
+	t.ClearCacheButton = widgets.NewQPushButton2("Clear", cacheBox)
+	t.ClearCacheButton.SetToolTip("Removes all gists from the cache, except the ones kept for offline use")
+	cacheLayout.AddWidget3(t.ClearCacheButton, 1, 2, 1, 1, 0)
+	t.EncryptCacheInput = widgets.NewQCheckBox2("Encrypt", cacheBox)
+	t.EncryptCacheInput.SetToolTip("Encrypts the cached gists. You will be asked for the passphrase when the application starts")
+	cacheLayout.AddWidget3(t.EncryptCacheInput, 2, 0, 1, 1, 0)
+	t.CacheKeyFileInput = widgets.NewQLineEdit(cacheBox)
+	t.CacheKeyFileInput.SetClearButtonEnabled(true)
+	t.CacheKeyFileInput.SetPlaceholderText("Key file (leave empty to use a passphrase)")
+	cacheLayout.AddWidget3(t.CacheKeyFileInput, 2, 1, 1, 2, 0)
+	t.SetCacheSize(0)
+
+	t.ClearCacheButton.ConnectClicked(func(bool) {
+		t.ClearCache()
+	})
+	t.CompactCacheButton.ConnectClicked(func(bool) {
+		t.CompactCache()
+	})
+	t.EncryptCacheInput.ConnectClicked(func(checked bool) {
+		t.EncryptCache(checked)
+	})
+}
+
+func (t *Tab) encryptionSection() {
+	box := widgets.NewQGroupBox2("Encrypted Gists", t)
+	box.SetGeometry(core.NewQRect4(10, 360, 511, 161))
+	layout := widgets.NewQGridLayout(box)
+	layout.SetObjectName("encryptionLayout")
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(0)
+	label := widgets.NewQLabel2("Identity file", box, core.Qt__Widget)
+	layout.AddWidget3(label, 0, 0, 1, 1, 0)
+	t.IdentityFileInput = widgets.NewQLineEdit(box)
+	t.IdentityFileInput.SetClearButtonEnabled(true)
+	t.IdentityFileInput.SetPlaceholderText("Leave empty to use a passphrase")
+	t.IdentityFileInput.SetToolTip("The file holding your private key for decrypting gists")
+	layout.AddWidget3(t.IdentityFileInput, 0, 1, 1, 1, 0)
+	t.GenerateIdentityButton = widgets.NewQPushButton2("Generate", box)
+	t.GenerateIdentityButton.SetToolTip("Creates a new identity and copies its public key to the clipboard")
+	layout.AddWidget3(t.GenerateIdentityButton, 0, 2, 1, 1, 0)
+	label2 := widgets.NewQLabel2("Recipients", box, core.Qt__Widget)
+	layout.AddWidget3(label2, 1, 0, 1, 1, 0)
+	t.RecipientsInput = widgets.NewQLineEdit(box)
+	t.RecipientsInput.SetClearButtonEnabled(true)
+	t.RecipientsInput.SetPlaceholderText("Public keys, separated by commas")
+	t.RecipientsInput.SetToolTip("Gists are also encrypted for these public keys")
+	layout.AddWidget3(t.RecipientsInput, 1, 1, 1, 2, 0)
+
+	t.GenerateIdentityButton.ConnectClicked(func(bool) {
+		t.GenerateIdentity()
+	})
+}
+
+func (t *Tab) scanSection() {
+	box := widgets.NewQGroupBox2("Secret Scanning", t)
+	box.SetGeometry(core.NewQRect4(10, 530, 511, 161))
+	layout := widgets.NewQVBoxLayout2(box)
+	layout.SetContentsMargins(0, 0, 0, 0)
+	labelText := "Gists are checked for tokens and keys before saving. Texts matching any of these regular expressions are not reported. You can also put gistflow:allow on a line to skip it."
+	label := widgets.NewQLabel2(labelText, box, core.Qt__Widget)
+	label.SetWordWrap(true)
+	layout.AddWidget(label, 0, 0)
+	t.AllowlistInput = widgets.NewQPlainTextEdit(box)
+	t.AllowlistInput.SetPlaceholderText("One pattern per line")
+	layout.AddWidget(t.AllowlistInput, 0, 0)
+}
+
+func (t *Tab) editorSection() {
+	box := widgets.NewQGroupBox2("Editor", t)
+	box.SetGeometry(core.NewQRect4(10, 700, 511, 61))
+	layout := widgets.NewQGridLayout(box)
+	layout.SetObjectName("editorLayout")
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(0)
+	label := widgets.NewQLabel2("Tab width", box, core.Qt__Widget)
+	layout.AddWidget3(label, 0, 0, 1, 1, 0)
+	t.TabWidthInput = widgets.NewQSpinBox(box)
+	t.TabWidthInput.SetRange(1, 16)
+	t.TabWidthInput.SetSuffix(" columns")
+	layout.AddWidget3(t.TabWidthInput, 0, 1, 1, 1, 0)
+	t.IndentSpacesInput = widgets.NewQCheckBox2("Indent with spaces", box)
+	t.IndentSpacesInput.SetToolTip("Inserts spaces instead of a tab when indenting")
+	layout.AddWidget3(t.IndentSpacesInput, 0, 2, 1, 1, 0)
+}
+
+func (t *Tab) autoSaveSection() {
+	box := widgets.NewQGroupBox2("Auto-save", t)
+	box.SetGeometry(core.NewQRect4(10, 770, 511, 61))
+	layout := widgets.NewQGridLayout(box)
+	layout.SetObjectName("autoSaveLayout")
+	layout.SetContentsMargins(0, 0, 0, 0)
+	layout.SetSpacing(0)
+	t.AutoSaveInput = widgets.NewQCheckBox2("Auto-save all gists", box)
+	t.AutoSaveInput.SetToolTip("Saves the changes of the open gists on github when you stop typing. It can also be turned on for each gist in its tab. New gists are never saved automatically.")
+	layout.AddWidget3(t.AutoSaveInput, 0, 0, 1, 1, 0)
+	label := widgets.NewQLabel2("After", box, core.Qt__Widget)
+	layout.AddWidget3(label, 0, 1, 1, 1, 0)
+	t.AutoSaveDelayInput = widgets.NewQSpinBox(box)
+	t.AutoSaveDelayInput.SetRange(1, 600)
+	t.AutoSaveDelayInput.SetSuffix(" seconds idle")
+	layout.AddWidget3(t.AutoSaveDelayInput, 0, 2, 1, 1, 0)
+}
+
+// SetCacheSize shows the current size of the cache. size is in bytes.
+func (t *Tab) SetCacheSize(size int64) {
+	t.CacheSizeLabel.SetText(fmt.Sprintf("Current size: %.1f MB", float64(size)/(1<<20)))
 }
 
 // SetSettings assigns the Settings instance and updates it when the values are
@@ -71,6 +252,11 @@ func (t *Tab) SetSettings(s *Settings) {
 		s.Token = text
 		s.Sync()
 	})
+	t.CacheLimitInput.ConnectValueChanged(func(value int) {
+		s.SetValue(CacheLimit, core.NewQVariant7(value))
+		s.CacheLimit = value
+		s.Sync()
+	})
 
 	v := s.Value(Username, core.NewQVariant17(""))
 	if v.ToString() != "" {
@@ -80,13 +266,90 @@ func (t *Tab) SetSettings(s *Settings) {
 	if v.ToString() != "" {
 		t.AccessTokenInput.SetText(v.ToString())
 	}
+	t.CacheLimitInput.SetValue(s.CacheLimit)
+
+	t.EncryptCacheInput.ConnectToggled(func(checked bool) {
+		s.SetValue(EncryptCache, core.NewQVariant11(checked))
+		s.EncryptCache = checked
+		s.Sync()
+	})
+	t.CacheKeyFileInput.ConnectTextChanged(func(text string) {
+		s.SetValue(CacheKeyFile, core.NewQVariant17(text))
+		s.CacheKeyFile = text
+		s.Sync()
+	})
+	t.EncryptCacheInput.SetChecked(s.EncryptCache)
+	t.CacheKeyFileInput.SetText(s.CacheKeyFile)
+
+	t.IdentityFileInput.ConnectTextChanged(func(text string) {
+		s.SetValue(IdentityFile, core.NewQVariant17(text))
+		s.IdentityFile = text
+		s.Sync()
+	})
+	t.RecipientsInput.ConnectTextChanged(func(text string) {
+		s.SetValue(Recipients, core.NewQVariant17(text))
+		s.Recipients = text
+		s.Sync()
+	})
+	t.IdentityFileInput.SetText(s.IdentityFile)
+	t.RecipientsInput.SetText(s.Recipients)
+
+	t.AllowlistInput.ConnectTextChanged(func() {
+		text := t.AllowlistInput.ToPlainText()
+		s.SetValue(Allowlist, core.NewQVariant17(text))
+		s.Allowlist = text
+		s.Sync()
+	})
+	t.AllowlistInput.SetPlainText(s.Allowlist)
+
+	t.TabWidthInput.ConnectValueChanged(func(value int) {
+		s.SetValue(TabWidth, core.NewQVariant7(value))
+		s.TabWidth = value
+		s.Sync()
+	})
+	t.IndentSpacesInput.ConnectToggled(func(checked bool) {
+		s.SetValue(IndentSpaces, core.NewQVariant11(checked))
+		s.IndentSpaces = checked
+		s.Sync()
+	})
+	t.TabWidthInput.SetValue(s.TabWidth)
+	t.IndentSpacesInput.SetChecked(s.IndentSpaces)
+
+	t.AutoSaveInput.ConnectToggled(func(checked bool) {
+		s.SetValue(AutoSave, core.NewQVariant11(checked))
+		s.AutoSave = checked
+		s.Sync()
+	})
+	t.AutoSaveDelayInput.ConnectValueChanged(func(value int) {
+		s.SetValue(AutoSaveDelay, core.NewQVariant7(value))
+		s.AutoSaveDelay = value
+		s.Sync()
+	})
+	t.AutoSaveInput.SetChecked(s.AutoSave)
+	t.AutoSaveDelayInput.SetValue(s.AutoSaveDelay)
 }
 
 // Settings holds the written settings loaded from system.
 type Settings struct {
 	*core.QSettings
-	Token    string
-	Username string
+	Token      string
+	Username   string
+	CacheLimit int // in megabytes, zero means no limit.
+
+	EncryptCache bool
+	CacheKeyFile string // when empty, the user is asked for a passphrase.
+
+	IdentityFile string // private key for decrypting gists.
+	Recipients   string // comma separated public keys for encrypting gists.
+
+	Allowlist string // newline separated patterns ignored by the secret scanner.
+
+	TabWidth     int  // columns of a tab in the editor.
+	IndentSpaces bool // indent with spaces instead of tabs.
+
+	AutoSave      bool   // auto-save all gists.
+	AutoSaveDelay int    // seconds of idle typing before auto-saving.
+	AutoSaveGists string // comma separated IDs of the gists to auto-save.
 }
 
 // New returns an instance of Settings. name is the application name, which is
@@ -109,9 +372,97 @@ func New(name string) (*Settings, error) {
 	if username.ToString() == "" {
 		err = errors.New("empty username")
 	}
+	cacheLimit := s.Value(CacheLimit, core.NewQVariant7(DefaultCacheLimit))
+	encryptCache := s.Value(EncryptCache, core.NewQVariant11(false))
+	cacheKeyFile := s.Value(CacheKeyFile, core.NewQVariant17(""))
+	identityFile := s.Value(IdentityFile, core.NewQVariant17(""))
+	recipients := s.Value(Recipients, core.NewQVariant17(""))
+	allowlist := s.Value(Allowlist, core.NewQVariant17(""))
+	tabWidth := s.Value(TabWidth, core.NewQVariant7(DefaultTabWidth))
+	indentSpaces := s.Value(IndentSpaces, core.NewQVariant11(false))
+	autoSave := s.Value(AutoSave, core.NewQVariant11(false))
+	autoSaveDelay := s.Value(AutoSaveDelay, core.NewQVariant7(DefaultAutoSaveDelay))
+	autoSaveGists := s.Value(AutoSaveGists, core.NewQVariant17(""))
 	return &Settings{
-		Token:     token.ToString(),
-		Username:  username.ToString(),
-		QSettings: s,
+		Token:         token.ToString(),
+		Username:      username.ToString(),
+		CacheLimit:    cacheLimit.ToInt(nil),
+		EncryptCache:  encryptCache.ToBool(),
+		CacheKeyFile:  cacheKeyFile.ToString(),
+		IdentityFile:  identityFile.ToString(),
+		Recipients:    recipients.ToString(),
+		Allowlist:     allowlist.ToString(),
+		TabWidth:      tabWidth.ToInt(nil),
+		IndentSpaces:  indentSpaces.ToBool(),
+		AutoSave:      autoSave.ToBool(),
+		AutoSaveDelay: autoSaveDelay.ToInt(nil),
+		AutoSaveGists: autoSaveGists.ToString(),
+		QSettings:     s,
 	}, err
 }
+
+// RecipientList returns the public keys in Recipients.
+func (s *Settings) RecipientList() []string {
+	var list []string
+	for _, r := range strings.Split(s.Recipients, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+// AllowlistEntries returns the patterns in Allowlist.
+func (s *Settings) AllowlistEntries() []string {
+	var list []string
+	for _, e := range strings.Split(s.Allowlist, "\n") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// CacheSize returns the cache limit in bytes.
+func (s *Settings) CacheSize() int64 {
+	return int64(s.CacheLimit) << 20
+}
+
+// SetEncryptCache turns encrypting the cache on or off, and writes the
+// setting.
+func (s *Settings) SetEncryptCache(encrypt bool) {
+	s.EncryptCache = encrypt
+	s.SetValue(EncryptCache, core.NewQVariant11(encrypt))
+	s.Sync()
+}
+
+// AutoSaveGist returns true if the gist identified by id is auto-saved, either
+// by itself or because all gists are.
+func (s *Settings) AutoSaveGist(id string) bool {
+	if s.AutoSave {
+		return true
+	}
+	for _, g := range strings.Split(s.AutoSaveGists, ",") {
+		if g == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAutoSaveGist turns auto-saving the gist identified by id on or off, and
+// writes the setting.
+func (s *Settings) SetAutoSaveGist(id string, on bool) {
+	var ids []string
+	for _, g := range strings.Split(s.AutoSaveGists, ",") {
+		if g != "" && g != id {
+			ids = append(ids, g)
+		}
+	}
+	if on {
+		ids = append(ids, id)
+	}
+	s.AutoSaveGists = strings.Join(ids, ",")
+	s.SetValue(AutoSaveGists, core.NewQVariant17(s.AutoSaveGists))
+	s.Sync()
+}
@@ -6,6 +6,7 @@ package conf
 
 import (
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/therecipe/qt/core"
@@ -101,6 +102,9 @@ func testTab(t *testing.T) {
 	if tab.AccessTokenInput == nil {
 		t.Error("tab.AccessTokenInput = nil, want *widgets.QLineEdit")
 	}
+	if tab.CacheLimitInput == nil {
+		t.Error("tab.CacheLimitInput = nil, want *widgets.QSpinBox")
+	}
 
 	_, cleanup := testSettings(appName)
 	defer cleanup()
@@ -152,3 +156,137 @@ func testTabPrePopulate(t *testing.T) {
 		t.Errorf("tab.AccessTokenInput.Text() = %s, want %s", tab.AccessTokenInput.Text(), accessToken)
 	}
 }
+
+func TestCacheLimit(t *testing.T) { tRunner.Run(func() { testCacheLimit(t) }) }
+func testCacheLimit(t *testing.T) {
+	_, cleanup := testSettings(appName)
+	defer cleanup()
+	settings, err := New(appName)
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if settings.CacheLimit != DefaultCacheLimit {
+		t.Errorf("settings.CacheLimit = %d, want %d", settings.CacheLimit, DefaultCacheLimit)
+	}
+
+	tab := NewTab(nil)
+	tab.SetSettings(settings)
+	if tab.CacheLimitInput.Value() != DefaultCacheLimit {
+		t.Errorf("tab.CacheLimitInput.Value() = %d, want %d", tab.CacheLimitInput.Value(), DefaultCacheLimit)
+	}
+	limit := 42
+	tab.CacheLimitInput.SetValue(limit)
+	v := settings.Value(CacheLimit, core.NewQVariant7(0))
+	if v.ToInt(nil) != limit {
+		t.Errorf("written cache limit = %d, want %d", v.ToInt(nil), limit)
+	}
+	if settings.CacheSize() != int64(limit)<<20 {
+		t.Errorf("settings.CacheSize() = %d, want %d", settings.CacheSize(), int64(limit)<<20)
+	}
+
+	settings.SetEncryptCache(true)
+	if !settings.EncryptCache || !settings.Value(EncryptCache, core.NewQVariant11(false)).ToBool() {
+		t.Error("encrypting the cache was not written")
+	}
+}
+
+func TestCacheButtons(t *testing.T) { tRunner.Run(func() { testCacheButtons(t) }) }
+func testCacheButtons(t *testing.T) {
+	var clearCalled, compactCalled bool
+	tab := NewTab(nil)
+	tab.ConnectClearCache(func() { clearCalled = true })
+	tab.ConnectCompactCache(func() { compactCalled = true })
+
+	tab.ClearCacheButton.Click()
+	if !clearCalled {
+		t.Error("didn't send the clearCache signal")
+	}
+	tab.CompactCacheButton.Click()
+	if !compactCalled {
+		t.Error("didn't send the compactCache signal")
+	}
+}
+
+func TestAllowlist(t *testing.T) { tRunner.Run(func() { testAllowlist(t) }) }
+func testAllowlist(t *testing.T) {
+	_, cleanup := testSettings(appName)
+	defer cleanup()
+	settings, _ := New(appName)
+	tab := NewTab(nil)
+	tab.SetSettings(settings)
+
+	tab.AllowlistInput.SetPlainText("AKIA.*EXAMPLE\n\n  ghp_test  \n")
+	v := settings.Value(Allowlist, core.NewQVariant17(""))
+	if v.ToString() != tab.AllowlistInput.ToPlainText() {
+		t.Errorf("written allowlist = %q, want %q", v.ToString(), tab.AllowlistInput.ToPlainText())
+	}
+	want := []string{"AKIA.*EXAMPLE", "ghp_test"}
+	if got := settings.AllowlistEntries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("settings.AllowlistEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestEditorSettings(t *testing.T) { tRunner.Run(func() { testEditorSettings(t) }) }
+func testEditorSettings(t *testing.T) {
+	_, cleanup := testSettings(appName)
+	defer cleanup()
+	settings, _ := New(appName)
+	if settings.TabWidth != DefaultTabWidth || settings.IndentSpaces {
+		t.Errorf("TabWidth = %d, IndentSpaces = %t, want %d and false", settings.TabWidth, settings.IndentSpaces, DefaultTabWidth)
+	}
+	tab := NewTab(nil)
+	tab.SetSettings(settings)
+	if tab.TabWidthInput.Value() != DefaultTabWidth {
+		t.Errorf("TabWidthInput.Value() = %d, want %d", tab.TabWidthInput.Value(), DefaultTabWidth)
+	}
+
+	tab.TabWidthInput.SetValue(2)
+	tab.IndentSpacesInput.SetChecked(true)
+	if v := settings.Value(TabWidth, core.NewQVariant7(0)).ToInt(nil); v != 2 {
+		t.Errorf("written tab width = %d, want 2", v)
+	}
+	if !settings.Value(IndentSpaces, core.NewQVariant11(false)).ToBool() || !settings.IndentSpaces {
+		t.Error("indenting with spaces was not written")
+	}
+}
+
+func TestAutoSaveSettings(t *testing.T) { tRunner.Run(func() { testAutoSaveSettings(t) }) }
+func testAutoSaveSettings(t *testing.T) {
+	_, cleanup := testSettings(appName)
+	defer cleanup()
+	settings, _ := New(appName)
+	if settings.AutoSave || settings.AutoSaveDelay != DefaultAutoSaveDelay {
+		t.Errorf("AutoSave = %t, AutoSaveDelay = %d, want false and %d", settings.AutoSave, settings.AutoSaveDelay, DefaultAutoSaveDelay)
+	}
+	tab := NewTab(nil)
+	tab.SetSettings(settings)
+	if tab.AutoSaveDelayInput.Value() != DefaultAutoSaveDelay {
+		t.Errorf("AutoSaveDelayInput.Value() = %d, want %d", tab.AutoSaveDelayInput.Value(), DefaultAutoSaveDelay)
+	}
+
+	settings.SetAutoSaveGist("a1", true)
+	settings.SetAutoSaveGist("b1", true)
+	settings.SetAutoSaveGist("a1", true)
+	if !settings.AutoSaveGist("a1") || !settings.AutoSaveGist("b1") || settings.AutoSaveGist("c1") {
+		t.Errorf("AutoSaveGists = %q", settings.AutoSaveGists)
+	}
+	settings.SetAutoSaveGist("a1", false)
+	if settings.AutoSaveGist("a1") {
+		t.Error("the gist is still auto-saved after turning it off")
+	}
+	if v := settings.Value(AutoSaveGists, core.NewQVariant17("")).ToString(); v != "b1" {
+		t.Errorf("written gists = %q, want %q", v, "b1")
+	}
+
+	tab.AutoSaveInput.SetChecked(true)
+	tab.AutoSaveDelayInput.SetValue(30)
+	if !settings.AutoSaveGist("c1") {
+		t.Error("the gists are not auto-saved when all are")
+	}
+	if v := settings.Value(AutoSaveDelay, core.NewQVariant7(0)).ToInt(nil); v != 30 {
+		t.Errorf("written delay = %d, want 30", v)
+	}
+	if !settings.Value(AutoSave, core.NewQVariant11(false)).ToBool() {
+		t.Error("auto-saving all gists was not written")
+	}
+}
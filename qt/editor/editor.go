@@ -0,0 +1,386 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package editor provides a plain text editor for the contents of the gists.
+package editor
+
+import (
+	"strings"
+
+	"github.com/arsham/gistflow/syntax"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const (
+	// DefaultTabWidth is the width of the tabs in spaces when it has not been
+	// set.
+	DefaultTabWidth = 4
+)
+
+// Editor is a code editor with line numbers, current line highlighting,
+// syntax highlighting, auto-indent, bracket matching, code folding, zooming
+// and going to a line.
+type Editor struct {
+	widgets.QPlainTextEdit
+
+	_ func() `constructor:"init"`
+
+	gutter      *widgets.QWidget // shows the line numbers and the fold markers.
+	highlighter *Highlighter
+	tabWidth    int
+	spaces      bool // indent with spaces instead of tabs.
+	zoom        int  // steps zoomed in, negative when zoomed out.
+	actions     []*widgets.QAction
+
+	askLine func(current, max int) (int, bool) // asks the user for a line number.
+}
+
+func (e *Editor) init() {
+	e.tabWidth = DefaultTabWidth
+	e.SetFont(gui.QFontDatabase_SystemFont(gui.QFontDatabase__FixedFont))
+	e.SetLineWrapMode(widgets.QPlainTextEdit__NoWrap)
+	e.updateTabStop()
+
+	e.gutter = widgets.NewQWidget(e, 0)
+	e.gutter.ConnectPaintEvent(e.paintGutter)
+	e.gutter.ConnectMousePressEvent(e.gutterClicked)
+	e.ConnectBlockCountChanged(func(int) { e.updateGutterWidth() })
+	e.ConnectUpdateRequest(func(rect *core.QRect, dy int) {
+		if dy != 0 {
+			e.gutter.Scroll(0, dy)
+			return
+		}
+		e.gutter.Update2(0, rect.Y(), e.gutter.Width(), rect.Height())
+	})
+	e.ConnectResizeEvent(func(event *gui.QResizeEvent) {
+		e.ResizeEventDefault(event)
+		rect := e.ContentsRect()
+		e.gutter.SetGeometry2(rect.Left(), rect.Top(), e.gutterWidth(), rect.Height())
+	})
+	e.updateGutterWidth()
+
+	e.highlighter = NewHighlighter2(e.Document())
+	e.highlighter.SetPalette(e.Palette())
+	e.ConnectChangeEvent(func(event *core.QEvent) {
+		if event.Type() == core.QEvent__PaletteChange {
+			e.highlighter.SetPalette(e.Palette())
+		}
+		e.ChangeEventDefault(event)
+	})
+
+	e.ConnectPaintEvent(e.paintEvent)
+	e.ConnectCursorPositionChanged(func() { e.Viewport().Update() })
+	e.ConnectKeyPressEvent(e.keyPressEvent)
+	e.ConnectWheelEvent(func(event *gui.QWheelEvent) {
+		if event.Modifiers()&core.Qt__ControlModifier == 0 {
+			e.WheelEventDefault(event)
+			return
+		}
+		if event.AngleDelta().Y() > 0 {
+			e.Zoom(1)
+		} else {
+			e.Zoom(-1)
+		}
+		event.Accept()
+	})
+
+	e.askLine = func(current, max int) (int, bool) {
+		var ok bool
+		line := widgets.QInputDialog_GetInt(e, "Go to Line", "Line number", current, 1, max, 1, &ok, 0)
+		return line, ok
+	}
+	e.setupActions()
+}
+
+func (e *Editor) setupActions() {
+	e.actions = NewActions(e, func() *Editor { return e })
+	for _, action := range e.actions {
+		action.SetShortcutContext(core.Qt__WidgetWithChildrenShortcut)
+		e.AddAction(action)
+	}
+}
+
+// NewActions returns the actions of the editors, which work on the editor that
+// current returns, and do nothing when it returns nil. Their shortcuts are only
+// active when they are added to a widget.
+func NewActions(parent core.QObject_ITF, current func() *Editor) []*widgets.QAction {
+	var actions []*widgets.QAction
+	add := func(text string, shortcuts []string, fn func(*Editor)) {
+		action := widgets.NewQAction2(text, parent)
+		var keys []*gui.QKeySequence
+		for _, s := range shortcuts {
+			keys = append(keys, gui.NewQKeySequence2(s, gui.QKeySequence__PortableText))
+		}
+		action.SetShortcuts(keys)
+		action.ConnectTriggered(func(bool) {
+			if e := current(); e != nil {
+				fn(e)
+			}
+		})
+		actions = append(actions, action)
+	}
+	add("Zoom In", []string{"Ctrl++", "Ctrl+="}, func(e *Editor) { e.Zoom(1) })
+	add("Zoom Out", []string{"Ctrl+-"}, func(e *Editor) { e.Zoom(-1) })
+	add("Reset Zoom", []string{"Ctrl+0"}, (*Editor).ResetZoom)
+	add("Go to Line...", []string{"Ctrl+G"}, (*Editor).askGoToLine)
+	add("Fold", []string{"Ctrl+Shift+["}, func(e *Editor) { e.Fold(e.currentLine()) })
+	add("Unfold", []string{"Ctrl+Shift+]"}, func(e *Editor) { e.Unfold(e.currentLine()) })
+	return actions
+}
+
+// Actions returns the actions of the editor with their shortcuts.
+func (e *Editor) Actions() []*widgets.QAction { return e.actions }
+
+// SetFileName highlights the text with the grammar of the file's language.
+func (e *Editor) SetFileName(name string) {
+	if g := syntax.ForFile(name); g != e.highlighter.Grammar() {
+		e.highlighter.SetGrammar(g)
+	}
+}
+
+// Highlighter returns the syntax highlighter of the text.
+func (e *Editor) Highlighter() *Highlighter { return e.highlighter }
+
+// SetTabWidth sets the width of the tabs and the indentation in spaces.
+func (e *Editor) SetTabWidth(width int) {
+	if width < 1 {
+		width = DefaultTabWidth
+	}
+	e.tabWidth = width
+	e.updateTabStop()
+}
+
+// TabWidth returns the width of the tabs in spaces.
+func (e *Editor) TabWidth() int { return e.tabWidth }
+
+// SetIndentWithSpaces sets whether the Tab key and auto-indent insert spaces
+// instead of tabs.
+func (e *Editor) SetIndentWithSpaces(spaces bool) { e.spaces = spaces }
+
+// IndentWithSpaces returns true if the editor indents with spaces.
+func (e *Editor) IndentWithSpaces() bool { return e.spaces }
+
+// indentUnit returns the text of one level of indentation.
+func (e *Editor) indentUnit() string {
+	if e.spaces {
+		return strings.Repeat(" ", e.tabWidth)
+	}
+	return "\t"
+}
+
+func (e *Editor) updateTabStop() {
+	metrics := gui.NewQFontMetricsF(e.Font())
+	e.SetTabStopDistance(float64(e.tabWidth) * metrics.Width(" "))
+}
+
+// Zoom makes the font larger by steps, or smaller if steps is negative.
+func (e *Editor) Zoom(steps int) {
+	if steps > 0 {
+		e.ZoomIn(steps)
+	} else {
+		e.ZoomOut(-steps)
+	}
+	e.zoom += steps
+	e.updateTabStop()
+	e.updateGutterWidth()
+}
+
+// ResetZoom brings the font back to its size before zooming.
+func (e *Editor) ResetZoom() { e.Zoom(-e.zoom) }
+
+// ZoomLevel returns the steps the font is zoomed in, or out if negative.
+func (e *Editor) ZoomLevel() int { return e.zoom }
+
+// currentLine returns the line of the cursor, starting from 1.
+func (e *Editor) currentLine() int {
+	return e.TextCursor().BlockNumber() + 1
+}
+
+func (e *Editor) askGoToLine() {
+	if line, ok := e.askLine(e.currentLine(), e.BlockCount()); ok {
+		e.GoToLine(line)
+	}
+}
+
+// GoToLine moves the cursor to the start of the line, starting from 1, and
+// unfolds it if it is hidden. It returns false if there is no such line.
+func (e *Editor) GoToLine(line int) bool {
+	block := e.Document().FindBlockByNumber(line - 1)
+	if !block.IsValid() {
+		return false
+	}
+	for header := block.Previous(); !block.IsVisible() && header.IsValid(); header = header.Previous() {
+		if header.IsVisible() {
+			e.Unfold(header.BlockNumber() + 1)
+		}
+	}
+	e.SetTextCursor(gui.NewQTextCursor4(block))
+	e.CenterCursor()
+	e.SetFocus2()
+	return true
+}
+
+func (e *Editor) keyPressEvent(event *gui.QKeyEvent) {
+	modifiers := event.Modifiers() &^ core.Qt__KeypadModifier
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_Return, core.Qt__Key_Enter:
+		if modifiers == core.Qt__NoModifier {
+			e.newLine()
+			return
+		}
+	case core.Qt__Key_Tab:
+		if modifiers == core.Qt__NoModifier {
+			e.indent()
+			return
+		}
+	case core.Qt__Key_Backtab:
+		e.unindent()
+		return
+	}
+	e.KeyPressEventDefault(event)
+}
+
+// newLine starts a new line with the indentation of the current one, and one
+// more level if the line opens a block.
+func (e *Editor) newLine() {
+	cursor := e.TextCursor()
+	before := prefix(cursor.Block().Text(), cursor.PositionInBlock())
+	indent := indentOf(before)
+	if opensBlock(before) {
+		indent += e.indentUnit()
+	}
+	e.InsertPlainText("\n" + indent)
+	e.EnsureCursorVisible()
+}
+
+// indent inserts one level of indentation, or indents all the lines of the
+// selection if it spans more than one line.
+func (e *Editor) indent() {
+	cursor := e.TextCursor()
+	first, last := e.selectedBlocks(cursor)
+	if first.BlockNumber() == last.BlockNumber() {
+		e.InsertPlainText(e.indentUnit())
+		return
+	}
+	cursor.BeginEditBlock()
+	defer cursor.EndEditBlock()
+	for block := first; block.IsValid() && block.BlockNumber() <= last.BlockNumber(); block = block.Next() {
+		c := gui.NewQTextCursor4(block)
+		c.InsertText(e.indentUnit())
+	}
+}
+
+// unindent removes one level of indentation from the selected lines.
+func (e *Editor) unindent() {
+	cursor := e.TextCursor()
+	first, last := e.selectedBlocks(cursor)
+	cursor.BeginEditBlock()
+	defer cursor.EndEditBlock()
+	for block := first; block.IsValid() && block.BlockNumber() <= last.BlockNumber(); block = block.Next() {
+		n := unindentLen(block.Text(), e.tabWidth)
+		if n == 0 {
+			continue
+		}
+		c := gui.NewQTextCursor4(block)
+		c.MovePosition(gui.QTextCursor__NextCharacter, gui.QTextCursor__KeepAnchor, n)
+		c.RemoveSelectedText()
+	}
+}
+
+// selectedBlocks returns the first and the last blocks of the selection of the
+// cursor. A selection ending at the start of a line does not include it.
+func (e *Editor) selectedBlocks(cursor *gui.QTextCursor) (*gui.QTextBlock, *gui.QTextBlock) {
+	doc := e.Document()
+	first := doc.FindBlock(cursor.SelectionStart())
+	last := doc.FindBlock(cursor.SelectionEnd())
+	if cursor.HasSelection() && last.BlockNumber() > first.BlockNumber() && cursor.SelectionEnd() == last.Position() {
+		last = last.Previous()
+	}
+	return first, last
+}
+
+// lines returns the lines of the text.
+func (e *Editor) lines() []string {
+	return strings.Split(e.ToPlainText(), "\n")
+}
+
+// Fold hides the lines under the line, starting from 1, that are indented
+// further than it. It returns false if the line cannot be folded.
+func (e *Editor) Fold(line int) bool {
+	end := foldEnd(e.lines(), line-1, e.tabWidth)
+	if end < 0 {
+		return false
+	}
+	doc := e.Document()
+	header := doc.FindBlockByNumber(line - 1)
+	for i := line; i <= end; i++ {
+		doc.FindBlockByNumber(i).SetVisible(false)
+	}
+	e.relayout(header, doc.FindBlockByNumber(end))
+	return true
+}
+
+// Unfold shows the lines that were folded under the line, starting from 1. It
+// returns false if the line is not folded.
+func (e *Editor) Unfold(line int) bool {
+	if !e.Folded(line) {
+		return false
+	}
+	header := e.Document().FindBlockByNumber(line - 1)
+	last := header
+	for block := header.Next(); block.IsValid() && !block.IsVisible(); block = block.Next() {
+		block.SetVisible(true)
+		last = block
+	}
+	e.relayout(header, last)
+	return true
+}
+
+// Folded returns true if the lines under the line, starting from 1, are
+// folded. The user states of the blocks are left to the highlighter.
+func (e *Editor) Folded(line int) bool {
+	header := e.Document().FindBlockByNumber(line - 1)
+	return header.IsValid() && header.IsVisible() && header.Next().IsValid() && !header.Next().IsVisible()
+}
+
+// relayout updates the view after the visibility of the blocks from first to
+// last has changed.
+func (e *Editor) relayout(first, last *gui.QTextBlock) {
+	start := first.Position()
+	e.Document().MarkContentsDirty(start, last.Position()+last.Length()-start)
+	e.Viewport().Update()
+	e.gutter.Update()
+	e.EnsureCursorVisible()
+}
+
+// paintEvent highlights the current line and the brackets around the cursor
+// before the text is drawn.
+func (e *Editor) paintEvent(event *gui.QPaintEvent) {
+	painter := gui.NewQPainter2(e.Viewport())
+	color := e.Palette().Color2(gui.QPalette__Highlight)
+	color.SetAlpha(40)
+	if !e.TextCursor().HasSelection() {
+		rect := e.BlockBoundingGeometry(e.TextCursor().Block()).Translated2(e.ContentOffset())
+		rect.SetLeft(0)
+		rect.SetWidth(float64(e.Viewport().Width()))
+		painter.FillRect(rect, gui.NewQBrush3(color, core.Qt__SolidPattern))
+	}
+	doc := e.Document()
+	charAt := func(pos int) uint16 { return doc.CharacterAt(pos).Unicode() }
+	if a, b, ok := bracketPair(charAt, doc.CharacterCount(), e.TextCursor().Position()); ok {
+		color.SetAlpha(90)
+		for _, pos := range []int{a, b} {
+			c := e.TextCursor()
+			c.SetPosition(pos, gui.QTextCursor__MoveAnchor)
+			left := e.CursorRect(c)
+			c.SetPosition(pos+1, gui.QTextCursor__MoveAnchor)
+			right := e.CursorRect(c)
+			painter.FillRect5(core.NewQRect4(left.Left(), left.Top(), right.Left()-left.Left(), left.Height()), gui.NewQBrush3(color, core.Qt__SolidPattern))
+		}
+	}
+	painter.End()
+	e.PaintEventDefault(event)
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/testlib"
+	"github.com/therecipe/qt/widgets"
+)
+
+var app *widgets.QApplication
+
+func TestMain(m *testing.M) {
+	app = widgets.NewQApplication(len(os.Args), os.Args)
+	go func() { app.Exit(m.Run()) }()
+	app.Exec()
+}
+
+// press sends the key with the modifiers to the editor.
+func press(e *Editor, key core.Qt__Key, modifiers core.Qt__KeyboardModifier) {
+	event := testlib.NewQTestEventList()
+	event.AddKeyClick(key, modifiers, -1)
+	event.Simulate(e)
+}
+
+// moveTo puts the cursor at the end of the line, starting from 1.
+func moveTo(e *Editor, line int) {
+	cursor := gui.NewQTextCursor4(e.Document().FindBlockByNumber(line - 1))
+	cursor.MovePosition(gui.QTextCursor__EndOfBlock, gui.QTextCursor__MoveAnchor, 1)
+	e.SetTextCursor(cursor)
+}
+
+func TestEditorDefaults(t *testing.T) { tRunner.Run(func() { testEditorDefaults(t) }) }
+func testEditorDefaults(t *testing.T) {
+	e := NewEditor(nil)
+	if !gui.NewQFontInfo(e.Font()).FixedPitch() {
+		t.Error("the font is not monospaced")
+	}
+	if e.TabWidth() != DefaultTabWidth || e.IndentWithSpaces() {
+		t.Errorf("TabWidth() = %d, IndentWithSpaces() = %t, want %d and tabs", e.TabWidth(), e.IndentWithSpaces(), DefaultTabWidth)
+	}
+	e.SetPlainText("one")
+	before := e.gutterWidth()
+	e.SetPlainText("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11")
+	if e.gutterWidth() <= before {
+		t.Errorf("gutterWidth() = %d, want wider than %d for two digits", e.gutterWidth(), before)
+	}
+	e.Resize2(300, 200)
+	e.Grab(e.Rect())
+}
+
+func TestAutoIndent(t *testing.T) { tRunner.Run(func() { testAutoIndent(t) }) }
+func testAutoIndent(t *testing.T) {
+	e := NewEditor(nil)
+	e.SetPlainText("func main() {\n\tx := 1")
+	moveTo(e, 2)
+	press(e, core.Qt__Key_Return, core.Qt__NoModifier)
+	if got, want := e.ToPlainText(), "func main() {\n\tx := 1\n\t"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+
+	e.SetIndentWithSpaces(true)
+	e.SetTabWidth(2)
+	moveTo(e, 1)
+	press(e, core.Qt__Key_Return, core.Qt__NoModifier)
+	if got := e.Document().FindBlockByNumber(1).Text(); got != "  " {
+		t.Errorf("line 2 = %q, want one level of spaces", got)
+	}
+	press(e, core.Qt__Key_Tab, core.Qt__NoModifier)
+	if got := e.Document().FindBlockByNumber(1).Text(); got != "    " {
+		t.Errorf("line 2 = %q, want two levels of spaces", got)
+	}
+	press(e, core.Qt__Key_Backtab, core.Qt__ShiftModifier)
+	if got := e.Document().FindBlockByNumber(1).Text(); got != "  " {
+		t.Errorf("line 2 = %q, want one level after unindenting", got)
+	}
+}
+
+func TestIndentSelection(t *testing.T) { tRunner.Run(func() { testIndentSelection(t) }) }
+func testIndentSelection(t *testing.T) {
+	e := NewEditor(nil)
+	e.SetPlainText("a\nb\nc")
+	e.SelectAll()
+	press(e, core.Qt__Key_Tab, core.Qt__NoModifier)
+	if got, want := e.ToPlainText(), "\ta\n\tb\n\tc"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	e.Document().Undo(nil)
+	if got, want := e.ToPlainText(), "a\nb\nc"; got != want {
+		t.Errorf("text = %q, want %q: indenting should be undone at once", got, want)
+	}
+}
+
+func TestFolding(t *testing.T) { tRunner.Run(func() { testFolding(t) }) }
+func testFolding(t *testing.T) {
+	e := NewEditor(nil)
+	e.SetPlainText("func main() {\n\tone()\n\ttwo()\n}\nfunc other() {}")
+	if e.Fold(5) {
+		t.Error("Fold(5) = true, want false for a line without a body")
+	}
+	if !e.Fold(1) || !e.Folded(1) {
+		t.Fatal("Fold(1) didn't fold the function")
+	}
+	doc := e.Document()
+	for line, visible := range map[int]bool{1: true, 2: false, 3: false, 4: true} {
+		if doc.FindBlockByNumber(line-1).IsVisible() != visible {
+			t.Errorf("line %d visible = %t, want %t", line, !visible, visible)
+		}
+	}
+
+	if !e.GoToLine(3) || e.Folded(1) {
+		t.Error("going to a folded line didn't unfold it")
+	}
+	if e.TextCursor().BlockNumber() != 2 {
+		t.Errorf("cursor is on line %d, want 3", e.TextCursor().BlockNumber()+1)
+	}
+	e.Fold(1)
+	if !e.Unfold(1) || !doc.FindBlockByNumber(1).IsVisible() {
+		t.Error("Unfold(1) didn't show the lines")
+	}
+	if e.Unfold(1) {
+		t.Error("Unfold(1) = true for a line that is not folded")
+	}
+}
+
+func TestGoToLine(t *testing.T) { tRunner.Run(func() { testGoToLine(t) }) }
+func testGoToLine(t *testing.T) {
+	e := NewEditor(nil)
+	e.SetPlainText("a\nb\nc")
+	if e.GoToLine(10) {
+		t.Error("GoToLine(10) = true, want false")
+	}
+	e.askLine = func(current, max int) (int, bool) {
+		if max != 3 {
+			t.Errorf("max = %d, want 3", max)
+		}
+		return 2, true
+	}
+	for _, a := range e.Actions() {
+		if a.Text() == "Go to Line..." {
+			a.Trigger()
+		}
+	}
+	if e.TextCursor().BlockNumber() != 1 {
+		t.Errorf("cursor is on line %d, want 2", e.TextCursor().BlockNumber()+1)
+	}
+}
+
+func TestZoom(t *testing.T) { tRunner.Run(func() { testZoom(t) }) }
+func testZoom(t *testing.T) {
+	e := NewEditor(nil)
+	size := e.Font().PointSizeF()
+	e.Zoom(2)
+	if e.ZoomLevel() != 2 || e.Font().PointSizeF() <= size {
+		t.Errorf("ZoomLevel() = %d, size = %f, want larger than %f", e.ZoomLevel(), e.Font().PointSizeF(), size)
+	}
+	e.ResetZoom()
+	if e.ZoomLevel() != 0 || e.Font().PointSizeF() != size {
+		t.Errorf("ZoomLevel() = %d, size = %f, want %f", e.ZoomLevel(), e.Font().PointSizeF(), size)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"strconv"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+const (
+	gutterPadding = 4
+	foldableMark  = "\u25be" // shown next to the lines that can be folded.
+	foldedMark    = "\u25b8" // shown next to the lines that are folded.
+)
+
+// gutterWidth returns the width needed for the largest line number and the
+// fold markers.
+func (e *Editor) gutterWidth() int {
+	metrics := gui.NewQFontMetrics(e.Font())
+	digits := len(strconv.Itoa(e.BlockCount()))
+	return 2*gutterPadding + digits*metrics.Width("9", -1) + e.markWidth()
+}
+
+// markWidth returns the width of the column of the fold markers.
+func (e *Editor) markWidth() int {
+	return gui.NewQFontMetrics(e.Font()).Height()
+}
+
+func (e *Editor) updateGutterWidth() {
+	e.SetViewportMargins(e.gutterWidth(), 0, 0, 0)
+	rect := e.ContentsRect()
+	e.gutter.SetGeometry2(rect.Left(), rect.Top(), e.gutterWidth(), rect.Height())
+}
+
+// paintGutter draws the numbers of the visible lines, with markers on the
+// lines that can be folded or are folded.
+func (e *Editor) paintGutter(event *gui.QPaintEvent) {
+	painter := gui.NewQPainter2(e.gutter)
+	defer painter.End()
+	area := event.Rect()
+	painter.FillRect5(area, gui.NewQBrush3(e.Palette().Color2(gui.QPalette__AlternateBase), core.Qt__SolidPattern))
+	painter.SetFont(e.Font())
+
+	lines := e.lines()
+	current := e.TextCursor().BlockNumber()
+	numbers := e.gutter.Width() - e.markWidth() - gutterPadding
+	block := e.FirstVisibleBlock()
+	top := int(e.BlockBoundingGeometry(block).Translated2(e.ContentOffset()).Top())
+	bottom := top + int(e.BlockBoundingRect(block).Height())
+	for block.IsValid() && top <= area.Bottom() {
+		if block.IsVisible() && bottom >= area.Top() {
+			n := block.BlockNumber()
+			role := gui.QPalette__Mid
+			if n == current {
+				role = gui.QPalette__Text
+			}
+			painter.SetPen3(e.Palette().Color2(role))
+			height := bottom - top
+			painter.DrawText6(0, top, numbers, height, int(core.Qt__AlignRight|core.Qt__AlignVCenter), strconv.Itoa(n+1), nil)
+			mark := ""
+			switch {
+			case e.Folded(n + 1):
+				mark = foldedMark
+			case foldEnd(lines, n, e.tabWidth) >= 0:
+				mark = foldableMark
+			}
+			if mark != "" {
+				painter.DrawText6(numbers+gutterPadding, top, e.markWidth(), height, int(core.Qt__AlignCenter), mark, nil)
+			}
+		}
+		block = block.Next()
+		top = bottom
+		bottom = top + int(e.BlockBoundingRect(block).Height())
+	}
+}
+
+// gutterClicked folds or unfolds the line next to the marker that is clicked.
+func (e *Editor) gutterClicked(event *gui.QMouseEvent) {
+	if event.Button() != core.Qt__LeftButton || event.X() < e.gutter.Width()-e.markWidth()-gutterPadding {
+		return
+	}
+	line := e.CursorForPosition(core.NewQPoint2(0, event.Y())).BlockNumber() + 1
+	if !e.Unfold(line) {
+		e.Fold(line)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"github.com/arsham/gistflow/syntax"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// chunkBlocks is the number of lines highlighted at once when a grammar is
+// set, before letting the editor handle the events.
+const chunkBlocks = 500
+
+// Highlighter colours the text of a document with a grammar. After a grammar
+// is set the lines are highlighted in chunks, and then only the lines that
+// change are highlighted again.
+type Highlighter struct {
+	gui.QSyntaxHighlighter
+
+	_ func() `constructor:"init"`
+
+	grammar *syntax.Grammar
+	formats map[syntax.Kind]*gui.QTextCharFormat
+	next    int // the first line not highlighted yet, or -1 when all are.
+	timer   *core.QTimer
+}
+
+func (h *Highlighter) init() {
+	h.next = -1
+	h.timer = core.NewQTimer(h)
+	h.timer.ConnectTimeout(h.highlightChunk)
+	h.ConnectHighlightBlock(h.highlightBlock)
+}
+
+// SetGrammar highlights the document with the grammar. When g is nil the
+// document is left plain.
+func (h *Highlighter) SetGrammar(g *syntax.Grammar) {
+	h.grammar = g
+	h.next = 0
+	h.highlightChunk()
+	if h.next >= 0 {
+		h.timer.Start(0)
+	}
+}
+
+// Grammar returns the grammar of the document, or nil.
+func (h *Highlighter) Grammar() *syntax.Grammar { return h.grammar }
+
+// SetPalette picks the colours for the background of the palette, and
+// highlights the document again.
+func (h *Highlighter) SetPalette(palette *gui.QPalette) {
+	h.formats = formats(palette)
+	h.SetGrammar(h.grammar)
+}
+
+// Highlighting returns true while the lines are highlighted in chunks.
+func (h *Highlighter) Highlighting() bool { return h.next >= 0 }
+
+func (h *Highlighter) highlightChunk() {
+	doc := h.Document()
+	if h.next < 0 || doc.Pointer() == nil {
+		h.timer.Stop()
+		return
+	}
+	from := h.next
+	h.next += chunkBlocks
+	for n := from; n < h.next && n < doc.BlockCount(); n++ {
+		h.RehighlightBlock(doc.FindBlockByNumber(n))
+	}
+	if h.next >= doc.BlockCount() {
+		h.next = -1
+		h.timer.Stop()
+	}
+}
+
+// highlightBlock sets the formats of the tokens of the line. The state of a
+// line is zero, or the region it ends in. The lines not reached by the chunks
+// yet keep their states, so the changes don't spread over them.
+func (h *Highlighter) highlightBlock(text string) {
+	if h.grammar == nil || (h.next >= 0 && h.CurrentBlock().BlockNumber() >= h.next) {
+		return
+	}
+	state := h.PreviousBlockState()
+	if state < 0 {
+		state = 0
+	}
+	tokens, state := h.grammar.Highlight(text, state)
+	// Qt counts in UTF-16 units, and the tokens are in bytes.
+	offset, units := 0, 0
+	for _, t := range tokens {
+		units += utf16Len(text[offset:t.Start])
+		length := utf16Len(text[t.Start:t.End])
+		if f, ok := h.formats[t.Kind]; ok {
+			h.SetFormat(units, length, f)
+		}
+		offset, units = t.End, units+length
+	}
+	h.SetCurrentBlockState(state)
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlighter(t *testing.T) { tRunner.Run(func() { testHighlighter(t) }) }
+func testHighlighter(t *testing.T) {
+	e := NewEditor(nil)
+	e.SetPlainText("package main\n/* one\ntwo */\nfunc main() {}")
+	e.SetFileName("main.go")
+	h := e.Highlighter()
+	if h.Grammar() == nil || h.Grammar().Name != "Go" {
+		t.Fatalf("Grammar() = %v, want Go", h.Grammar())
+	}
+	if h.Highlighting() {
+		t.Error("Highlighting() = true, want false for a short text")
+	}
+	doc := e.Document()
+	for line, want := range []int{0, 1, 0, 0} {
+		if got := doc.FindBlockByNumber(line).UserState(); got != want {
+			t.Errorf("line %d: state = %d, want %d", line+1, got, want)
+		}
+	}
+
+	e.SetFileName("notes.txt")
+	if h.Grammar() != nil {
+		t.Errorf("Grammar() = %v, want nil", h.Grammar())
+	}
+}
+
+func TestHighlighterChunks(t *testing.T) { tRunner.Run(func() { testHighlighterChunks(t) }) }
+func testHighlighterChunks(t *testing.T) {
+	e := NewEditor(nil)
+	lines := make([]string, chunkBlocks*2+10)
+	lines[0] = "/*"
+	lines[len(lines)-1] = "*/"
+	e.SetPlainText(strings.Join(lines, "\n"))
+	e.SetFileName("main.go")
+	h := e.Highlighter()
+	doc := e.Document()
+	if !h.Highlighting() {
+		t.Fatal("Highlighting() = false, want true for a long text")
+	}
+	if got := doc.FindBlockByNumber(chunkBlocks - 1).UserState(); got != 1 {
+		t.Errorf("state of the first chunk = %d, want 1", got)
+	}
+	if got := doc.FindBlockByNumber(chunkBlocks).UserState(); got == 1 {
+		t.Error("the second chunk was highlighted with the first one")
+	}
+	h.highlightChunk()
+	h.highlightChunk()
+	if h.Highlighting() {
+		t.Error("Highlighting() = true after all chunks")
+	}
+	if got := doc.LastBlock().UserState(); got != 0 {
+		t.Errorf("state of the last line = %d, want 0", got)
+	}
+}
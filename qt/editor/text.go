@@ -0,0 +1,147 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// These work on the text of the editor without the widgets. Positions in a
+// QTextDocument count UTF-16 code units, therefore the brackets are matched
+// on the code units.
+
+// maxBracketScan is the number of characters looked through for a matching
+// bracket, so long texts don't slow down painting.
+const maxBracketScan = 10000
+
+// pairs maps the brackets to their counterparts.
+var pairs = map[uint16]uint16{
+	'(': ')', '[': ']', '{': '}',
+	')': '(', ']': '[', '}': '{',
+}
+
+// matchBracket returns the position of the bracket matching the one at pos,
+// or -1 if there is no bracket at pos or it is not matched within
+// maxBracketScan characters. charAt returns the code unit at a position of
+// the text, which has size code units.
+func matchBracket(charAt func(int) uint16, size, pos int) int {
+	if pos < 0 || pos >= size {
+		return -1
+	}
+	c := charAt(pos)
+	other, ok := pairs[c]
+	if !ok {
+		return -1
+	}
+	step := 1
+	if strings.ContainsRune(")]}", rune(c)) {
+		step = -1
+	}
+	depth := 0
+	for i, n := pos, 0; i >= 0 && i < size && n < maxBracketScan; i, n = i+step, n+1 {
+		switch charAt(i) {
+		case c:
+			depth++
+		case other:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// bracketPair returns the positions of the brackets around the cursor. The
+// bracket after the cursor is preferred over the one before it.
+func bracketPair(charAt func(int) uint16, size, cursor int) (int, int, bool) {
+	for _, pos := range []int{cursor, cursor - 1} {
+		if other := matchBracket(charAt, size, pos); other >= 0 {
+			return pos, other, true
+		}
+	}
+	return -1, -1, false
+}
+
+// prefix returns the first n UTF-16 code units of s.
+func prefix(s string, n int) string {
+	units := utf16.Encode([]rune(s))
+	if n > len(units) {
+		n = len(units)
+	}
+	return string(utf16.Decode(units[:n]))
+}
+
+// utf16Len returns the number of UTF-16 code units of s.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n++ // surrogate pair.
+		}
+		n++
+	}
+	return n
+}
+
+// indentOf returns the leading spaces and tabs of line.
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// indentWidth returns the width of the indentation of line in columns.
+func indentWidth(line string, tabWidth int) int {
+	width := 0
+	for _, c := range indentOf(line) {
+		if c == '\t' {
+			width += tabWidth - width%tabWidth
+			continue
+		}
+		width++
+	}
+	return width
+}
+
+// opensBlock reports whether the line ends with an opening bracket or a
+// colon, after which the next line is indented further.
+func opensBlock(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	return line != "" && strings.ContainsRune("([{:", rune(line[len(line)-1]))
+}
+
+// unindentLen returns the number of characters to remove from the start of
+// line to unindent it by one level.
+func unindentLen(line string, tabWidth int) int {
+	if strings.HasPrefix(line, "\t") {
+		return 1
+	}
+	n := 0
+	for n < len(line) && n < tabWidth && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+// foldEnd returns the index of the last line that is folded under the line at
+// i, or -1 if it cannot be folded. The lines following a line that are blank
+// or indented further than it are folded, except the trailing blank ones.
+func foldEnd(lines []string, i, tabWidth int) int {
+	if i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == "" {
+		return -1
+	}
+	width := indentWidth(lines[i], tabWidth)
+	end := -1
+	for j := i + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if indentWidth(lines[j], tabWidth) <= width {
+			break
+		}
+		end = j
+	}
+	return end
+}
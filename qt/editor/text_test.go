@@ -0,0 +1,99 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestBracketPair(t *testing.T) {
+	tcs := []struct {
+		text   string
+		cursor int
+		a, b   int
+		ok     bool
+	}{
+		{"f(a[1])", 1, 1, 6, true},
+		{"f(a[1])", 2, 1, 6, true},
+		{"f(a[1])", 7, 6, 1, true},
+		{"f(a[1])", 3, 3, 5, true},
+		{"{ {} }", 0, 0, 5, true},
+		{"(()", 0, -1, -1, false},
+		{"abc", 1, -1, -1, false},
+		// the emoji takes two code units.
+		{"\U0001F600(x)", 2, 2, 4, true},
+		{"(" + strings.Repeat(" ", maxBracketScan) + ")", 0, -1, -1, false},
+	}
+	for _, tc := range tcs {
+		units := utf16.Encode([]rune(tc.text))
+		charAt := func(pos int) uint16 { return units[pos] }
+		a, b, ok := bracketPair(charAt, len(units), tc.cursor)
+		if a != tc.a || b != tc.b || ok != tc.ok {
+			t.Errorf("bracketPair(%.20q, %d) = (%d, %d, %t), want (%d, %d, %t)", tc.text, tc.cursor, a, b, ok, tc.a, tc.b, tc.ok)
+		}
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	if got := prefix("\U0001F600ab", 3); got != "\U0001F600a" {
+		t.Errorf("prefix() = %q, want %q", got, "\U0001F600a")
+	}
+	if got := prefix("ab", 5); got != "ab" {
+		t.Errorf("prefix() = %q, want ab", got)
+	}
+}
+
+func TestUTF16Len(t *testing.T) {
+	for s, want := range map[string]int{"": 0, "ab": 2, "\u00e9": 1, "\U0001F600a": 3} {
+		if got := utf16Len(s); got != want {
+			t.Errorf("utf16Len(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestIndentation(t *testing.T) {
+	if got := indentOf("\t  x := 1"); got != "\t  " {
+		t.Errorf("indentOf() = %q, want %q", got, "\t  ")
+	}
+	if got := indentWidth("  \tx", 4); got != 4 {
+		t.Errorf("indentWidth() = %d, want 4", got)
+	}
+	for line, want := range map[string]bool{
+		"func main() {": true,
+		"if x:  ":       true,
+		"x := []int{":   true,
+		"return x":      false,
+		"":              false,
+	} {
+		if got := opensBlock(line); got != want {
+			t.Errorf("opensBlock(%q) = %t, want %t", line, got, want)
+		}
+	}
+	for line, want := range map[string]int{"\t\tx": 1, "      x": 4, "  x": 2, "x": 0} {
+		if got := unindentLen(line, 4); got != want {
+			t.Errorf("unindentLen(%q) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestFoldEnd(t *testing.T) {
+	lines := strings.Split(`func main() {
+	if ok {
+		run()
+
+	}
+
+	stop()
+}
+`, "\n")
+	tcs := map[int]int{0: 6, 1: 2, 2: -1, 3: -1, 7: -1, 100: -1}
+	for i, want := range tcs {
+		if got := foldEnd(lines, i, 4); got != want {
+			t.Errorf("foldEnd(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
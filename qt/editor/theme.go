@@ -0,0 +1,94 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/arsham/gistflow/syntax"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+)
+
+// style is how a kind of token is drawn.
+type style struct {
+	light, dark  string // colours on light and dark backgrounds.
+	bold, italic bool
+}
+
+var styles = map[syntax.Kind]style{
+	syntax.Keyword:  {"#0033b3", "#cc7832", true, false},
+	syntax.Type:     {"#007f7f", "#4ec9b0", false, false},
+	syntax.Builtin:  {"#871094", "#c586c0", false, false},
+	syntax.Function: {"#00627a", "#ffc66d", false, false},
+	syntax.String:   {"#067d17", "#6a8759", false, false},
+	syntax.Number:   {"#1750eb", "#6897bb", false, false},
+	syntax.Comment:  {"#8c8c8c", "#808080", false, true},
+	syntax.Operator: {"#6f42c1", "#b392f0", false, false},
+	syntax.Variable: {"#9e5b00", "#9cdcfe", false, false},
+	syntax.Key:      {"#871094", "#9876aa", false, false},
+	syntax.Heading:  {"#0033b3", "#cc7832", true, false},
+	syntax.Emphasis: {"", "", false, true},
+	syntax.Link:     {"#1750eb", "#589df6", false, false},
+	syntax.Code:     {"#067d17", "#6a8759", false, false},
+}
+
+// dark returns true if the palette has a dark background.
+func dark(palette *gui.QPalette) bool {
+	return palette.Color2(gui.QPalette__Base).LightnessF() < 0.5
+}
+
+// formats returns the formats of the kinds of tokens on the background of the
+// palette.
+func formats(palette *gui.QPalette) map[syntax.Kind]*gui.QTextCharFormat {
+	isDark := dark(palette)
+	m := make(map[syntax.Kind]*gui.QTextCharFormat, len(styles))
+	for kind, s := range styles {
+		f := gui.NewQTextCharFormat()
+		colour := s.light
+		if isDark {
+			colour = s.dark
+		}
+		if colour != "" {
+			f.SetForeground(gui.NewQBrush3(gui.NewQColor6(colour), core.Qt__SolidPattern))
+		}
+		if s.bold {
+			f.SetFontWeight(int(gui.QFont__Bold))
+		}
+		f.SetFontItalic(s.italic)
+		m[kind] = f
+	}
+	return m
+}
+
+// StyleSheet returns the CSS of the classes named after the kinds of tokens,
+// for showing highlighted code in rich texts on the background of the palette.
+func StyleSheet(palette *gui.QPalette) string {
+	isDark := dark(palette)
+	buf := &bytes.Buffer{}
+	for kind := syntax.Plain; kind <= syntax.Code; kind++ {
+		s, ok := styles[kind]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, ".%s {", kind)
+		colour := s.light
+		if isDark {
+			colour = s.dark
+		}
+		if colour != "" {
+			fmt.Fprintf(buf, " color: %s;", colour)
+		}
+		if s.bold {
+			buf.WriteString(" font-weight: bold;")
+		}
+		if s.italic {
+			buf.WriteString(" font-style: italic;")
+		}
+		buf.WriteString(" }\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/syntax"
+	"github.com/therecipe/qt/gui"
+)
+
+func TestFormats(t *testing.T) { tRunner.Run(func() { testFormats(t) }) }
+func testFormats(t *testing.T) {
+	light := gui.NewQPalette()
+	light.SetColor2(gui.QPalette__Base, gui.NewQColor6("#ffffff"))
+	dark := gui.NewQPalette()
+	dark.SetColor2(gui.QPalette__Base, gui.NewQColor6("#202020"))
+
+	lf, df := formats(light), formats(dark)
+	for kind, s := range styles {
+		if s.light == "" {
+			continue
+		}
+		if got := lf[kind].Foreground().Color().Name(); got != s.light {
+			t.Errorf("%s on light: colour = %s, want %s", kind, got, s.light)
+		}
+		if got := df[kind].Foreground().Color().Name(); got != s.dark {
+			t.Errorf("%s on dark: colour = %s, want %s", kind, got, s.dark)
+		}
+	}
+	if lf[syntax.Keyword].FontWeight() != int(gui.QFont__Bold) {
+		t.Error("keywords are not bold")
+	}
+	if !lf[syntax.Comment].FontItalic() {
+		t.Error("comments are not italic")
+	}
+}
+
+func TestStyleSheet(t *testing.T) { tRunner.Run(func() { testStyleSheet(t) }) }
+func testStyleSheet(t *testing.T) {
+	light := gui.NewQPalette()
+	light.SetColor2(gui.QPalette__Base, gui.NewQColor6("#ffffff"))
+	css := StyleSheet(light)
+	for _, want := range []string{
+		".keyword { color: " + styles[syntax.Keyword].light + "; font-weight: bold; }",
+		".comment { color: " + styles[syntax.Comment].light + "; font-style: italic; }",
+		".emphasis { font-style: italic; }",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("StyleSheet() = %q, want it to contain %q", css, want)
+		}
+	}
+}
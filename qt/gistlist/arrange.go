@@ -0,0 +1,371 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// SortOrder is the order of the gists in the list.
+type SortOrder int
+
+// Gists are sorted in one of these orders. The most recent and the biggest
+// gists come first.
+const (
+	SortNone SortOrder = iota // in the order they were added.
+	SortUpdated
+	SortCreated
+	SortName
+	SortSize
+)
+
+var sortNames = []string{"Default Order", "Sort by Updated", "Sort by Created", "Sort by Name", "Sort by Size"}
+
+// Grouping decides the sections the gists are shown in.
+type Grouping int
+
+// Gists can be grouped by these.
+const (
+	GroupNone Grouping = iota
+	GroupLanguage
+	GroupVisibility
+	GroupTag
+	GroupMonth
+)
+
+var groupNames = []string{"No Groups", "Group by Language", "Group by Visibility", "Group by Tag", "Group by Month"}
+
+// Names of the groups of the gists without the grouped property. They are
+// shown after the others.
+const (
+	otherLanguage = "Other"
+	untagged      = "Untagged"
+	unknownMonth  = "Unknown"
+)
+
+func (c *Container) setupArrangeActions() {
+	sortGroup := widgets.NewQActionGroup(c)
+	for i, name := range sortNames {
+		order := SortOrder(i)
+		a := widgets.NewQAction2(name, c)
+		a.SetCheckable(true)
+		a.SetActionGroup(sortGroup)
+		a.ConnectTriggered(func(bool) { c.SetSortOrder(order) })
+		c.sortActions = append(c.sortActions, a)
+	}
+	groupGroup := widgets.NewQActionGroup(c)
+	for i, name := range groupNames {
+		grouping := Grouping(i)
+		a := widgets.NewQAction2(name, c)
+		a.SetCheckable(true)
+		a.SetActionGroup(groupGroup)
+		a.ConnectTriggered(func(bool) { c.SetGrouping(grouping) })
+		c.groupActions = append(c.groupActions, a)
+	}
+	c.sortActions[SortNone].SetChecked(true)
+	c.groupActions[GroupNone].SetChecked(true)
+}
+
+// ArrangeActions returns the actions that sort and group the list.
+func (c *Container) ArrangeActions() []*widgets.QAction {
+	return append(append([]*widgets.QAction{}, c.sortActions...), c.groupActions...)
+}
+
+// SetSortOrder sorts the gists in each group.
+func (c *Container) SetSortOrder(order SortOrder) {
+	if order < 0 || int(order) >= len(sortNames) {
+		order = SortNone
+	}
+	c.sortOrder = order
+	c.sortActions[order].SetChecked(true)
+	c.arrange()
+	c.Arranged(int(c.sortOrder), int(c.grouping))
+}
+
+// SetGrouping shows the gists in groups with headers.
+func (c *Container) SetGrouping(grouping Grouping) {
+	if grouping < 0 || int(grouping) >= len(groupNames) {
+		grouping = GroupNone
+	}
+	c.grouping = grouping
+	c.groupActions[grouping].SetChecked(true)
+	c.arrange()
+	c.Arranged(int(c.sortOrder), int(c.grouping))
+}
+
+// arrange puts the items in the order and in the groups that are set. The
+// selection and the current item are kept.
+func (c *Container) arrange() {
+	selected := make(map[uintptr]bool)
+	for _, item := range c.SelectedItems() {
+		selected[uintptr(item.Pointer())] = true
+	}
+	current := c.CurrentItem()
+	expanded := make(map[uintptr]bool)
+	for _, item := range c.order {
+		expanded[uintptr(item.Pointer())] = item.IsExpanded()
+	}
+
+	for c.Count() > 0 {
+		c.TakeTopLevelItem(0)
+	}
+	for _, h := range c.headers {
+		h.DestroyQTreeWidgetItem()
+	}
+	c.headers = nil
+
+	gists := make([]gist.Gist, len(c.order))
+	for i, item := range c.order {
+		gists[i] = c.gists[item.Data(0, int(core.Qt__UserRole)).ToString()]
+	}
+	indexes := sortGists(gists, c.sortOrder)
+	groups := []*group{{indexes: indexes}}
+	if c.grouping != GroupNone {
+		groups = groupGists(gists, indexes, c.grouping)
+	}
+	for _, gr := range groups {
+		if c.grouping != GroupNone {
+			text := fmt.Sprintf("%s (%d)", gr.name, len(gr.indexes))
+			header := widgets.NewQTreeWidgetItem2([]string{text}, 0)
+			header.SetFlags(core.Qt__NoItemFlags)
+			header.SetData(0, headerRole, core.NewQVariant11(true))
+			font := header.Font(0)
+			font.SetBold(true)
+			header.SetFont(0, font)
+			c.AddTopLevelItem(header)
+			c.headers = append(c.headers, header)
+		}
+		for _, i := range gr.indexes {
+			c.AddTopLevelItem(c.order[i])
+		}
+	}
+
+	for _, item := range c.order {
+		item.SetSelected(selected[uintptr(item.Pointer())])
+		item.SetExpanded(expanded[uintptr(item.Pointer())])
+	}
+	if current != nil && current.Pointer() != nil {
+		c.SetCurrentItem(current)
+	}
+	c.applyFilter()
+}
+
+// applyFilter hides the gists that don't pass the filters, and the groups with
+// no gists shown.
+func (c *Container) applyFilter() {
+	for _, item := range c.order {
+		item.SetHidden(!c.accepts(item.Data(0, int(core.Qt__UserRole)).ToString()))
+	}
+	var header *widgets.QTreeWidgetItem
+	shown := false
+	for i := 0; i < c.Count(); i++ {
+		item := c.Item(i)
+		if item.Data(0, headerRole).ToBool() {
+			if header != nil {
+				header.SetHidden(!shown)
+			}
+			header, shown = item, false
+			continue
+		}
+		shown = shown || !item.IsHidden()
+	}
+	if header != nil {
+		header.SetHidden(!shown)
+	}
+}
+
+// group is a section of the list.
+type group struct {
+	name    string
+	key     string // groups are ordered by their keys.
+	indexes []int  // of the gists in the group.
+}
+
+// displayName returns the description of the gist, or the first of its file
+// names if it has no description.
+func displayName(g gist.Gist) string {
+	if g.Description != "" {
+		return g.Description
+	}
+	names := make([]string, 0, len(g.Files))
+	for name := range g.Files {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// truncate shortens s to n characters, with the last ones replaced by the
+// truncation string.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-len([]rune(truncateStr))]) + truncateStr
+}
+
+// sortGists returns the indexes of the gists in the order. Gists that are
+// equal in the order keep their positions.
+func sortGists(gists []gist.Gist, by SortOrder) []int {
+	indexes := make([]int, len(gists))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var less func(a, b gist.Gist) bool
+	switch by {
+	case SortUpdated:
+		less = func(a, b gist.Gist) bool { return a.UpdatedAt > b.UpdatedAt }
+	case SortCreated:
+		less = func(a, b gist.Gist) bool { return a.CreatedAt > b.CreatedAt }
+	case SortName:
+		less = func(a, b gist.Gist) bool {
+			return strings.ToLower(displayName(a)) < strings.ToLower(displayName(b))
+		}
+	case SortSize:
+		less = func(a, b gist.Gist) bool { return search.NewMeta(a).Size > search.NewMeta(b).Size }
+	default:
+		return indexes
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return less(gists[indexes[i]], gists[indexes[j]])
+	})
+	return indexes
+}
+
+// primaryLanguage returns the language of the biggest file of the gist.
+func primaryLanguage(g gist.Gist) string {
+	var lang, name string
+	size := -1
+	for n, f := range g.Files {
+		l := search.Language(n, f.Language)
+		if l == "" {
+			continue
+		}
+		s := f.Size
+		if s == 0 {
+			s = len(f.Content)
+		}
+		if s > size || s == size && n < name {
+			lang, name, size = l, n, s
+		}
+	}
+	return lang
+}
+
+// groupOf returns the name of the group of the gist, and the key the groups
+// are ordered by.
+func groupOf(g gist.Gist, by Grouping) (name, key string) {
+	// the keys of the groups without the property start with a high rune, so
+	// they come last.
+	const last = "\uffff"
+	switch by {
+	case GroupLanguage:
+		if lang := primaryLanguage(g); lang != "" {
+			return strings.Title(lang), lang
+		}
+		return otherLanguage, last
+	case GroupVisibility:
+		if g.Public {
+			return "Public", "0"
+		}
+		return "Secret", "1"
+	case GroupTag:
+		if tags := search.Tags(g.Description); len(tags) > 0 {
+			return "#" + tags[0], tags[0]
+		}
+		return untagged, last
+	case GroupMonth:
+		t, err := time.Parse(time.RFC3339, g.UpdatedAt)
+		if err != nil {
+			return unknownMonth, last
+		}
+		// the newest months come first.
+		t = t.UTC()
+		return t.Format("January 2006"), fmt.Sprintf("%06d", 999999-(t.Year()*12+int(t.Month())))
+	}
+	return "", ""
+}
+
+// groupGists puts the gists at indexes into their groups, keeping their order
+// in each group. Gists with several tags are grouped by their first tag.
+func groupGists(gists []gist.Gist, indexes []int, by Grouping) []*group {
+	var groups []*group
+	byName := make(map[string]*group)
+	for _, i := range indexes {
+		name, key := groupOf(gists[i], by)
+		gr, ok := byName[name]
+		if !ok {
+			gr = &group{name: name, key: key}
+			byName[name] = gr
+			groups = append(groups, gr)
+		}
+		gr.indexes = append(gr.indexes, i)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+	return groups
+}
+
+// relativeTime returns how long ago the RFC3339 timestamp was, for example
+// "3 days ago". It returns an empty string if the timestamp is invalid.
+func relativeTime(timestamp string, now time.Time) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ""
+	}
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute") + " ago"
+	case d < day:
+		return plural(int(d/time.Hour), "hour") + " ago"
+	case d < month:
+		return plural(int(d/day), "day") + " ago"
+	case d < year:
+		return plural(int(d/month), "month") + " ago"
+	}
+	return plural(int(d/year), "year") + " ago"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// details returns the summary of the gist that is shown under its description.
+func details(g gist.Gist, now time.Time) string {
+	parts := []string{"secret"}
+	if g.Public {
+		parts[0] = "public"
+	}
+	parts = append(parts, plural(len(g.Files), "file"))
+	if lang := primaryLanguage(g); lang != "" {
+		parts = append(parts, lang)
+	}
+	if ago := relativeTime(g.UpdatedAt, now); ago != "" {
+		parts = append(parts, "updated "+ago)
+	}
+	return strings.Join(parts, " · ")
+}
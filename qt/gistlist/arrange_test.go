@@ -0,0 +1,185 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+var arrangeGists = []gist.Gist{
+	{ID: "a", Description: "beta #k8s", Public: true, CreatedAt: "2018-01-05T10:00:00Z", UpdatedAt: "2018-05-01T10:00:00Z",
+		Files: map[string]gist.File{"main.go": {Size: 300}, "README.md": {Size: 10}}},
+	{ID: "b", Description: "Alpha", CreatedAt: "2018-03-01T10:00:00Z", UpdatedAt: "2018-06-02T10:00:00Z",
+		Files: map[string]gist.File{"q.sql": {Size: 20}}},
+	{ID: "c", Description: "gamma #helm #k8s", CreatedAt: "2017-12-01T10:00:00Z", UpdatedAt: "2018-06-01T10:00:00Z",
+		Files: map[string]gist.File{"notes": {Size: 1000}}},
+}
+
+func TestTruncate(t *testing.T) {
+	tcs := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a bit longer", 10, "a bit l..."},
+		{"ééééééééééé", 10, "ééééééé..."},
+		{"\U0001F512\U0001F512\U0001F512\U0001F512", 4, "\U0001F512\U0001F512\U0001F512\U0001F512"},
+	}
+	for _, tc := range tcs {
+		if got := truncate(tc.text, tc.n); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.text, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestSortGists(t *testing.T) {
+	tcs := []struct {
+		by   SortOrder
+		want []int
+	}{
+		{SortNone, []int{0, 1, 2}},
+		{SortUpdated, []int{1, 2, 0}},
+		{SortCreated, []int{1, 0, 2}},
+		{SortName, []int{1, 0, 2}},
+		{SortSize, []int{2, 0, 1}},
+	}
+	for _, tc := range tcs {
+		if got := sortGists(arrangeGists, tc.by); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("sortGists(%d) = %v, want %v", tc.by, got, tc.want)
+		}
+	}
+}
+
+func TestGroupGists(t *testing.T) {
+	tcs := []struct {
+		by   Grouping
+		want []string
+	}{
+		{GroupLanguage, []string{"Go:a", "Sql:b", "Other:c"}},
+		{GroupVisibility, []string{"Public:a", "Secret:b,c"}},
+		{GroupTag, []string{"#helm:c", "#k8s:a", "Untagged:b"}},
+		{GroupMonth, []string{"June 2018:b,c", "May 2018:a"}},
+	}
+	for _, tc := range tcs {
+		var got []string
+		for _, gr := range groupGists(arrangeGists, []int{1, 2, 0}, tc.by) {
+			var ids []string
+			for _, i := range gr.indexes {
+				ids = append(ids, arrangeGists[i].ID)
+			}
+			got = append(got, gr.name+":"+strings.Join(ids, ","))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("groupGists(%d) = %v, want %v", tc.by, got, tc.want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	tcs := []struct{ timestamp, want string }{
+		{"", ""},
+		{"2018-06-01T11:59:30Z", "just now"},
+		{"2018-06-01T13:00:00Z", "just now"},
+		{"2018-06-01T11:59:00Z", "1 minute ago"},
+		{"2018-06-01T09:00:00Z", "3 hours ago"},
+		{"2018-05-29T12:00:00Z", "3 days ago"},
+		{"2018-03-01T12:00:00Z", "3 months ago"},
+		{"2016-05-01T12:00:00Z", "2 years ago"},
+	}
+	for _, tc := range tcs {
+		if got := relativeTime(tc.timestamp, now); got != tc.want {
+			t.Errorf("relativeTime(%q) = %q, want %q", tc.timestamp, got, tc.want)
+		}
+	}
+}
+
+func TestDetails(t *testing.T) {
+	now := time.Date(2018, 6, 4, 10, 0, 0, 0, time.UTC)
+	if got, want := details(arrangeGists[1], now), "secret · 1 file · sql · updated 2 days ago"; got != want {
+		t.Errorf("details() = %q, want %q", got, want)
+	}
+	if got, want := details(gist.Gist{Public: true}, now), "public · 0 files"; got != want {
+		t.Errorf("details() = %q, want %q", got, want)
+	}
+}
+
+func TestArrange(t *testing.T) { tRunner.Run(func() { testArrange(t) }) }
+func testArrange(t *testing.T) {
+	var arranged []int
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.ConnectArranged(func(order, grouping int) { arranged = []int{order, grouping} })
+	for _, g := range arrangeGists {
+		c.Add(g)
+	}
+	rows := func() []string {
+		var res []string
+		for i := 0; i < c.Count(); i++ {
+			if c.Item(i).IsHidden() {
+				continue
+			}
+			if id := c.ID(i); id != "" {
+				res = append(res, id)
+				continue
+			}
+			res = append(res, c.Item(i).Text(0))
+		}
+		return res
+	}
+
+	c.Item(2).SetSelected(true)
+	c.SetSortOrder(SortUpdated)
+	if got := rows(); !reflect.DeepEqual(got, []string{"b", "c", "a"}) {
+		t.Errorf("rows = %v, want [b c a]", got)
+	}
+	if !reflect.DeepEqual(c.SelectedIDs(), []string{"c"}) {
+		t.Errorf("SelectedIDs() = %v, want the selection kept", c.SelectedIDs())
+	}
+	if !reflect.DeepEqual(arranged, []int{int(SortUpdated), int(GroupNone)}) {
+		t.Errorf("arranged = %v, want the new order", arranged)
+	}
+
+	c.SetGrouping(GroupVisibility)
+	want := []string{"Public (1)", "a", "Secret (2)", "b", "c"}
+	if got := rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+	c.Add(gist.Gist{ID: "d", Public: true, UpdatedAt: "2018-07-01T10:00:00Z"})
+	want = []string{"Public (2)", "d", "a", "Secret (2)", "b", "c"}
+	if got := rows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+	c.FilterTags([]string{"helm"})
+	if got := rows(); !reflect.DeepEqual(got, []string{"Secret (2)", "c"}) {
+		t.Errorf("rows = %v, want the public group hidden", got)
+	}
+	c.FilterTags(nil)
+	c.Remove("a")
+	c.Remove("d")
+	if got := rows(); !reflect.DeepEqual(got, []string{"Secret (2)", "b", "c"}) {
+		t.Errorf("rows = %v, want the empty group removed", got)
+	}
+
+	for _, a := range c.ArrangeActions() {
+		if a.Text() == "Group by Month" {
+			a.Trigger()
+		}
+	}
+	if c.grouping != GroupMonth {
+		t.Errorf("grouping = %d, want %d", c.grouping, GroupMonth)
+	}
+	c.SetGrouping(Grouping(100))
+	if c.grouping != GroupNone || !c.groupActions[GroupNone].IsChecked() {
+		t.Error("an invalid grouping was not reset")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// newDelegate returns a delegate that draws the description of each gist with
+// its details under it. Group headers and files are drawn as they are.
+func (c *Container) newDelegate() *widgets.QStyledItemDelegate {
+	delegate := widgets.NewQStyledItemDelegate(c)
+	delegate.ConnectPaint(func(painter *gui.QPainter, option *widgets.QStyleOptionViewItem, index *core.QModelIndex) {
+		g, ok := c.gists[c.IndexID(index)]
+		if !ok || index.Data(headerRole).ToBool() || c.IndexFile(index) != "" {
+			delegate.PaintDefault(painter, option, index)
+			return
+		}
+		opt := widgets.NewQStyleOptionViewItem2(option)
+		delegate.InitStyleOption(opt, index)
+		text := opt.Text()
+		opt.SetText("")
+		style := c.Style()
+		style.DrawControl(widgets.QStyle__CE_ItemViewItem, opt, painter, c)
+		rect := style.SubElementRect(widgets.QStyle__SE_ItemViewItemText, opt, c)
+
+		role := gui.QPalette__Text
+		if opt.State()&widgets.QStyle__State_Selected != 0 {
+			role = gui.QPalette__HighlightedText
+		}
+		color := opt.Palette().Color2(role).Name()
+		metrics := gui.NewQFontMetrics(opt.Font())
+		text = metrics.ElidedText(text, core.Qt__ElideRight, rect.Width(), 0)
+		info := metrics.ElidedText(details(g, time.Now()), core.Qt__ElideRight, rect.Width(), 0)
+
+		doc := gui.NewQTextDocument(nil)
+		defer doc.DestroyQTextDocument()
+		doc.SetDocumentMargin(0)
+		doc.SetDefaultFont(opt.Font())
+		doc.SetHtml(fmt.Sprintf(`<div style="color: %s">%s</div><div style="color: %s"><small>%s</small></div>`,
+			color, html.EscapeString(text), color, html.EscapeString(info)))
+		top := rect.Y() + (rect.Height()-int(doc.Size().Height()))/2
+
+		painter.Save()
+		painter.Translate3(float64(rect.X()), float64(top))
+		doc.DrawContents(painter, core.NewQRectF4(0, 0, float64(rect.Width()), float64(rect.Height())))
+		painter.Restore()
+	})
+	delegate.ConnectSizeHint(func(option *widgets.QStyleOptionViewItem, index *core.QModelIndex) *core.QSize {
+		size := delegate.SizeHintDefault(option, index)
+		if index.Data(headerRole).ToBool() || c.IndexFile(index) != "" {
+			return size
+		}
+		// room for the details line.
+		lines := 2*gui.NewQFontMetrics(option.Font()).LineSpacing() + 6
+		if size.Height() < lines {
+			size.SetHeight(lines)
+		}
+		return size
+	})
+	return delegate
+}
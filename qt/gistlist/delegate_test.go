@@ -0,0 +1,42 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestDelegate(t *testing.T) { tRunner.Run(func() { testDelegate(t) }) }
+func testDelegate(t *testing.T) {
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	description := strings.Repeat("long description ", 5)
+	c.Add(gist.Gist{ID: "a1", Description: description, Public: true})
+	c.SetGrouping(GroupVisibility)
+
+	header, item := c.Item(0), c.Item(1)
+	if !strings.Contains(item.ToolTip(0), description) || !strings.Contains(item.ToolTip(0), "public") {
+		t.Errorf("ToolTip() = %q, want the full description and the details", item.ToolTip(0))
+	}
+
+	option := widgets.NewQStyleOptionViewItem()
+	option.SetFont(c.Font())
+	lines := 2 * gui.NewQFontMetrics(c.Font()).LineSpacing()
+	delegate := c.ItemDelegate()
+	if h := delegate.SizeHint(option, c.IndexFromItem(item, 0)).Height(); h < lines {
+		t.Errorf("gist height = %d, want at least %d for two lines", h, lines)
+	}
+	if h := delegate.SizeHint(option, c.IndexFromItem(header, 0)).Height(); h >= lines {
+		t.Errorf("header height = %d, want less than %d", h, lines)
+	}
+
+	// painting should not panic.
+	c.Resize2(300, 300)
+	c.Grab(c.Rect())
+}
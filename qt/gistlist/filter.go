@@ -0,0 +1,142 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"github.com/arsham/gistflow/search"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const filterHint = "Narrow with lang:go file:*.sql tag:k8s updated:>2018-01-01"
+
+// FilterBox is the field above a Container that narrows its gists as the user
+// types. It takes the same queries as the search box. Down and Enter move the
+// focus to the list, and Up on the first gist brings it back.
+type FilterBox struct {
+	widgets.QLineEdit
+
+	_ func() `constructor:"init"`
+
+	container *Container
+}
+
+func (b *FilterBox) init() {
+	b.SetPlaceholderText("Filter gists")
+	b.SetClearButtonEnabled(true)
+	b.SetToolTip(filterHint)
+	b.ConnectTextChanged(b.apply)
+	b.ConnectKeyPressEvent(func(event *gui.QKeyEvent) {
+		switch core.Qt__Key(event.Key()) {
+		case core.Qt__Key_Down, core.Qt__Key_Enter, core.Qt__Key_Return:
+			if b.container != nil && b.container.SelectFirst() {
+				b.container.SetFocus2()
+			}
+		case core.Qt__Key_Escape:
+			b.Clear()
+		default:
+			b.KeyPressEventDefault(event)
+		}
+	})
+}
+
+// SetContainer filters the gists of c with the text of the box.
+func (b *FilterBox) SetContainer(c *Container) {
+	b.container = c
+	c.ConnectTopReached(func() {
+		b.SetFocus2()
+		b.SelectAll()
+	})
+	b.apply(b.Text())
+}
+
+// apply filters the container. An invalid query is shown in red with the
+// error as its tooltip, and the container keeps the last valid one.
+func (b *FilterBox) apply(text string) {
+	if b.container == nil {
+		return
+	}
+	if err := b.container.SetFilter(text); err != nil {
+		b.SetStyleSheet("color: red;")
+		b.SetToolTip(err.Error())
+		return
+	}
+	b.SetStyleSheet("")
+	b.SetToolTip(filterHint)
+}
+
+// SetFilter only shows the gists that match the query, the same way the
+// search box matches them without their contents. The filter is kept when the
+// gists are cleared and added again.
+func (c *Container) SetFilter(query string) error {
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return err
+	}
+	c.filter = q
+	c.applyFilter()
+	return nil
+}
+
+// matchesFilter reports whether the gist identified by id has the description
+// or a file name that fuzzy matches the text of the filter, and satisfies its
+// qualifiers.
+func (c *Container) matchesFilter(id string) bool {
+	if c.filter == nil {
+		return true
+	}
+	g := c.gists[id]
+	if c.filter.Filters() && !c.filter.Match(search.NewMeta(g)) {
+		return false
+	}
+	text := c.filter.Text()
+	if text == "" {
+		return true
+	}
+	if _, _, ok := search.Fuzzy(text, displayName(g)); ok {
+		return true
+	}
+	for name := range g.Files {
+		if _, _, ok := search.Fuzzy(text, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectFirst makes the first gist that is shown the current one, and returns
+// false if no gists are shown.
+func (c *Container) SelectFirst() bool {
+	if item := c.firstShown(); item != nil {
+		c.SetCurrentItem(item)
+		return true
+	}
+	return false
+}
+
+// firstShown returns the first gist item that is not hidden, or nil.
+func (c *Container) firstShown() *widgets.QTreeWidgetItem {
+	for i := 0; i < c.Count(); i++ {
+		item := c.Item(i)
+		if !item.IsHidden() && !item.Data(0, headerRole).ToBool() {
+			return item
+		}
+	}
+	return nil
+}
+
+// keyPressEvent emits topReached when Up is pressed on the first gist shown.
+func (c *Container) keyPressEvent(event *gui.QKeyEvent) {
+	if core.Qt__Key(event.Key()) == core.Qt__Key_Up && event.Modifiers() == core.Qt__NoModifier {
+		current := c.CurrentItem()
+		first := c.firstShown()
+		if current != nil && first != nil && current.Pointer() == first.Pointer() {
+			c.TopReached()
+			return
+		}
+	}
+	c.KeyPressEventDefault(event)
+}
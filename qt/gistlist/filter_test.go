@@ -0,0 +1,112 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/testlib"
+	"github.com/therecipe/qt/widgets"
+)
+
+// shownIDs returns the IDs of the gists that are not hidden.
+func shownIDs(c *Container) []string {
+	var ids []string
+	for i := 0; i < c.Count(); i++ {
+		if !c.Item(i).IsHidden() {
+			ids = append(ids, c.ID(i))
+		}
+	}
+	return ids
+}
+
+func TestFilterBox(t *testing.T) { tRunner.Run(func() { testFilterBox(t) }) }
+func testFilterBox(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	c := NewContainer(parent)
+	gists := []gist.Gist{
+		{ID: "a1", Description: "http server", Public: true, Files: map[string]gist.File{"main.go": {}}},
+		{ID: "b2", Description: "user queries", Files: map[string]gist.File{"users.sql": {}}},
+		{ID: "c3", Description: "notes", Files: map[string]gist.File{"deploy.sh": {}}},
+	}
+	for _, g := range gists {
+		c.Add(g)
+	}
+	b := NewFilterBox(parent)
+	b.SetContainer(c)
+
+	tcs := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{"a1", "b2", "c3"}},
+		{"srv", []string{"a1"}},
+		{"deploy", []string{"c3"}},
+		{"lang:sql", []string{"b2"}},
+		{"public:false", []string{"b2", "c3"}},
+		{"srv public:false", nil},
+	}
+	for _, tc := range tcs {
+		b.SetText(tc.text)
+		if got := shownIDs(c); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: shown = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+
+	b.SetText("srv")
+	b.SetText("srv owner:me")
+	if b.ToolTip() == filterHint {
+		t.Error("the error is not shown")
+	}
+	if got := shownIDs(c); !reflect.DeepEqual(got, []string{"a1"}) {
+		t.Errorf("shown = %v, want [a1]: the last valid filter was not kept", got)
+	}
+
+	b.SetText("deploy")
+	c.Clear()
+	for _, g := range gists {
+		c.Add(g)
+	}
+	if got := shownIDs(c); !reflect.DeepEqual(got, []string{"c3"}) {
+		t.Errorf("shown = %v, want [c3]: the filter was not kept after a refresh", got)
+	}
+}
+
+func TestFilterBoxNavigation(t *testing.T) { tRunner.Run(func() { testFilterBoxNavigation(t) }) }
+func testFilterBoxNavigation(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	c := NewContainer(parent)
+	c.Add(gist.Gist{ID: "a1", Description: "alpha"})
+	c.Add(gist.Gist{ID: "b2", Description: "beta"})
+	b := NewFilterBox(parent)
+	b.SetContainer(c)
+	var reached bool
+	c.ConnectTopReached(func() { reached = true })
+
+	b.SetText("bet")
+	event := testlib.NewQTestEventList()
+	event.AddKeyClick(core.Qt__Key_Down, core.Qt__NoModifier, -1)
+	event.Simulate(b)
+	if c.CurrentItem().Pointer() != c.Item(1).Pointer() {
+		t.Error("the first gist shown is not the current one")
+	}
+
+	event = testlib.NewQTestEventList()
+	event.AddKeyClick(core.Qt__Key_Up, core.Qt__NoModifier, -1)
+	event.Simulate(c)
+	if !reached {
+		t.Error("Up on the first gist didn't go back to the filter")
+	}
+
+	event = testlib.NewQTestEventList()
+	event.AddKeyClick(core.Qt__Key_Escape, core.Qt__NoModifier, -1)
+	event.Simulate(b)
+	if b.Text() != "" || len(shownIDs(c)) != 2 {
+		t.Error("Escape didn't clear the filter")
+	}
+}
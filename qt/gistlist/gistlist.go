@@ -5,57 +5,368 @@
 package gistlist
 
 import (
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/arsham/gistflow/search"
 	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
 )
 
 const (
 	maxLen      = 40
 	truncateStr = "..."
+	lockStr     = "\U0001F512 " // shown before encrypted gists.
+	headerRole  = int(core.Qt__UserRole) + 1
+	fileRole    = int(core.Qt__UserRole) + 2 // name of the file, on file items.
 )
 
-// Container holds a list of gists. This is used for the left side gist list.
+// Container holds a tree of gists and their files. This is used for the left
+// side gist list.
 type Container struct {
-	widgets.QListWidget
+	widgets.QTreeWidget
+
+	_ func()                             `constructor:"init"`
+	_ func(gist.Gist)                    `signal:"add"`
+	_ func([]gist.Gist)                  `signal:"addGists"`
+	_ func(string, string, string, bool) `signal:"fileDropped"`
+	_ func([]string)                     `signal:"mergeGists"`
+	_ func(string)                       `signal:"splitGist"`
+	_ func([]string)                     `signal:"addTag"`
+	_ func([]string)                     `signal:"removeTag"`
+	_ func()                             `signal:"tagsChanged"`
+	_ func(int, int)                     `signal:"arranged"`
+	_ func()                             `signal:"topReached"`
+	_ func([]string)                     `signal:"openGists"`
+	_ func(string)                       `signal:"openInBrowser"`
+	_ func(string)                       `signal:"copyURL"`
+	_ func([]string)                     `signal:"copyIDs"`
+	_ func(string)                       `signal:"cloneGist"`
+	_ func([]string, bool)               `signal:"starGists"`
+	_ func([]string)                     `signal:"exportGists"`
+	_ func([]string)                     `signal:"deleteGists"`
 
-	_ func()          `constructor:"init"`
-	_ func(gist.Gist) `signal:"add"`
+	items     map[string]*widgets.QTreeWidgetItem
+	gists     map[string]gist.Gist       // as they were added, without the contents.
+	order     []*widgets.QTreeWidgetItem // of the gists, as they were added.
+	headers   []*widgets.QTreeWidgetItem // of the groups.
+	tagFilter []string
+	filter    *search.Query // of the FilterBox.
 
-	items map[string]*widgets.QListWidgetItem
+	sortOrder    SortOrder
+	grouping     Grouping
+	sortActions  []*widgets.QAction
+	groupActions []*widgets.QAction
+	gistActions  []*widgets.QAction
+	enabledFor   []func(selected int) bool // of the gist actions.
 }
 
 func (c *Container) init() {
 	c.ConnectAdd(c.add)
-	c.items = make(map[string]*widgets.QListWidgetItem, 10)
+	c.ConnectAddGists(c.addGists)
+	c.ConnectClear(c.clear)
+	c.ConnectKeyPressEvent(c.keyPressEvent)
+	c.items = make(map[string]*widgets.QTreeWidgetItem, 10)
+	c.gists = make(map[string]gist.Gist, 10)
+	c.SetColumnCount(1)
+	c.SetHeaderHidden(true)
+	c.SetExpandsOnDoubleClick(false)
+	c.SetSelectionMode(widgets.QAbstractItemView__ExtendedSelection)
+	c.SetItemDelegate(c.newDelegate())
+	c.setupArrangeActions()
+	c.setupGistActions()
+	c.ConnectItemSelectionChanged(c.updateGistActions)
+
+	c.SetAcceptDrops(true)
+	c.SetDragDropMode(widgets.QAbstractItemView__DropOnly)
+	c.ConnectDragEnterEvent(func(event *gui.QDragEnterEvent) {
+		if _, _, ok := tab.FileFromMimeData(event.MimeData()); ok {
+			event.AcceptProposedAction()
+			return
+		}
+		event.Ignore()
+	})
+	c.ConnectDragMoveEvent(func(event *gui.QDragMoveEvent) {
+		id, _, ok := tab.FileFromMimeData(event.MimeData())
+		if dst := c.idAt(event.Pos()); ok && dst != "" && dst != id {
+			event.AcceptProposedAction()
+			return
+		}
+		event.Ignore()
+	})
+	c.ConnectDropEvent(func(event *gui.QDropEvent) {
+		id, name, ok := tab.FileFromMimeData(event.MimeData())
+		dst := c.idAt(event.Pos())
+		if !ok || dst == "" || dst == id {
+			event.Ignore()
+			return
+		}
+		event.AcceptProposedAction()
+		c.FileDropped(id, name, dst, event.DropAction() == core.Qt__CopyAction)
+	})
+
+	c.SetContextMenuPolicy(core.Qt__CustomContextMenu)
+	c.ConnectCustomContextMenuRequested(func(pos *core.QPoint) {
+		c.ContextMenu().Exec2(c.MapToGlobal(pos), nil)
+	})
+}
+
+// setupGistActions creates the actions on the selected gists. The actions
+// that can work on many gists are enabled for any selection.
+func (c *Container) setupGistActions() {
+	some := func(n int) bool { return n > 0 }
+	one := func(n int) bool { return n == 1 }
+	add := func(text string, enabled func(int) bool, fn func(ids []string)) {
+		a := widgets.NewQAction2(text, c)
+		a.ConnectTriggered(func(bool) {
+			if ids := c.SelectedIDs(); enabled(len(ids)) {
+				fn(ids)
+			}
+		})
+		c.gistActions = append(c.gistActions, a)
+		c.enabledFor = append(c.enabledFor, enabled)
+	}
+	separator := func() {
+		a := widgets.NewQAction(c)
+		a.SetSeparator(true)
+		c.gistActions = append(c.gistActions, a)
+		c.enabledFor = append(c.enabledFor, func(int) bool { return true })
+	}
+	add("Open", some, c.OpenGists)
+	add("Open in Browser", one, func(ids []string) { c.OpenInBrowser(ids[0]) })
+	add("Copy URL", one, func(ids []string) { c.CopyURL(ids[0]) })
+	add("Copy ID", some, c.CopyIDs)
+	add("Clone", one, func(ids []string) { c.CloneGist(ids[0]) })
+	separator()
+	add("Star", some, func(ids []string) { c.StarGists(ids, true) })
+	add("Unstar", some, func(ids []string) { c.StarGists(ids, false) })
+	add("Add Tag...", some, c.AddTag)
+	add("Remove Tag...", some, c.RemoveTag)
+	add("Export...", some, c.ExportGists)
+	separator()
+	add("Merge Selected Gists", func(n int) bool { return n > 1 }, c.MergeGists)
+	add("Split Into Gists", one, func(ids []string) { c.SplitGist(ids[0]) })
+	separator()
+	add("Delete", some, c.DeleteGists)
+	c.updateGistActions()
+}
+
+// updateGistActions enables the gist actions that can work on the selection.
+func (c *Container) updateGistActions() {
+	n := len(c.SelectedIDs())
+	for i, a := range c.gistActions {
+		a.SetEnabled(c.enabledFor[i](n))
+	}
+}
+
+// GistActions returns the actions on the selected gists, which are shown in
+// the context menu.
+func (c *Container) GistActions() []*widgets.QAction { return c.gistActions }
+
+// ContextMenu returns the menu of actions on the selected gists, and of the
+// actions that sort and group the list.
+func (c *Container) ContextMenu() *widgets.QMenu {
+	c.updateGistActions()
+	menu := widgets.NewQMenu(c)
+	menu.AddActions(c.gistActions)
+	menu.AddSeparator()
+	menu.AddMenu2("Sort By").AddActions(c.sortActions)
+	menu.AddMenu2("Group By").AddActions(c.groupActions)
+	return menu
+}
+
+// SelectedIDs returns the IDs of the selected gists, in the order they are
+// shown.
+func (c *Container) SelectedIDs() []string {
+	var ids []string
+	for i := 0; i < c.Count(); i++ {
+		if item := c.Item(i); item.IsSelected() {
+			ids = append(ids, item.Data(0, int(core.Qt__UserRole)).ToString())
+		}
+	}
+	return ids
+}
+
+// idAt returns the ID of the gist at the position, or an empty string if there
+// is no gist there.
+func (c *Container) idAt(pos core.QPoint_ITF) string {
+	item := c.ItemAt(pos)
+	if item == nil || item.Pointer() == nil {
+		return ""
+	}
+	return item.Data(0, int(core.Qt__UserRole)).ToString()
+}
+
+// add adds the gist to the list, or updates its item if it is already there.
+func (c *Container) add(g gist.Gist) { c.addGists([]gist.Gist{g}) }
+
+// addGists adds the gists to the list, or updates their items if they are
+// already there. The list is arranged and tagsChanged is emitted once for all
+// of them.
+func (c *Container) addGists(gists []gist.Gist) {
+	arrange := c.sortOrder != SortNone || c.grouping != GroupNone
+	for _, g := range gists {
+		item := c.setItem(g)
+		if !arrange {
+			item.SetHidden(!c.accepts(g.ID))
+		}
+	}
+	if arrange {
+		c.arrange()
+	}
+	c.TagsChanged()
+}
+
+// setItem adds the item of the gist, or updates it, and returns the item.
+func (c *Container) setItem(g gist.Gist) *widgets.QTreeWidgetItem {
+	item, ok := c.items[g.ID]
+	ok = ok && g.ID != ""
+	if !ok {
+		item = widgets.NewQTreeWidgetItem(0)
+	}
+	description := truncate(displayName(g), maxLen)
+	tooltip := displayName(g)
+	if g.Encrypted() {
+		description = lockStr + description
+		tooltip += "\nEncrypted"
+	}
+	item.SetText(0, description)
+	item.SetToolTip(0, tooltip+"\n"+details(g, time.Now()))
+	item.SetIcon(0, c.visibilityIcon(g.Public))
+	item.SetData(0, int(core.Qt__UserRole), core.NewQVariant14(g.ID))
+	c.setFiles(item, g)
+	if !ok {
+		c.AddTopLevelItem(item)
+		c.items[g.ID] = item
+		c.order = append(c.order, item)
+	}
+	c.gists[g.ID] = g
+	return item
 }
 
-func (c *Container) add(g gist.Gist) {
-	item := widgets.NewQListWidgetItem(c, 0)
-	description := g.Description
-	if description == "" {
-		for n := range g.Files {
-			description = n
-			break
+// setFiles replaces the file items of the gist item with the files of g.
+func (c *Container) setFiles(item *widgets.QTreeWidgetItem, g gist.Gist) {
+	for _, child := range item.TakeChildren() {
+		child.DestroyQTreeWidgetItem()
+	}
+	names := make([]string, 0, len(g.Files))
+	for name := range g.Files {
+		names = append(names, strings.TrimSuffix(name, gist.EncryptedExt))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := widgets.NewQTreeWidgetItem2([]string{name}, 0)
+		child.SetToolTip(0, name)
+		child.SetData(0, int(core.Qt__UserRole), core.NewQVariant14(g.ID))
+		child.SetData(0, fileRole, core.NewQVariant14(name))
+		item.AddChild(child)
+	}
+}
+
+func (c *Container) visibilityIcon(public bool) *gui.QIcon {
+	if public {
+		return c.Style().StandardIcon(widgets.QStyle__SP_DriveNetIcon, nil, nil)
+	}
+	return c.Style().StandardIcon(widgets.QStyle__SP_DriveHDIcon, nil, nil)
+}
+
+// clear removes all gists from the list.
+func (c *Container) clear() {
+	c.ClearDefault()
+	c.items = make(map[string]*widgets.QTreeWidgetItem, 10)
+	c.gists = make(map[string]gist.Gist, 10)
+	c.order = nil
+	c.headers = nil
+	c.TagsChanged()
+}
+
+// Gist returns the gist identified by id as it was added to the list.
+func (c *Container) Gist(id string) (gist.Gist, bool) {
+	g, ok := c.gists[id]
+	return g, ok
+}
+
+// Tags returns the tags in the descriptions of the gists, with the number of
+// the gists that have them.
+func (c *Container) Tags() map[string]int {
+	counts := make(map[string]int)
+	for _, g := range c.gists {
+		for _, tag := range search.Tags(g.Description) {
+			counts[tag]++
 		}
 	}
-	if len(description) > maxLen {
-		description = description[:maxLen-len(truncateStr)] + truncateStr
+	return counts
+}
+
+// TagNames returns the tags of all gists, sorted.
+func (c *Container) TagNames() []string {
+	var tags []string
+	for tag := range c.Tags() {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// IDsWithTag returns the IDs of the gists that have the tag, in the order they
+// are shown.
+func (c *Container) IDsWithTag(tag string) []string {
+	var ids []string
+	for i := 0; i < c.Count(); i++ {
+		id := c.ID(i)
+		if c.hasTags(id, []string{tag}) {
+			ids = append(ids, id)
+		}
 	}
-	item.SetText(description)
-	item.SetData(int(core.Qt__UserRole), core.NewQVariant14(g.ID))
-	c.AddItem2(item)
-	c.items[g.ID] = item
+	return ids
 }
 
+// FilterTags only shows the gists that have all the tags. All gists are shown
+// if tags is empty.
+func (c *Container) FilterTags(tags []string) {
+	c.tagFilter = tags
+	c.applyFilter()
+}
+
+// accepts reports whether the gist identified by id passes the filters.
+func (c *Container) accepts(id string) bool {
+	return c.hasTags(id, c.tagFilter) && c.matchesFilter(id)
+}
+
+func (c *Container) hasTags(id string, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	have := make(map[string]bool)
+	for _, tag := range search.Tags(c.gists[id].Description) {
+		have[tag] = true
+	}
+	for _, tag := range tags {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of the items on the top level of the tree.
+func (c *Container) Count() int { return c.TopLevelItemCount() }
+
+// Item returns the item on the top level of the tree at row.
+func (c *Container) Item(row int) *widgets.QTreeWidgetItem { return c.TopLevelItem(row) }
+
 // ID returns the ID of the gist associated with the index.
 func (c *Container) ID(row int) string {
-	return c.Item(row).Data(int(core.Qt__UserRole)).ToString()
+	return c.Item(row).Data(0, int(core.Qt__UserRole)).ToString()
 }
 
 // Description returns the Description of the gist associated with the index.
 func (c *Container) Description(row int) string {
-	return c.Item(row).Text()
+	return c.Item(row).Text(0)
 }
 
 // IndexID returns the ID of the gist associated with the index.
@@ -63,6 +374,12 @@ func (c *Container) IndexID(index *core.QModelIndex) string {
 	return index.Data(int(core.Qt__UserRole)).ToString()
 }
 
+// IndexFile returns the name of the file associated with the index, or an
+// empty string if the index is not on a file.
+func (c *Container) IndexFile(index *core.QModelIndex) string {
+	return index.Data(fileRole).ToString()
+}
+
 // IndexDescription returns the Description of the gist associated with the index.
 func (c *Container) IndexDescription(index *core.QModelIndex) string {
 	return index.Data(int(core.Qt__DisplayRole)).ToString()
@@ -71,9 +388,20 @@ func (c *Container) IndexDescription(index *core.QModelIndex) string {
 // Remove removes an item from the list by its gist ID.
 func (c *Container) Remove(id string) {
 	if item, ok := c.items[id]; ok {
-		row := c.Row(item)
-		c.TakeItem(row)
+		c.TakeTopLevelItem(c.IndexOfTopLevelItem(item))
 		delete(c.items, id)
+		delete(c.gists, id)
+		for i, o := range c.order {
+			if o.Pointer() == item.Pointer() {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+		if c.grouping != GroupNone {
+			// updating the counts and dropping the empty groups.
+			c.arrange()
+		}
+		c.TagsChanged()
 	}
 }
 
@@ -5,11 +5,16 @@
 package gistlist
 
 import (
+	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
 )
 
@@ -39,7 +44,7 @@ func testTruncateDescription(t *testing.T) {
 			Description: tc.text,
 		}
 		c.Add(r)
-		ret := c.Item(0).Text()
+		ret := c.Item(0).Text(0)
 		switch tc.truncate {
 		case true:
 			if len(ret) > maxLen {
@@ -102,13 +107,13 @@ func testIndexID(t *testing.T) {
 	c.Add(gist.Gist{ID: id1})
 	c.Add(gist.Gist{ID: id2})
 	item := c.Item(0)
-	index := c.IndexFromItem(item)
+	index := c.IndexFromItem(item, 0)
 	if c.IndexID(index) != id1 {
 		t.Errorf("c.IndexID(:0) = %s, want %s", c.IndexID(index), id1)
 	}
 
 	item = c.Item(1)
-	index = c.IndexFromItem(item)
+	index = c.IndexFromItem(item, 0)
 	if c.IndexID(index) != id2 {
 		t.Errorf("c.IndexID(:1) = %s, want %s", c.IndexID(index), id2)
 	}
@@ -124,13 +129,13 @@ func testIndexDescription(t *testing.T) {
 	c.Add(gist.Gist{Description: desciption1})
 	c.Add(gist.Gist{Description: desciption2})
 	item := c.Item(0)
-	index := c.IndexFromItem(item)
+	index := c.IndexFromItem(item, 0)
 	if c.IndexDescription(index) != desciption1 {
 		t.Errorf("c.IndexDescription(:0) = %s, want %s", c.IndexDescription(index), desciption1)
 	}
 
 	item = c.Item(1)
-	index = c.IndexFromItem(item)
+	index = c.IndexFromItem(item, 0)
 	if c.IndexDescription(index) != desciption2 {
 		t.Errorf("c.IndexDescription(:1) = %s, want %s", c.IndexDescription(index), desciption2)
 	}
@@ -177,7 +182,7 @@ func testRemoveItem(t *testing.T) {
 		t.Errorf("len(c.items) = %d, want %d", len(c.items), currentLen-1)
 		return
 	}
-	row := c.IndexFromItem(item).Row()
+	row := c.IndexFromItem(item, 0).Row()
 	if row != -1 {
 		t.Errorf("row  %d, want -1", row)
 	}
@@ -194,8 +199,292 @@ func testRemoveItem(t *testing.T) {
 		t.Errorf("len(c.items) = %d, want %d", len(c.items), currentLen-2)
 		return
 	}
-	row = c.IndexFromItem(item).Row()
+	row = c.IndexFromItem(item, 0).Row()
 	if row != -1 {
 		t.Errorf("row  %d, want -1", row)
 	}
 }
+
+func TestEncryptedIndicator(t *testing.T) { tRunner.Run(func() { testEncryptedIndicator(t) }) }
+func testEncryptedIndicator(t *testing.T) {
+	var (
+		description = "wE2fSGuVYV"
+		c           = NewContainer(widgets.NewQWidget(nil, 0))
+	)
+	c.Add(gist.Gist{
+		Description: description,
+		Files: map[string]gist.File{
+			"file" + gist.EncryptedExt: gist.File{},
+		},
+	})
+	c.Add(gist.Gist{
+		Description: description,
+		Files: map[string]gist.File{
+			"file": gist.File{},
+		},
+	})
+	if c.Description(0) != lockStr+description {
+		t.Errorf("c.Description(0) = %s, want %s", c.Description(0), lockStr+description)
+	}
+	if c.Description(1) != description {
+		t.Errorf("c.Description(1) = %s, want %s", c.Description(1), description)
+	}
+}
+
+func TestContextMenu(t *testing.T) { tRunner.Run(func() { testContextMenu(t) }) }
+func testContextMenu(t *testing.T) {
+	var (
+		merged []string
+		split  string
+	)
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.ConnectMergeGists(func(ids []string) { merged = ids })
+	c.ConnectSplitGist(func(id string) { split = id })
+	for _, id := range []string{"qN8wE", "Ly2mR", "Zt5vK"} {
+		c.Add(gist.Gist{ID: id, Description: id})
+	}
+	action := func(menu *widgets.QMenu, text string) *widgets.QAction {
+		for _, a := range menu.Actions() {
+			if a.Text() == text {
+				return a
+			}
+		}
+		t.Fatalf("%s action not found", text)
+		return nil
+	}
+
+	for _, a := range c.GistActions() {
+		if a.IsEnabled() && !a.IsSeparator() {
+			t.Errorf("%s is enabled without a selection", a.Text())
+		}
+	}
+	c.Item(1).SetSelected(true)
+	for _, a := range c.GistActions() {
+		if a.Text() == "Split Into Gists" && !a.IsEnabled() {
+			t.Error("split is not enabled after selecting a gist")
+		}
+	}
+	menu := c.ContextMenu()
+	if action(menu, "Merge Selected Gists").IsEnabled() {
+		t.Error("merge is enabled for one gist")
+	}
+	action(menu, "Split Into Gists").Trigger()
+	if split != "Ly2mR" {
+		t.Errorf("split = %s, want Ly2mR", split)
+	}
+
+	c.Item(0).SetSelected(true)
+	c.Item(2).SetSelected(true)
+	menu = c.ContextMenu()
+	if action(menu, "Split Into Gists").IsEnabled() {
+		t.Error("split is enabled for several gists")
+	}
+	action(menu, "Merge Selected Gists").Trigger()
+	want := []string{"qN8wE", "Ly2mR", "Zt5vK"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged = %v, want %v", merged, want)
+	}
+}
+
+func TestContextMenuActions(t *testing.T) { tRunner.Run(func() { testContextMenuActions(t) }) }
+func testContextMenuActions(t *testing.T) {
+	var got []string
+	record := func(name string) func([]string) {
+		return func(ids []string) { got = append(got, name+" "+strings.Join(ids, ",")) }
+	}
+	one := func(name string) func(string) {
+		return func(id string) { got = append(got, name+" "+id) }
+	}
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.ConnectOpenGists(record("open"))
+	c.ConnectOpenInBrowser(one("browser"))
+	c.ConnectCopyURL(one("url"))
+	c.ConnectCopyIDs(record("ids"))
+	c.ConnectCloneGist(one("clone"))
+	c.ConnectStarGists(func(ids []string, star bool) {
+		got = append(got, fmt.Sprintf("star %t %s", star, strings.Join(ids, ",")))
+	})
+	c.ConnectExportGists(record("export"))
+	c.ConnectDeleteGists(record("delete"))
+	for _, id := range []string{"qN8wE", "Ly2mR"} {
+		c.Add(gist.Gist{ID: id, Description: id})
+	}
+
+	trigger := func(texts ...string) {
+		for _, a := range c.ContextMenu().Actions() {
+			for _, text := range texts {
+				if a.Text() == text && a.IsEnabled() {
+					a.Trigger()
+				}
+			}
+		}
+	}
+	all := []string{"Open", "Open in Browser", "Copy URL", "Copy ID", "Clone", "Star", "Unstar", "Export...", "Delete"}
+	c.Item(1).SetSelected(true)
+	trigger(all...)
+	want := []string{
+		"open Ly2mR", "browser Ly2mR", "url Ly2mR", "ids Ly2mR", "clone Ly2mR",
+		"star true Ly2mR", "star false Ly2mR", "export Ly2mR", "delete Ly2mR",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = nil
+	c.Item(0).SetSelected(true)
+	trigger(all...)
+	want = []string{
+		"open qN8wE,Ly2mR", "ids qN8wE,Ly2mR", "star true qN8wE,Ly2mR",
+		"star false qN8wE,Ly2mR", "export qN8wE,Ly2mR", "delete qN8wE,Ly2mR",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v: single gist actions should be disabled", got, want)
+	}
+}
+
+func TestFileDropped(t *testing.T) { tRunner.Run(func() { testFileDropped(t) }) }
+func testFileDropped(t *testing.T) {
+	var src, name, dst string
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.Resize2(200, 200)
+	c.Add(gist.Gist{ID: "bR4xW", Description: "target"})
+	c.ConnectFileDropped(func(id, fileName, dstID string, copy bool) {
+		src, name, dst = id, fileName, dstID
+	})
+	pos := c.VisualItemRect(c.Item(0)).Center()
+	event := gui.NewQDropEvent(
+		core.NewQPointF2(pos),
+		core.Qt__MoveAction,
+		tab.NewFileMimeData("pH7cN", "main.go"),
+		core.Qt__LeftButton,
+		core.Qt__NoModifier,
+		core.QEvent__Drop,
+	)
+	core.QCoreApplication_SendEvent(c.Viewport(), event)
+	if src != "pH7cN" || name != "main.go" || dst != "bR4xW" {
+		t.Errorf("dropped (%s, %s, %s), want (pH7cN, main.go, bR4xW)", src, name, dst)
+	}
+}
+
+func TestUpdateItem(t *testing.T) { tRunner.Run(func() { testUpdateItem(t) }) }
+func testUpdateItem(t *testing.T) {
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.Add(gist.Gist{ID: "pX3nD", Description: "old"})
+	c.Add(gist.Gist{ID: "pX3nD", Description: "new #go"})
+	if c.Count() != 1 {
+		t.Fatalf("c.Count() = %d, want 1", c.Count())
+	}
+	if c.Description(0) != "new #go" {
+		t.Errorf("Description(0) = %s, want new #go", c.Description(0))
+	}
+	if g, ok := c.Gist("pX3nD"); !ok || g.Description != "new #go" {
+		t.Errorf("Gist() = %v, %t, want the new description", g, ok)
+	}
+}
+
+func TestTagFilter(t *testing.T) { tRunner.Run(func() { testTagFilter(t) }) }
+func testTagFilter(t *testing.T) {
+	var changed int
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.ConnectTagsChanged(func() { changed++ })
+	c.Add(gist.Gist{ID: "a1", Description: "deploy #k8s #helm"})
+	c.Add(gist.Gist{ID: "b2", Description: "pods #K8s"})
+	c.Add(gist.Gist{ID: "c3", Description: "no tags"})
+	if changed != 3 {
+		t.Errorf("changed = %d, want 3", changed)
+	}
+	c.AddGists([]gist.Gist{
+		{ID: "a1", Description: "deploy #k8s #helm"},
+		{ID: "b2", Description: "pods #K8s"},
+	})
+	if changed != 4 || c.Count() != 3 {
+		t.Errorf("changed = %d, Count() = %d, want 4 and 3", changed, c.Count())
+	}
+
+	want := map[string]int{"k8s": 2, "helm": 1}
+	if got := c.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+	if got := c.IDsWithTag("k8s"); !reflect.DeepEqual(got, []string{"a1", "b2"}) {
+		t.Errorf("IDsWithTag(k8s) = %v, want [a1 b2]", got)
+	}
+
+	hidden := func() []bool {
+		return []bool{c.Item(0).IsHidden(), c.Item(1).IsHidden(), c.Item(2).IsHidden()}
+	}
+	c.FilterTags([]string{"k8s", "helm"})
+	if got := hidden(); !reflect.DeepEqual(got, []bool{false, true, true}) {
+		t.Errorf("hidden = %v, want [false true true]", got)
+	}
+	c.Add(gist.Gist{ID: "d4", Description: "#helm chart"})
+	if !c.Item(3).IsHidden() {
+		t.Error("the new gist is shown, want it filtered out")
+	}
+	c.FilterTags(nil)
+	if got := hidden(); !reflect.DeepEqual(got, []bool{false, false, false}) {
+		t.Errorf("hidden = %v, want all shown", got)
+	}
+
+	c.Remove("a1")
+	want = map[string]int{"k8s": 1, "helm": 1}
+	if got := c.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+	c.Clear()
+	if len(c.Tags()) != 0 || c.HasID("b2") {
+		t.Error("the gists are kept after Clear")
+	}
+}
+
+func TestTagActions(t *testing.T) { tRunner.Run(func() { testTagActions(t) }) }
+func testTagActions(t *testing.T) {
+	var added, removed []string
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.ConnectAddTag(func(ids []string) { added = ids })
+	c.ConnectRemoveTag(func(ids []string) { removed = ids })
+	c.Add(gist.Gist{ID: "a1"})
+	c.Add(gist.Gist{ID: "b2"})
+	c.Item(1).SetSelected(true)
+	for _, a := range c.ContextMenu().Actions() {
+		switch a.Text() {
+		case "Add Tag...", "Remove Tag...":
+			a.Trigger()
+		}
+	}
+	if !reflect.DeepEqual(added, []string{"b2"}) {
+		t.Errorf("added = %v, want [b2]", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"b2"}) {
+		t.Errorf("removed = %v, want [b2]", removed)
+	}
+}
+
+func TestFiles(t *testing.T) { tRunner.Run(func() { testFiles(t) }) }
+func testFiles(t *testing.T) {
+	c := NewContainer(widgets.NewQWidget(nil, 0))
+	c.Add(gist.Gist{ID: "a1", Files: map[string]gist.File{
+		"b.go" + gist.EncryptedExt: {},
+		"a.md" + gist.EncryptedExt: {},
+	}})
+	item := c.Item(0)
+	if item.ChildCount() != 2 {
+		t.Fatalf("ChildCount() = %d, want 2", item.ChildCount())
+	}
+	for i, want := range []string{"a.md", "b.go"} {
+		index := c.IndexFromItem(item.Child(i), 0)
+		if got := c.IndexFile(index); got != want {
+			t.Errorf("IndexFile(%d) = %q, want %q", i, got, want)
+		}
+		if got := c.IndexID(index); got != "a1" {
+			t.Errorf("IndexID(%d) = %q, want a1", i, got)
+		}
+	}
+	if got := c.IndexFile(c.IndexFromItem(item, 0)); got != "" {
+		t.Errorf("IndexFile(gist) = %q, want empty", got)
+	}
+
+	c.Add(gist.Gist{ID: "a1", Files: map[string]gist.File{"c.txt": {}}})
+	if item.ChildCount() != 1 || item.Child(0).Text(0) != "c.txt" {
+		t.Errorf("files were not replaced on update: %d children", item.ChildCount())
+	}
+}
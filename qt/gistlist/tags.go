@@ -0,0 +1,110 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// TagBrowser lists the tags of the gists in a Container with the number of
+// gists that have them. Selecting tags shows only the gists that have all of
+// them.
+type TagBrowser struct {
+	widgets.QListWidget
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"renameTag"`
+	_ func(string) `signal:"removeTag"`
+
+	container *Container
+}
+
+func (b *TagBrowser) init() {
+	b.SetSelectionMode(widgets.QAbstractItemView__MultiSelection)
+	b.ConnectItemSelectionChanged(func() {
+		if b.container != nil {
+			b.container.FilterTags(b.SelectedTags())
+		}
+	})
+	b.SetContextMenuPolicy(core.Qt__CustomContextMenu)
+	b.ConnectCustomContextMenuRequested(func(pos *core.QPoint) {
+		item := b.ItemAt(pos)
+		if item == nil || item.Pointer() == nil {
+			return
+		}
+		b.ContextMenu(tagOf(item)).Exec2(b.MapToGlobal(pos), nil)
+	})
+}
+
+// ContextMenu returns the menu of actions on the tag.
+func (b *TagBrowser) ContextMenu(tag string) *widgets.QMenu {
+	menu := widgets.NewQMenu(b)
+	rename := menu.AddAction(fmt.Sprintf("Rename #%s...", tag))
+	rename.ConnectTriggered(func(bool) {
+		b.RenameTag(tag)
+	})
+	remove := menu.AddAction(fmt.Sprintf("Remove #%s From All Gists", tag))
+	remove.ConnectTriggered(func(bool) {
+		b.RemoveTag(tag)
+	})
+	return menu
+}
+
+// SetContainer shows the tags of the gists in c, and keeps them updated.
+func (b *TagBrowser) SetContainer(c *Container) {
+	b.container = c
+	c.ConnectTagsChanged(b.refresh)
+	b.refresh()
+}
+
+// SelectedTags returns the tags that are selected, in the order they are
+// shown.
+func (b *TagBrowser) SelectedTags() []string {
+	var tags []string
+	for i := 0; i < b.Count(); i++ {
+		if item := b.Item(i); item.IsSelected() {
+			tags = append(tags, tagOf(item))
+		}
+	}
+	return tags
+}
+
+// refresh lists the tags of the container, the most used ones first. The
+// selected tags that are still used stay selected.
+func (b *TagBrowser) refresh() {
+	selected := make(map[string]bool)
+	for _, tag := range b.SelectedTags() {
+		selected[tag] = true
+	}
+	counts := b.container.Tags()
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+
+	b.BlockSignals(true)
+	b.Clear()
+	for _, tag := range tags {
+		item := widgets.NewQListWidgetItem2(fmt.Sprintf("#%s (%d)", tag, counts[tag]), b, 0)
+		item.SetData(int(core.Qt__UserRole), core.NewQVariant14(tag))
+		item.SetSelected(selected[tag])
+	}
+	b.BlockSignals(false)
+	b.container.FilterTags(b.SelectedTags())
+}
+
+func tagOf(item *widgets.QListWidgetItem) string {
+	return item.Data(int(core.Qt__UserRole)).ToString()
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package gistlist
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestTagBrowser(t *testing.T) { tRunner.Run(func() { testTagBrowser(t) }) }
+func testTagBrowser(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	c := NewContainer(parent)
+	c.Add(gist.Gist{ID: "a1", Description: "deploy #k8s #helm"})
+	c.Add(gist.Gist{ID: "b2", Description: "pods #k8s"})
+	b := NewTagBrowser(parent)
+	b.SetContainer(c)
+
+	texts := func() []string {
+		var res []string
+		for i := 0; i < b.Count(); i++ {
+			res = append(res, b.Item(i).Text())
+		}
+		return res
+	}
+	if got := texts(); !reflect.DeepEqual(got, []string{"#k8s (2)", "#helm (1)"}) {
+		t.Errorf("tags = %v, want [#k8s (2) #helm (1)]", got)
+	}
+
+	b.Item(1).SetSelected(true)
+	if !c.Item(1).IsHidden() || c.Item(0).IsHidden() {
+		t.Error("selecting #helm didn't filter the gists")
+	}
+	c.Add(gist.Gist{ID: "c3", Description: "#go"})
+	if got := b.SelectedTags(); !reflect.DeepEqual(got, []string{"helm"}) {
+		t.Errorf("SelectedTags() = %v, want [helm]", got)
+	}
+	c.Remove("a1")
+	if len(b.SelectedTags()) != 0 {
+		t.Errorf("SelectedTags() = %v, want none", b.SelectedTags())
+	}
+	if c.Item(0).IsHidden() {
+		t.Error("the gists are still filtered by a removed tag")
+	}
+}
+
+func TestTagBrowserMenu(t *testing.T) { tRunner.Run(func() { testTagBrowserMenu(t) }) }
+func testTagBrowserMenu(t *testing.T) {
+	var renamed, removed string
+	b := NewTagBrowser(widgets.NewQWidget(nil, 0))
+	b.ConnectRenameTag(func(tag string) { renamed = tag })
+	b.ConnectRemoveTag(func(tag string) { removed = tag })
+	for _, a := range b.ContextMenu("k8s").Actions() {
+		a.Trigger()
+	}
+	if renamed != "k8s" || removed != "k8s" {
+		t.Errorf("renamed = %q, removed = %q, want k8s", renamed, removed)
+	}
+}
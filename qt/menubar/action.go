@@ -40,7 +40,7 @@ func (a *Action) init() {
 	a.Quit.SetObjectName("ActionQuit")
 	a.Quit.SetShortcut(gui.QKeySequence_FromString("Ctrl+Q", 0))
 
-	a.NewGist = widgets.NewQAction2("NewGist", a)
+	a.NewGist = widgets.NewQAction2("New Gist", a)
 	a.NewGist.SetObjectName("ActionNew")
 	a.NewGist.SetShortcut(gui.QKeySequence_FromString("Ctrl+N", 0))
 
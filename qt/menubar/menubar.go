@@ -5,6 +5,9 @@
 package menubar
 
 import (
+	"strings"
+
+	"github.com/arsham/gistflow/qt/command"
 	"github.com/therecipe/qt/widgets"
 )
 
@@ -81,3 +84,12 @@ func (m *MenuBar) Window() *widgets.QMenu { return m.window }
 
 // Edit returns the edit.
 func (m *MenuBar) Edit() *widgets.QMenu { return m.edit }
+
+// Register adds the actions of the menus to the registry, under the titles of
+// their menus.
+func (m *MenuBar) Register(r *command.Registry) {
+	for _, menu := range []*widgets.QMenu{m.options, m.edit, m.window} {
+		title := strings.Replace(menu.Title(), "&", "", -1)
+		r.Register(title, menu.Actions()...)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/arsham/gistflow/qt/command"
 	"github.com/therecipe/qt/core"
 	"github.com/therecipe/qt/testlib"
 	"github.com/therecipe/qt/widgets"
@@ -124,3 +125,36 @@ func testToggleGistList(t *testing.T) {
 		t.Error("didn't send the ToggleGistList signal")
 	}
 }
+
+func TestRegister(t *testing.T) { tRunner.Run(func() { testRegister(t) }) }
+func testRegister(t *testing.T) {
+	m := NewMenuBar(widgets.NewQWidget(nil, 0))
+	r := command.NewRegistry()
+	m.Register(r)
+
+	shortcuts := make(map[string]string)
+	for _, c := range r.Commands() {
+		shortcuts[c.Name()] = c.Shortcut()
+	}
+	for name, shortcut := range map[string]string{
+		"Options: New Gist": "Ctrl+N",
+		"Options: Settings": "",
+		"Options: Quit":     "Ctrl+Q",
+		"Edit: In Browser":  "",
+		"Edit: Copy URL":    "",
+		"Window: Toolbar":   "",
+		"Window: Gist List": "",
+	} {
+		got, ok := shortcuts[name]
+		if !ok {
+			t.Errorf("%s is not registered: %v", name, shortcuts)
+			continue
+		}
+		if got != shortcut {
+			t.Errorf("%s: shortcut = %q, want %q", name, got, shortcut)
+		}
+	}
+	if len(shortcuts) != 7 {
+		t.Errorf("registered %d commands, want 7: %v", len(shortcuts), shortcuts)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"strings"
+
+	"github.com/arsham/gistflow/qt/command"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// commandPrefix switches the dialog to the command mode.
+const commandPrefix = ">"
+
+func (d *Dialog) setupCommands() {
+	d.commands = command.NewRegistry()
+	d.commandList = widgets.NewQTreeWidget(d)
+	d.commandList.SetObjectName("Commands")
+	d.commandList.SetColumnCount(2)
+	d.commandList.SetHeaderHidden(true)
+	d.commandList.SetRootIsDecorated(false)
+	header := d.commandList.Header()
+	header.SetStretchLastSection(false)
+	header.SetSectionResizeMode2(0, widgets.QHeaderView__Stretch)
+	header.SetSectionResizeMode2(1, widgets.QHeaderView__ResizeToContents)
+	d.commandList.Hide()
+	d.commandList.ConnectItemActivated(func(*widgets.QTreeWidgetItem, int) {
+		d.runCommand()
+	})
+	d.commandList.ConnectKeyPressEvent(d.handleCommandsKeyPress)
+}
+
+// SetCommands sets the registry the command mode lists its commands from.
+func (d *Dialog) SetCommands(r *command.Registry) {
+	d.commands = r
+	if d.commandMode() {
+		d.filter(d.input.Text())
+	}
+}
+
+func (d *Dialog) viewCommands(r *core.QRect) {
+	d.view(r)
+	d.input.SetText(commandPrefix)
+}
+
+// commandMode reports whether the dialog is listing the commands.
+func (d *Dialog) commandMode() bool {
+	return strings.HasPrefix(d.input.Text(), commandPrefix)
+}
+
+// setCommandMode shows the commands instead of the gists.
+func (d *Dialog) setCommandMode(on bool) {
+	d.commandList.SetVisible(on)
+	d.results.SetVisible(!on)
+	d.preview.SetVisible(!on)
+	d.hints.SetVisible(!on)
+	if on {
+		d.queryError.Hide()
+	}
+}
+
+// filterCommands lists the commands matching the query, with their shortcuts.
+func (d *Dialog) filterCommands(query string) {
+	d.shownCommands = d.commands.Find(query)
+	d.commandList.Clear()
+	for _, c := range d.shownCommands {
+		item := widgets.NewQTreeWidgetItem2([]string{c.Name(), c.Shortcut()}, 0)
+		d.commandList.AddTopLevelItem(item)
+	}
+	if len(d.shownCommands) > 0 {
+		d.commandList.SetCurrentItem(d.commandList.TopLevelItem(0))
+	}
+}
+
+// runCommand hides the dialog and triggers the selected command.
+func (d *Dialog) runCommand() {
+	row := d.commandList.IndexOfTopLevelItem(d.commandList.CurrentItem())
+	if row < 0 || row >= len(d.shownCommands) {
+		return
+	}
+	action := d.shownCommands[row].Action
+	d.Hide()
+	action.Trigger()
+}
+
+func (d *Dialog) handleCommandsKeyPress(event *gui.QKeyEvent) {
+	switch core.Qt__Key(event.Key()) {
+	case core.Qt__Key_Up, core.Qt__Key_Down:
+		d.commandList.KeyPressEventDefault(event)
+	case core.Qt__Key_Enter, core.Qt__Key_Return:
+		d.runCommand()
+	default:
+		d.input.SetText(d.input.Text() + event.Text())
+		d.input.SetFocus2()
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/command"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/testlib"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestCommandMode(t *testing.T) { tRunner.Run(func() { testCommandMode(t) }) }
+func testCommandMode(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	d := NewDialog(parent, 0)
+	d.Add(gist.Gist{ID: "Jk2Wq", Description: "new things"})
+
+	var called string
+	newGist := widgets.NewQAction2("New Gist", parent)
+	newGist.SetShortcut(gui.NewQKeySequence2("Ctrl+N", gui.QKeySequence__NativeText))
+	newGist.ConnectTriggered(func(bool) { called = "new" })
+	quit := widgets.NewQAction2("&Quit", parent)
+	quit.ConnectTriggered(func(bool) { called = "quit" })
+	r := command.NewRegistry()
+	r.Register("Options", newGist, quit)
+	d.SetCommands(r)
+
+	d.View(parent.Geometry())
+	d.input.SetText(">")
+	if !d.commandList.IsVisible() || d.results.IsVisible() {
+		t.Error("the commands are not shown instead of the gists")
+	}
+	if n := d.commandList.TopLevelItemCount(); n != 2 {
+		t.Fatalf("TopLevelItemCount() = %d, want 2", n)
+	}
+	item := d.commandList.TopLevelItem(0)
+	if item.Text(0) != "Options: New Gist" || item.Text(1) != "Ctrl+N" {
+		t.Errorf("item = %q %q, want Options: New Gist with Ctrl+N", item.Text(0), item.Text(1))
+	}
+
+	d.input.SetText(">qui")
+	if n := d.commandList.TopLevelItemCount(); n != 1 {
+		t.Fatalf("TopLevelItemCount() = %d, want 1", n)
+	}
+	event := testlib.NewQTestEventList()
+	event.AddKeyClick(core.Qt__Key_Return, core.Qt__NoModifier, -1)
+	event.Simulate(d.input)
+	if called != "quit" {
+		t.Errorf("called = %q, want quit", called)
+	}
+	if d.IsVisible() {
+		t.Error("the dialog is still visible")
+	}
+
+	d.View(parent.Geometry())
+	if d.input.Text() != "" || d.commandList.IsVisible() || !d.results.IsVisible() {
+		t.Error("the dialog didn't go back to searching gists")
+	}
+}
+
+func TestViewCommands(t *testing.T) { tRunner.Run(func() { testViewCommands(t) }) }
+func testViewCommands(t *testing.T) {
+	parent := widgets.NewQWidget(nil, 0)
+	d := NewDialog(parent, 0)
+	d.input.SetText("something")
+	d.ViewCommands(parent.Geometry())
+	defer d.Hide()
+	if d.input.Text() != commandPrefix {
+		t.Errorf("input = %q, want %q", d.input.Text(), commandPrefix)
+	}
+	if !d.commandList.IsVisible() {
+		t.Error("the commands are not shown")
+	}
+}
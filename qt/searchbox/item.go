@@ -5,6 +5,7 @@
 package searchbox
 
 import (
+	"github.com/arsham/gistflow/search"
 	"github.com/therecipe/qt/core"
 )
 
@@ -27,6 +28,9 @@ type listItem struct {
 
 	GistID      string
 	Description string
+	UpdatedAt   string
+
+	meta search.Meta
 }
 
 func (l *listModel) init() {
@@ -77,6 +81,17 @@ func (l *listModel) addGist(p *listItem) {
 	l.EndInsertRows()
 }
 
+// updated notifies the views that the item has changed.
+func (l *listModel) updated(p *listItem) {
+	for row, g := range l.gists {
+		if g == p {
+			index := l.Index(row, 0, core.NewQModelIndex())
+			l.DataChanged(index, index, []int{gistID, description})
+			return
+		}
+	}
+}
+
 // remove removes the gist identified by gistID from the list.
 func (l *listModel) remove(gistID string) {
 	for row, p := range l.gists {
@@ -0,0 +1,243 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// indexWeight brings the scores of the index to the range of fuzzy scores.
+const indexWeight = 10
+
+// match is the result of matching the query against a row.
+type match struct {
+	ok        bool
+	score     float64
+	positions []int  // of the matched runes in the description.
+	file      string // the best matching file, if any.
+}
+
+// filter lists the commands if the text starts with the command prefix.
+// Otherwise it parses the text as a query and shows the gists that satisfy its
+// qualifiers, with descriptions that fuzzy match its free text or with any
+// files that contain its words. The best matches come first. If the query is
+// invalid, the error is shown and the results are left as they were.
+func (d *Dialog) filter(text string) {
+	if strings.HasPrefix(text, commandPrefix) {
+		d.setCommandMode(true)
+		d.filterCommands(strings.TrimPrefix(text, commandPrefix))
+		return
+	}
+	d.setCommandMode(false)
+	q, err := search.ParseQuery(text)
+	if err != nil {
+		d.queryError.SetText(err.Error())
+		d.queryError.Show()
+		return
+	}
+	d.queryError.Hide()
+	d.parsed = q
+	d.query = q.Text()
+	d.matches = make(map[*listItem]match)
+	d.scores = make(map[string]float64)
+	d.files = make(map[string][]string)
+	for _, r := range d.index.Search(d.query) {
+		d.scores[r.ID] = r.Score
+		d.files[r.ID] = r.Files
+	}
+	d.proxy.Invalidate()
+	if d.query == "" {
+		// going back to the insertion order.
+		d.proxy.Sort(-1, core.Qt__AscendingOrder)
+	} else {
+		d.proxy.Sort(0, core.Qt__DescendingOrder)
+	}
+	d.selectFirstRow()
+}
+
+// filtering reports whether any rows could be hidden by the current query.
+func (d *Dialog) filtering() bool {
+	return d.query != "" || d.parsed != nil && d.parsed.Filters()
+}
+
+// match returns the match of the item against the current query. The results
+// are kept until the query changes.
+func (d *Dialog) match(item *listItem) match {
+	if m, ok := d.matches[item]; ok {
+		return m
+	}
+	var m match
+	if d.query == "" {
+		// only the qualifiers are left to check.
+		m.ok = true
+	} else if score, positions, ok := search.Fuzzy(d.query, item.Description); ok {
+		m = match{ok: true, score: float64(score), positions: positions}
+	}
+	if d.query != "" {
+		// a file name that matches better than the description points the
+		// result at the file.
+		for _, name := range item.meta.Files {
+			name = strings.TrimSuffix(name, gist.EncryptedExt)
+			if score, _, ok := search.Fuzzy(d.query, name); ok && (!m.ok || float64(score) > m.score) {
+				m = match{ok: true, score: float64(score), file: name}
+			}
+		}
+	}
+	if score, ok := d.scores[item.GistID]; ok && item.GistID != "" {
+		m.ok = true
+		m.score += score * indexWeight
+		if files := d.files[item.GistID]; m.file == "" && len(files) > 0 {
+			m.file = strings.TrimSuffix(files[0], gist.EncryptedExt)
+		}
+	}
+	if m.ok && d.parsed != nil && d.parsed.Filters() {
+		m.ok = d.parsed.Match(d.meta(item))
+	}
+	if m.ok {
+		m.score += float64(search.Recency(item.UpdatedAt, time.Now()))
+	}
+	d.matches[item] = m
+	return m
+}
+
+// meta returns the metadata of the item, with the contents of its files if
+// they are indexed.
+func (d *Dialog) meta(item *listItem) search.Meta {
+	m := item.meta
+	if contents := d.index.Contents(item.GistID); len(contents) > 0 {
+		m.Contents = contents
+	}
+	m.Starred = d.isStarred(item.GistID)
+	return m
+}
+
+// SetStarred marks the gists identified by ids as starred, which is used by
+// the starred: qualifier. It is safe to call it from other goroutines.
+func (d *Dialog) SetStarred(ids []string) {
+	starred := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		starred[id] = true
+	}
+	d.starredMu.Lock()
+	defer d.starredMu.Unlock()
+	d.starred = starred
+}
+
+func (d *Dialog) isStarred(id string) bool {
+	d.starredMu.RLock()
+	defer d.starredMu.RUnlock()
+	return d.starred[id]
+}
+
+func (d *Dialog) item(sourceRow int) *listItem {
+	if sourceRow < 0 || sourceRow >= len(d.model.gists) {
+		return nil
+	}
+	return d.model.gists[sourceRow]
+}
+
+func (d *Dialog) filterAcceptsRow(sourceRow int, sourceParent *core.QModelIndex) bool {
+	if !d.filtering() {
+		return true
+	}
+	item := d.item(sourceRow)
+	return item != nil && d.match(item).ok
+}
+
+// lessThan orders the rows by their scores. Rows with the same score keep
+// their insertion order when sorted descending.
+func (d *Dialog) lessThan(left, right *core.QModelIndex) bool {
+	l, r := d.item(left.Row()), d.item(right.Row())
+	if l == nil || r == nil {
+		return false
+	}
+	if ls, rs := d.match(l).score, d.match(r).score; ls != rs {
+		return ls < rs
+	}
+	return left.Row() > right.Row()
+}
+
+// Highlighted returns the description of the gist at row as HTML, with the
+// characters that matched the query in bold. The file the result points at is
+// shown after the description.
+func (d *Dialog) Highlighted(row int) string {
+	index := d.proxy.MapToSource(d.proxy.Index(row, 0, core.NewQModelIndex()))
+	item := d.item(index.Row())
+	if item == nil {
+		return ""
+	}
+	if d.query == "" {
+		return html.EscapeString(item.Description)
+	}
+	m := d.match(item)
+	text := search.Highlight(item.Description, m.positions, "b")
+	if m.file != "" {
+		text += " <small>&rsaquo; " + search.HighlightWords(m.file, search.Tokens(d.query), "b") + "</small>"
+	}
+	return text
+}
+
+// File returns the name of the file the result at row points at, or an empty
+// string if it points at the whole gist.
+func (d *Dialog) File(row int) string {
+	return d.fileAt(d.proxy.Index(row, 0, core.NewQModelIndex()))
+}
+
+func (d *Dialog) fileAt(index *core.QModelIndex) string {
+	if d.query == "" || !index.IsValid() {
+		return ""
+	}
+	item := d.item(d.proxy.MapToSource(index).Row())
+	if item == nil {
+		return ""
+	}
+	return d.match(item).file
+}
+
+// open opens the gist of the result at index, or its file if the result points
+// at one.
+func (d *Dialog) open(index *core.QModelIndex) {
+	id := index.Data(gistID).ToString()
+	if file := d.fileAt(index); file != "" {
+		d.OpenFile(id, file)
+		return
+	}
+	d.OpenGist(id)
+}
+
+// newDelegate returns a delegate that draws the descriptions with their
+// highlighted characters.
+func (d *Dialog) newDelegate() *widgets.QStyledItemDelegate {
+	delegate := widgets.NewQStyledItemDelegate(d)
+	delegate.ConnectPaint(func(painter *gui.QPainter, option *widgets.QStyleOptionViewItem, index *core.QModelIndex) {
+		opt := widgets.NewQStyleOptionViewItem2(option)
+		delegate.InitStyleOption(opt, index)
+		opt.SetText("")
+		style := d.results.Style()
+		style.DrawControl(widgets.QStyle__CE_ItemViewItem, opt, painter, d.results)
+
+		doc := gui.NewQTextDocument(nil)
+		defer doc.DestroyQTextDocument()
+		doc.SetDocumentMargin(0)
+		doc.SetDefaultFont(opt.Font())
+		doc.SetHtml(d.Highlighted(index.Row()))
+		rect := style.SubElementRect(widgets.QStyle__SE_ItemViewItemText, opt, d.results)
+		top := rect.Y() + (rect.Height()-int(doc.Size().Height()))/2
+
+		painter.Save()
+		painter.Translate3(float64(rect.X()), float64(top))
+		doc.DrawContents(painter, core.NewQRectF4(0, 0, float64(rect.Width()), float64(rect.Height())))
+		painter.Restore()
+	})
+	return delegate
+}
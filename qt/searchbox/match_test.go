@@ -0,0 +1,208 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestRanking(t *testing.T) { tRunner.Run(func() { testRanking(t) }) }
+func testRanking(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "scattered", Description: "a bag of sorted hats"})
+	d.Add(gist.Gist{ID: "none", Description: "nothing to see"})
+	d.Add(gist.Gist{ID: "boundary", Description: "bar shell"})
+	d.Add(gist.Gist{ID: "exact", Description: "bash snippets"})
+
+	d.input.SetText("bash")
+	want := []string{"exact", "boundary", "scattered"}
+	if c := d.Model().RowCount(core.NewQModelIndex()); c != len(want) {
+		t.Fatalf("RowCount() = %d, want %d", c, len(want))
+	}
+	for row, id := range want {
+		if d.ID(row) != id {
+			t.Errorf("d.ID(%d) = %s, want %s", row, d.ID(row), id)
+		}
+	}
+
+	d.input.SetText("")
+	if c := d.Model().RowCount(core.NewQModelIndex()); c != 4 {
+		t.Fatalf("RowCount() = %d, want 4", c)
+	}
+	if d.ID(0) != "scattered" {
+		t.Errorf("d.ID(0) = %s, want scattered: insertion order was not restored", d.ID(0))
+	}
+}
+
+func TestRankingRecency(t *testing.T) { tRunner.Run(func() { testRankingRecency(t) }) }
+func testRankingRecency(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "old", Description: "deploy", UpdatedAt: "2010-01-01T00:00:00Z"})
+	d.Add(gist.Gist{ID: "new", Description: "deploy", UpdatedAt: time.Now().Format(time.RFC3339)})
+	d.input.SetText("dep")
+	if d.ID(0) != "new" {
+		t.Errorf("d.ID(0) = %s, want new", d.ID(0))
+	}
+}
+
+func TestSpecialCharacters(t *testing.T) { tRunner.Run(func() { testSpecialCharacters(t) }) }
+func testSpecialCharacters(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "star", Description: "select * from"})
+	d.Add(gist.Gist{ID: "question", Description: "why?"})
+	for query, id := range map[string]string{"*": "star", "y?": "question"} {
+		d.input.SetText(query)
+		if c := d.Model().RowCount(core.NewQModelIndex()); c != 1 {
+			t.Errorf("%q: RowCount() = %d, want 1", query, c)
+			continue
+		}
+		if d.ID(0) != id {
+			t.Errorf("%q: d.ID(0) = %s, want %s", query, d.ID(0), id)
+		}
+	}
+}
+
+func TestHighlighted(t *testing.T) { tRunner.Run(func() { testHighlighted(t) }) }
+func testHighlighted(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "a", Description: "http <server>"})
+	if got, want := d.Highlighted(0), "http &lt;server&gt;"; got != want {
+		t.Errorf("d.Highlighted(0) = %q, want %q", got, want)
+	}
+	d.input.SetText("hs")
+	if got, want := d.Highlighted(0), "<b>h</b>ttp &lt;<b>s</b>erver&gt;"; got != want {
+		t.Errorf("d.Highlighted(0) = %q, want %q", got, want)
+	}
+	if d.Highlighted(10) != "" {
+		t.Errorf("d.Highlighted(10) = %q, want empty", d.Highlighted(10))
+	}
+}
+
+func TestQualifiers(t *testing.T) { tRunner.Run(func() { testQualifiers(t) }) }
+func testQualifiers(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{
+		ID:          "go",
+		Description: "http server #web",
+		Public:      true,
+		Files:       map[string]gist.File{"main.go": {Size: 20 << 10}},
+	})
+	d.Add(gist.Gist{
+		ID:          "sql",
+		Description: "user queries",
+		Files:       map[string]gist.File{"users.sql": {Content: "SELECT name FROM users"}},
+	})
+	d.SetStarred([]string{"sql"})
+
+	tcs := []struct {
+		query string
+		want  []string
+	}{
+		{"lang:go", []string{"go"}},
+		{"-lang:go", []string{"sql"}},
+		{"file:*.sql", []string{"sql"}},
+		{"public:false", []string{"sql"}},
+		{"starred:true", []string{"sql"}},
+		{"tag:web", []string{"go"}},
+		{"size:>10k", []string{"go"}},
+		{`"select name"`, []string{"sql"}},
+		{"-user", []string{"go"}},
+		{"server public:true", []string{"go"}},
+		{"server public:false", nil},
+	}
+	for _, tc := range tcs {
+		d.input.SetText(tc.query)
+		if d.queryError.IsVisible() {
+			t.Errorf("%q: unexpected error: %s", tc.query, d.queryError.Text())
+		}
+		if c := d.Model().RowCount(core.NewQModelIndex()); c != len(tc.want) {
+			t.Errorf("%q: RowCount() = %d, want %d", tc.query, c, len(tc.want))
+			continue
+		}
+		for row, id := range tc.want {
+			if d.ID(row) != id {
+				t.Errorf("%q: d.ID(%d) = %s, want %s", tc.query, row, d.ID(row), id)
+			}
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) { tRunner.Run(func() { testQueryError(t) }) }
+func testQueryError(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "a", Description: "alpha"})
+	d.Add(gist.Gist{ID: "b", Description: "beta"})
+	d.Show()
+	defer d.Hide()
+
+	d.input.SetText("alp")
+	d.input.SetText("alp public:maybe")
+	if !d.queryError.IsVisible() {
+		t.Error("the error is not shown")
+	}
+	if !strings.Contains(d.queryError.Text(), "true or false") {
+		t.Errorf("error = %q, want the error of the public qualifier", d.queryError.Text())
+	}
+	if c := d.Model().RowCount(core.NewQModelIndex()); c != 1 {
+		t.Errorf("RowCount() = %d, want 1: results were changed", c)
+	}
+
+	d.input.SetText("alp")
+	if d.queryError.IsVisible() {
+		t.Error("the error is still shown")
+	}
+}
+
+func TestMatchFile(t *testing.T) { tRunner.Run(func() { testMatchFile(t) }) }
+func testMatchFile(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "a", Description: "notes", Files: map[string]gist.File{
+		"deploy.sh":  {Content: "kubectl apply"},
+		"readme.md":  {Content: "how to release"},
+		"secret.enc": {},
+	}})
+	var got []string
+	d.ConnectOpenGist(func(id string) { got = append(got, "gist "+id) })
+	d.ConnectOpenFile(func(id, name string) { got = append(got, "file "+id+" "+name) })
+
+	tcs := []struct {
+		query string
+		want  string
+	}{
+		{"deploy", "deploy.sh"},
+		{"release", "readme.md"},
+		{"secret", "secret"},
+		{"notes", ""},
+		{"", ""},
+	}
+	for _, tc := range tcs {
+		d.input.SetText(tc.query)
+		if d.Model().RowCount(core.NewQModelIndex()) != 1 {
+			t.Errorf("%q: the gist is not shown", tc.query)
+			continue
+		}
+		if got := d.File(0); got != tc.want {
+			t.Errorf("%q: File(0) = %q, want %q", tc.query, got, tc.want)
+		}
+		if tc.want != "" && !strings.Contains(d.Highlighted(0), tc.want) {
+			t.Errorf("%q: Highlighted(0) = %q, want the file in it", tc.query, d.Highlighted(0))
+		}
+	}
+
+	d.input.SetText("deploy")
+	d.results.DoubleClicked(d.Model().Index(0, 0, core.NewQModelIndex()))
+	d.input.SetText("notes")
+	d.results.DoubleClicked(d.Model().Index(0, 0, core.NewQModelIndex()))
+	want := []string{"file a deploy.sh", "gist a"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// maxPreviewLen is the number of bytes of each file shown in the preview.
+const maxPreviewLen = 10 << 10
+
+// resultActions are the actions on the selected result, which can be used
+// without opening the gist.
+type resultActions struct {
+	copyContent   *widgets.QAction
+	copyURL       *widgets.QAction
+	openInBrowser *widgets.QAction
+	star          *widgets.QAction
+	delete        *widgets.QAction
+}
+
+func (d *Dialog) setupActions() {
+	newAction := func(text, shortcut string, fn func(id string)) *widgets.QAction {
+		a := widgets.NewQAction2(text, d)
+		a.SetShortcut(gui.NewQKeySequence2(shortcut, gui.QKeySequence__NativeText))
+		a.SetShortcutContext(core.Qt__WidgetWithChildrenShortcut)
+		a.ConnectTriggered(func(bool) {
+			if id := d.CurrentID(); id != "" {
+				fn(id)
+			}
+		})
+		d.AddAction(a)
+		d.results.AddAction(a)
+		return a
+	}
+	d.actions = &resultActions{
+		copyContent:   newAction("Copy Content", "Alt+C", d.CopyContent),
+		copyURL:       newAction("Copy URL", "Alt+U", d.CopyURL),
+		openInBrowser: newAction("Open in Browser", "Alt+B", d.OpenInBrowser),
+		star: newAction("Star", "Alt+S", func(id string) {
+			d.StarGist(id, !d.isStarred(id))
+		}),
+		delete: newAction("Delete", "Shift+Del", d.DeleteGist),
+	}
+	d.results.SetContextMenuPolicy(core.Qt__ActionsContextMenu)
+	d.results.ConnectDoubleClicked(d.open)
+
+	hint := "Enter: open, Alt+C: copy content, Alt+U: copy URL, Alt+B: browser, Alt+S: star, Shift+Del: delete"
+	d.hints = widgets.NewQLabel2(hint, d, 0)
+	d.hints.SetObjectName("Hints")
+	d.hints.SetWordWrap(true)
+}
+
+// CurrentID returns the ID of the selected gist.
+func (d *Dialog) CurrentID() string {
+	return d.results.CurrentIndex().Data(gistID).ToString()
+}
+
+// MarkStarred records the star of the gist identified by id.
+func (d *Dialog) MarkStarred(id string, starred bool) {
+	d.starredMu.Lock()
+	if starred {
+		d.starred[id] = true
+	} else {
+		delete(d.starred, id)
+	}
+	d.starredMu.Unlock()
+	if id == d.CurrentID() {
+		d.updatePreview()
+	}
+}
+
+// updatePreview shows the files of the selected gist. The contents are only
+// available for the gists that are indexed.
+func (d *Dialog) updatePreview() {
+	id := d.CurrentID()
+	star := "Star"
+	if d.isStarred(id) {
+		star = "Unstar"
+	}
+	d.actions.star.SetText(star)
+	if id == "" {
+		d.preview.Clear()
+		return
+	}
+	d.preview.SetHtml(d.previewHTML(id))
+}
+
+func (d *Dialog) previewHTML(id string) string {
+	var item *listItem
+	for _, p := range d.model.gists {
+		if p.GistID == id {
+			item = p
+			break
+		}
+	}
+	if item == nil {
+		return ""
+	}
+	words := search.Tokens(d.query)
+	contents := d.index.Files(id)
+	names := append([]string(nil), item.meta.Files...)
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<h3>%s</h3>", search.HighlightWords(item.Description, words, "b"))
+	for _, name := range names {
+		fmt.Fprintf(buf, "<p><b>%s</b></p>", html.EscapeString(name))
+		content, ok := contents[name]
+		switch {
+		case strings.HasSuffix(name, gist.EncryptedExt):
+			buf.WriteString("<p><i>Encrypted</i></p>")
+			continue
+		case !ok:
+			buf.WriteString("<p><i>Open the gist to see its contents</i></p>")
+			continue
+		}
+		truncated := len(content) > maxPreviewLen
+		if truncated {
+			// not cutting a character in half.
+			n := maxPreviewLen
+			for n > 0 && !utf8.RuneStart(content[n]) {
+				n--
+			}
+			content = content[:n]
+		}
+		fmt.Fprintf(buf, "<pre>%s</pre>", search.HighlightWords(content, words, "b"))
+		if truncated {
+			buf.WriteString("<p><i>…</i></p>")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package searchbox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestPreview(t *testing.T) { tRunner.Run(func() { testPreview(t) }) }
+func testPreview(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{
+		ID:          "first",
+		Description: "Nginx config",
+		Files: map[string]gist.File{
+			"nginx.conf":                  {Content: "proxy_pass http://upstream;"},
+			"notes.md":                    {},
+			"key.txt" + gist.EncryptedExt: {Content: "c2VjcmV0"},
+		},
+	})
+	d.Add(gist.Gist{
+		ID:          "second",
+		Description: "Other",
+		Files:       map[string]gist.File{"a.go": {Content: "package a"}},
+	})
+
+	d.input.SetText("upstream")
+	if d.CurrentID() != "first" {
+		t.Fatalf("d.CurrentID() = %s, want first", d.CurrentID())
+	}
+	html := d.previewHTML("first")
+	for _, want := range []string{
+		"nginx.conf",
+		"http://<b>upstream</b>;",
+		"notes.md",
+		"Open the gist to see its contents",
+		"Encrypted",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("preview = %q, want it to contain %q", html, want)
+		}
+	}
+	if strings.Contains(html, "c2VjcmV0") {
+		t.Error("the encrypted content is shown")
+	}
+	if !strings.Contains(d.preview.ToPlainText(), "proxy_pass") {
+		t.Errorf("preview = %q, want the selected gist", d.preview.ToPlainText())
+	}
+
+	d.input.SetText("other")
+	if !strings.Contains(d.preview.ToPlainText(), "package a") {
+		t.Errorf("preview = %q, want the selected gist", d.preview.ToPlainText())
+	}
+	d.Remove("second")
+	d.input.SetText("other")
+	if d.preview.ToPlainText() != "" {
+		t.Errorf("preview = %q, want empty", d.preview.ToPlainText())
+	}
+}
+
+func TestPreviewTruncate(t *testing.T) { tRunner.Run(func() { testPreviewTruncate(t) }) }
+func testPreviewTruncate(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	content := strings.Repeat("é", maxPreviewLen)
+	d.Add(gist.Gist{ID: "big", Files: map[string]gist.File{"big.txt": {Content: content}}})
+	html := d.previewHTML("big")
+	if !strings.Contains(html, "…") {
+		t.Error("the content was not truncated")
+	}
+	if strings.ContainsRune(html, '�') || len(html) > maxPreviewLen+200 {
+		t.Errorf("the content was not truncated properly, len = %d", len(html))
+	}
+}
+
+func TestResultActions(t *testing.T) { tRunner.Run(func() { testResultActions(t) }) }
+func testResultActions(t *testing.T) {
+	var got []string
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "Jk2Wq", Description: "desc"})
+	d.ConnectCopyContent(func(id string) { got = append(got, "content "+id) })
+	d.ConnectCopyURL(func(id string) { got = append(got, "url "+id) })
+	d.ConnectOpenInBrowser(func(id string) { got = append(got, "browser "+id) })
+	d.ConnectStarGist(func(id string, star bool) {
+		if star {
+			got = append(got, "star "+id)
+			return
+		}
+		got = append(got, "unstar "+id)
+	})
+	d.ConnectDeleteGist(func(id string) { got = append(got, "delete "+id) })
+	d.ConnectOpenGist(func(id string) { got = append(got, "open "+id) })
+	d.input.SetText("")
+
+	d.actions.copyContent.Trigger()
+	d.actions.copyURL.Trigger()
+	d.actions.openInBrowser.Trigger()
+	d.actions.star.Trigger()
+	d.MarkStarred("Jk2Wq", true)
+	if d.actions.star.Text() != "Unstar" {
+		t.Errorf("star.Text() = %s, want Unstar", d.actions.star.Text())
+	}
+	d.actions.star.Trigger()
+	d.actions.delete.Trigger()
+	d.results.DoubleClicked(d.results.CurrentIndex())
+
+	want := []string{
+		"content Jk2Wq",
+		"url Jk2Wq",
+		"browser Jk2Wq",
+		"star Jk2Wq",
+		"unstar Jk2Wq",
+		"delete Jk2Wq",
+		"open Jk2Wq",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = nil
+	d.Clear()
+	d.actions.copyContent.Trigger()
+	if len(got) != 0 {
+		t.Errorf("got %v, want no actions without a selection", got)
+	}
+	if d.results.ContextMenuPolicy() != core.Qt__ActionsContextMenu {
+		t.Error("results don't show the actions in the context menu")
+	}
+}
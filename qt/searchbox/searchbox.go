@@ -5,9 +5,11 @@
 package searchbox
 
 import (
-	"strings"
+	"sync"
 
 	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/command"
+	"github.com/arsham/gistflow/search"
 	"github.com/therecipe/qt/core"
 	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
@@ -17,22 +19,48 @@ const (
 	description = int(core.Qt__DisplayRole)
 	gistID      = int(core.Qt__UserRole) + 1<<iota
 	gistURL
-	dialogWidth = 500
+	dialogWidth = 900
+	lockStr     = "\U0001F512 " // shown before encrypted gists.
 )
 
 // Dialog is shown when user hits Ctrl+P.
 type Dialog struct {
 	widgets.QDialog
 
-	_ func()            `constructor:"init"`
-	_ func(*core.QRect) `slot:"view"`
-	_ func(gist.Gist)   `slot:"add"`
-	_ func(string)      `signal:"openGist"`
+	_ func()               `constructor:"init"`
+	_ func(*core.QRect)    `slot:"view"`
+	_ func(*core.QRect)    `slot:"viewCommands"`
+	_ func(gist.Gist)      `slot:"add"`
+	_ func(string)         `signal:"openGist"`
+	_ func(string, string) `signal:"openFile"`
+	_ func(string)         `signal:"copyContent"`
+	_ func(string)         `signal:"copyURL"`
+	_ func(string)         `signal:"openInBrowser"`
+	_ func(string, bool)   `signal:"starGist"`
+	_ func(string)         `signal:"deleteGist"`
 
-	input   *widgets.QLineEdit
-	results *widgets.QListView
-	model   *listModel
-	proxy   *core.QSortFilterProxyModel
+	input      *widgets.QLineEdit
+	queryError *widgets.QLabel // shows the syntax errors of the query.
+	results    *widgets.QListView
+	preview    *widgets.QTextBrowser
+	hints      *widgets.QLabel
+	actions    *resultActions
+	model      *listModel
+	proxy      *core.QSortFilterProxyModel
+	index      *search.Index
+
+	commands      *command.Registry
+	commandList   *widgets.QTreeWidget
+	shownCommands []command.Command
+
+	parsed  *search.Query
+	query   string              // free text of the query, used for ranking.
+	scores  map[string]float64  // gist ID to the score in the index.
+	files   map[string][]string // gist ID to the files matched in the index.
+	matches map[*listItem]match
+
+	starredMu sync.RWMutex // SetStarred is called while listing the gists.
+	starred   map[string]bool
 }
 
 func init() {
@@ -44,8 +72,15 @@ func (d *Dialog) init() {
 	d.SetModal(true)
 	d.input = widgets.NewQLineEdit(d)
 	d.input.SetObjectName("Input")
+	d.input.SetPlaceholderText("Search, narrow with lang:go file:*.sql tag:k8s updated:>2018-01-01, or type > for commands")
+	d.queryError = widgets.NewQLabel(d, 0)
+	d.queryError.SetObjectName("QueryError")
+	d.queryError.SetStyleSheet("color: red;")
+	d.queryError.Hide()
 	d.results = widgets.NewQListView(d)
 	d.results.SetObjectName("Results")
+	d.preview = widgets.NewQTextBrowser(d)
+	d.preview.SetObjectName("Preview")
 	d.ConnectKeyPressEvent(func(event *gui.QKeyEvent) {
 		if event.Key() == int(core.Qt__Key_Escape) {
 			d.Hide()
@@ -59,20 +94,34 @@ func (d *Dialog) init() {
 	vLayout.SetContentsMargins(0, 0, 0, 0)
 	vLayout.SetSpacing(0)
 	vLayout.AddWidget(d.input, 0, 0)
-	vLayout.AddWidget(d.results, 0, 0)
+	vLayout.AddWidget(d.queryError, 0, 0)
+	hLayout := widgets.NewQHBoxLayout()
+	hLayout.SetSpacing(0)
+	hLayout.AddWidget(d.results, 2, 0)
+	hLayout.AddWidget(d.preview, 3, 0)
+	d.setupCommands()
+	hLayout.AddWidget(d.commandList, 5, 0)
+	vLayout.AddLayout(hLayout, 0)
 	d.Hide()
 	d.ConnectView(d.view)
+	d.ConnectViewCommands(d.viewCommands)
 	d.model = NewListModel(d)
+	d.index = search.NewIndex()
+	d.matches = make(map[*listItem]match)
+	d.starred = make(map[string]bool)
 	d.proxy = core.NewQSortFilterProxyModel(d)
 	d.proxy.SetSourceModel(d.model)
+	d.proxy.ConnectFilterAcceptsRow(d.filterAcceptsRow)
+	d.proxy.ConnectLessThan(d.lessThan)
 	d.results.SetModel(d.proxy)
-
-	d.input.ConnectTextChanged(func(text string) {
-		text = strings.Join(strings.Split(text, ""), "*")
-		d.proxy.SetFilterWildcard(text)
-		d.proxy.SetFilterCaseSensitivity(core.Qt__CaseInsensitive)
-		d.selectFirstRow()
+	d.results.SetItemDelegate(d.newDelegate())
+	d.results.SelectionModel().ConnectCurrentChanged(func(*core.QModelIndex, *core.QModelIndex) {
+		d.updatePreview()
 	})
+	d.setupActions()
+	vLayout.AddWidget(d.hints, 0, 0)
+
+	d.input.ConnectTextChanged(d.filter)
 
 	d.ConnectKeyPressEvent(d.handleArrowKeys)
 	d.results.ConnectKeyPressEvent(d.handleResultsKeyPress)
@@ -82,11 +131,15 @@ func (d *Dialog) selectFirstRow() {
 	index := d.Model().Index(0, 0, core.NewQModelIndex())
 	d.results.SelectionModel().Select(index, core.QItemSelectionModel__ClearAndSelect)
 	d.results.SetCurrentIndex(index)
+	d.updatePreview()
 }
 
 func (d *Dialog) view(r *core.QRect) {
 	c := core.NewQRect4(r.Width()/2-dialogWidth/2, 0, dialogWidth, 500)
 	d.SetGeometry(c)
+	if d.commandMode() {
+		d.input.Clear()
+	}
 	d.Show()
 	d.input.SetFocus2()
 	d.selectFirstRow()
@@ -97,9 +150,16 @@ func (d *Dialog) Model() *core.QAbstractItemModel {
 	return d.results.Model()
 }
 
+// add adds the gist to the results, or updates it if it is already there.
 func (d *Dialog) add(r gist.Gist) {
-	item := NewListItem(d)
+	item := d.find(r.ID)
+	exists := item != nil
+	if !exists {
+		item = NewListItem(d)
+	}
 	item.GistID = r.ID
+	item.UpdatedAt = r.UpdatedAt
+	item.meta = search.NewMeta(r)
 	description := r.Description
 	if description == "" {
 		for name := range r.Files {
@@ -107,10 +167,32 @@ func (d *Dialog) add(r gist.Gist) {
 			break
 		}
 	}
+	if r.Encrypted() {
+		description = lockStr + description
+	}
 	item.Description = description
+	d.index.Add(r)
+	if exists {
+		delete(d.matches, item)
+		d.model.updated(item)
+		return
+	}
 	d.model.AddGist(item)
 }
 
+// find returns the item of the gist identified by id, or nil if there is none.
+func (d *Dialog) find(id string) *listItem {
+	if id == "" {
+		return nil
+	}
+	for _, p := range d.model.gists {
+		if p.GistID == id {
+			return p
+		}
+	}
+	return nil
+}
+
 // ID returns the ID of gist at row.
 func (d *Dialog) ID(row int) string {
 	index := d.results.Model().Index(row, 0, core.NewQModelIndex())
@@ -124,12 +206,20 @@ func (d *Dialog) Description(row int) string {
 }
 
 func (d *Dialog) handleArrowKeys(event *gui.QKeyEvent) {
+	if d.commandMode() {
+		switch core.Qt__Key(event.Key()) {
+		case core.Qt__Key_Up, core.Qt__Key_Down:
+			d.commandList.SetFocus2()
+		case core.Qt__Key_Enter, core.Qt__Key_Return:
+			d.runCommand()
+		}
+		return
+	}
 	switch core.Qt__Key(event.Key()) {
 	case core.Qt__Key_Up, core.Qt__Key_Down:
 		d.results.SetFocus2()
 	case core.Qt__Key_Enter, core.Qt__Key_Return:
-		index := d.results.CurrentIndex()
-		d.OpenGist(index.Data(gistID).ToString())
+		d.open(d.results.CurrentIndex())
 	}
 }
 
@@ -138,14 +228,17 @@ func (d *Dialog) handleResultsKeyPress(event *gui.QKeyEvent) {
 	case core.Qt__Key_Up, core.Qt__Key_Down:
 		d.results.KeyPressEventDefault(event)
 	case core.Qt__Key_Enter, core.Qt__Key_Return:
-		index := d.results.CurrentIndex()
-		d.OpenGist(index.Data(gistID).ToString())
+		d.open(d.results.CurrentIndex())
 	default:
 		d.input.SetText(d.input.Text() + event.Text())
 		d.input.SetFocus2()
 	}
 }
 
+// Index returns the full-text index of the gists. Gists added to the dialog are
+// indexed with the contents they come with.
+func (d *Dialog) Index() *search.Index { return d.index }
+
 // Results returns the result list view.
 func (d *Dialog) Results() *widgets.QListView { return d.results }
 
@@ -162,9 +255,14 @@ func (d *Dialog) HasID(gistID string) bool {
 // Remove removes the gist identified by gistID from the model.
 func (d *Dialog) Remove(gistID string) {
 	d.model.remove(gistID)
+	d.index.Remove(gistID)
+	d.updatePreview()
 }
 
 // Clear removes all data from model.
 func (d *Dialog) Clear() {
 	d.model.clear()
+	d.index = search.NewIndex()
+	d.matches = make(map[*listItem]match)
+	d.preview.Clear()
 }
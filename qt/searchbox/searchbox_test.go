@@ -143,7 +143,7 @@ func testFiltering(t *testing.T) {
 		return
 	}
 
-	d.input.SetText("A*A*A")
+	d.input.SetText("AAA")
 
 	if d.Model().RowCount(core.NewQModelIndex()) != 1 {
 		t.Errorf("RowCount() = %d, want 1", d.Model().RowCount(core.NewQModelIndex()))
@@ -152,7 +152,7 @@ func testFiltering(t *testing.T) {
 		t.Errorf("d.Description(0) = %s, want %s", d.Description(0), description1)
 	}
 
-	d.input.SetText("B*B*B")
+	d.input.SetText("BBB")
 
 	if d.Model().RowCount(core.NewQModelIndex()) != 1 {
 		t.Errorf("RowCount() = %d, want 1", d.Model().RowCount(core.NewQModelIndex()))
@@ -162,6 +162,52 @@ func testFiltering(t *testing.T) {
 	}
 }
 
+func TestContentSearch(t *testing.T) { tRunner.Run(func() { testContentSearch(t) }) }
+func testContentSearch(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{
+		ID:          "first",
+		Description: "kT4bMx",
+		Files:       map[string]gist.File{"a.go": {Content: "func reverse(s string) string"}},
+	})
+	d.Add(gist.Gist{
+		ID:          "second",
+		Description: "reverse proxy",
+		Files:       map[string]gist.File{"nginx.conf": {}},
+	})
+	d.Index().Add(gist.Gist{
+		ID:    "second",
+		Files: map[string]gist.File{"nginx.conf": {Content: "proxy_pass upstream;"}},
+	})
+
+	d.input.SetText("upstream")
+	if d.Model().RowCount(core.NewQModelIndex()) != 1 {
+		t.Fatalf("RowCount() = %d, want 1", d.Model().RowCount(core.NewQModelIndex()))
+	}
+	if d.ID(0) != "second" {
+		t.Errorf("d.ID(0) = %s, want second", d.ID(0))
+	}
+
+	// description matches rank higher than content matches.
+	d.input.SetText("reverse")
+	if d.Model().RowCount(core.NewQModelIndex()) != 2 {
+		t.Fatalf("RowCount() = %d, want 2", d.Model().RowCount(core.NewQModelIndex()))
+	}
+	if d.ID(0) != "second" {
+		t.Errorf("d.ID(0) = %s, want second", d.ID(0))
+	}
+
+	d.Remove("second")
+	d.input.SetText("upstream")
+	if d.Model().RowCount(core.NewQModelIndex()) != 0 {
+		t.Errorf("RowCount() = %d, want 0", d.Model().RowCount(core.NewQModelIndex()))
+	}
+	d.input.SetText("")
+	if d.Model().RowCount(core.NewQModelIndex()) != 1 {
+		t.Errorf("RowCount() = %d, want 1", d.Model().RowCount(core.NewQModelIndex()))
+	}
+}
+
 func TestKeepTopMostIndexOnResults(t *testing.T) {
 	tRunner.Run(func() { testKeepTopMostIndexOnResults(t) })
 }
@@ -348,3 +394,20 @@ func testTypeInResults(t *testing.T) {
 		t.Error("Input didn't get focused")
 	}
 }
+
+func TestUpdateGist(t *testing.T) { tRunner.Run(func() { testUpdateGist(t) }) }
+func testUpdateGist(t *testing.T) {
+	d := NewDialog(widgets.NewQWidget(nil, 0), 0)
+	d.Add(gist.Gist{ID: "mR7yL", Description: "old"})
+	d.Add(gist.Gist{ID: "mR7yL", Description: "new #go"})
+	if n := d.Model().RowCount(core.NewQModelIndex()); n != 1 {
+		t.Fatalf("RowCount() = %d, want 1", n)
+	}
+	if d.Description(0) != "new #go" {
+		t.Errorf("d.Description(0) = %s, want new #go", d.Description(0))
+	}
+	d.input.SetText("tag:go")
+	if d.ID(0) != "mR7yL" {
+		t.Errorf("d.ID(0) = %q, want the updated gist to match tag:go", d.ID(0))
+	}
+}
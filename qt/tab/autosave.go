@@ -0,0 +1,37 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"errors"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/scan"
+)
+
+// ErrSecrets is returned when the gist is not auto-saved because it might
+// contain secrets. The user should check them and save the gist by hand.
+var ErrSecrets = errors.New("the gist might contain secrets")
+
+// AutoSave returns true if the changes of the gist should be saved when the
+// user stops typing. New gists are never auto-saved.
+func (t *Tab) AutoSave() bool {
+	return t.autoSaveBox.IsEnabled() && t.autoSaveBox.IsChecked()
+}
+
+// SetAutoSave sets the state of the auto-save checkbox without emitting the
+// autoSaveToggled signal.
+func (t *Tab) SetAutoSave(on bool) { t.autoSaveBox.SetChecked(on) }
+
+// AutoSaveGist returns the gist to be auto-saved, without showing the review.
+// The suspicious lines are highlighted, and ErrSecrets is returned when saving
+// by hand would ask about them, as that would interrupt the typing. The window
+// calls Saved after the gist is saved.
+func (t *Tab) AutoSaveGist() (*gist.Gist, error) {
+	if scan.Decide(t.findSecrets(), t.gist.Public) != scan.Allow {
+		return nil, ErrSecrets
+	}
+	return t.editedGist(), nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"sort"
+
+	"github.com/arsham/gistflow/diff"
+)
+
+// changeKind is what happened to a part of the gist since it was loaded or
+// saved.
+type changeKind int
+
+const (
+	modified changeKind = iota
+	added
+	deleted
+	renamed
+	described // the description changed.
+	encryption
+)
+
+// change is a difference between the saved gist and the tab. The texts are
+// the contents of the file, the description, or whether the gist is encrypted.
+type change struct {
+	kind             changeKind
+	oldName, newName string
+	oldText, newText string
+	file             *File // nil for deleted files and the gist level changes.
+}
+
+// label returns the text of the change in the list of changes.
+func (c *change) label() string {
+	name := c.newName
+	if name == "" {
+		name = "(unnamed)"
+	}
+	switch c.kind {
+	case added:
+		return "Added: " + name
+	case deleted:
+		return "Deleted: " + c.oldName
+	case renamed:
+		return "Renamed: " + c.oldName + " → " + name
+	case described:
+		return "Description"
+	case encryption:
+		return "Encryption"
+	}
+	return "Modified: " + name
+}
+
+// snapshot is the gist as it was loaded or last saved.
+type snapshot struct {
+	description string
+	encrypted   bool
+	files       map[string]string // contents by the names of the files.
+}
+
+// encryptionText returns the text of the encryption state in the diffs.
+func encryptionText(encrypted bool) string {
+	if encrypted {
+		return "encrypted\n"
+	}
+	return "not encrypted\n"
+}
+
+// takeSnapshot records the tab as the saved gist.
+func (t *Tab) takeSnapshot() {
+	s := &snapshot{
+		description: t.description.Text(),
+		encrypted:   t.Encrypted(),
+		files:       make(map[string]string, len(t.files)),
+	}
+	for _, f := range t.files {
+		s.files[f.FileName()] = f.Content().ToPlainText()
+		f.savedName = f.FileName()
+	}
+	t.saved = s
+	t.updateDirty()
+}
+
+// Dirty returns true if the tab has changes that are not saved.
+func (t *Tab) Dirty() bool { return t.dirty }
+
+// updateDirty marks the files that are changed since the gist was saved, and
+// emits dirtyChanged if the tab became dirty or clean. New gists are dirty
+// when anything is written in them. It emits changed every time.
+func (t *Tab) updateDirty() {
+	changed := make(map[*File]bool, len(t.files))
+	var dirty bool
+	if t.saved == nil {
+		dirty = t.description.Text() != ""
+		for _, f := range t.files {
+			changed[f] = f.FileName() != "" || f.Content().ToPlainText() != ""
+		}
+	} else {
+		for _, c := range t.changes() {
+			dirty = true
+			if c.file != nil {
+				changed[c.file] = true
+			}
+		}
+	}
+	for _, f := range t.files {
+		f.setDirty(changed[f])
+		dirty = dirty || changed[f]
+	}
+	if dirty != t.dirty {
+		t.dirty = dirty
+		t.DirtyChanged(dirty)
+	}
+	t.Changed()
+}
+
+// changes returns the differences between the saved gist and the tab. The
+// gist level changes come first, then the files in the order of the tab, and
+// then the deleted files by their names. It returns nil if the gist has never
+// been saved.
+func (t *Tab) changes() []*change {
+	if t.saved == nil {
+		return nil
+	}
+	entries := make([]entry, len(t.files))
+	for i, f := range t.files {
+		entries[i] = entry{
+			savedName: f.savedName,
+			name:      f.FileName(),
+			text:      f.Content().ToPlainText(),
+			file:      f,
+		}
+	}
+	return compare(t.saved, t.description.Text(), t.Encrypted(), entries)
+}
+
+// entry is a file as it is in the tab or in a draft.
+type entry struct {
+	savedName, name, text string
+	file                  *File // nil for the files of drafts.
+}
+
+// compare returns the differences between the saved gist and the entries.
+func compare(saved *snapshot, description string, encrypted bool, entries []entry) []*change {
+	var changes []*change
+	if description != saved.description {
+		changes = append(changes, &change{kind: described, oldText: saved.description, newText: description})
+	}
+	if encrypted != saved.encrypted {
+		changes = append(changes, &change{kind: encryption, oldText: encryptionText(saved.encrypted), newText: encryptionText(encrypted)})
+	}
+
+	kept := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		c := &change{
+			kind:    modified,
+			oldName: e.savedName,
+			newName: e.name,
+			newText: e.text,
+			file:    e.file,
+		}
+		old, ok := saved.files[e.savedName]
+		if ok {
+			kept[e.savedName] = true
+		}
+		switch {
+		case !ok:
+			c.kind = added
+		case c.newName != c.oldName:
+			c.kind, c.oldText = renamed, old
+		case c.newText != old:
+			c.oldText = old
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+
+	var gone []string
+	for name := range saved.files {
+		if !kept[name] {
+			gone = append(gone, name)
+		}
+	}
+	sort.Strings(gone)
+	for _, name := range gone {
+		changes = append(changes, &change{kind: deleted, oldName: name, oldText: saved.files[name]})
+	}
+	return changes
+}
+
+// revert undoes the change in the tab.
+func (t *Tab) revert(c *change) {
+	switch c.kind {
+	case described:
+		t.description.SetText(c.oldText)
+	case encryption:
+		t.encryptBox.SetChecked(t.saved.encrypted)
+	case added:
+		t.discardFile(c.file)
+	case deleted:
+		t.showFile(c.oldName, c.oldText)
+	default:
+		if c.newName != c.oldName {
+			c.file.SetFileName(c.oldName)
+		}
+		if c.newText != c.oldText {
+			c.file.SetContents(c.oldText)
+		}
+	}
+	t.updateDirty()
+}
+
+// revertHunk undoes the part of the change in the hunk. The hunk should be one
+// of the hunks of the texts of the change.
+func (t *Tab) revertHunk(c *change, h diff.Hunk) {
+	text := diff.Revert(c.newText, h)
+	switch {
+	case c.kind == described:
+		t.description.SetText(text)
+	case c.file != nil:
+		c.file.SetContents(text)
+	default:
+		t.revert(c)
+	}
+}
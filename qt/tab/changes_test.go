@@ -0,0 +1,238 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/diff"
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func showChangesGist() *Tab {
+	tab := NewTab(widgets.NewQWidget(nil, 0))
+	tab.ShowGist(widgets.NewQTabWidget(nil), &gist.Gist{
+		ID:          "c1",
+		Description: "notes",
+		Files: map[string]gist.File{
+			"a.txt": {Content: "one\ntwo\nthree\n"},
+			"b.txt": {Content: "bee\n"},
+		},
+	})
+	return tab
+}
+
+func fileNamed(tab *Tab, name string) *File {
+	for _, f := range tab.files {
+		if f.FileName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func changeLabels(changes []*change) []string {
+	var labels []string
+	for _, c := range changes {
+		labels = append(labels, c.label())
+	}
+	return labels
+}
+
+func TestChanges(t *testing.T) { tRunner.Run(func() { testChanges(t) }) }
+func testChanges(t *testing.T) {
+	tab := NewTab(widgets.NewQWidget(nil, 0))
+	tab.NewGist(widgets.NewQTabWidget(nil), "new")
+	if c := tab.changes(); c != nil {
+		t.Errorf("changes() = %v for a new gist, want nil", changeLabels(c))
+	}
+
+	tab = showChangesGist()
+	if c := tab.changes(); len(c) != 0 {
+		t.Fatalf("changes() = %v, want none", changeLabels(c))
+	}
+
+	tab.description.SetText("more notes")
+	fileNamed(tab, "a.txt").Content().SetPlainText("one\n2\nthree\n")
+	fileNamed(tab, "b.txt").SetFileName("c.txt")
+	added := tab.addFile()
+	added.SetFileName("d.txt")
+	tab.saved.files["gone.txt"] = "bye\n"
+
+	want := []string{
+		"Description",
+		"Modified: a.txt",
+		"Renamed: b.txt → c.txt",
+		"Added: d.txt",
+		"Deleted: gone.txt",
+	}
+	got := changeLabels(tab.changes())
+	// the files are shown in any order.
+	if len(got) != len(want) {
+		t.Fatalf("changes() = %v, want %v", got, want)
+	}
+	for _, w := range want {
+		var found bool
+		for _, g := range got {
+			found = found || g == w
+		}
+		if !found {
+			t.Errorf("%q not in changes() = %v", w, got)
+		}
+	}
+
+	tab.takeSnapshot()
+	if c := tab.changes(); len(c) != 0 {
+		t.Errorf("changes() = %v after the snapshot, want none", changeLabels(c))
+	}
+}
+
+func TestRevertChange(t *testing.T) { tRunner.Run(func() { testRevertChange(t) }) }
+func testRevertChange(t *testing.T) {
+	tab := showChangesGist()
+	tab.description.SetText("more notes")
+	a := fileNamed(tab, "a.txt")
+	a.Content().SetPlainText("1\ntwo\nthree\n")
+	a.SetFileName("z.txt")
+	tab.discardFile(fileNamed(tab, "b.txt"))
+	tab.addFile().SetFileName("d.txt")
+	tab.encryptBox.SetChecked(true)
+
+	for _, c := range tab.changes() {
+		tab.revert(c)
+	}
+	if c := tab.changes(); len(c) != 0 {
+		t.Errorf("changes() = %v after reverting them, want none", changeLabels(c))
+	}
+	if got := tab.description.Text(); got != "notes" {
+		t.Errorf("description = %q, want %q", got, "notes")
+	}
+	if got := a.Content().ToPlainText(); got != "one\ntwo\nthree\n" {
+		t.Errorf("content = %q, want the saved content", got)
+	}
+	if len(tab.files) != 2 || fileNamed(tab, "b.txt") == nil {
+		t.Errorf("files = %d, want the deleted file back", len(tab.files))
+	}
+	if tab.Encrypted() {
+		t.Error("the encryption is not reverted")
+	}
+}
+
+func TestRevertHunk(t *testing.T) { tRunner.Run(func() { testRevertHunk(t) }) }
+func testRevertHunk(t *testing.T) {
+	tab := showChangesGist()
+	a := fileNamed(tab, "a.txt")
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	tab.saved.files["a.txt"] = old
+	a.Content().SetPlainText("one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+
+	changes := tab.changes()
+	if len(changes) != 1 {
+		t.Fatalf("changes() = %v, want one", changeLabels(changes))
+	}
+	c := changes[0]
+	hunks := diff.Hunks(c.oldText, c.newText, 1)
+	if len(hunks) != 2 {
+		t.Fatalf("len(hunks) = %d, want 2", len(hunks))
+	}
+	tab.revertHunk(c, hunks[1])
+	want := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	if got := a.Content().ToPlainText(); got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+
+	// reverting is undoable in the editor.
+	a.Content().Undo()
+	if got := a.Content().ToPlainText(); got != c.newText {
+		t.Errorf("content = %q after undo, want %q", got, c.newText)
+	}
+
+	tab.description.SetText("more notes")
+	c = tab.changes()[0]
+	tab.revertHunk(c, diff.Hunks(c.oldText, c.newText, 3)[0])
+	if got := tab.description.Text(); got != "notes" {
+		t.Errorf("description = %q, want %q", got, "notes")
+	}
+}
+
+func TestDirty(t *testing.T) { tRunner.Run(func() { testDirty(t) }) }
+func testDirty(t *testing.T) {
+	tab := showChangesGist()
+	var emitted []bool
+	tab.ConnectDirtyChanged(func(dirty bool) { emitted = append(emitted, dirty) })
+	if tab.Dirty() {
+		t.Fatal("the tab is dirty after showing the gist")
+	}
+
+	a := fileNamed(tab, "a.txt")
+	a.Content().SetPlainText("changed")
+	a.Content().SetPlainText("changed again")
+	if !tab.Dirty() || !a.Dirty() || fileNamed(tab, "b.txt").Dirty() {
+		t.Errorf("Dirty() = %t, %t, %t, want only a.txt dirty", tab.Dirty(), a.Dirty(), fileNamed(tab, "b.txt").Dirty())
+	}
+	a.Content().SetPlainText("one\ntwo\nthree\n")
+	if tab.Dirty() || a.Dirty() {
+		t.Error("the tab is dirty after undoing the change")
+	}
+	if want := []bool{true, false}; !reflect.DeepEqual(emitted, want) {
+		t.Errorf("dirtyChanged = %v, want %v", emitted, want)
+	}
+
+	tab.SetEncrypted(true)
+	tab.SetDescription("retagged #x")
+	if tab.Dirty() {
+		t.Error("the stored state made the tab dirty")
+	}
+	tab.encryptBox.Click()
+	if !tab.Dirty() {
+		t.Error("changing the encryption didn't make the tab dirty")
+	}
+
+	tab = NewTab(widgets.NewQWidget(nil, 0))
+	tab.NewGist(widgets.NewQTabWidget(nil), "new")
+	if tab.Dirty() {
+		t.Error("an empty new gist is dirty")
+	}
+	tab.files[0].Content().SetPlainText("text")
+	if !tab.Dirty() {
+		t.Error("a new gist with text is not dirty")
+	}
+	tab.GistCreated(&gist.Gist{ID: "n1"})
+	if tab.Dirty() {
+		t.Error("the created gist is dirty")
+	}
+}
+
+func TestSaveFailed(t *testing.T) { tRunner.Run(func() { testSaveFailed(t) }) }
+func testSaveFailed(t *testing.T) {
+	tab := showChangesGist()
+	tab.review = func() bool { return true }
+	var updated int
+	tab.ConnectUpdateGist(func(*gist.Gist) { updated++ })
+	var dirtyChanges []bool
+	tab.ConnectDirtyChanged(func(dirty bool) { dirtyChanges = append(dirtyChanges, dirty) })
+
+	fileNamed(tab, "b.txt").Content().SetPlainText("wasp\n")
+	tab.saveButton.Click()
+	if updated != 1 {
+		t.Fatalf("updated = %d, want 1", updated)
+	}
+	if !tab.Dirty() || !tab.saveButton.IsEnabled() {
+		t.Errorf("Dirty() = %t, save enabled = %t, want the changes kept until the gist is saved", tab.Dirty(), tab.saveButton.IsEnabled())
+	}
+	if len(dirtyChanges) != 1 || !dirtyChanges[0] {
+		t.Errorf("dirtyChanged = %v, want [true]", dirtyChanges)
+	}
+
+	tab.Saved()
+	if tab.Dirty() || tab.saveButton.IsEnabled() {
+		t.Errorf("Dirty() = %t, save enabled = %t after Saved(), want clean", tab.Dirty(), tab.saveButton.IsEnabled())
+	}
+	if c := tab.changes(); len(c) != 0 {
+		t.Errorf("changes() = %v after Saved(), want none", changeLabels(c))
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+// Draft returns the tab as a draft to be kept under the key.
+func (t *Tab) Draft(key string) gist.Draft {
+	d := gist.Draft{
+		Key:         key,
+		Description: t.description.Text(),
+		Public:      t.publicCheckBox.IsChecked(),
+		Encrypted:   t.Encrypted(),
+		Files:       make([]gist.DraftFile, len(t.files)),
+		Updated:     time.Now(),
+	}
+	if t.gist != nil {
+		d.GistID = t.gist.ID
+	}
+	for i, f := range t.files {
+		d.Files[i] = gist.DraftFile{
+			Name:      f.FileName(),
+			SavedName: f.savedName,
+			Content:   f.Content().ToPlainText(),
+		}
+	}
+	return d
+}
+
+// RestoreDraft puts the draft in the tab. The files are matched by their names
+// in the saved gist, and the changes can be undone in their editors.
+func (t *Tab) RestoreDraft(d gist.Draft) {
+	t.description.SetText(d.Description)
+	if t.publicCheckBox.IsEnabled() {
+		t.publicCheckBox.SetChecked(d.Public)
+	}
+	t.encryptBox.SetChecked(d.Encrypted)
+	used := make(map[*File]bool, len(d.Files))
+	for _, df := range d.Files {
+		f := t.draftFile(df.SavedName, used)
+		used[f] = true
+		if f.FileName() != df.Name {
+			f.SetFileName(df.Name)
+		}
+		if f.Content().ToPlainText() != df.Content {
+			f.SetContents(df.Content)
+		}
+	}
+	t.saveButton.SetEnabled(true)
+	t.updateDirty()
+}
+
+// draftFile returns the file with the saved name that is not used yet. The new
+// files of drafts take the empty new files of the tab, or are added.
+func (t *Tab) draftFile(savedName string, used map[*File]bool) *File {
+	for _, f := range t.files {
+		if used[f] || f.savedName != savedName {
+			continue
+		}
+		if savedName != "" || (f.FileName() == "" && f.Content().ToPlainText() == "") {
+			return f
+		}
+	}
+	return t.newFile()
+}
+
+// draftChanges returns the differences between the gist and the draft. The
+// gist is nil for new gists, or if it can't be fetched.
+func draftChanges(d gist.Draft, g *gist.Gist) []*change {
+	saved := &snapshot{
+		encrypted: d.Encrypted, // the gist is already decrypted.
+		files:     make(map[string]string),
+	}
+	if g != nil {
+		saved.description = g.Description
+		for name, f := range g.Files {
+			saved.files[name] = f.Content
+		}
+	}
+	entries := make([]entry, len(d.Files))
+	for i, f := range d.Files {
+		entries[i] = entry{savedName: f.SavedName, name: f.Name, text: f.Content}
+	}
+	return compare(saved, d.Description, d.Encrypted, entries)
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestDraftRoundTrip(t *testing.T) { tRunner.Run(func() { testDraftRoundTrip(t) }) }
+func testDraftRoundTrip(t *testing.T) {
+	tab := showChangesGist()
+	tab.description.SetText("more notes")
+	fileNamed(tab, "a.txt").SetFileName("c.txt")
+	fileNamed(tab, "b.txt").Content().SetPlainText("buzz\n")
+	added := tab.newFile()
+	added.SetFileName("d.txt")
+	added.SetContents("new\n")
+
+	d := tab.Draft("c1")
+	if d.Key != "c1" || d.GistID != "c1" || d.Description != "more notes" {
+		t.Errorf("Draft() = %+v", d)
+	}
+	if len(d.Files) != 3 {
+		t.Fatalf("len(Files) = %d, want 3", len(d.Files))
+	}
+
+	restored := showChangesGist()
+	restored.RestoreDraft(d)
+	if !restored.Dirty() {
+		t.Error("the restored tab is not dirty")
+	}
+	if got := restored.description.Text(); got != "more notes" {
+		t.Errorf("description = %q, want %q", got, "more notes")
+	}
+	if f := fileNamed(restored, "c.txt"); f == nil || f.savedName != "a.txt" {
+		t.Error("the renamed file is not matched with its saved name")
+	}
+	if f := fileNamed(restored, "b.txt"); f == nil || f.Content().ToPlainText() != "buzz\n" {
+		t.Error("the modified content is not restored")
+	}
+	if f := fileNamed(restored, "d.txt"); f == nil || f.savedName != "" || f.Content().ToPlainText() != "new\n" {
+		t.Error("the new file is not restored")
+	}
+	if got, want := changeLabels(restored.changes()), changeLabels(tab.changes()); !sameLabels(got, want) {
+		t.Errorf("changes() = %v, want %v", got, want)
+	}
+
+	newTab := NewTab(widgets.NewQWidget(nil, 0))
+	newTab.NewGist(widgets.NewQTabWidget(nil), "new")
+	newTab.RestoreDraft(gist.Draft{
+		Key:   "new-1",
+		Files: []gist.DraftFile{{Name: "e.txt", Content: "eee"}},
+	})
+	if len(newTab.files) != 1 || newTab.files[0].FileName() != "e.txt" {
+		t.Errorf("the empty file of the new gist is not used for the draft")
+	}
+	if !newTab.Dirty() {
+		t.Error("the restored new gist is not dirty")
+	}
+}
+
+func sameLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDraftChanges(t *testing.T) { tRunner.Run(func() { testDraftChanges(t) }) }
+func testDraftChanges(t *testing.T) {
+	g := &gist.Gist{
+		ID:          "g1",
+		Description: "notes",
+		Files: map[string]gist.File{
+			"a.txt": {Content: "one\n"},
+			"b.txt": {Content: "bee\n"},
+		},
+	}
+	d := gist.Draft{
+		Key:         "g1",
+		GistID:      "g1",
+		Description: "notes",
+		Encrypted:   true,
+		Files: []gist.DraftFile{
+			{Name: "a.txt", SavedName: "a.txt", Content: "one\n"},
+			{Name: "b.txt", SavedName: "b.txt", Content: "bee\n"},
+		},
+	}
+	if c := draftChanges(d, g); len(c) != 0 {
+		t.Errorf("draftChanges() = %v, want none", changeLabels(c))
+	}
+
+	d.Description = "more notes"
+	d.Files = []gist.DraftFile{
+		{Name: "a.txt", SavedName: "a.txt", Content: "two\n"},
+		{Name: "c.txt", Content: "sea\n"},
+	}
+	want := []string{"Description", "Modified: a.txt", "Deleted: b.txt", "Added: c.txt"}
+	if got := changeLabels(draftChanges(d, g)); !sameLabels(got, want) {
+		t.Errorf("draftChanges() = %v, want %v", got, want)
+	}
+
+	d = gist.Draft{Key: "new-1", Files: []gist.DraftFile{{Name: "a.txt", Content: "one\n"}}}
+	want = []string{"Added: a.txt"}
+	if got := changeLabels(draftChanges(d, nil)); !sameLabels(got, want) {
+		t.Errorf("draftChanges() = %v, want %v", got, want)
+	}
+}
@@ -5,7 +5,10 @@
 package tab
 
 import (
+	"github.com/arsham/gistflow/qt/editor"
 	"github.com/arsham/gistflow/qt/messagebox"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
 )
 
@@ -17,9 +20,21 @@ type File struct {
 	_ func(string) `signal:"copyToClipboard"`
 	_ func()       `signal:"updateGist"`
 	_ func(string) `signal:"deleteFile"`
+	_ func(string) `signal:"dragFile"`
+	_ func(string) `signal:"openLink"`
 
+	dragHandle   *widgets.QLabel
 	fileName     *widgets.QLineEdit
-	content      *widgets.QTextEdit
+	dirtyMark    *widgets.QLabel // shown when the file has unsaved changes.
+	content      *editor.Editor
+	splitter     *widgets.QSplitter
+	kind         *viewKind // viewer of the file, or nil if it has none.
+	viewer       Viewer    // created when it is shown the first time.
+	viewButton   *widgets.QPushButton
+	viewTimer    *core.QTimer
+	syncing      bool   // the viewer is changing the contents.
+	savedName    string // name of the file in the saved gist, empty for new files.
+	dirty        bool
 	copyButton   *widgets.QPushButton
 	deleteButton *widgets.QPushButton
 	messageBox   messagebox.Message
@@ -29,8 +44,14 @@ func (f *File) init() {
 	f.SetObjectName("File")
 
 	f.messageBox = messagebox.New(f)
+	f.dragHandle = widgets.NewQLabel2("\u2630", f, core.Qt__Widget)
+	f.dragHandle.SetToolTip("Drag to move this file to another gist. Hold Ctrl to copy it instead.")
+	f.dragHandle.SetCursor(gui.NewQCursor2(core.Qt__OpenHandCursor))
 	f.fileName = widgets.NewQLineEdit(f)
 	f.fileName.SetPlaceholderText("Filename")
+	f.dirtyMark = widgets.NewQLabel2("\u25CF", f, core.Qt__Widget)
+	f.dirtyMark.SetToolTip("This file has unsaved changes")
+	f.dirtyMark.Hide()
 
 	f.deleteButton = widgets.NewQPushButton(f)
 	f.deleteButton.SetText("Delete")
@@ -40,29 +61,59 @@ func (f *File) init() {
 	f.copyButton.SetText("Copy Contents")
 	f.copyButton.SetToolTip("Copy contents to system's clipboard")
 
-	f.content = widgets.NewQTextEdit(f)
+	f.viewButton = widgets.NewQPushButton(f)
+	f.viewButton.SetCheckable(true)
+	f.viewButton.Hide()
+	f.viewTimer = core.NewQTimer(f)
+	f.viewTimer.SetSingleShot(true)
+
+	f.content = editor.NewEditor(f)
 	f.content.SetObjectName("content")
 	f.content.SetPlaceholderText("Contents")
 
 	vLayout := widgets.NewQVBoxLayout2(f)
 	hLayout := widgets.NewQHBoxLayout()
+	hLayout.AddWidget(f.dragHandle, 0, 0)
 	hLayout.AddWidget(f.fileName, 0, 0)
+	hLayout.AddWidget(f.dirtyMark, 0, 0)
 	hSpacer := widgets.NewQSpacerItem(40, 20, widgets.QSizePolicy__Expanding, widgets.QSizePolicy__Minimum)
 	hLayout.AddItem(hSpacer)
+	hLayout.AddWidget(f.viewButton, 0, 0)
 	hLayout.AddWidget(f.copyButton, 0, 0)
 	hLayout.AddWidget(f.deleteButton, 0, 0)
 	vLayout.AddLayout(hLayout, 0)
-	vLayout.AddWidget(f.content, 0, 0)
+	f.splitter = widgets.NewQSplitter2(core.Qt__Horizontal, f)
+	f.splitter.AddWidget(f.content)
+	vLayout.AddWidget(f.splitter, 0, 0)
 
 	f.copyButton.ConnectClicked(func(bool) {
 		f.CopyToClipboard(f.content.ToPlainText())
 	})
 	f.content.ConnectTextChanged(func() {
+		if f.viewerShown() && !f.syncing {
+			f.viewTimer.Start(viewDelay)
+		}
 		f.UpdateGist()
 	})
+	f.viewTimer.ConnectTimeout(func() {
+		if f.viewerShown() {
+			f.viewer.ShowText(f.content.ToPlainText())
+		}
+	})
+	f.content.VerticalScrollBar().ConnectValueChanged(func(int) {
+		f.syncViewer()
+	})
 	f.fileName.ConnectTextChanged(func(text string) {
+		f.content.SetFileName(text)
+		f.setViewKind(text)
 		f.UpdateGist()
 	})
+	f.viewButton.ConnectToggled(f.toggleViewer)
+	f.dragHandle.ConnectMousePressEvent(func(event *gui.QMouseEvent) {
+		if event.Button() == core.Qt__LeftButton {
+			f.DragFile(f.FileName())
+		}
+	})
 
 	f.deleteButton.ConnectClicked(func(bool) {
 		b := f.messageBox.Critical("Are you sure you want to delete this file?")
@@ -78,8 +129,159 @@ func (f *File) FileName() string { return f.fileName.Text() }
 // SetFileName returns the fileName.
 func (f *File) SetFileName(fileName string) { f.fileName.SetText(fileName) }
 
+// Dirty returns true if the file has changes that are not saved.
+func (f *File) Dirty() bool { return f.dirty }
+
+func (f *File) setDirty(dirty bool) {
+	f.dirty = dirty
+	f.dirtyMark.SetVisible(dirty)
+}
+
 // Content returns the content.
-func (f *File) Content() *widgets.QTextEdit { return f.content }
+func (f *File) Content() *editor.Editor { return f.content }
+
+// SetEditorOptions sets the width of tabs and whether the indentation is made
+// of spaces.
+func (f *File) SetEditorOptions(tabWidth int, spaces bool) {
+	f.content.SetTabWidth(tabWidth)
+	f.content.SetIndentWithSpaces(spaces)
+}
+
+// ViewButton returns the button that shows the viewer of the file. It is
+// hidden if the file has no viewer.
+func (f *File) ViewButton() *widgets.QPushButton { return f.viewButton }
+
+// Viewer returns the viewer of the file, or nil if it has never been shown.
+func (f *File) Viewer() Viewer { return f.viewer }
+
+// setViewKind picks the viewer for the file name. The viewer that is shown is
+// removed if it doesn't suit the file anymore.
+func (f *File) setViewKind(name string) {
+	kind := viewKindFor(name)
+	if kind == f.kind {
+		if v, ok := f.viewer.(interface{ SetFileName(string) }); ok {
+			v.SetFileName(name)
+		}
+		return
+	}
+	f.viewButton.SetChecked(false)
+	if f.viewer != nil {
+		f.viewer.QWidget_PTR().DeleteLater()
+		f.viewer = nil
+	}
+	f.kind = kind
+	f.viewButton.SetVisible(kind != nil)
+	if kind != nil {
+		f.viewButton.SetText(kind.label)
+		f.viewButton.SetToolTip(kind.tip)
+	}
+}
+
+// showInitialViewer shows the viewer of the file if it is shown when files
+// are opened.
+func (f *File) showInitialViewer() {
+	if f.kind != nil && f.kind.initial {
+		f.viewButton.SetChecked(true)
+	}
+}
+
+// toggleViewer shows or hides the viewer. Viewers that replace the editor hide
+// it while they are shown, others can hide it by collapsing the splitter.
+func (f *File) toggleViewer(show bool) {
+	if f.kind == nil {
+		return
+	}
+	if !show {
+		f.viewTimer.Stop()
+		if f.viewer != nil {
+			f.viewer.QWidget_PTR().Hide()
+		}
+		f.content.Show()
+		return
+	}
+	if f.viewer == nil {
+		f.viewer = f.kind.create(f.splitter, f.FileName())
+		f.splitter.AddWidget(f.viewer)
+		f.connectViewer(f.viewer)
+	}
+	f.viewer.ShowText(f.content.ToPlainText())
+	f.viewer.QWidget_PTR().Show()
+	f.content.SetVisible(!f.kind.replaces)
+	f.syncViewer()
+}
+
+// connectViewer connects the signals of the viewer the file listens to.
+func (f *File) connectViewer(v Viewer) {
+	if v, ok := v.(interface{ ConnectTextEdited(func(string)) }); ok {
+		v.ConnectTextEdited(f.viewerEdited)
+	}
+	if v, ok := v.(interface{ ConnectOpenLink(func(string)) }); ok {
+		v.ConnectOpenLink(f.OpenLink)
+	}
+	if v, ok := v.(interface{ ConnectCopyToClipboard(func(string)) }); ok {
+		v.ConnectCopyToClipboard(f.CopyToClipboard)
+	}
+}
+
+// viewerEdited replaces the contents with the text edited in the viewer.
+func (f *File) viewerEdited(text string) {
+	f.syncing = true
+	defer func() { f.syncing = false }()
+	f.SetContents(text)
+}
+
+// SetContents replaces the contents, so the change can be undone in the
+// editor.
+func (f *File) SetContents(text string) {
+	cursor := gui.NewQTextCursor2(f.content.Document())
+	cursor.BeginEditBlock()
+	cursor.Select(gui.QTextCursor__Document)
+	cursor.InsertText(text)
+	cursor.EndEditBlock()
+}
+
+func (f *File) viewerShown() bool {
+	return f.viewer != nil && f.viewButton.IsChecked()
+}
+
+// syncViewer scrolls the viewer to the first line shown in the editor, if it
+// follows the editor.
+func (f *File) syncViewer() {
+	if !f.viewerShown() {
+		return
+	}
+	if v, ok := f.viewer.(interface{ ScrollToLine(int) }); ok {
+		v.ScrollToLine(f.content.FirstVisibleBlock().BlockNumber() + 1)
+	}
+}
 
 // CopyButton returns the copyButton.
 func (f *File) CopyButton() *widgets.QPushButton { return f.copyButton }
+
+// HighlightLines marks the lines, starting from 1, as suspicious. The marks
+// are removed with ClearHighlights.
+func (f *File) HighlightLines(lines []int) {
+	doc := f.content.Document()
+	format := gui.NewQTextCharFormat()
+	format.SetBackground(gui.NewQBrush3(gui.NewQColor3(255, 200, 200, 255), core.Qt__SolidPattern))
+	f.content.BlockSignals(true)
+	defer f.content.BlockSignals(false)
+	for _, line := range lines {
+		block := doc.FindBlockByLineNumber(line - 1)
+		if !block.IsValid() {
+			continue
+		}
+		cursor := gui.NewQTextCursor4(block)
+		cursor.MovePosition(gui.QTextCursor__EndOfBlock, gui.QTextCursor__KeepAnchor, 1)
+		cursor.MergeCharFormat(format)
+	}
+}
+
+// ClearHighlights removes the marks set by HighlightLines.
+func (f *File) ClearHighlights() {
+	f.content.BlockSignals(true)
+	defer f.content.BlockSignals(false)
+	cursor := gui.NewQTextCursor2(f.content.Document())
+	cursor.Select(gui.QTextCursor__Document)
+	cursor.SetCharFormat(gui.NewQTextCharFormat())
+}
@@ -5,6 +5,7 @@
 package tab
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/therecipe/qt/widgets"
@@ -17,7 +18,7 @@ func testFile(t *testing.T) {
 		t.Error("file.fileName = nil, want *widgets.QLineEdit")
 	}
 	if file.content == nil {
-		t.Error("file.content = nil, want *widgets.QTextEdit")
+		t.Error("file.content = nil, want *editor.Editor")
 	}
 	if file.copyButton == nil {
 		t.Error("file.copyButton = nil, want *widgets.QPushButton")
@@ -47,7 +48,7 @@ func testFileSignals(t *testing.T) {
 	file.ConnectUpdateGist(func() {
 		called = true
 	})
-	file.content.SetText(content)
+	file.content.SetPlainText(content)
 	file.CopyButton().Click()
 	if !called {
 		t.Error("didn't trigger the signal")
@@ -103,3 +104,120 @@ func testFileDeleteConfirmation(t *testing.T) {
 		t.Error("didn't send deletion signal")
 	}
 }
+
+func TestFileHighlighting(t *testing.T) { tRunner.Run(func() { testFileHighlighting(t) }) }
+func testFileHighlighting(t *testing.T) {
+	file := NewFile(widgets.NewQWidget(nil, 0), 0)
+	file.SetFileName("main.go")
+	if g := file.Content().Highlighter().Grammar(); g == nil || g.Name != "Go" {
+		t.Errorf("Grammar() = %v, want Go", g)
+	}
+	file.SetFileName("query.sql.enc")
+	if g := file.Content().Highlighter().Grammar(); g == nil || g.Name != "SQL" {
+		t.Errorf("Grammar() = %v, want SQL", g)
+	}
+}
+
+func TestFilePreview(t *testing.T) { tRunner.Run(func() { testFilePreview(t) }) }
+func testFilePreview(t *testing.T) {
+	file := NewFile(widgets.NewQWidget(nil, 0), 0)
+	file.SetFileName("main.go")
+	if !file.ViewButton().IsHidden() {
+		t.Error("the view button is shown for a Go file")
+	}
+	file.SetFileName("README.md")
+	if file.ViewButton().IsHidden() || file.ViewButton().Text() != "Preview" {
+		t.Fatalf("view button = %q, hidden %t, want a Preview button", file.ViewButton().Text(), file.ViewButton().IsHidden())
+	}
+	file.Content().SetPlainText("# Title\n\n- [x] done")
+	file.ViewButton().Click()
+	p, ok := file.Viewer().(*Preview)
+	if !ok {
+		t.Fatalf("Viewer() = %T, want *Preview", file.Viewer())
+	}
+	if !strings.Contains(p.ToPlainText(), "Title") || !strings.Contains(p.ToPlainText(), "☑ done") {
+		t.Errorf("preview = %q, want the rendered text", p.ToPlainText())
+	}
+	if file.Content().IsHidden() {
+		t.Error("the preview hid the editor")
+	}
+
+	file.Content().SetPlainText("# Changed")
+	if !file.viewTimer.IsActive() {
+		t.Error("editing didn't queue the preview")
+	}
+	file.viewTimer.Timeout()
+	if !strings.Contains(p.ToPlainText(), "Changed") {
+		t.Errorf("preview = %q, want the changed text", p.ToPlainText())
+	}
+
+	file.SetFileName("notes.txt")
+	if file.ViewButton().IsChecked() || file.Viewer() != nil {
+		t.Error("the preview is still shown for a text file")
+	}
+}
+
+func TestFileTable(t *testing.T) { tRunner.Run(func() { testFileTable(t) }) }
+func testFileTable(t *testing.T) {
+	file := NewFile(widgets.NewQWidget(nil, 0), 0)
+	file.SetFileName("people.csv")
+	file.Content().SetPlainText("name,age\nBob,42\n")
+	if file.ViewButton().Text() != "Table" {
+		t.Fatalf("ViewButton().Text() = %q, want Table", file.ViewButton().Text())
+	}
+	file.ViewButton().Click()
+	table, ok := file.Viewer().(*Table)
+	if !ok {
+		t.Fatalf("Viewer() = %T, want *Table", file.Viewer())
+	}
+	if !file.Content().IsHidden() {
+		t.Error("the editor is shown with the table")
+	}
+
+	table.Model().Item(0, 1).SetText("43")
+	if got, want := file.Content().ToPlainText(), "name,age\nBob,43\n"; got != want {
+		t.Errorf("contents = %q, want %q", got, want)
+	}
+	if file.viewTimer.IsActive() {
+		t.Error("editing the table queued showing the contents again")
+	}
+	file.Content().Undo()
+	if got, want := file.Content().ToPlainText(), "name,age\nBob,42\n"; got != want {
+		t.Errorf("contents = %q after undo, want %q", got, want)
+	}
+	file.viewTimer.Timeout()
+	if got := table.Model().Item(0, 1).Text(); got != "42" {
+		t.Errorf("cell = %q after undo, want 42", got)
+	}
+
+	file.SetFileName("people.tsv")
+	if file.Viewer() == nil {
+		t.Fatal("renaming to another table removed the table")
+	}
+	if table.comma != '\t' {
+		t.Errorf("comma = %q, want a tab", table.comma)
+	}
+
+	file.ViewButton().Click()
+	if file.Content().IsHidden() {
+		t.Error("the editor is hidden after hiding the table")
+	}
+}
+
+func TestFileTree(t *testing.T) { tRunner.Run(func() { testFileTree(t) }) }
+func testFileTree(t *testing.T) {
+	file := NewFile(widgets.NewQWidget(nil, 0), 0)
+	file.SetFileName("data.json")
+	file.Content().SetPlainText(`{"items": [{"name": "a"}]}`)
+	var copied string
+	file.ConnectCopyToClipboard(func(text string) { copied = text })
+	file.ViewButton().Click()
+	tree, ok := file.Viewer().(*Tree)
+	if !ok {
+		t.Fatalf("Viewer() = %T, want *Tree", file.Viewer())
+	}
+	tree.CopyToClipboard("$.items")
+	if copied != "$.items" {
+		t.Errorf("copied = %q, want $.items", copied)
+	}
+}
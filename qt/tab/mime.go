@@ -0,0 +1,36 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+
+	"github.com/therecipe/qt/core"
+)
+
+// FileMimeType is the mime type of a file dragged out of a gist.
+const FileMimeType = "application/x-gistflow-file"
+
+// NewFileMimeData returns the mime data for dragging the file identified by
+// name out of the gist identified by id.
+func NewFileMimeData(id, name string) *core.QMimeData {
+	payload := id + "\n" + name
+	data := core.NewQMimeData()
+	data.SetData(FileMimeType, core.NewQByteArray2(payload, len(payload)))
+	return data
+}
+
+// FileFromMimeData returns the gist id and the file name in the data. It
+// returns false if the data does not hold a file.
+func FileFromMimeData(data *core.QMimeData) (id, name string, ok bool) {
+	if data == nil || data.Pointer() == nil || !data.HasFormat(FileMimeType) {
+		return "", "", false
+	}
+	parts := strings.SplitN(data.Data(FileMimeType).ConstData(), "\n", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
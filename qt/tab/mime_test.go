@@ -0,0 +1,32 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"testing"
+
+	"github.com/therecipe/qt/core"
+)
+
+func TestFileMimeData(t *testing.T) { tRunner.Run(func() { testFileMimeData(t) }) }
+func testFileMimeData(t *testing.T) {
+	data := NewFileMimeData("u8Ws2k", "main.go")
+	id, name, ok := FileFromMimeData(data)
+	if !ok {
+		t.Fatal("FileFromMimeData(): ok = false, want true")
+	}
+	if id != "u8Ws2k" || name != "main.go" {
+		t.Errorf("FileFromMimeData() = (%s, %s), want (u8Ws2k, main.go)", id, name)
+	}
+
+	text := core.NewQMimeData()
+	text.SetText("main.go")
+	if _, _, ok := FileFromMimeData(text); ok {
+		t.Error("FileFromMimeData(): ok = true for a text")
+	}
+	if _, _, ok := FileFromMimeData(nil); ok {
+		t.Error("FileFromMimeData(): ok = true for nil")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"html"
+
+	"github.com/arsham/gistflow/notebook"
+	"github.com/arsham/gistflow/qt/editor"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Notebook shows a rendered Jupyter notebook. It can't be edited, the raw JSON
+// is edited in the editor instead. Clicking on a link emits openLink.
+type Notebook struct {
+	widgets.QTextBrowser
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"openLink"`
+
+	text   string
+	images map[string]*core.QVariant // images of the cells by their names.
+}
+
+func (n *Notebook) init() {
+	n.SetObjectName("notebook")
+	n.SetOpenLinks(false)
+	n.images = make(map[string]*core.QVariant)
+	n.ConnectAnchorClicked(func(link *core.QUrl) {
+		n.OpenLink(link.ToString(0))
+	})
+	n.ConnectLoadResource(func(typ int, name *core.QUrl) *core.QVariant {
+		if img, ok := n.images[name.ToString(0)]; ok {
+			return img
+		}
+		return n.LoadResourceDefault(typ, name)
+	})
+	n.ConnectChangeEvent(func(event *core.QEvent) {
+		if event.Type() == core.QEvent__PaletteChange {
+			n.setStyle()
+		}
+		n.ChangeEventDefault(event)
+	})
+	n.setStyle()
+}
+
+// setStyle colours the code in the cells to match the editor.
+func (n *Notebook) setStyle() {
+	n.Document().SetDefaultStyleSheet(editor.StyleSheet(n.Palette()) + notebook.StyleSheet)
+	if text := n.text; text != "" {
+		n.text = ""
+		n.ShowText(text)
+	}
+}
+
+// ShowText renders the notebook, and keeps the view where it was. If the text
+// is not a notebook, the error is shown instead.
+func (n *Notebook) ShowText(text string) {
+	if text == n.text {
+		return
+	}
+	n.text = text
+	doc, err := notebook.Render([]byte(text))
+	if err != nil {
+		n.SetHtml(`<p class="error">The notebook can't be shown: ` + html.EscapeString(err.Error()) + "</p>")
+		return
+	}
+	n.images = make(map[string]*core.QVariant, len(doc.Images))
+	for name, img := range doc.Images {
+		image := gui.NewQImage()
+		if image.LoadFromData2(core.NewQByteArray2(string(img.Data), len(img.Data)), "") {
+			n.images[name] = image.ToVariant()
+		}
+	}
+	bar := n.VerticalScrollBar()
+	pos := bar.Value()
+	n.SetHtml(doc.HTML)
+	bar.SetValue(pos)
+}
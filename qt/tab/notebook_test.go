@@ -0,0 +1,52 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+	"testing"
+)
+
+// pixel is a notebook with a markdown cell, a code cell and a PNG image of one
+// pixel.
+const pixel = `{"nbformat": 4, "metadata": {}, "cells": [
+	{"cell_type": "markdown", "source": "# Pixel\n[docs](https://example.com)"},
+	{"cell_type": "code", "execution_count": 1, "source": "show()", "outputs": [
+		{"output_type": "display_data", "data": {"image/png":
+			"iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="}}
+	]}
+]}`
+
+func TestNotebook(t *testing.T) { tRunner.Run(func() { testNotebook(t) }) }
+func testNotebook(t *testing.T) {
+	n := NewNotebook(nil)
+	n.ShowText(pixel)
+	if text := n.ToPlainText(); !strings.Contains(text, "Pixel") || !strings.Contains(text, "In [1]:") || !strings.Contains(text, "show()") {
+		t.Errorf("ToPlainText() = %q, want the cells", text)
+	}
+	if len(n.images) != 1 {
+		t.Fatalf("len(images) = %d, want 1", len(n.images))
+	}
+	for name, img := range n.images {
+		if !strings.Contains(n.ToHtml(), name) {
+			t.Errorf("the image %s is not shown", name)
+		}
+		if img.IsNull() {
+			t.Errorf("image %s was not loaded", name)
+		}
+	}
+
+	var opened string
+	n.ConnectOpenLink(func(link string) { opened = link })
+	n.OpenLink("https://example.com")
+	if opened != "https://example.com" {
+		t.Errorf("opened = %q, want https://example.com", opened)
+	}
+
+	n.ShowText(`{"cells": [`)
+	if text := n.ToPlainText(); !strings.Contains(text, "can't be shown") {
+		t.Errorf("ToPlainText() = %q, want the error", text)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+
+	"github.com/arsham/gistflow/markdown"
+	"github.com/arsham/gistflow/qt/editor"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Preview shows the rendered Markdown of a file. Clicking on a link emits
+// openLink, except for the links to the headings of the same text.
+type Preview struct {
+	widgets.QTextBrowser
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"openLink"`
+
+	doc  *markdown.Document
+	text string
+}
+
+func (p *Preview) init() {
+	p.SetObjectName("preview")
+	p.SetOpenLinks(false)
+	p.doc = markdown.Render("")
+	p.ConnectAnchorClicked(func(link *core.QUrl) {
+		s := link.ToString(0)
+		if strings.HasPrefix(s, "#") {
+			p.ScrollToAnchor(s[1:])
+			return
+		}
+		p.OpenLink(s)
+	})
+	p.ConnectChangeEvent(func(event *core.QEvent) {
+		if event.Type() == core.QEvent__PaletteChange {
+			p.setStyle()
+		}
+		p.ChangeEventDefault(event)
+	})
+	p.setStyle()
+}
+
+// setStyle colours the code in the preview to match the editor.
+func (p *Preview) setStyle() {
+	p.Document().SetDefaultStyleSheet(editor.StyleSheet(p.Palette()))
+	if p.text != "" {
+		p.ShowText(p.text)
+	}
+}
+
+// ShowText renders the text, and keeps the preview where it was.
+func (p *Preview) ShowText(text string) {
+	p.text = text
+	bar := p.VerticalScrollBar()
+	pos := bar.Value()
+	p.doc = markdown.Render(text)
+	p.SetHtml(p.doc.HTML)
+	bar.SetValue(pos)
+}
+
+// ScrollToLine shows the block of the line of the text, starting from 1, at
+// the top of the preview.
+func (p *Preview) ScrollToLine(line int) {
+	if a := p.doc.AnchorFor(line); a != "" && line > 1 {
+		p.ScrollToAnchor(a)
+		return
+	}
+	p.VerticalScrollBar().SetValue(0)
+}
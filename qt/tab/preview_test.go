@@ -0,0 +1,48 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) { tRunner.Run(func() { testPreview(t) }) }
+func testPreview(t *testing.T) {
+	p := NewPreview(nil, 0)
+	p.Resize2(300, 200)
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("paragraph %d\n", i))
+	}
+	p.ShowText("# Title\n\n" + strings.Join(lines, "\n"))
+	if !strings.Contains(p.ToPlainText(), "Title") {
+		t.Errorf("ToPlainText() = %q, want the rendered title", p.ToPlainText())
+	}
+
+	p.ScrollToLine(150)
+	if p.VerticalScrollBar().Value() == 0 {
+		t.Error("ScrollToLine(150) didn't scroll")
+	}
+	p.ScrollToLine(1)
+	if p.VerticalScrollBar().Value() != 0 {
+		t.Error("ScrollToLine(1) didn't scroll to the top")
+	}
+
+	p.ScrollToLine(150)
+	pos := p.VerticalScrollBar().Value()
+	p.ShowText("# Title\n\n" + strings.Join(lines, "\n") + "\nmore")
+	if p.VerticalScrollBar().Value() != pos {
+		t.Errorf("position = %d after rendering again, want %d", p.VerticalScrollBar().Value(), pos)
+	}
+
+	var opened []string
+	p.ConnectOpenLink(func(link string) { opened = append(opened, link) })
+	p.OpenLink("other.md")
+	if len(opened) != 1 || opened[0] != "other.md" {
+		t.Errorf("opened = %v, want [other.md]", opened)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/arsham/gistflow/diff"
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Recovery offers to restore the drafts left from the last session. Each draft
+// is shown as its changes from the gist on the server. Restoring or discarding
+// a draft emits restoreDraft or discardDraft with its key, and the drafts that
+// are left are kept for the next time.
+type Recovery struct {
+	widgets.QDialog
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"restoreDraft"`
+	_ func(string) `signal:"discardDraft"`
+
+	list          *widgets.QListWidget
+	diffView      *widgets.QTextBrowser
+	restoreButton *widgets.QPushButton
+	discardButton *widgets.QPushButton
+	laterButton   *widgets.QPushButton
+
+	drafts []gist.Draft
+	gists  map[string]*gist.Gist // gists of the drafts by their IDs.
+}
+
+func (r *Recovery) init() {
+	r.SetWindowTitle("Restore Drafts")
+	r.Resize2(800, 500)
+	label := widgets.NewQLabel2("These changes were not saved when GistFlow was closed last time.", r, 0)
+	r.list = widgets.NewQListWidget(r)
+	r.list.SetObjectName("drafts")
+	r.diffView = widgets.NewQTextBrowser(r)
+	r.diffView.SetObjectName("diff")
+	r.restoreButton = widgets.NewQPushButton2("Restore", r)
+	r.restoreButton.SetToolTip("Opens the gist with the changes of the draft")
+	r.discardButton = widgets.NewQPushButton2("Discard", r)
+	r.discardButton.SetToolTip("Removes the draft. This action is irreversible.")
+	r.laterButton = widgets.NewQPushButton2("Later", r)
+	r.laterButton.SetToolTip("Keeps the drafts for the next time")
+
+	hLayout := widgets.NewQHBoxLayout()
+	hLayout.AddWidget(r.list, 1, 0)
+	hLayout.AddWidget(r.diffView, 3, 0)
+	buttons := widgets.NewQHBoxLayout()
+	buttons.AddWidget(r.restoreButton, 0, 0)
+	buttons.AddWidget(r.discardButton, 0, 0)
+	buttons.AddStretch(1)
+	buttons.AddWidget(r.laterButton, 0, 0)
+	vLayout := widgets.NewQVBoxLayout2(r)
+	vLayout.AddWidget(label, 0, 0)
+	vLayout.AddLayout(hLayout, 1)
+	vLayout.AddLayout(buttons, 0)
+
+	r.list.ConnectCurrentRowChanged(r.showDraft)
+	r.restoreButton.ConnectClicked(func(bool) {
+		if d := r.current(); d != nil {
+			key := d.Key
+			r.take(key)
+			r.RestoreDraft(key)
+		}
+	})
+	r.discardButton.ConnectClicked(func(bool) {
+		if d := r.current(); d != nil {
+			key := d.Key
+			r.take(key)
+			r.DiscardDraft(key)
+		}
+	})
+	r.laterButton.ConnectClicked(func(bool) { r.Close() })
+}
+
+// SetDrafts lists the drafts. The gists are the versions on the server by
+// their IDs, decrypted if needed.
+func (r *Recovery) SetDrafts(drafts []gist.Draft, gists map[string]*gist.Gist) {
+	r.drafts = drafts
+	r.gists = gists
+	r.list.Clear()
+	for _, d := range drafts {
+		r.list.AddItem(draftTitle(d))
+	}
+	r.list.SetCurrentRow(0)
+	r.showDraft(0)
+}
+
+// Drafts returns the drafts that are not restored or discarded yet.
+func (r *Recovery) Drafts() []gist.Draft { return r.drafts }
+
+// current returns the selected draft, or nil if there is none.
+func (r *Recovery) current() *gist.Draft {
+	row := r.list.CurrentRow()
+	if row < 0 || row >= len(r.drafts) {
+		return nil
+	}
+	return &r.drafts[row]
+}
+
+// take removes the draft from the list, and closes the dialog when there are
+// no drafts left.
+func (r *Recovery) take(key string) {
+	for i, d := range r.drafts {
+		if d.Key == key {
+			r.drafts = append(r.drafts[:i], r.drafts[i+1:]...)
+			r.list.TakeItem(i)
+			break
+		}
+	}
+	if len(r.drafts) == 0 {
+		r.Close()
+	}
+}
+
+func (r *Recovery) showDraft(int) {
+	d := r.current()
+	r.restoreButton.SetEnabled(d != nil)
+	r.discardButton.SetEnabled(d != nil)
+	if d == nil {
+		r.diffView.Clear()
+		return
+	}
+	g := r.gists[d.GistID]
+	var buf bytes.Buffer
+	if d.GistID != "" && g == nil {
+		buf.WriteString("<p><i>The gist could not be fetched, or it has been deleted.</i></p>\n")
+	}
+	changes := draftChanges(*d, g)
+	if len(changes) == 0 {
+		buf.WriteString("<p>The draft is the same as the gist.</p>\n")
+	}
+	for _, c := range changes {
+		buf.WriteString("<h3>" + html.EscapeString(c.label()) + "</h3>\n")
+		buf.WriteString(diffHTML(c, diff.Hunks(c.oldText, c.newText, diffContext), false))
+	}
+	r.diffView.SetHtml(buf.String())
+}
+
+// draftTitle returns the description of the draft, or the name of its first
+// file, with the time it was written.
+func draftTitle(d gist.Draft) string {
+	title := d.Description
+	if title == "" && len(d.Files) > 0 {
+		title = d.Files[0].Name
+	}
+	if title == "" {
+		title = "(untitled)"
+	}
+	if d.GistID == "" {
+		title += " (new gist)"
+	}
+	return title + "\n" + d.Updated.Local().Format("Jan 2 15:04")
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func TestDraftTitle(t *testing.T) {
+	updated := time.Date(2018, 5, 4, 10, 30, 0, 0, time.Local)
+	tcs := []struct {
+		draft gist.Draft
+		want  string
+	}{
+		{gist.Draft{GistID: "a", Description: "notes"}, "notes"},
+		{gist.Draft{GistID: "a", Files: []gist.DraftFile{{Name: "a.go"}}}, "a.go"},
+		{gist.Draft{Files: []gist.DraftFile{{Name: "a.go"}}}, "a.go (new gist)"},
+		{gist.Draft{}, "(untitled) (new gist)"},
+	}
+	for _, tc := range tcs {
+		tc.draft.Updated = updated
+		want := tc.want + "\nMay 4 10:30"
+		if got := draftTitle(tc.draft); got != want {
+			t.Errorf("draftTitle(%+v) = %q, want %q", tc.draft, got, want)
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) { tRunner.Run(func() { testRecovery(t) }) }
+func testRecovery(t *testing.T) {
+	drafts := []gist.Draft{
+		{Key: "g1", GistID: "g1", Files: []gist.DraftFile{{Name: "a.txt", SavedName: "a.txt", Content: "two\n"}}},
+		{Key: "g2", GistID: "g2", Files: []gist.DraftFile{{Name: "b.txt", SavedName: "b.txt", Content: "bee\n"}}},
+		{Key: "new-1", Files: []gist.DraftFile{{Name: "c.txt", Content: "sea\n"}}},
+	}
+	gists := map[string]*gist.Gist{
+		"g1": {ID: "g1", Files: map[string]gist.File{"a.txt": {Content: "one\n"}}},
+	}
+	r := NewRecovery(nil, 0)
+	r.SetDrafts(drafts, gists)
+	if got := r.list.Count(); got != 3 {
+		t.Fatalf("list.Count() = %d, want 3", got)
+	}
+	if html := r.diffView.ToPlainText(); !strings.Contains(html, "Modified: a.txt") {
+		t.Errorf("diff = %q, want the changes of the first draft", html)
+	}
+
+	var restored, discarded []string
+	r.ConnectRestoreDraft(func(key string) { restored = append(restored, key) })
+	r.ConnectDiscardDraft(func(key string) { discarded = append(discarded, key) })
+
+	r.list.SetCurrentRow(1)
+	if html := r.diffView.ToPlainText(); !strings.Contains(html, "could not be fetched") {
+		t.Errorf("diff = %q, want a note about the missing gist", html)
+	}
+	r.discardButton.Click()
+	r.restoreButton.Click()
+	if len(discarded) != 1 || discarded[0] != "g2" {
+		t.Errorf("discarded = %v, want [g2]", discarded)
+	}
+	if len(restored) != 1 {
+		t.Fatalf("restored = %v, want one draft", restored)
+	}
+	left := r.Drafts()
+	if len(left) != 1 || r.list.Count() != 1 {
+		t.Fatalf("Drafts() = %v, want one draft left", left)
+	}
+	r.list.SetCurrentRow(0)
+	r.restoreButton.Click()
+	if len(r.Drafts()) != 0 || len(restored) != 2 {
+		t.Errorf("Drafts() = %v, restored = %v", r.Drafts(), restored)
+	}
+	if r.restoreButton.IsEnabled() {
+		t.Error("the restore button is enabled without drafts")
+	}
+}
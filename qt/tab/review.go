@@ -0,0 +1,173 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/arsham/gistflow/diff"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+// diffContext is the number of unchanged lines shown around the changes.
+const diffContext = 3
+
+var lineColours = map[diff.Op]string{
+	diff.Delete: "#cb2431",
+	diff.Insert: "#22863a",
+}
+
+// Review shows the changes of a tab since the gist was loaded or saved, before
+// they are saved. Each hunk, or a whole change, can be reverted.
+type Review struct {
+	widgets.QDialog
+
+	_ func() `constructor:"init"`
+
+	list         *widgets.QListWidget
+	diffView     *widgets.QTextBrowser
+	revertButton *widgets.QPushButton
+	buttons      *widgets.QDialogButtonBox
+
+	tab     *Tab
+	changes []*change
+	hunks   []diff.Hunk // hunks of the selected change.
+}
+
+func (r *Review) init() {
+	r.SetWindowTitle("Review Changes")
+	r.SetModal(true)
+	r.Resize2(800, 500)
+	r.list = widgets.NewQListWidget(r)
+	r.list.SetObjectName("changes")
+	r.diffView = widgets.NewQTextBrowser(r)
+	r.diffView.SetObjectName("diff")
+	r.diffView.SetOpenLinks(false)
+	r.revertButton = widgets.NewQPushButton2("Revert File", r)
+	r.revertButton.SetObjectName("revertButton")
+	r.revertButton.SetToolTip("Undoes all the changes of the selected item")
+	r.buttons = widgets.NewQDialogButtonBox(r)
+	r.buttons.SetStandardButtons(widgets.QDialogButtonBox__Save | widgets.QDialogButtonBox__Cancel)
+
+	hLayout := widgets.NewQHBoxLayout()
+	hLayout.AddWidget(r.list, 1, 0)
+	hLayout.AddWidget(r.diffView, 3, 0)
+	bottom := widgets.NewQHBoxLayout()
+	bottom.AddWidget(r.revertButton, 0, 0)
+	bottom.AddStretch(1)
+	bottom.AddWidget(r.buttons, 0, 0)
+	vLayout := widgets.NewQVBoxLayout2(r)
+	vLayout.AddLayout(hLayout, 1)
+	vLayout.AddLayout(bottom, 0)
+
+	r.list.ConnectCurrentRowChanged(r.showChange)
+	r.diffView.ConnectAnchorClicked(func(link *core.QUrl) {
+		r.revertHunk(link.ToString(0))
+	})
+	r.revertButton.ConnectClicked(func(bool) {
+		if c := r.current(); c != nil {
+			r.tab.revert(c)
+			r.refresh()
+		}
+	})
+	r.buttons.ConnectAccepted(r.Accept)
+	r.buttons.ConnectRejected(r.Reject)
+}
+
+// SetTab shows the changes of the tab.
+func (r *Review) SetTab(t *Tab) {
+	r.tab = t
+	r.refresh()
+}
+
+// refresh lists the changes again, keeping the selected row.
+func (r *Review) refresh() {
+	row := r.list.CurrentRow()
+	r.changes = r.tab.changes()
+	r.list.BlockSignals(true)
+	r.list.Clear()
+	for _, c := range r.changes {
+		r.list.AddItem(c.label())
+	}
+	r.list.BlockSignals(false)
+	if row >= len(r.changes) {
+		row = len(r.changes) - 1
+	}
+	if row < 0 && len(r.changes) > 0 {
+		row = 0
+	}
+	r.list.SetCurrentRow(row)
+	r.showChange(row)
+	r.revertButton.SetEnabled(len(r.changes) > 0)
+}
+
+// current returns the selected change, or nil if there is none.
+func (r *Review) current() *change {
+	row := r.list.CurrentRow()
+	if row < 0 || row >= len(r.changes) {
+		return nil
+	}
+	return r.changes[row]
+}
+
+func (r *Review) showChange(int) {
+	c := r.current()
+	if c == nil {
+		r.hunks = nil
+		r.diffView.SetHtml("<p>There are no changes.</p>")
+		return
+	}
+	r.hunks = diff.Hunks(c.oldText, c.newText, diffContext)
+	r.diffView.SetHtml(diffHTML(c, r.hunks, true))
+}
+
+// revertHunk reverts the hunk of the link, which is like "hunk:2".
+func (r *Review) revertHunk(link string) {
+	i, err := strconv.Atoi(strings.TrimPrefix(link, "hunk:"))
+	c := r.current()
+	if err != nil || c == nil || i < 0 || i >= len(r.hunks) {
+		return
+	}
+	r.tab.revertHunk(c, r.hunks[i])
+	r.refresh()
+}
+
+// diffHTML returns the hunks of the change. If revert is true, each hunk has a
+// link to revert it.
+func diffHTML(c *change, hunks []diff.Hunk, revert bool) string {
+	var buf bytes.Buffer
+	if c.kind == renamed {
+		buf.WriteString("<p>" + html.EscapeString(c.oldName+" → "+c.newName) + "</p>\n")
+	}
+	for i, h := range hunks {
+		buf.WriteString("<p><b>" + h.Header() + "</b>")
+		if revert {
+			buf.WriteString(` <a href="hunk:` + strconv.Itoa(i) + `">Revert</a>`)
+		}
+		buf.WriteString("</p>\n<pre>")
+		for _, l := range h.Lines {
+			text := html.EscapeString(strings.TrimSuffix(l.String(), "\n"))
+			if colour, ok := lineColours[l.Op]; ok {
+				text = `<span style="color: ` + colour + `;">` + text + "</span>"
+			}
+			buf.WriteString(text + "\n")
+		}
+		buf.WriteString("</pre>\n")
+	}
+	return buf.String()
+}
+
+// showReview shows the changes of the tab, and returns true if they should be
+// saved.
+func (t *Tab) showReview() bool {
+	r := NewReview(t, 0)
+	defer r.DeleteLater()
+	r.SetTab(t)
+	return r.Exec() == int(widgets.QDialog__Accepted)
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/diff"
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestReview(t *testing.T) { tRunner.Run(func() { testReview(t) }) }
+func testReview(t *testing.T) {
+	tab := showChangesGist()
+	tab.description.SetText("<more> notes")
+	fileNamed(tab, "a.txt").Content().SetPlainText("one\n2\nthree\n")
+
+	r := NewReview(nil, 0)
+	r.SetTab(tab)
+	if r.list.Count() != 2 {
+		t.Fatalf("r.list.Count() = %d, want 2", r.list.Count())
+	}
+	if r.list.CurrentRow() != 0 {
+		t.Errorf("r.list.CurrentRow() = %d, want 0", r.list.CurrentRow())
+	}
+	text := r.diffView.ToPlainText()
+	for _, want := range []string{"@@ -1 +1 @@", "-notes", "+<more> notes", "Revert"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("%q not in the diff:\n%s", want, text)
+		}
+	}
+
+	r.list.SetCurrentRow(1)
+	r.revertHunk("hunk:0")
+	if r.list.Count() != 1 {
+		t.Fatalf("r.list.Count() = %d after reverting the file, want 1", r.list.Count())
+	}
+	if got := fileNamed(tab, "a.txt").Content().ToPlainText(); got != "one\ntwo\nthree\n" {
+		t.Errorf("content = %q, want the saved content", got)
+	}
+
+	r.revertHunk("hunk:9")
+	r.revertHunk("nothing")
+	r.revertButton.Click()
+	if r.list.Count() != 0 {
+		t.Errorf("r.list.Count() = %d, want 0", r.list.Count())
+	}
+	if r.revertButton.IsEnabled() {
+		t.Error("revertButton is enabled without changes")
+	}
+	if tab.description.Text() != "notes" {
+		t.Errorf("description = %q, want %q", tab.description.Text(), "notes")
+	}
+}
+
+func TestReviewBeforeSave(t *testing.T) { tRunner.Run(func() { testReviewBeforeSave(t) }) }
+func testReviewBeforeSave(t *testing.T) {
+	tab := showChangesGist()
+	var saved *gist.Gist
+	tab.ConnectUpdateGist(func(g *gist.Gist) {
+		saved = g
+		tab.Saved()
+	})
+	var reviewed int
+	accept := false
+	tab.review = func() bool {
+		reviewed++
+		return accept
+	}
+
+	fileNamed(tab, "b.txt").Content().SetPlainText("wasp\n")
+	tab.saveButton.Click()
+	if reviewed != 1 || saved != nil {
+		t.Fatalf("reviewed = %d, saved = %v, want the save cancelled", reviewed, saved)
+	}
+	if !tab.saveButton.IsEnabled() {
+		t.Error("cancelling the review disabled the saveButton")
+	}
+
+	accept = true
+	tab.saveButton.Click()
+	if saved == nil {
+		t.Fatal("accepting the review didn't save the gist")
+	}
+	if got := saved.Files["b.txt"].Content; got != "wasp\n" {
+		t.Errorf("saved content = %q, want %q", got, "wasp\n")
+	}
+	if c := tab.changes(); len(c) != 0 {
+		t.Errorf("changes() = %v after saving, want none", changeLabels(c))
+	}
+
+	// reverting everything in the review leaves nothing to save.
+	saved = nil
+	fileNamed(tab, "b.txt").Content().SetPlainText("bee\n")
+	tab.review = func() bool {
+		for _, c := range tab.changes() {
+			tab.revert(c)
+		}
+		return true
+	}
+	tab.saveButton.Click()
+	if saved != nil {
+		t.Error("saved the gist without any changes")
+	}
+	if tab.saveButton.IsEnabled() {
+		t.Error("saveButton is enabled without any changes")
+	}
+}
+
+func TestDiffHTML(t *testing.T) {
+	c := &change{kind: renamed, oldName: "a<b", newName: "c", oldText: "x\n", newText: "<y>\n"}
+	hunks := diff.Hunks(c.oldText, c.newText, diffContext)
+	got := diffHTML(c, hunks, true)
+	for _, want := range []string{
+		"a&lt;b → c",
+		`<a href="hunk:0">Revert</a>`,
+		`<span style="color: #cb2431;">-x</span>`,
+		`<span style="color: #22863a;">+&lt;y&gt;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q not in %q", want, got)
+		}
+	}
+	if got := diffHTML(c, hunks, false); strings.Contains(got, "Revert") {
+		t.Errorf("the hunks can be reverted in %q", got)
+	}
+}
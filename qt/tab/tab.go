@@ -5,9 +5,15 @@
 package tab
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"github.com/arsham/gistflow/gist"
 	"github.com/arsham/gistflow/qt/messagebox"
+	"github.com/arsham/gistflow/scan"
 	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
 	"github.com/therecipe/qt/widgets"
 )
 
@@ -15,26 +21,45 @@ import (
 type Tab struct {
 	widgets.QTabWidget
 
-	_ func()                   `constructor:"init"`
-	_ func(string)             `signal:"copyToClipboard"`
-	_ func(*gist.Gist, string) `signal:"deleteFile"`
-	_ func(string)             `slot:"fileDeleted"`
-	_ func(*gist.Gist)         `signal:"updateGist"`
-	_ func(*gist.Gist)         `signal:"createGist"`
-	_ func(*gist.Gist)         `signal:"GistCreated"`
-	_ func(*gist.Gist)         `signal:"deleteGist"`
+	_ func()                     `constructor:"init"`
+	_ func(string)               `signal:"copyToClipboard"`
+	_ func(*gist.Gist, string)   `signal:"deleteFile"`
+	_ func(string)               `slot:"fileDeleted"`
+	_ func(*gist.Gist)           `signal:"updateGist"`
+	_ func()                     `slot:"saved"`
+	_ func(*gist.Gist)           `signal:"createGist"`
+	_ func(*gist.Gist)           `signal:"GistCreated"`
+	_ func(*gist.Gist)           `signal:"deleteGist"`
+	_ func(*gist.Gist, bool)     `signal:"pinGist"`
+	_ func(*gist.Gist)           `signal:"changeVisibility"`
+	_ func(string, string, bool) `signal:"dropFile"`
+	_ func(bool)                 `signal:"dirtyChanged"`
+	_ func()                     `signal:"changed"`
+	_ func(bool)                 `signal:"autoSaveToggled"`
 
-	// TODO: add dirty property
 	messageBox messagebox.Message
 	files      []*File
 	gist       *gist.Gist
+	allowlist  *scan.Allowlist
+	tabWidth   int
+	spaces     bool
+	openURL    func(link string) // opens the links of the previews.
+	saved      *snapshot         // nil until the gist is loaded or saved.
+	review     func() bool       // returns false if saving is cancelled.
+	dirty      bool              // there are changes that are not saved.
 
-	description    *widgets.QLineEdit
-	vBoxLayout     *widgets.QVBoxLayout // layout on gist level operations.
-	saveButton     *widgets.QPushButton
-	publicCheckBox *widgets.QCheckBox
-	deleteButton   *widgets.QPushButton
-	addFileButton  *widgets.QPushButton
+	description      *widgets.QLineEdit
+	tags             *core.QStringListModel // offered while typing a #tag.
+	tagCompleter     *widgets.QCompleter
+	vBoxLayout       *widgets.QVBoxLayout // layout on gist level operations.
+	saveButton       *widgets.QPushButton
+	publicCheckBox   *widgets.QCheckBox
+	visibilityButton *widgets.QPushButton
+	pinCheckBox      *widgets.QCheckBox
+	autoSaveBox      *widgets.QCheckBox
+	encryptBox       *widgets.QCheckBox
+	deleteButton     *widgets.QPushButton
+	addFileButton    *widgets.QPushButton
 }
 
 func init() {
@@ -47,20 +72,39 @@ func (t *Tab) init() {
 	t.vBoxLayout = layout
 	t.SetLayout(layout)
 	t.messageBox = messagebox.New(t)
+	t.openURL = func(link string) {
+		gui.QDesktopServices_OpenUrl(core.NewQUrl3(link, 0))
+	}
+	t.review = t.showReview
 
 	t.saveButton = widgets.NewQPushButton2("Save Gist", t)
 	t.saveButton.SetToolTip("Saves the gist on github")
 	t.deleteButton = widgets.NewQPushButton2("Delete", t)
 	t.deleteButton.SetToolTip("Deletes the gist on github. This action is irreversible.")
 	t.publicCheckBox = widgets.NewQCheckBox2("Public", t)
+	t.visibilityButton = widgets.NewQPushButton2("Make Public", t)
+	t.visibilityButton.SetToolTip("Creates a copy of this gist with the other visibility, and removes this one after your confirmation. The gist's URL will change.")
+	t.visibilityButton.SetVisible(false)
+	t.pinCheckBox = widgets.NewQCheckBox2("Offline", t)
+	t.pinCheckBox.SetToolTip("Keeps this gist in the cache, so it is available offline")
+	t.pinCheckBox.SetDisabled(true)
+	t.autoSaveBox = widgets.NewQCheckBox2("Auto-save", t)
+	t.autoSaveBox.SetToolTip("Saves the changes on github when you stop typing")
+	t.autoSaveBox.SetDisabled(true)
+	t.encryptBox = widgets.NewQCheckBox2("Encrypted", t)
+	t.encryptBox.SetToolTip("Encrypts the files before sending them to github")
 	t.addFileButton = widgets.NewQPushButton2("Add File", t)
 
 	t.description = widgets.NewQLineEdit(t)
 	t.description.SetToolTip("Set the gist's description")
 	t.description.SetPlaceholderText("Description")
+	t.setupTagCompleter()
 	butttons := widgets.NewQHBoxLayout()
 	hLayout := widgets.NewQHBoxLayout()
 	hLayout.AddWidget(t.publicCheckBox, 0, 0)
+	hLayout.AddWidget(t.pinCheckBox, 0, 0)
+	hLayout.AddWidget(t.autoSaveBox, 0, 0)
+	hLayout.AddWidget(t.encryptBox, 0, 0)
 	hLayout.AddWidget(t.description, 0, 0)
 	hLayout.AddLayout(butttons, 0)
 
@@ -70,14 +114,41 @@ func (t *Tab) init() {
 
 	layout.AddItem(hLayout)
 	layout.AddWidget(line, 0, 0)
+	butttons.AddWidget(t.visibilityButton, 0, 0)
 	butttons.AddWidget(t.deleteButton, 0, 0)
 	butttons.AddWidget(t.addFileButton, 0, 0)
 	butttons.AddWidget(t.saveButton, 0, 0)
 	t.files = make([]*File, 0)
 	t.description.ConnectTextChanged(func(string) {
 		t.saveButton.SetEnabled(true)
+		t.updateDirty()
+	})
+	t.encryptBox.ConnectClicked(func(bool) {
+		t.saveButton.SetEnabled(true)
+		t.updateDirty()
 	})
 	t.ConnectFileDeleted(t.removeFile)
+	t.ConnectSaved(func() {
+		t.takeSnapshot()
+		t.saveButton.SetDisabled(true)
+	})
+	t.SetAcceptDrops(true)
+	t.ConnectDragEnterEvent(func(event *gui.QDragEnterEvent) {
+		if id, _, ok := FileFromMimeData(event.MimeData()); ok && t.acceptsFilesFrom(id) {
+			event.AcceptProposedAction()
+			return
+		}
+		event.Ignore()
+	})
+	t.ConnectDropEvent(func(event *gui.QDropEvent) {
+		id, name, ok := FileFromMimeData(event.MimeData())
+		if !ok || !t.acceptsFilesFrom(id) {
+			event.Ignore()
+			return
+		}
+		event.AcceptProposedAction()
+		t.DropFile(id, name, event.DropAction() == core.Qt__CopyAction)
+	})
 	t.deleteButton.ConnectClicked(func(bool) {
 		b := t.messageBox.Critical("Are you sure you want to delete this gist?")
 		if b == widgets.QMessageBox__Ok {
@@ -85,25 +156,15 @@ func (t *Tab) init() {
 		}
 	})
 	t.addFileButton.ConnectClicked(func(bool) {
-		f := t.addFile()
-		// disabling the dialog.
-		f.deleteButton.DisconnectClicked()
-		f.deleteButton.ConnectClicked(func(bool) {
-			f.DestroyQWidget()
-		})
+		t.newFile()
 	})
 }
 
 // ShowGist shows each file in a separate container.
 func (t *Tab) ShowGist(tabWidget *widgets.QTabWidget, g *gist.Gist) {
+	t.gist = g
 	for label, gf := range g.Files {
-		f := t.addFile()
-		f.SetObjectName(label)
-		f.Content().SetText(gf.Content)
-		f.ConnectDeleteFile(func(name string) {
-			t.DeleteFile(g, name)
-		})
-		f.SetFileName(label)
+		t.showFile(label, gf.Content).showInitialViewer()
 	}
 	for label := range g.Files {
 		tabWidget.AddTab(t, label)
@@ -111,31 +172,49 @@ func (t *Tab) ShowGist(tabWidget *widgets.QTabWidget, g *gist.Gist) {
 	}
 	t.description.SetText(g.Description)
 	tabWidget.SetCurrentWidget(t)
-	t.gist = g
+	t.takeSnapshot()
 	t.saveButton.ConnectClicked(func(bool) {
-		g := t.gist
-		g.Description = t.description.Text()
-		names := make(map[string]struct{}, len(t.files))
-		for _, f := range t.files {
-			content := g.Files[f.FileName()]
-			content.Content = f.Content().ToPlainText()
-			g.Files[f.FileName()] = content
-			names[f.FileName()] = struct{}{}
+		if len(t.changes()) == 0 {
+			t.saveButton.SetDisabled(true)
+			return
 		}
-		for name := range g.Files {
-			if _, ok := names[name]; !ok {
-				g.Files[name] = gist.File{}
-			}
+		if !t.review() {
+			return
 		}
-		t.UpdateGist(g)
-		t.saveButton.SetDisabled(true)
+		if len(t.changes()) == 0 {
+			// everything was reverted in the review.
+			t.saveButton.SetDisabled(true)
+			return
+		}
+		if !t.checkSecrets(t.gist.Public) {
+			return
+		}
+		// the changes are recorded when the window calls Saved.
+		t.UpdateGist(t.editedGist())
 	})
 	t.saveButton.SetDisabled(true)
 	if g.Public {
 		t.publicCheckBox.SetChecked(true)
 	}
+	t.pinCheckBox.SetEnabled(true)
+	t.pinCheckBox.ConnectClicked(func(checked bool) {
+		t.PinGist(t.gist, checked)
+	})
+	t.autoSaveBox.SetEnabled(true)
+	t.autoSaveBox.ConnectClicked(t.AutoSaveToggled)
 	t.publicCheckBox.SetDisabled(true)
-	t.publicCheckBox.SetToolTip("Unfortunately the API doesn't allow us to change this. You need to update it from your browser.")
+	t.publicCheckBox.SetToolTip("The API doesn't allow us to change this. Use the visibility button to move the files to a new gist.")
+	if g.Public {
+		t.visibilityButton.SetText("Make Secret")
+	}
+	t.visibilityButton.SetVisible(true)
+	t.visibilityButton.ConnectClicked(func(bool) {
+		if t.saveButton.IsEnabled() {
+			t.messageBox.Warning("Please save your changes before changing the visibility.")
+			return
+		}
+		t.ChangeVisibility(t.gist)
+	})
 }
 
 // NewGist opens a new tab for creating a new gist.
@@ -147,6 +226,9 @@ func (t *Tab) NewGist(tabWidget *widgets.QTabWidget, label string) {
 	t.gist = new(gist.Gist)
 
 	t.saveButton.ConnectClicked(func(bool) {
+		if !t.checkSecrets(t.publicCheckBox.IsChecked()) {
+			return
+		}
 		g := t.gist
 		g.Description = t.description.Text()
 		g.Files = make(map[string]gist.File, len(t.files))
@@ -162,11 +244,16 @@ func (t *Tab) NewGist(tabWidget *widgets.QTabWidget, label string) {
 	})
 	t.ConnectGistCreated(func(g *gist.Gist) {
 		t.gist = g
+		t.pinCheckBox.SetEnabled(true)
+		t.pinCheckBox.ConnectClicked(func(checked bool) {
+			t.PinGist(t.gist, checked)
+		})
 		index := tabWidget.IndexOf(t)
 		for label := range g.Files {
 			tabWidget.SetTabText(index, label)
 			break
 		}
+		t.takeSnapshot()
 	})
 }
 
@@ -175,17 +262,178 @@ func (t Tab) HTMLURL() string {
 	return t.gist.HTMLURL
 }
 
+// SetPinned sets the state of the offline checkbox without emitting the
+// pinGist signal.
+func (t *Tab) SetPinned(pinned bool) { t.pinCheckBox.SetChecked(pinned) }
+
+// SetEncrypted sets the state of the encrypted checkbox, as the gist is
+// stored.
+func (t *Tab) SetEncrypted(encrypted bool) {
+	t.encryptBox.SetChecked(encrypted)
+	if t.saved != nil {
+		t.saved.encrypted = encrypted
+	}
+	t.updateDirty()
+}
+
+// Encrypted returns true if the files should be encrypted before sending them
+// to github.
+func (t *Tab) Encrypted() bool { return t.encryptBox.IsChecked() }
+
+// SetReview replaces the dialog that shows the changes before saving the gist.
+// The review returns false if saving is cancelled.
+func (t *Tab) SetReview(review func() bool) { t.review = review }
+
+// SetAllowlist sets the patterns that are not reported by the secret scanner.
+func (t *Tab) SetAllowlist(a *scan.Allowlist) { t.allowlist = a }
+
+// SetEditorOptions sets the width of tabs and whether the indentation is made
+// of spaces in the files, including the ones added later.
+func (t *Tab) SetEditorOptions(tabWidth int, spaces bool) {
+	t.tabWidth, t.spaces = tabWidth, spaces
+	for _, f := range t.files {
+		f.SetEditorOptions(tabWidth, spaces)
+	}
+}
+
+// editedGist returns the gist with the description and the files in the
+// editors. The files that are removed have empty contents.
+func (t *Tab) editedGist() *gist.Gist {
+	g := t.gist
+	g.Description = t.description.Text()
+	names := make(map[string]struct{}, len(t.files))
+	for _, f := range t.files {
+		content := g.Files[f.FileName()]
+		content.Content = f.Content().ToPlainText()
+		g.Files[f.FileName()] = content
+		names[f.FileName()] = struct{}{}
+	}
+	for name := range g.Files {
+		if _, ok := names[name]; !ok {
+			g.Files[name] = gist.File{}
+		}
+	}
+	return g
+}
+
+// findSecrets scans the files and the description for secrets and highlights
+// the suspicious lines. Encrypted gists are not checked as they are never
+// published in plain text.
+func (t *Tab) findSecrets() []scan.Finding {
+	for _, f := range t.files {
+		f.ClearHighlights()
+	}
+	if t.Encrypted() {
+		return nil
+	}
+	contents := make(map[string]string, len(t.files))
+	for _, f := range t.files {
+		contents[f.FileName()] = f.Content().ToPlainText()
+	}
+	findings := scan.Files(contents, t.allowlist)
+	findings = append(findings, scan.Text("description", t.description.Text(), t.allowlist)...)
+	if len(findings) == 0 {
+		return nil
+	}
+	lines := make(map[string][]int)
+	for _, f := range findings {
+		lines[f.File] = append(lines[f.File], f.Line)
+	}
+	for _, f := range t.files {
+		f.HighlightLines(lines[f.FileName()])
+	}
+	return findings
+}
+
+// checkSecrets highlights the secrets in the gist and asks the user about
+// them. It returns false if the gist should not be saved.
+func (t *Tab) checkSecrets(public bool) bool {
+	findings := t.findSecrets()
+	if len(findings) == 0 {
+		return true
+	}
+	switch scan.Decide(findings, public) {
+	case scan.Block:
+		msg := fmt.Sprintf("This public gist contains secrets and cannot be saved:\n%s", scan.Summary(findings))
+		t.messageBox.Error(msg)
+		return false
+	case scan.Warn:
+		msg := fmt.Sprintf("This gist might contain secrets:\n%s\n\nDo you want to save it anyway?", scan.Summary(findings))
+		return t.messageBox.Critical(msg) == widgets.QMessageBox__Ok
+	}
+	return true
+}
+
+// acceptsFilesFrom returns true if files of the gist identified by id can be
+// dropped on this gist. New gists should be saved first.
+func (t *Tab) acceptsFilesFrom(id string) bool {
+	return t.gist != nil && t.gist.ID != "" && t.gist.ID != id
+}
+
+// startDrag lets the user drag the file out of this gist and drop it on
+// another one.
+func (t *Tab) startDrag(name string) {
+	if t.gist == nil || t.gist.ID == "" {
+		return
+	}
+	drag := gui.NewQDrag(t)
+	drag.SetMimeData(NewFileMimeData(t.gist.ID, name))
+	drag.Exec2(core.Qt__MoveAction|core.Qt__CopyAction, core.Qt__MoveAction)
+}
+
 // Files returns the *File slice.
 func (t *Tab) Files() []*File { return t.files }
 
+// FocusFile moves the focus to the content of the file, and returns false if
+// there is no such file in the tab.
+func (t *Tab) FocusFile(name string) bool {
+	for _, f := range t.files {
+		if f.FileName() == name {
+			f.Content().SetFocus2()
+			f.Content().EnsureCursorVisible()
+			return true
+		}
+	}
+	return false
+}
+
+// openLink focuses the file a relative link points to if it is in the gist,
+// or opens the link in the browser. Other relative links are resolved against
+// the page of the gist.
+func (t *Tab) openLink(link string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return
+	}
+	if !u.IsAbs() {
+		if name := strings.TrimPrefix(u.Path, "./"); name != "" && t.FocusFile(name) {
+			return
+		}
+		if t.gist == nil || t.gist.HTMLURL == "" {
+			return
+		}
+		base, err := url.Parse(t.gist.HTMLURL + "/")
+		if err != nil {
+			return
+		}
+		u = base.ResolveReference(u)
+	}
+	t.openURL(u.String())
+}
+
 // Gist returns Gist.
 func (t *Tab) Gist() *gist.Gist { return t.gist }
 
 // SaveButton returns SaveButton.
 func (t *Tab) SaveButton() *widgets.QPushButton { return t.saveButton }
 
-// SetDescription sets the description
-func (t *Tab) SetDescription(text string) { t.description.SetText(text) }
+// SetDescription sets the description, as the gist is stored.
+func (t *Tab) SetDescription(text string) {
+	if t.saved != nil {
+		t.saved.description = text
+	}
+	t.description.SetText(text)
+}
 
 // removeFile removes the file section that corresponds to name from the layout.
 func (t *Tab) removeFile(name string) {
@@ -201,15 +449,63 @@ func (t *Tab) removeFile(name string) {
 	f := NewFileFromPointer(c)
 	f.DestroyQWidget()
 	delete(t.gist.Files, name)
+	if t.saved != nil {
+		delete(t.saved.files, name)
+	}
+	t.updateDirty()
+}
+
+// showFile adds a file of the gist to the tab.
+func (t *Tab) showFile(name, content string) *File {
+	f := t.addFile()
+	f.SetObjectName(name)
+	f.Content().SetPlainText(content)
+	f.ConnectDeleteFile(func(name string) {
+		t.DeleteFile(t.gist, name)
+	})
+	f.SetFileName(name)
+	f.savedName = name
+	t.updateDirty()
+	return f
+}
+
+// newFile adds a file that is not in the saved gist. It is deleted without a
+// confirmation.
+func (t *Tab) newFile() *File {
+	f := t.addFile()
+	f.deleteButton.DisconnectClicked()
+	f.deleteButton.ConnectClicked(func(bool) {
+		t.discardFile(f)
+	})
+	t.updateDirty()
+	return f
+}
+
+// discardFile removes a file that is not in the saved gist from the tab.
+func (t *Tab) discardFile(f *File) {
+	for i, file := range t.files {
+		if file == f {
+			t.files = append(t.files[:i], t.files[i+1:]...)
+			break
+		}
+	}
+	f.DestroyQWidget()
+	t.updateDirty()
 }
 
 func (t *Tab) addFile() *File {
 	f := NewFile(t, 0)
 	t.vBoxLayout.AddWidget(f, 0, 0)
 	t.files = append(t.files, f)
+	if t.tabWidth > 0 {
+		f.SetEditorOptions(t.tabWidth, t.spaces)
+	}
 	f.ConnectCopyToClipboard(t.CopyToClipboard)
+	f.ConnectDragFile(t.startDrag)
+	f.ConnectOpenLink(t.openLink)
 	f.ConnectUpdateGist(func() {
 		t.saveButton.SetEnabled(true)
+		t.updateDirty()
 	})
 	return f
 }
@@ -0,0 +1,298 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// Table shows the rows of a CSV or TSV file, with the first row as the header.
+// Rows can be sorted by clicking on the headers and filtered by any of their
+// cells. Editing a cell emits textEdited with the new contents of the file, in
+// which only the row of the cell is written again. The rows are read-only when
+// they can't be found in the text.
+type Table struct {
+	widgets.QWidget
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"textEdited"`
+
+	filter     *widgets.QLineEdit
+	errorLabel *widgets.QLabel
+	view       *widgets.QTableView
+	model      *gui.QStandardItemModel
+	proxy      *core.QSortFilterProxyModel
+
+	comma    rune
+	text     string
+	widths   []int    // number of cells of each row in the text, with the header.
+	spans    [][2]int // start and end of each row in the text, without its newline.
+	crlf     bool
+	loading  bool
+	triggers widgets.QAbstractItemView__EditTrigger
+}
+
+func (t *Table) init() {
+	t.SetObjectName("table")
+	t.comma = ','
+	t.filter = widgets.NewQLineEdit(t)
+	t.filter.SetPlaceholderText("Filter rows")
+	t.filter.SetClearButtonEnabled(true)
+	t.errorLabel = widgets.NewQLabel(t, 0)
+	t.errorLabel.SetStyleSheet("color: red;")
+	t.errorLabel.SetWordWrap(true)
+	t.errorLabel.Hide()
+
+	t.model = gui.NewQStandardItemModel(t)
+	t.proxy = core.NewQSortFilterProxyModel(t)
+	t.proxy.SetSourceModel(t.model)
+	t.proxy.SetFilterKeyColumn(-1)
+	t.proxy.SetFilterCaseSensitivity(core.Qt__CaseInsensitive)
+	t.proxy.ConnectLessThan(t.lessThan)
+	t.view = widgets.NewQTableView(t)
+	t.view.SetModel(t.proxy)
+	t.view.SetSortingEnabled(true)
+	t.view.SortByColumn(-1, core.Qt__AscendingOrder)
+	t.triggers = t.view.EditTriggers()
+
+	vLayout := widgets.NewQVBoxLayout2(t)
+	vLayout.SetContentsMargins(0, 0, 0, 0)
+	vLayout.AddWidget(t.filter, 0, 0)
+	vLayout.AddWidget(t.errorLabel, 0, 0)
+	vLayout.AddWidget(t.view, 0, 0)
+
+	t.filter.ConnectTextChanged(t.proxy.SetFilterFixedString)
+	t.model.ConnectItemChanged(func(item *gui.QStandardItem) {
+		if !t.loading {
+			t.edited(item.Row())
+		}
+	})
+}
+
+// SetFileName sets the separator of the values by the extension of the file.
+func (t *Table) SetFileName(name string) {
+	comma := delimiter(name)
+	if comma == t.comma {
+		return
+	}
+	t.comma = comma
+	if text := t.text; text != "" {
+		t.text = ""
+		t.ShowText(text)
+	}
+}
+
+// Filter returns the field filtering the rows.
+func (t *Table) Filter() *widgets.QLineEdit { return t.filter }
+
+// View returns the view of the rows, which are sorted and filtered.
+func (t *Table) View() *widgets.QTableView { return t.view }
+
+// Model returns the rows in the order of the text.
+func (t *Table) Model() *gui.QStandardItemModel { return t.model }
+
+// ShowText shows the rows of the text. If the text can't be read, the error is
+// shown above the rows of the last text that could.
+func (t *Table) ShowText(text string) {
+	if text == t.text {
+		return
+	}
+	t.text = text
+	rows, err := parseDelimited(text, t.comma)
+	if err != nil {
+		t.errorLabel.SetText("The rows can't be read: " + err.Error())
+		t.errorLabel.Show()
+		return
+	}
+	t.errorLabel.Hide()
+	t.crlf = strings.Contains(text, "\r\n")
+	t.spans = recordSpans(text, t.comma, rows)
+	if t.spans == nil && len(rows) > 0 {
+		t.view.SetEditTriggers(widgets.QAbstractItemView__NoEditTriggers)
+		t.errorLabel.SetText("The rows are read-only, as they can't be written without changing the rest of the file.")
+		t.errorLabel.Show()
+	} else {
+		t.view.SetEditTriggers(t.triggers)
+	}
+
+	hBar, vBar := t.view.HorizontalScrollBar(), t.view.VerticalScrollBar()
+	x, y := hBar.Value(), vBar.Value()
+	t.loading = true
+	defer func() { t.loading = false }()
+
+	t.widths = t.widths[:0]
+	columns := 0
+	for _, row := range rows {
+		t.widths = append(t.widths, len(row))
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	t.model.Clear()
+	t.model.SetColumnCount(columns)
+	if len(rows) == 0 {
+		return
+	}
+	t.model.SetHorizontalHeaderLabels(rows[0])
+	t.model.SetRowCount(len(rows) - 1)
+	for i, row := range rows[1:] {
+		for j := 0; j < columns; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			t.model.SetItem(i, j, gui.NewQStandardItem2(cell))
+		}
+	}
+	hBar.SetValue(x)
+	vBar.SetValue(y)
+}
+
+// edited writes the row of the model back to its place in the text, and emits
+// textEdited with the new text. The row keeps the number of cells it had,
+// unless its extra cells have been filled in.
+func (t *Table) edited(i int) {
+	record := i + 1 // the header is the first record.
+	if record >= len(t.spans) {
+		return
+	}
+	columns := t.model.ColumnCount(core.NewQModelIndex())
+	row := make([]string, columns)
+	width := t.width(record)
+	for j := range row {
+		row[j] = t.model.Item(i, j).Text()
+		if row[j] != "" && j >= width {
+			width = j + 1
+		}
+	}
+	t.widths[record] = width
+	line := formatDelimited([][]string{row[:width]}, t.comma, t.crlf, false)
+	span := t.spans[record]
+	t.text = t.text[:span[0]] + line + t.text[span[1]:]
+	shift := len(line) - (span[1] - span[0])
+	t.spans[record][1] += shift
+	for k := record + 1; k < len(t.spans); k++ {
+		t.spans[k][0] += shift
+		t.spans[k][1] += shift
+	}
+	t.TextEdited(t.text)
+}
+
+// width returns the number of cells the row had in the text.
+func (t *Table) width(row int) int {
+	if row < len(t.widths) {
+		return t.widths[row]
+	}
+	return 0
+}
+
+// lessThan sorts the cells by their numbers if both of them are numbers, and
+// by their texts otherwise.
+func (t *Table) lessThan(left, right *core.QModelIndex) bool {
+	l, r := left.Data(int(core.Qt__DisplayRole)).ToString(), right.Data(int(core.Qt__DisplayRole)).ToString()
+	return lessCell(l, r)
+}
+
+func lessCell(l, r string) bool {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(l), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(r), 64)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+	return strings.ToLower(l) < strings.ToLower(r)
+}
+
+// delimiter returns the separator of the values of the file.
+func delimiter(name string) rune {
+	if extension(name) == ".csv" {
+		return ','
+	}
+	return '\t'
+}
+
+// parseDelimited returns the rows of the text. Rows can have any number of
+// cells, and stray quotes are kept in the cells.
+func parseDelimited(text string, comma rune) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+// recordSpans returns where the rows are in the text, without their newlines.
+// It returns nil if they can't be told apart, which happens with stray quotes,
+// as each span should hold exactly its row.
+func recordSpans(text string, comma rune, rows [][]string) [][2]int {
+	var spans [][2]int
+	start, quoted, fieldStart := 0, false, true
+	add := func(end int) {
+		if end > start && text[end-1] == '\r' {
+			end--
+		}
+		if end > start {
+			// empty lines are skipped by the reader.
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quoted:
+			if c == '"' {
+				if i+1 < len(text) && text[i+1] == '"' {
+					i++
+				} else {
+					quoted = false
+				}
+			}
+			continue
+		case c == '"' && fieldStart:
+			quoted = true
+		case c == '\n':
+			add(i)
+			start = i + 1
+			fieldStart = true
+			continue
+		}
+		fieldStart = rune(c) == comma
+	}
+	add(len(text))
+
+	if len(spans) != len(rows) {
+		return nil
+	}
+	for i, s := range spans {
+		got, err := parseDelimited(text[s[0]:s[1]], comma)
+		if err != nil || len(got) != 1 || !reflect.DeepEqual(got[0], rows[i]) {
+			return nil
+		}
+	}
+	return spans
+}
+
+// formatDelimited returns the text of the rows, quoting the cells that need
+// it.
+func formatDelimited(rows [][]string, comma rune, crlf, newline bool) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	w.UseCRLF = crlf
+	// the writer only fails when the buffer does.
+	w.WriteAll(rows)
+	text := buf.String()
+	if !newline {
+		text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+	}
+	return text
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestDelimited(t *testing.T) {
+	tcs := []struct {
+		name    string
+		text    string
+		comma   rune
+		rows    [][]string
+		crlf    bool
+		newline bool
+	}{
+		{"csv", "a,b\n1,2\n", ',', [][]string{{"a", "b"}, {"1", "2"}}, false, true},
+		{"no newline", "a,b\n1,2", ',', [][]string{{"a", "b"}, {"1", "2"}}, false, false},
+		{"crlf", "a,b\r\n1,2\r\n", ',', [][]string{{"a", "b"}, {"1", "2"}}, true, true},
+		{"quoted", "a,b\n\"x, y\",\"say \"\"hi\"\"\"\n", ',', [][]string{{"a", "b"}, {"x, y", `say "hi"`}}, false, true},
+		{"ragged", "a,b,c\n1\n1,2,3\n", ',', [][]string{{"a", "b", "c"}, {"1"}, {"1", "2", "3"}}, false, true},
+		{"tsv", "a\tb\n1,5\t2\n", '\t', [][]string{{"a", "b"}, {"1,5", "2"}}, false, true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := parseDelimited(tc.text, tc.comma)
+			if err != nil {
+				t.Fatalf("parseDelimited() error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, tc.rows) {
+				t.Errorf("parseDelimited() = %q, want %q", rows, tc.rows)
+			}
+			if got := formatDelimited(rows, tc.comma, tc.crlf, tc.newline); got != tc.text {
+				t.Errorf("formatDelimited() = %q, want %q", got, tc.text)
+			}
+		})
+	}
+}
+
+func TestRecordSpans(t *testing.T) {
+	tcs := []struct {
+		name string
+		text string
+		want [][2]int
+	}{
+		{"lines", "a,b\n1,2\n", [][2]int{{0, 3}, {4, 7}}},
+		{"crlf and empty lines", "a,b\r\n\r\n\"x\n y\",2\r\n", [][2]int{{0, 3}, {7, 15}}},
+		{"quoted", "\"a\",b\n\"x,\"\"q\"\"\",3", [][2]int{{0, 5}, {6, 17}}},
+		{"stray quote", "a,b\n\"x\"y,1\n", nil},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := parseDelimited(tc.text, ',')
+			if err != nil {
+				t.Fatalf("parseDelimited() error = %v", err)
+			}
+			if got := recordSpans(tc.text, ',', rows); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("recordSpans() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLessCell(t *testing.T) {
+	tcs := []struct {
+		l, r string
+		want bool
+	}{
+		{"2", "10", true},
+		{"10", "2", false},
+		{"-1.5", "1", true},
+		{"apple", "Banana", true},
+		{"10", "apple", true},
+		{"b", "a", false},
+	}
+	for _, tc := range tcs {
+		if got := lessCell(tc.l, tc.r); got != tc.want {
+			t.Errorf("lessCell(%q, %q) = %t, want %t", tc.l, tc.r, got, tc.want)
+		}
+	}
+}
+
+func TestTable(t *testing.T) { tRunner.Run(func() { testTable(t) }) }
+func testTable(t *testing.T) {
+	table := NewTable(widgets.NewQWidget(nil, 0), 0)
+	table.SetFileName("fruits.csv")
+	table.ShowText("name,count\napple,10\nbanana,2\ncherry\n")
+	view := table.View()
+	if got := view.Model().RowCount(core.NewQModelIndex()); got != 3 {
+		t.Fatalf("RowCount() = %d, want 3", got)
+	}
+	if got := table.Model().HorizontalHeaderItem(1).Text(); got != "count" {
+		t.Errorf("header = %q, want count", got)
+	}
+
+	cell := func(row, column int) string {
+		return view.Model().Index(row, column, core.NewQModelIndex()).Data(int(core.Qt__DisplayRole)).ToString()
+	}
+	view.SortByColumn(1, core.Qt__AscendingOrder)
+	if got := []string{cell(0, 1), cell(1, 1), cell(2, 1)}; !reflect.DeepEqual(got, []string{"", "2", "10"}) {
+		t.Errorf("sorted counts = %q, want [ 2 10]", got)
+	}
+
+	table.Filter().SetText("AN")
+	if got := view.Model().RowCount(core.NewQModelIndex()); got != 1 || cell(0, 0) != "banana" {
+		t.Errorf("filtered rows = %d, first %q, want 1 banana", got, cell(0, 0))
+	}
+	table.Filter().Clear()
+
+	var edited string
+	table.ConnectTextEdited(func(text string) { edited = text })
+	table.Model().Item(2, 1).SetText("7")
+	if want := "name,count\napple,10\nbanana,2\ncherry,7\n"; edited != want {
+		t.Errorf("edited = %q, want %q", edited, want)
+	}
+	table.ShowText("\"name\",count\r\napple,10\n\nbanana,2\n")
+	table.Model().Item(1, 0).SetText("blood orange, red")
+	if want := "\"name\",count\r\napple,10\n\n\"blood orange, red\",2\n"; edited != want {
+		t.Errorf("edited = %q, want only the row changed", edited)
+	}
+	table.Model().Item(0, 1).SetText("11")
+	if want := "\"name\",count\r\napple,11\n\n\"blood orange, red\",2\n"; edited != want {
+		t.Errorf("edited = %q, want %q", edited, want)
+	}
+
+	table.ShowText("name,count\n\"apple\"s,1\n")
+	if table.View().EditTriggers() != widgets.QAbstractItemView__NoEditTriggers || table.errorLabel.IsHidden() {
+		t.Error("the rows can be edited while they can't be found in the text")
+	}
+	edited = ""
+	table.ShowText("name,count\napple,1\n")
+	if table.View().EditTriggers() == widgets.QAbstractItemView__NoEditTriggers || !table.errorLabel.IsHidden() {
+		t.Error("the rows are still read-only")
+	}
+	if edited != "" {
+		t.Error("showing a text emitted textEdited")
+	}
+	if got := table.Model().RowCount(core.NewQModelIndex()); got != 1 {
+		t.Errorf("RowCount() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"unicode"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/widgets"
+)
+
+func (t *Tab) setupTagCompleter() {
+	t.tags = core.NewQStringListModel(t)
+	t.tagCompleter = widgets.NewQCompleter2(t.tags, t)
+	t.tagCompleter.SetCaseSensitivity(core.Qt__CaseInsensitive)
+	t.tagCompleter.SetWidget(t.description)
+	t.tagCompleter.ConnectActivated(func(tag string) {
+		text, cursor := completeTag(t.description.Text(), t.description.CursorPosition(), tag)
+		t.description.SetText(text)
+		t.description.SetCursorPosition(cursor)
+	})
+	t.description.ConnectTextEdited(func(text string) {
+		_, prefix, ok := tagAt(text, t.description.CursorPosition())
+		if !ok {
+			t.tagCompleter.Popup().Hide()
+			return
+		}
+		t.tagCompleter.SetCompletionPrefix(prefix)
+		if t.tagCompleter.CompletionCount() == 0 {
+			t.tagCompleter.Popup().Hide()
+			return
+		}
+		t.tagCompleter.Complete(core.NewQRect())
+	})
+}
+
+// SetTags sets the tags that are offered when a #tag is typed in the
+// description.
+func (t *Tab) SetTags(tags []string) { t.tags.SetStringList(tags) }
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '/'
+}
+
+// tagAt returns the position of the hash of the tag that ends at the cursor,
+// and the part of the tag after the hash. Positions are counted in runes.
+func tagAt(text string, cursor int) (start int, prefix string, ok bool) {
+	runes := []rune(text)
+	if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	start = cursor
+	for start > 0 && isTagRune(runes[start-1]) {
+		start--
+	}
+	if start == 0 || runes[start-1] != '#' {
+		return 0, "", false
+	}
+	start--
+	if start > 0 && !unicode.IsSpace(runes[start-1]) {
+		return 0, "", false
+	}
+	return start, string(runes[start+1 : cursor]), true
+}
+
+// completeTag replaces the tag that ends at the cursor with tag, and returns
+// the new text and the position of the cursor after it.
+func completeTag(text string, cursor int, tag string) (string, int) {
+	start, _, ok := tagAt(text, cursor)
+	if !ok {
+		return text, cursor
+	}
+	runes := []rune(text)
+	if cursor > len(runes) {
+		cursor = len(runes)
+	}
+	rest := runes[cursor:]
+	insert := []rune("#" + tag)
+	if len(rest) == 0 || !unicode.IsSpace(rest[0]) {
+		insert = append(insert, ' ')
+	}
+	res := append(append(append([]rune{}, runes[:start]...), insert...), rest...)
+	return string(res), start + len(insert)
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"testing"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/testlib"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestTagAt(t *testing.T) {
+	tcs := []struct {
+		text   string
+		cursor int
+		start  int
+		prefix string
+		ok     bool
+	}{
+		{"#", 1, 0, "", true},
+		{"deploy #k8", 10, 7, "k8", true},
+		{"deploy #k8 now", 10, 7, "k8", true},
+		{"deploy #k8 now", 14, 0, "", false},
+		{"issue#12", 8, 0, "", false},
+		{"déploiement #ku", 15, 12, "ku", true},
+		{"deploy", 20, 0, "", false},
+	}
+	for _, tc := range tcs {
+		start, prefix, ok := tagAt(tc.text, tc.cursor)
+		if ok != tc.ok || start != tc.start || prefix != tc.prefix {
+			t.Errorf("tagAt(%q, %d) = (%d, %q, %t), want (%d, %q, %t)",
+				tc.text, tc.cursor, start, prefix, ok, tc.start, tc.prefix, tc.ok)
+		}
+	}
+}
+
+func TestCompleteTag(t *testing.T) {
+	tcs := []struct {
+		text   string
+		cursor int
+		want   string
+		pos    int
+	}{
+		{"deploy #k", 9, "deploy #k8s ", 12},
+		{"#k and more", 2, "#k8s and more", 4},
+		{"#k,", 2, "#k8s ,", 5},
+		{"deploy", 6, "deploy", 6},
+	}
+	for _, tc := range tcs {
+		got, pos := completeTag(tc.text, tc.cursor, "k8s")
+		if got != tc.want || pos != tc.pos {
+			t.Errorf("completeTag(%q, %d) = (%q, %d), want (%q, %d)", tc.text, tc.cursor, got, pos, tc.want, tc.pos)
+		}
+	}
+}
+
+func TestTagCompletion(t *testing.T) { tRunner.Run(func() { testTagCompletion(t) }) }
+func testTagCompletion(t *testing.T) {
+	tab := NewTab(widgets.NewQWidget(nil, 0))
+	tab.SetTags([]string{"helm", "k8s", "kubernetes"})
+
+	event := testlib.NewQTestEventList()
+	event.AddKeyClicks("deploy #k", core.Qt__NoModifier, -1)
+	event.Simulate(tab.description)
+	if got := tab.tagCompleter.CompletionPrefix(); got != "k" {
+		t.Errorf("CompletionPrefix() = %q, want k", got)
+	}
+	if got := tab.tagCompleter.CompletionCount(); got != 2 {
+		t.Errorf("CompletionCount() = %d, want 2", got)
+	}
+
+	tab.tagCompleter.Activated("kubernetes")
+	if got := tab.description.Text(); got != "deploy #kubernetes " {
+		t.Errorf("description = %q, want deploy #kubernetes", got)
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const (
+	pathRole  = int(core.Qt__UserRole) + 1 // path of the value, like $.items[0].
+	valueRole = int(core.Qt__UserRole) + 2 // text of the value, for copying.
+)
+
+var errTrailingData = errors.New("unexpected data after the value")
+
+// Tree shows the values of a JSON file. Objects and arrays can be collapsed,
+// and keep their state when the text changes. The path or the value of the
+// selected node can be copied from the context menu.
+type Tree struct {
+	widgets.QWidget
+
+	_ func()       `constructor:"init"`
+	_ func(string) `signal:"copyToClipboard"`
+
+	errorLabel *widgets.QLabel
+	view       *widgets.QTreeWidget
+	text       string
+}
+
+func (t *Tree) init() {
+	t.SetObjectName("tree")
+	t.errorLabel = widgets.NewQLabel(t, 0)
+	t.errorLabel.SetStyleSheet("color: red;")
+	t.errorLabel.SetWordWrap(true)
+	t.errorLabel.Hide()
+	t.view = widgets.NewQTreeWidget(t)
+	t.view.SetColumnCount(2)
+	t.view.SetHeaderLabels([]string{"Key", "Value"})
+	t.view.SetUniformRowHeights(true)
+
+	vLayout := widgets.NewQVBoxLayout2(t)
+	vLayout.SetContentsMargins(0, 0, 0, 0)
+	vLayout.AddWidget(t.errorLabel, 0, 0)
+	vLayout.AddWidget(t.view, 0, 0)
+
+	newAction := func(text, shortcut string, role int) {
+		a := widgets.NewQAction2(text, t.view)
+		a.SetShortcut(gui.NewQKeySequence2(shortcut, gui.QKeySequence__NativeText))
+		a.SetShortcutContext(core.Qt__WidgetShortcut)
+		a.ConnectTriggered(func(bool) {
+			if item := t.view.CurrentItem(); item != nil && item.Pointer() != nil {
+				t.CopyToClipboard(item.Data(0, role).ToString())
+			}
+		})
+		t.view.AddAction(a)
+	}
+	newAction("Copy Path", "Ctrl+Shift+C", pathRole)
+	newAction("Copy Value", "Ctrl+C", valueRole)
+	t.view.SetContextMenuPolicy(core.Qt__ActionsContextMenu)
+}
+
+// View returns the tree of the values.
+func (t *Tree) View() *widgets.QTreeWidget { return t.view }
+
+// ShowText shows the values of the text. If the text is not valid, the error
+// is shown above the values of the last text that was.
+func (t *Tree) ShowText(text string) {
+	if text == t.text {
+		return
+	}
+	t.text = text
+	var root *jsonNode
+	if strings.TrimSpace(text) != "" {
+		var err error
+		if root, err = parseJSON(text); err != nil {
+			t.errorLabel.SetText("The values can't be read: " + jsonError(text, err))
+			t.errorLabel.Show()
+			return
+		}
+	}
+	t.errorLabel.Hide()
+
+	first := t.view.TopLevelItemCount() == 0
+	expanded := make(map[string]bool)
+	var walk func(item *widgets.QTreeWidgetItem)
+	walk = func(item *widgets.QTreeWidgetItem) {
+		if item.IsExpanded() {
+			expanded[item.Data(0, pathRole).ToString()] = true
+		}
+		for i := 0; i < item.ChildCount(); i++ {
+			walk(item.Child(i))
+		}
+	}
+	for i := 0; i < t.view.TopLevelItemCount(); i++ {
+		walk(t.view.TopLevelItem(i))
+	}
+	bar := t.view.VerticalScrollBar()
+	pos := bar.Value()
+
+	t.view.Clear()
+	if root == nil {
+		return
+	}
+	item := newItem(root)
+	t.view.AddTopLevelItem(item)
+	expanded[root.path] = expanded[root.path] || first
+	expand(item, expanded)
+	t.view.ResizeColumnToContents(0)
+	bar.SetValue(pos)
+}
+
+// newItem returns the item of the node with the items of its children.
+func newItem(n *jsonNode) *widgets.QTreeWidgetItem {
+	item := widgets.NewQTreeWidgetItem(0)
+	item.SetText(0, n.key)
+	item.SetText(1, n.summary())
+	item.SetToolTip(0, n.path)
+	item.SetData(0, pathRole, core.NewQVariant14(n.path))
+	item.SetData(0, valueRole, core.NewQVariant14(n.value))
+	if n.children != nil {
+		item.SetForeground(1, gui.NewQBrush2(core.Qt__gray, core.Qt__SolidPattern))
+	}
+	for _, c := range n.children {
+		item.AddChild(newItem(c))
+	}
+	return item
+}
+
+// expand expands the item and its children if their paths are in expanded.
+// Items can only be expanded once they are in the tree.
+func expand(item *widgets.QTreeWidgetItem, expanded map[string]bool) {
+	if expanded[item.Data(0, pathRole).ToString()] {
+		item.SetExpanded(true)
+	}
+	for i := 0; i < item.ChildCount(); i++ {
+		expand(item.Child(i), expanded)
+	}
+}
+
+// jsonNode is a value in a JSON text. Objects and arrays have children, and
+// their values are their texts.
+type jsonNode struct {
+	key      string // key in the object, or index in the array.
+	path     string
+	value    string
+	kind     string // object, array, string, number, bool or null.
+	children []*jsonNode
+}
+
+// summary returns the text shown for the value. Strings are quoted, and
+// objects and arrays show the number of their children.
+func (n *jsonNode) summary() string {
+	switch n.kind {
+	case "object":
+		return fmt.Sprintf("{%d}", len(n.children))
+	case "array":
+		return fmt.Sprintf("[%d]", len(n.children))
+	case "string":
+		return quote(n.value)
+	}
+	return n.value
+}
+
+// parseJSON returns the root of the text, keeping the keys of the objects in
+// the order of the text.
+func parseJSON(text string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	root, err := decodeNode(dec, "$", "$")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errTrailingData
+		}
+		return nil, err
+	}
+	return root, nil
+}
+
+func decodeNode(dec *json.Decoder, key, path string) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{key: key, path: path}
+	switch v := tok.(type) {
+	case json.Delim:
+		n.kind, n.children = "object", []*jsonNode{}
+		if v == '[' {
+			n.kind = "array"
+		}
+		for i := 0; dec.More(); i++ {
+			key, sub := strconv.Itoa(i), "["+strconv.Itoa(i)+"]"
+			if n.kind == "object" {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = tok.(string)
+				sub = pathKey(key)
+			}
+			child, err := decodeNode(dec, key, path+sub)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+		// the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		n.value = n.compact()
+	case string:
+		n.kind, n.value = "string", v
+	case json.Number:
+		n.kind, n.value = "number", v.String()
+	case bool:
+		n.kind, n.value = "bool", strconv.FormatBool(v)
+	case nil:
+		n.kind, n.value = "null", "null"
+	}
+	return n, nil
+}
+
+// compact returns the JSON text of an object or an array.
+func (n *jsonNode) compact() string {
+	var b bytes.Buffer
+	open, close := "{", "}"
+	if n.kind == "array" {
+		open, close = "[", "]"
+	}
+	b.WriteString(open)
+	for i, c := range n.children {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		if n.kind == "object" {
+			b.WriteString(quote(c.key) + ":")
+		}
+		if c.kind == "string" {
+			b.WriteString(quote(c.value))
+		} else {
+			b.WriteString(c.value)
+		}
+	}
+	b.WriteString(close)
+	return b.String()
+}
+
+// quote returns the string as a JSON string.
+func quote(s string) string {
+	v, _ := json.Marshal(s) // strings are always marshalled.
+	return string(v)
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// pathKey returns the part of a path selecting the key of an object.
+func pathKey(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	return "[" + quote(key) + "]"
+}
+
+// jsonError returns the error with the line and the column it happened on, if
+// it is known.
+func jsonError(text string, err error) string {
+	e, ok := err.(*json.SyntaxError)
+	if !ok || e.Offset <= 0 || int(e.Offset) > len(text) {
+		return err.Error()
+	}
+	before := text[:e.Offset]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	return fmt.Sprintf("line %d, column %d: %s", line, column, err)
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestParseJSON(t *testing.T) {
+	root, err := parseJSON(`{"b": 1, "a": [true, null, "x\"y"], "a key": {"c": 1.50}}`)
+	if err != nil {
+		t.Fatalf("parseJSON() error = %v", err)
+	}
+	var got []string
+	var walk func(n *jsonNode)
+	walk = func(n *jsonNode) {
+		got = append(got, n.path+" "+n.kind+" "+n.summary())
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(root)
+	want := []string{
+		`$ object {3}`,
+		`$.b number 1`,
+		`$.a array [3]`,
+		`$.a[0] bool true`,
+		`$.a[1] null null`,
+		`$.a[2] string "x\"y"`,
+		`$["a key"] object {1}`,
+		`$["a key"].c number 1.50`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("nodes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if want := `{"b":1,"a":[true,null,"x\"y"],"a key":{"c":1.50}}`; root.value != want {
+		t.Errorf("root.value = %s, want %s", root.value, want)
+	}
+	if got := root.children[2].children[0].value; got != "1.50" {
+		t.Errorf("value = %q, want 1.50", got)
+	}
+
+	for _, text := range []string{`{"a": }`, `[1, 2`, `{} {}`, `{"a" 1}`} {
+		if _, err := parseJSON(text); err == nil {
+			t.Errorf("parseJSON(%q): want an error", text)
+		}
+	}
+}
+
+func TestJSONError(t *testing.T) {
+	text := "{\n  \"a\": 1,\n  \"b\": x\n}"
+	_, err := parseJSON(text)
+	if err == nil {
+		t.Fatal("parseJSON(): want an error")
+	}
+	if got := jsonError(text, err); !strings.HasPrefix(got, "line 3, column ") {
+		t.Errorf("jsonError() = %q, want it on line 3", got)
+	}
+	if got := jsonError(text, errTrailingData); got != errTrailingData.Error() {
+		t.Errorf("jsonError() = %q, want %q", got, errTrailingData)
+	}
+}
+
+func TestTree(t *testing.T) { tRunner.Run(func() { testTree(t) }) }
+func testTree(t *testing.T) {
+	tree := NewTree(widgets.NewQWidget(nil, 0), 0)
+	tree.ShowText(`{"items": [{"name": "a"}, {"name": "b"}], "count": 2}`)
+	view := tree.View()
+	if view.TopLevelItemCount() != 1 {
+		t.Fatalf("TopLevelItemCount() = %d, want 1", view.TopLevelItemCount())
+	}
+	root := view.TopLevelItem(0)
+	if !root.IsExpanded() || root.ChildCount() != 2 {
+		t.Fatalf("root expanded %t with %d children, want expanded with 2", root.IsExpanded(), root.ChildCount())
+	}
+	items := root.Child(0)
+	if items.Text(0) != "items" || items.Text(1) != "[2]" || items.IsExpanded() {
+		t.Errorf("items = %q %q expanded %t, want a collapsed [2]", items.Text(0), items.Text(1), items.IsExpanded())
+	}
+	items.SetExpanded(true)
+	items.Child(1).SetExpanded(true)
+
+	var copied []string
+	tree.ConnectCopyToClipboard(func(text string) { copied = append(copied, text) })
+	view.SetCurrentItem(items.Child(1).Child(0))
+	for _, a := range view.Actions() {
+		a.Trigger()
+	}
+	if strings.Join(copied, " ") != "$.items[1].name b" {
+		t.Errorf("copied = %q, want the path and the value", copied)
+	}
+
+	tree.ShowText(`{"items": [{"name": "a"}, {"name": "c"}, {"name": "d"}], "count": 3}`)
+	items = view.TopLevelItem(0).Child(0)
+	if !items.IsExpanded() || !items.Child(1).IsExpanded() || items.Child(2).IsExpanded() {
+		t.Error("the expanded values were collapsed after the text changed")
+	}
+
+	tree.ShowText(`{"items": `)
+	if tree.errorLabel.IsHidden() {
+		t.Error("the error is not shown for an invalid text")
+	}
+	if view.TopLevelItemCount() != 1 {
+		t.Error("the values were removed for an invalid text")
+	}
+	tree.ShowText("")
+	if view.TopLevelItemCount() != 0 || !tree.errorLabel.IsHidden() {
+		t.Error("an empty text is not shown as an empty tree")
+	}
+}
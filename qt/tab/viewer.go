@@ -0,0 +1,127 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import (
+	"path"
+	"strings"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+// viewDelay is how long the typing should pause, in milliseconds, before the
+// viewer shows the contents again.
+const viewDelay = 300
+
+// Viewer is an alternate view of the contents of a file. Viewers can also have
+// these methods, which the file connects to:
+//
+//	ConnectTextEdited(func(text string))  // the viewer changed the contents.
+//	ConnectOpenLink(func(link string))    // a link was clicked.
+//	ConnectCopyToClipboard(func(string))  // a text should be copied.
+//	ScrollToLine(line int)                // follows the editor as it scrolls.
+//	SetFileName(name string)              // the file was renamed.
+type Viewer interface {
+	widgets.QWidget_ITF
+	// ShowText shows the contents, keeping the viewer where it was.
+	ShowText(text string)
+}
+
+// viewKind is a viewer for the files it matches. If replaces is true, the
+// viewer is shown instead of the editor, otherwise next to it. If initial is
+// true, the viewer is shown when the file is opened.
+type viewKind struct {
+	label    string
+	tip      string
+	replaces bool
+	initial  bool
+	match    func(name string) bool
+	create   func(parent widgets.QWidget_ITF, name string) Viewer
+}
+
+// viewKinds are checked in order for the first one matching a file.
+var viewKinds = []*viewKind{
+	{
+		label:  "Preview",
+		tip:    "Shows the rendered Markdown next to the contents",
+		match:  isMarkdown,
+		create: func(parent widgets.QWidget_ITF, _ string) Viewer { return NewPreview(parent) },
+	},
+	{
+		label:    "Table",
+		tip:      "Shows the rows in a table that can be sorted, filtered and edited",
+		replaces: true,
+		match:    isDelimited,
+		create: func(parent widgets.QWidget_ITF, name string) Viewer {
+			t := NewTable(parent, 0)
+			t.SetFileName(name)
+			return t
+		},
+	},
+	{
+		label:    "Tree",
+		tip:      "Shows the values in a tree. Right click on a value to copy its path",
+		replaces: true,
+		match:    isJSON,
+		create:   func(parent widgets.QWidget_ITF, _ string) Viewer { return NewTree(parent, 0) },
+	},
+	{
+		label:    "Notebook",
+		tip:      "Shows the rendered notebook. Click again to edit its JSON",
+		replaces: true,
+		initial:  true,
+		match:    isNotebook,
+		create:   func(parent widgets.QWidget_ITF, _ string) Viewer { return NewNotebook(parent) },
+	},
+}
+
+// viewKindFor returns the viewer of the file, or nil if it has none.
+func viewKindFor(name string) *viewKind {
+	for _, k := range viewKinds {
+		if k.match(name) {
+			return k
+		}
+	}
+	return nil
+}
+
+// extension returns the extension of the file in lower case, ignoring the one
+// added to encrypted files.
+func extension(name string) string {
+	return strings.ToLower(path.Ext(strings.TrimSuffix(name, gist.EncryptedExt)))
+}
+
+// isMarkdown returns true if the file can be previewed.
+func isMarkdown(name string) bool {
+	switch extension(name) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// isDelimited returns true if the file has comma or tab separated values.
+func isDelimited(name string) bool {
+	switch extension(name) {
+	case ".csv", ".tsv", ".tab":
+		return true
+	}
+	return false
+}
+
+// isJSON returns true if the file can be shown as a tree.
+func isJSON(name string) bool {
+	switch extension(name) {
+	case ".json", ".geojson":
+		return true
+	}
+	return false
+}
+
+// isNotebook returns true if the file is a Jupyter notebook.
+func isNotebook(name string) bool {
+	return extension(name) == ".ipynb"
+}
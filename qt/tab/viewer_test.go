@@ -0,0 +1,35 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package tab
+
+import "testing"
+
+func TestViewKindFor(t *testing.T) {
+	tcs := map[string]string{
+		"README.md":       "Preview",
+		"notes.MARKDOWN":  "Preview",
+		"runbook.md.enc":  "Preview",
+		"people.csv":      "Table",
+		"people.TSV":      "Table",
+		"people.tab.enc":  "Table",
+		"data.json":       "Tree",
+		"map.geojson":     "Tree",
+		"analysis.ipynb":  "Notebook",
+		"main.go":         "",
+		"md":              "",
+		"README.md.go":    "",
+		"archive.enc":     "",
+		"notes.markdown~": "",
+	}
+	for name, want := range tcs {
+		got := ""
+		if k := viewKindFor(name); k != nil {
+			got = k.label
+		}
+		if got != want {
+			t.Errorf("viewKindFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
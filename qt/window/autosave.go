@@ -0,0 +1,150 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/conf"
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/therecipe/qt/core"
+)
+
+const (
+	errorMark          = "!" // added to the titles of the tabs that could not be auto-saved.
+	maxAutoSaveBackoff = 5 * time.Minute
+)
+
+// autoSaver pushes the changes of a tab to github when the typing pauses. The
+// edits made in the meantime are saved together.
+type autoSaver struct {
+	timer    *core.QTimer
+	update   func(*gist.Gist) error
+	failures int       // failed attempts since the last save.
+	err      error     // error of the last attempt.
+	saved    time.Time // time of the last auto-save.
+}
+
+// autoSaveDelay returns how long the typing should pause before auto-saving.
+func (m *MainWindow) autoSaveDelay() time.Duration {
+	seconds := conf.DefaultAutoSaveDelay
+	if m.settings != nil && m.settings.AutoSaveDelay > 0 {
+		seconds = m.settings.AutoSaveDelay
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// autoSaveBackoff returns how long to wait before trying again after the
+// number of failures. It doubles with each failure up to maxAutoSaveBackoff.
+func autoSaveBackoff(delay time.Duration, failures int) time.Duration {
+	for i := 0; i < failures && delay < maxAutoSaveBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxAutoSaveBackoff {
+		delay = maxAutoSaveBackoff
+	}
+	return delay
+}
+
+// autoSave saves the changes of the tab of the gist identified by id with the
+// update function, when auto-saving is turned on for it.
+func (m *MainWindow) autoSave(t *tab.Tab, id string, update func(*gist.Gist) error) {
+	a := &autoSaver{timer: core.NewQTimer(t), update: update}
+	a.timer.SetSingleShot(true)
+	m.autoSavers[t] = a
+	t.SetAutoSave(m.settings != nil && m.settings.AutoSaveGist(id))
+
+	a.timer.ConnectTimeout(func() {
+		if m.autoSavers[t] == a {
+			m.runAutoSave(t, a)
+		}
+	})
+	t.ConnectChanged(func() {
+		if !t.AutoSave() || !t.Dirty() {
+			return
+		}
+		if a.failures > 0 && a.timer.IsActive() {
+			// backing off. The retry saves these changes too.
+			return
+		}
+		a.timer.Start(int(m.autoSaveDelay() / time.Millisecond))
+		m.updateTabTitle(t)
+	})
+	t.ConnectDirtyChanged(func(dirty bool) {
+		if !dirty {
+			// saved by hand, or the changes were reverted.
+			a.timer.Stop()
+			a.failures, a.err = 0, nil
+			m.updateTabTitle(t)
+		}
+	})
+	t.ConnectAutoSaveToggled(func(on bool) {
+		if m.settings != nil {
+			m.settings.SetAutoSaveGist(id, on)
+		}
+		a.timer.Stop()
+		a.failures, a.err = 0, nil
+		if on && t.Dirty() {
+			a.timer.Start(int(m.autoSaveDelay() / time.Millisecond))
+		}
+		m.updateTabTitle(t)
+	})
+}
+
+// runAutoSave saves the changes of the tab. On errors it tries again later,
+// waiting longer each time, unless the gist might contain secrets.
+func (m *MainWindow) runAutoSave(t *tab.Tab, a *autoSaver) {
+	if !t.AutoSave() || !t.Dirty() {
+		return
+	}
+	g, err := t.AutoSaveGist()
+	if err == nil {
+		err = a.update(g)
+	}
+	a.err = err
+	switch {
+	case err == tab.ErrSecrets:
+		// waiting for the user to check them.
+		a.failures = 0
+	case err != nil:
+		a.failures++
+		a.timer.Start(int(autoSaveBackoff(m.autoSaveDelay(), a.failures) / time.Millisecond))
+	default:
+		a.failures = 0
+		a.saved = time.Now()
+		t.Saved()
+	}
+	m.updateTabTitle(t)
+}
+
+// stopAutoSave stops auto-saving the tab, which is being closed.
+func (m *MainWindow) stopAutoSave(t *tab.Tab) {
+	if a, ok := m.autoSavers[t]; ok {
+		a.timer.Stop()
+		delete(m.autoSavers, t)
+	}
+}
+
+// syncStatus returns the auto-save status of the tab, to be shown as the
+// tooltip of its title.
+func (m *MainWindow) syncStatus(t *tab.Tab) string {
+	a, ok := m.autoSavers[t]
+	if !ok || !t.AutoSave() {
+		return ""
+	}
+	switch {
+	case a.err == tab.ErrSecrets:
+		return "Not auto-saved, as the gist might contain secrets. Save it to check them."
+	case a.err != nil:
+		return fmt.Sprintf("Auto-save failed: %s\nTrying again in %s", a.err, autoSaveBackoff(m.autoSaveDelay(), a.failures))
+	case t.Dirty():
+		return "Auto-saves when you stop typing"
+	case !a.saved.IsZero():
+		return "Auto-saved at " + a.saved.Format("15:04:05")
+	}
+	return "Auto-save is on"
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// These are the actions of the gist list's context menu. Most of them work on
+// all selected gists.
+
+// failure is a gist that an operation on many gists could not handle.
+type failure struct {
+	id  string
+	err error
+}
+
+func (m *MainWindow) setupGistListActions() {
+	m.gistList.ConnectOpenGists(m.openGists)
+	m.gistList.ConnectOpenInBrowser(m.openGistInBrowser)
+	m.gistList.ConnectCopyURL(m.copyGistURL)
+	m.gistList.ConnectCopyIDs(func(ids []string) {
+		m.clipboard().SetText(strings.Join(ids, "\n"), gui.QClipboard__Clipboard)
+		m.showNotification(fmt.Sprintf("%s copied to clipboard", plural(len(ids), "ID has", "IDs have")))
+	})
+	m.gistList.ConnectCloneGist(m.cloneGist)
+	m.gistList.ConnectStarGists(m.starGists)
+	m.gistList.ConnectExportGists(m.exportGists)
+	m.gistList.ConnectDeleteGists(m.deleteGists)
+}
+
+// openGists opens a tab for each gist.
+func (m *MainWindow) openGists(ids []string) {
+	for _, id := range ids {
+		if err := m.openGist(id); err != nil {
+			m.logger.Error(err.Error())
+		}
+	}
+}
+
+// cloneGist creates a copy of the latest version of the gist with the same
+// visibility, and opens it. Encrypted gists stay encrypted.
+func (m *MainWindow) cloneGist(id string) {
+	remote, err := m.gistService.Fetch(id)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	clone, err := m.gistService.Clone(remote, remote.Public, false)
+	if err != nil {
+		msg := fmt.Sprintf("Could not clone the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.searchbox.Add(clone)
+	m.gistList.Add(clone)
+	if err := m.openGist(clone.ID); err != nil {
+		msg := fmt.Sprintf("Opening gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.showNotification("Gist has been cloned")
+}
+
+// starGists stars or unstars the gists.
+func (m *MainWindow) starGists(ids []string, star bool) {
+	doing, done, fn := "Starring", "starred", m.gistService.Star
+	if !star {
+		doing, done, fn = "Unstarring", "unstarred", m.gistService.Unstar
+	}
+	for _, id := range m.bulk(doing, done, ids, fn) {
+		m.searchbox.MarkStarred(id, star)
+	}
+}
+
+// deleteGists deletes the gists after the user confirms.
+func (m *MainWindow) deleteGists(ids []string) {
+	msg := fmt.Sprintf("Are you sure you want to delete %s? This action is irreversible.", plural(len(ids), "gist", "gists"))
+	if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+		return
+	}
+	m.bulk("Deleting", "deleted", ids, m.deleteGist)
+}
+
+// exportGists writes the files of the gists into a directory the user
+// chooses. Each gist goes into its own directory, and encrypted gists are
+// decrypted.
+func (m *MainWindow) exportGists(ids []string) {
+	dir, ok := m.askDir("Export Gists")
+	if !ok {
+		return
+	}
+	m.bulk("Exporting", "exported", ids, func(id string) error {
+		g, err := m.fetchPlain(id)
+		if err != nil {
+			return err
+		}
+		return gist.Export(g, dir)
+	})
+}
+
+// bulk runs fn on each gist while showing the progress, until the user
+// cancels. The result is shown as a notification, or as a warning with the
+// errors if any of the gists failed. It returns the IDs of the gists that
+// were done.
+func (m *MainWindow) bulk(doing, done string, ids []string, fn func(id string) error) []string {
+	progress := widgets.NewQProgressDialog2(fmt.Sprintf("%s %s...", doing, plural(len(ids), "gist", "gists")), "Cancel", 0, len(ids), m, 0)
+	defer progress.DestroyQProgressDialog()
+	progress.SetWindowModality(core.Qt__WindowModal)
+	progress.SetMinimumDuration(500)
+
+	var (
+		succeeded []string
+		failed    []failure
+	)
+	for i, id := range ids {
+		if progress.WasCanceled() {
+			break
+		}
+		progress.SetValue(i)
+		if err := fn(id); err != nil {
+			failed = append(failed, failure{id: id, err: err})
+			continue
+		}
+		succeeded = append(succeeded, id)
+	}
+	progress.SetValue(len(ids))
+
+	msg := summary(done, len(ids), len(succeeded), failed)
+	if len(failed) > 0 {
+		m.logger.Warning(msg)
+	} else {
+		m.showNotification(msg)
+	}
+	return succeeded
+}
+
+// summary describes the result of an operation on total gists, with the
+// errors of the failed ones.
+func summary(done string, total, succeeded int, failed []failure) string {
+	if succeeded == total {
+		return fmt.Sprintf("%s been %s", plural(total, "gist has", "gists have"), done)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%d of %s been %s.", succeeded, plural(total, "gist has", "gists have"), done)
+	if len(failed) > 0 {
+		fmt.Fprintf(buf, " %d failed.", len(failed))
+	}
+	if skipped := total - succeeded - len(failed); skipped > 0 {
+		fmt.Fprintf(buf, " %d cancelled.", skipped)
+	}
+	if len(failed) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, f := range failed {
+		fmt.Fprintf(buf, "\n%s: %s", f.id, f.err)
+	}
+	return buf.String()
+}
+
+// plural returns n with the singular or the plural form of the noun.
+func plural(n int, singular, plurals string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plurals)
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// bulkServer serves the gists, and fails any requests for the broken one.
+func bulkServer(gists map[string]gist.Gist, broken string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gists/")
+		id := strings.TrimSuffix(path, "/star")
+		g, ok := gists[id]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+		case id == broken:
+			w.WriteHeader(http.StatusInternalServerError)
+		case path != id || r.Method == http.MethodDelete:
+			if path == id {
+				delete(gists, id)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			json.NewEncoder(w).Encode(g)
+		}
+	}))
+}
+
+func TestBulkActions(t *testing.T) { tRunner.Run(func() { testBulkActions(t) }) }
+func testBulkActions(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Description: "alpha", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+		"b2": {ID: "b2", Description: "beta", Files: map[string]gist.File{"b.go": {Content: "package b"}}},
+		"c3": {ID: "c3", Description: "gamma", Files: map[string]gist.File{"c.go": {Content: "package c"}}},
+	}
+	ts := bulkServer(gists, "b2")
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var warnings []string
+	window.logger = &logger{
+		errorFunc:    func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc:  func(msg string) { warnings = append(warnings, msg) },
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton { return widgets.QMessageBox__Ok },
+	}
+	for _, id := range []string{"a1", "b2", "c3"} {
+		window.gistList.Add(gists[id])
+		window.searchbox.Add(gists[id])
+	}
+	ids := []string{"a1", "b2", "c3"}
+
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	window.askDir = func(string) (string, bool) { return dir, true }
+	window.gistList.ExportGists(ids)
+	for id, name := range map[string]string{"a1": "a.go", "c3": "c.go"} {
+		if _, err := os.Stat(filepath.Join(dir, id, name)); err != nil {
+			t.Errorf("%s was not exported: %v", id, err)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "2 of 3 gists have been exported") || !strings.Contains(warnings[0], "b2:") {
+		t.Errorf("warnings = %q, want a summary with the failed gist", warnings)
+	}
+
+	warnings = nil
+	window.gistList.StarGists([]string{"a1", "c3"}, true)
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %q, want none", warnings)
+	}
+
+	var copied string
+	window.clipboard = func() clipboard {
+		return &fakeClipboard{textFunc: func(text string, mode gui.QClipboard__Mode) { copied = text }}
+	}
+	for i := 0; i < window.gistList.Count(); i++ {
+		id := window.gistList.ID(i)
+		window.gistList.Item(i).SetSelected(id == "a1" || id == "c3")
+	}
+	for _, c := range window.commands.Commands() {
+		if c.Name() == "Gist List: Copy ID" {
+			c.Action.Trigger()
+		}
+	}
+	if copied != "a1\nc3" {
+		t.Errorf("copied = %q, want the selected IDs copied from the command", copied)
+	}
+
+	warnings = nil
+	window.gistList.DeleteGists(ids)
+	if window.gistList.HasID("a1") || window.gistList.HasID("c3") {
+		t.Error("deleted gists are still listed")
+	}
+	if !window.gistList.HasID("b2") || !window.searchbox.HasID("b2") {
+		t.Error("the gist that failed to be deleted was removed")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "1 failed") {
+		t.Errorf("warnings = %q, want a summary with the failed gist", warnings)
+	}
+}
+
+func TestCloneGist(t *testing.T) { tRunner.Run(func() { testCloneGist(t) }) }
+func testCloneGist(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Description: "alpha", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(string) {},
+	}
+	if _, err := window.gistService.Get("a1"); err != nil {
+		t.Fatal(err)
+	}
+	g := gists["a1"]
+	g.Files = map[string]gist.File{"a.go": {Content: "package fresh"}}
+	gists["a1"] = g
+
+	window.cloneGist("a1")
+	clone, ok := gists["new1"]
+	if !ok {
+		t.Fatal("the gist was not cloned")
+	}
+	if got := clone.Files["a.go"].Content; got != "package fresh" {
+		t.Errorf("content = %q, want the latest content", got)
+	}
+	if _, ok := window.tabGistList["new1"]; !ok {
+		t.Error("the clone is not opened")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	errBoom := errors.New("boom")
+	tcs := []struct {
+		total, succeeded int
+		failed           []failure
+		want             string
+	}{
+		{1, 1, nil, "1 gist has been deleted"},
+		{3, 3, nil, "3 gists have been deleted"},
+		{3, 2, []failure{{"b2", errBoom}}, "2 of 3 gists have been deleted. 1 failed.\n\nb2: boom"},
+		{3, 1, nil, "1 of 3 gists have been deleted. 2 cancelled."},
+	}
+	for _, tc := range tcs {
+		if got := summary("deleted", tc.total, tc.succeeded, tc.failed); got != tc.want {
+			t.Errorf("summary(%d, %d) = %q, want %q", tc.total, tc.succeeded, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"testing"
+
+	"github.com/arsham/gistflow/qt/conf"
+)
+
+func TestUnlockCache(t *testing.T) { tRunner.Run(func() { testUnlockCache(t) }) }
+func testUnlockCache(t *testing.T) {
+	var (
+		asked      int
+		warned     bool
+		passphrase = "Vb7ejTaQ"
+	)
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.settings = &conf.Settings{}
+	window.passphrase = func(string) (string, bool) {
+		asked++
+		return passphrase, true
+	}
+
+	window.unlockCache()
+	if asked != 0 {
+		t.Error("asked for passphrase when the cache is not encrypted")
+	}
+
+	window.settings.EncryptCache = true
+	window.passphrase = func(string) (string, bool) {
+		asked++
+		return "", false
+	}
+	window.unlockCache()
+	if asked != 1 || window.settings.EncryptCache {
+		t.Errorf("asked = %d, EncryptCache = %t, want encrypting turned off after cancelling", asked, window.settings.EncryptCache)
+	}
+	if window.gistService.Cache().Encrypted() {
+		t.Error("cache is encrypted without a passphrase")
+	}
+
+	asked = 0
+	window.passphrase = func(string) (string, bool) {
+		asked++
+		return passphrase, true
+	}
+
+	window.settings.EncryptCache = true
+	window.unlockCache()
+	if asked != 1 {
+		t.Errorf("asked = %d, want 1", asked)
+	}
+	if window.gistService.CacheKey == nil {
+		t.Error("window.gistService.CacheKey = nil, want key")
+	}
+	if !window.gistService.Cache().Encrypted() {
+		t.Error("cache is not encrypted")
+	}
+
+	asked = 0
+	window.gistService.CacheKey = nil
+	window.passphrase = func(string) (string, bool) {
+		asked++
+		return "wrong", true
+	}
+	window.logger = &logger{
+		warningFunc: func(string) { warned = true },
+	}
+	window.unlockCache()
+	if asked != unlockAttempts {
+		t.Errorf("asked = %d, want %d", asked, unlockAttempts)
+	}
+	if window.gistService.CacheKey != nil {
+		t.Error("cache was unlocked with a wrong passphrase")
+	}
+	if !warned {
+		t.Error("didn't warn about the locked cache")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+const (
+	dirtyMark    = "*" // added to the titles of the tabs with unsaved changes.
+	trayIconSize = 64
+	trayToolTip  = "GistFlow"
+)
+
+// watchDirty marks the title of the tab and the tray icon when the tab has
+// unsaved changes.
+func (m *MainWindow) watchDirty(t *tab.Tab) {
+	t.ConnectDirtyChanged(func(bool) {
+		m.updateTabTitle(t)
+		m.updateTrayBadge()
+	})
+}
+
+// updateTabTitle marks the title of the tab when it has unsaved changes, or it
+// could not be auto-saved, and shows its auto-save status as the tooltip.
+func (m *MainWindow) updateTabTitle(t *tab.Tab) {
+	index := m.tabsWidget.IndexOf(t)
+	if index < 0 {
+		return
+	}
+	title := m.tabTitle(t)
+	if t.Dirty() {
+		title += dirtyMark
+	}
+	if a, ok := m.autoSavers[t]; ok && a.err != nil && t.AutoSave() {
+		title += errorMark
+	}
+	m.tabsWidget.SetTabText(index, title)
+	m.tabsWidget.SetTabToolTip(index, m.syncStatus(t))
+}
+
+// tabTitle returns the title of the tab without the marks.
+func (m *MainWindow) tabTitle(t *tab.Tab) string {
+	title := m.tabsWidget.TabText(m.tabsWidget.IndexOf(t))
+	return strings.TrimSuffix(strings.TrimSuffix(title, errorMark), dirtyMark)
+}
+
+// dirtyTabs returns the tabs with unsaved changes in the order they are shown.
+func (m *MainWindow) dirtyTabs() []*tab.Tab {
+	var tabs []*tab.Tab
+	for i := 0; i < m.tabsWidget.Count(); i++ {
+		if t, ok := m.tabGistList[m.tabIDFromIndex(i)]; ok && t.Dirty() {
+			tabs = append(tabs, t)
+		}
+	}
+	return tabs
+}
+
+// confirmClose asks the user whether to save the changes of the tab before
+// closing it. It returns false if the tab should stay open, which is also the
+// case when saving is cancelled or fails.
+func (m *MainWindow) confirmClose(t *tab.Tab) bool {
+	if !t.Dirty() {
+		return true
+	}
+	msg := fmt.Sprintf("%s has unsaved changes. Do you want to save them before closing?", m.tabTitle(t))
+	switch m.askSave(msg) {
+	case widgets.QMessageBox__Save:
+		return m.saveTab(t)
+	case widgets.QMessageBox__Discard:
+		return true
+	}
+	return false
+}
+
+// confirmQuit asks the user whether to save the changes of all tabs before
+// quitting. It returns false if the application should keep running. If a
+// gist is not saved, its tab is shown.
+func (m *MainWindow) confirmQuit() bool {
+	tabs := m.dirtyTabs()
+	if len(tabs) == 0 {
+		return true
+	}
+	titles := make([]string, len(tabs))
+	for i, t := range tabs {
+		titles[i] = m.tabTitle(t)
+	}
+	msg := fmt.Sprintf("These gists have unsaved changes:\n\n%s\n\nDo you want to save them before quitting?", strings.Join(titles, "\n"))
+	switch m.askSave(msg) {
+	case widgets.QMessageBox__Save:
+		for _, t := range tabs {
+			if !m.saveTab(t) {
+				m.tabsWidget.SetCurrentWidget(t)
+				return false
+			}
+		}
+		return true
+	case widgets.QMessageBox__Discard:
+		for _, t := range tabs {
+			m.releaseTab(t)
+		}
+		return true
+	}
+	return false
+}
+
+// saveTab saves the changes of the tab, and returns true if they are saved on
+// github. The tab keeps its changes until the gist is updated, so it is still
+// dirty when the review is cancelled or the update fails.
+func (m *MainWindow) saveTab(t *tab.Tab) bool {
+	t.SaveButton().Click()
+	return !t.Dirty()
+}
+
+// updateTrayBadge shows a dot on the tray icon while there are unsaved
+// changes.
+func (m *MainWindow) updateTrayBadge() {
+	n := len(m.dirtyTabs())
+	if n == 0 {
+		m.sysTray.SetIcon(m.icon)
+		m.sysTray.SetToolTip(trayToolTip)
+		return
+	}
+	if m.badgeIcon == nil {
+		m.badgeIcon = badgeIcon(m.icon)
+	}
+	m.sysTray.SetIcon(m.badgeIcon)
+	m.sysTray.SetToolTip(fmt.Sprintf("%s (%d unsaved)", trayToolTip, n))
+}
+
+// badgeIcon returns the icon with a dot on its top right corner.
+func badgeIcon(icon *gui.QIcon) *gui.QIcon {
+	pixmap := icon.Pixmap(core.NewQSize2(trayIconSize, trayIconSize), gui.QIcon__Normal, gui.QIcon__Off)
+	if pixmap.IsNull() {
+		pixmap = gui.NewQPixmap3(trayIconSize, trayIconSize)
+		pixmap.Fill(gui.NewQColor2(core.Qt__transparent))
+	}
+	colour := gui.NewQColor6("#e36209")
+	painter := gui.NewQPainter2(pixmap)
+	painter.SetRenderHint(gui.QPainter__Antialiasing, true)
+	painter.SetPen3(colour)
+	painter.SetBrush(gui.NewQBrush3(colour, core.Qt__SolidPattern))
+	size := float64(trayIconSize) * 3 / 8
+	painter.DrawEllipse(core.NewQRectF4(trayIconSize-size-1, 1, size, size))
+	painter.End()
+	return gui.NewQIcon2(pixmap)
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestCloseDirtyTab(t *testing.T) { tRunner.Run(func() { testCloseDirtyTab(t) }) }
+func testCloseDirtyTab(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var asked int
+	answer := widgets.QMessageBox__Cancel
+	window.askSave = func(msg string) widgets.QMessageBox__StandardButton {
+		asked++
+		if !strings.Contains(msg, "a.go") {
+			t.Errorf("the gist is not named in %q", msg)
+		}
+		return answer
+	}
+
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	tab := window.tabGistList["a1"]
+	index := window.tabsWidget.IndexOf(tab)
+	window.closeTab(index)
+	if asked != 0 {
+		t.Error("asked to save a tab without changes")
+	}
+	if _, ok := window.tabGistList["a1"]; ok {
+		t.Fatal("didn't close the tab")
+	}
+
+	window.openGist("a1")
+	tab = window.tabGistList["a1"]
+	index = window.tabsWidget.IndexOf(tab)
+	file := tab.Files()[0]
+	file.Content().SetPlainText("package b")
+	if !tab.Dirty() || !file.Dirty() {
+		t.Errorf("tab.Dirty() = %t, file.Dirty() = %t, want true", tab.Dirty(), file.Dirty())
+	}
+	if got := window.tabsWidget.TabText(index); got != "a.go"+dirtyMark {
+		t.Errorf("TabText() = %q, want %q", got, "a.go"+dirtyMark)
+	}
+	if got := window.sysTray.ToolTip(); got != trayToolTip+" (1 unsaved)" {
+		t.Errorf("sysTray.ToolTip() = %q", got)
+	}
+
+	window.closeTab(index)
+	if _, ok := window.tabGistList["a1"]; !ok || asked != 1 {
+		t.Fatalf("asked = %d, want the tab kept open after cancelling", asked)
+	}
+
+	tab.SetReview(func() bool { return true })
+	answer = widgets.QMessageBox__Save
+	window.closeTab(index)
+	if _, ok := window.tabGistList["a1"]; ok {
+		t.Error("didn't close the tab after saving")
+	}
+	if got := gists["a1"].Files["a.go"].Content; got != "package b" {
+		t.Errorf("saved content = %q, want %q", got, "package b")
+	}
+	if got := window.sysTray.ToolTip(); got != trayToolTip {
+		t.Errorf("sysTray.ToolTip() = %q, want %q", got, trayToolTip)
+	}
+
+	window.openGist("a1")
+	tab = window.tabGistList["a1"]
+	tab.Files()[0].Content().SetPlainText("package c")
+	answer = widgets.QMessageBox__Discard
+	window.closeTab(window.tabsWidget.IndexOf(tab))
+	if _, ok := window.tabGistList["a1"]; ok {
+		t.Error("didn't close the tab after discarding")
+	}
+	if got := gists["a1"].Files["a.go"].Content; got != "package b" {
+		t.Errorf("saved content = %q, want the discarded changes not saved", got)
+	}
+}
+
+func TestConfirmQuit(t *testing.T) { tRunner.Run(func() { testConfirmQuit(t) }) }
+func testConfirmQuit(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+		"b1": {ID: "b1", Files: map[string]gist.File{"b.go": {Content: "package b"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var msg string
+	answer := widgets.QMessageBox__Cancel
+	window.askSave = func(m string) widgets.QMessageBox__StandardButton {
+		msg = m
+		return answer
+	}
+	window.openGist("a1")
+	window.openGist("b1")
+	if !window.confirmQuit() || msg != "" {
+		t.Errorf("msg = %q, want to quit without asking", msg)
+	}
+
+	for _, id := range []string{"a1", "b1"} {
+		tab := window.tabGistList[id]
+		tab.SetReview(func() bool { return true })
+		tab.Files()[0].Content().SetPlainText("changed " + id)
+	}
+	if len(window.dirtyTabs()) != 2 {
+		t.Fatalf("len(dirtyTabs()) = %d, want 2", len(window.dirtyTabs()))
+	}
+	if window.confirmQuit() {
+		t.Error("quitting after cancelling")
+	}
+	if !strings.Contains(msg, "a.go") || !strings.Contains(msg, "b.go") {
+		t.Errorf("the gists are not named in %q", msg)
+	}
+
+	answer = widgets.QMessageBox__Save
+	if !window.confirmQuit() {
+		t.Error("not quitting after saving")
+	}
+	for _, id := range []string{"a1", "b1"} {
+		if window.tabGistList[id].Dirty() {
+			t.Errorf("%s is not saved", id)
+		}
+	}
+	if got := gists["b1"].Files["b.go"].Content; got != "changed b1" {
+		t.Errorf("saved content = %q, want %q", got, "changed b1")
+	}
+}
+
+// failUpdates makes the server respond to the updates with an error while the
+// returned value is true.
+func failUpdates(ts *httptest.Server) *bool {
+	fail := new(bool)
+	next := ts.Config.Handler
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *fail && r.Method == http.MethodPatch {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	return fail
+}
+
+func TestSaveFailedKeepsTab(t *testing.T) { tRunner.Run(func() { testSaveFailedKeepsTab(t) }) }
+func testSaveFailedKeepsTab(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	fail := failUpdates(ts)
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.askSave = func(string) widgets.QMessageBox__StandardButton {
+		return widgets.QMessageBox__Save
+	}
+
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	tab := window.tabGistList["a1"]
+	tab.SetReview(func() bool { return true })
+	tab.Files()[0].Content().SetPlainText("package b")
+
+	*fail = true
+	window.closeTab(window.tabsWidget.IndexOf(tab))
+	if _, ok := window.tabGistList["a1"]; !ok {
+		t.Fatal("closed the tab after the update failed")
+	}
+	if !tab.Dirty() {
+		t.Error("the changes are marked as saved after the update failed")
+	}
+	if window.confirmQuit() {
+		t.Error("quitting after the update failed")
+	}
+	if got := gists["a1"].Files["a.go"].Content; got != "package a" {
+		t.Errorf("saved content = %q, want the gist unchanged", got)
+	}
+
+	*fail = false
+	window.closeTab(window.tabsWidget.IndexOf(tab))
+	if _, ok := window.tabGistList["a1"]; ok {
+		t.Error("didn't close the tab after saving")
+	}
+	if got := gists["a1"].Files["a.go"].Content; got != "package b" {
+		t.Errorf("saved content = %q, want %q", got, "package b")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/therecipe/qt/core"
+)
+
+// draftDelay is how long the typing should pause, in milliseconds, before the
+// changes are written to the draft.
+const draftDelay = 1000
+
+// newDraftKey returns the key of the draft of a new gist.
+func newDraftKey() string {
+	return fmt.Sprintf("new-%d", time.Now().UnixNano())
+}
+
+// draftDir returns the directory of the drafts in the data location.
+func (m *MainWindow) draftDir() string {
+	loc := core.QStandardPaths_StandardLocations(core.QStandardPaths__GenericDataLocation)[0]
+	dir := path.Join(loc, m.name, "drafts")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		m.logger.Warningf("Creating drafts dir: %s", err)
+	}
+	return dir
+}
+
+// journal writes the unsaved changes of the tab to a draft under the key, a
+// short while after they are made. The draft is removed when the tab has no
+// unsaved changes anymore.
+func (m *MainWindow) journal(t *tab.Tab, key string) {
+	m.draftKeys[t] = key
+	timer := core.NewQTimer(t)
+	timer.SetSingleShot(true)
+	timer.ConnectTimeout(func() {
+		if _, ok := m.draftKeys[t]; ok {
+			m.writeDraft(t, key)
+		}
+	})
+	t.ConnectChanged(func() {
+		timer.Start(draftDelay)
+	})
+	t.ConnectDirtyChanged(func(dirty bool) {
+		if !dirty {
+			m.removeDraft(key)
+		}
+	})
+}
+
+// writeDraft keeps the unsaved changes of the tab on the disk. Errors are
+// shown in the status bar, as they would interrupt the typing otherwise. The
+// drafts of encrypted gists are only kept when they can be sealed with the
+// cache key, so their contents are never written in plain text.
+func (m *MainWindow) writeDraft(t *tab.Tab, key string) {
+	if m.gistService.DraftDir == "" {
+		return
+	}
+	if !t.Dirty() || (t.Encrypted() && len(m.gistService.CacheKey) == 0) {
+		m.removeDraft(key)
+		return
+	}
+	if err := m.gistService.Drafts().Save(t.Draft(key)); err != nil {
+		m.statusArea.ShowMessage(fmt.Sprintf("Could not write the draft: %s", err), 5000)
+	}
+}
+
+func (m *MainWindow) removeDraft(key string) {
+	if m.gistService.DraftDir == "" {
+		return
+	}
+	if err := m.gistService.Drafts().Remove(key); err != nil {
+		m.statusArea.ShowMessage(fmt.Sprintf("Could not remove the draft: %s", err), 5000)
+	}
+}
+
+// dropDraft stops journaling the tab and removes its draft.
+func (m *MainWindow) dropDraft(t *tab.Tab) {
+	key, ok := m.draftKeys[t]
+	if !ok {
+		return
+	}
+	delete(m.draftKeys, t)
+	m.removeDraft(key)
+}
+
+// restoreDrafts offers to restore the drafts left from the last session, and
+// returns the dialog, or nil if there are no drafts. The drafts are compared
+// with the gists on the server.
+func (m *MainWindow) restoreDrafts() *tab.Recovery {
+	drafts, err := m.gistService.Drafts().Load()
+	if err == gist.ErrDraftLocked {
+		m.logger.Warning("Some drafts are encrypted with another key and can't be restored")
+	} else if err != nil {
+		m.logger.Warningf("Reading drafts: %s", err)
+	}
+	if len(drafts) == 0 {
+		return nil
+	}
+	gists := make(map[string]*gist.Gist)
+	for _, d := range drafts {
+		if d.GistID == "" || gists[d.GistID] != nil {
+			continue
+		}
+		g, err := m.gistService.Fetch(d.GistID)
+		if err == nil && g.Encrypted() {
+			g, err = m.decryptGist(g)
+		}
+		if err == nil {
+			gists[d.GistID] = &g
+		}
+	}
+
+	r := tab.NewRecovery(m, 0)
+	r.SetDrafts(drafts, gists)
+	r.ConnectRestoreDraft(func(key string) {
+		for _, d := range drafts {
+			if d.Key == key {
+				m.restoreDraft(d)
+				return
+			}
+		}
+	})
+	r.ConnectDiscardDraft(m.removeDraft)
+	r.Show()
+	return r
+}
+
+// restoreDraft opens the gist of the draft and puts the draft in its tab. If
+// the gist can't be opened, the draft is restored as a new gist.
+func (m *MainWindow) restoreDraft(d gist.Draft) {
+	var t *tab.Tab
+	if d.GistID != "" {
+		if err := m.openGist(d.GistID); err != nil {
+			m.logger.Warningf("The draft is restored as a new gist, as the gist can't be opened: %s", err)
+		} else {
+			t = m.tabGistList[d.GistID]
+		}
+	}
+	if t == nil {
+		t = m.newGistTab(d.Key)
+		for i := range d.Files {
+			d.Files[i].SavedName = ""
+		}
+	}
+	t.RestoreDraft(d)
+	if m.draftKeys[t] != d.Key {
+		m.removeDraft(d.Key)
+	}
+	m.tabsWidget.SetCurrentWidget(t)
+}
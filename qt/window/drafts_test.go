@@ -0,0 +1,194 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func draftKeys(t *testing.T, window *MainWindow) []string {
+	drafts, err := window.gistService.Drafts().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, len(drafts))
+	for i, d := range drafts {
+		keys[i] = d.Key
+	}
+	return keys
+}
+
+func TestJournal(t *testing.T) { tRunner.Run(func() { testJournal(t) }) }
+func testJournal(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	dir, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	window.gistService.DraftDir = dir
+
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	tab := window.tabGistList["a1"]
+	window.writeDraft(tab, "a1")
+	if keys := draftKeys(t, window); len(keys) != 0 {
+		t.Errorf("drafts = %v, want none for a tab without changes", keys)
+	}
+
+	tab.Files()[0].Content().SetPlainText("package b")
+	window.writeDraft(tab, "a1")
+	drafts, err := window.gistService.Drafts().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 1 || drafts[0].GistID != "a1" || drafts[0].Files[0].Content != "package b" {
+		t.Fatalf("drafts = %v, want the changes of the tab", drafts)
+	}
+
+	tab.SetReview(func() bool { return true })
+	tab.SaveButton().Click()
+	if keys := draftKeys(t, window); len(keys) != 0 {
+		t.Errorf("drafts = %v, want the draft removed after saving", keys)
+	}
+
+	tab.Files()[0].Content().SetPlainText("package c")
+	window.writeDraft(tab, "a1")
+	window.askSave = func(string) widgets.QMessageBox__StandardButton {
+		return widgets.QMessageBox__Discard
+	}
+	window.closeTab(window.tabsWidget.IndexOf(tab))
+	if keys := draftKeys(t, window); len(keys) != 0 {
+		t.Errorf("drafts = %v, want the draft removed after discarding", keys)
+	}
+	if _, ok := window.draftKeys[tab]; ok {
+		t.Error("the closed tab is still journaled")
+	}
+}
+
+func TestRestoreDrafts(t *testing.T) { tRunner.Run(func() { testRestoreDrafts(t) }) }
+func testRestoreDrafts(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	dir, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	window.gistService.DraftDir = dir
+
+	if r := window.restoreDrafts(); r != nil {
+		t.Error("offered to restore without drafts")
+	}
+	drafts := []gist.Draft{
+		{Key: "a1", GistID: "a1", Files: []gist.DraftFile{{Name: "a.go", SavedName: "a.go", Content: "package b"}}},
+		{Key: "new-1", Description: "scratch", Files: []gist.DraftFile{{Name: "n.go", Content: "package n"}}},
+		{Key: "gone", GistID: "gone", Files: []gist.DraftFile{{Name: "g.go", SavedName: "g.go", Content: "package g"}}},
+	}
+	for _, d := range drafts {
+		if err := window.gistService.Drafts().Save(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	window.restoreDraft(drafts[0])
+	tab := window.tabGistList["a1"]
+	if tab == nil || !tab.Dirty() {
+		t.Fatal("the draft of the gist is not restored in its tab")
+	}
+	if got := tab.Files()[0].Content().ToPlainText(); got != "package b" {
+		t.Errorf("content = %q, want %q", got, "package b")
+	}
+
+	window.restoreDraft(drafts[1])
+	newTab := window.tabGistList["untitled"]
+	if newTab == nil || window.draftKeys[newTab] != "new-1" {
+		t.Fatal("the new gist is not restored under its key")
+	}
+	if !newTab.Dirty() {
+		t.Error("the restored new gist is not dirty")
+	}
+
+	window.restoreDraft(drafts[2])
+	if _, ok := window.tabGistList["gone"]; ok {
+		t.Error("opened a tab for a missing gist")
+	}
+	current := window.tabsWidget.CurrentIndex()
+	if id := window.tabIDFromIndex(current); id == "a1" || id == "untitled" {
+		t.Errorf("current tab = %q, want the draft of the missing gist in a new tab", id)
+	}
+}
+
+func TestJournalEncrypted(t *testing.T) { tRunner.Run(func() { testJournalEncrypted(t) }) }
+func testJournalEncrypted(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	dir, err := ioutil.TempDir("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	window.gistService.DraftDir = dir
+
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	tab := window.tabGistList["a1"]
+	tab.SetEncrypted(true)
+	tab.Files()[0].Content().SetPlainText("secret words")
+	window.writeDraft(tab, "a1")
+	if keys := draftKeys(t, window); len(keys) != 0 {
+		t.Errorf("drafts = %v, want none for an encrypted gist without the cache key", keys)
+	}
+
+	window.gistService.CacheKey = make([]byte, 32)
+	window.writeDraft(tab, "a1")
+	if keys := draftKeys(t, window); len(keys) != 1 {
+		t.Fatalf("drafts = %v, want the sealed draft", keys)
+	}
+	raw, err := ioutil.ReadFile(path.Join(dir, "a1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret words") {
+		t.Error("the draft of the encrypted gist is written in plain text")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"github.com/arsham/gistflow/qt/editor"
+)
+
+// setupEditorActions registers the actions of the editors, such as zooming and
+// going to a line, which work on the editor of the current tab. The editors
+// handle their shortcuts themselves, and the actions do nothing without tabs.
+func (m *MainWindow) setupEditorActions() {
+	m.commands.Register("Editor", editor.NewActions(m, m.currentEditor)...)
+}
+
+// currentEditor returns the editor of the current tab that was focused last,
+// or the editor of its first file. It returns nil if there are no tabs.
+func (m *MainWindow) currentEditor() *editor.Editor {
+	t, ok := m.tabGistList[m.tabIDFromIndex(m.tabsWidget.CurrentIndex())]
+	if !ok || len(t.Files()) == 0 {
+		return nil
+	}
+	if focus := m.FocusWidget(); focus != nil && focus.Pointer() != nil {
+		for _, f := range t.Files() {
+			if f.Content().Pointer() == focus.Pointer() {
+				return f.Content()
+			}
+		}
+	}
+	return t.Files()[0].Content()
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+func TestEditorActions(t *testing.T) { tRunner.Run(func() { testEditorActions(t) }) }
+func testEditorActions(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Files: map[string]gist.File{
+			"a.go": {Content: "package a"},
+			"b.go": {Content: "package b"},
+		}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+
+	trigger := func(name string) {
+		for _, c := range window.commands.Commands() {
+			if c.Name() == name {
+				c.Action.Trigger()
+				return
+			}
+		}
+		t.Errorf("%s is not registered", name)
+	}
+	// nothing to zoom without tabs.
+	trigger("Editor: Zoom In")
+
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	window.Show()
+	defer window.Hide()
+	files := window.tabGistList["a1"].Files()
+	files[1].Content().SetFocus2()
+	trigger("Editor: Zoom In")
+	if files[0].Content().ZoomLevel() != 0 || files[1].Content().ZoomLevel() != 1 {
+		t.Errorf("zoom levels = %d and %d, want the focused editor zoomed in",
+			files[0].Content().ZoomLevel(), files[1].Content().ZoomLevel())
+	}
+	trigger("Editor: Reset Zoom")
+	if files[1].Content().ZoomLevel() != 0 {
+		t.Errorf("ZoomLevel() = %d, want 0", files[1].Content().ZoomLevel())
+	}
+}
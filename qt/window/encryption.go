@@ -0,0 +1,123 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/arsham/gistflow/crypt"
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/conf"
+	"github.com/pkg/errors"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// ErrNoPassphrase is returned when the user cancels the passphrase dialog.
+var ErrNoPassphrase = errors.New("no passphrase was provided")
+
+// keyring returns the keys for encrypting and decrypting gists. The identity
+// and the recipients are read from the settings. If askPassphrase is true, or
+// there are no keys to encrypt with, the user is asked for a passphrase once
+// in each session.
+func (m *MainWindow) keyring(askPassphrase bool) (*crypt.Keyring, error) {
+	k := &crypt.Keyring{}
+	if m.settings.IdentityFile != "" {
+		b, err := ioutil.ReadFile(m.settings.IdentityFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading identity")
+		}
+		id, err := crypt.ParsePrivateKey(string(b))
+		if err != nil {
+			return nil, errors.Wrap(err, "reading identity")
+		}
+		k.Identities = append(k.Identities, id)
+		k.Recipients = append(k.Recipients, id.Public())
+	}
+	for _, r := range m.settings.RecipientList() {
+		p, err := crypt.ParsePublicKey(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "recipient %s", r)
+		}
+		k.Recipients = append(k.Recipients, p)
+	}
+	if len(k.Recipients) == 0 {
+		askPassphrase = true
+	}
+	if askPassphrase && m.gistPassphrase == nil {
+		text, ok := m.passphrase("Passphrase for encrypted gists")
+		if !ok || text == "" {
+			return nil, ErrNoPassphrase
+		}
+		m.gistPassphrase = []byte(text)
+	}
+	k.Passphrase = m.gistPassphrase
+	return k, nil
+}
+
+// decryptGist tries the identity first, then asks for the passphrase.
+func (m *MainWindow) decryptGist(g gist.Gist) (gist.Gist, error) {
+	k, err := m.keyring(false)
+	if err != nil {
+		return gist.Gist{}, err
+	}
+	plain, err := gist.Decrypt(g, k)
+	if errors.Cause(err) != crypt.ErrNoMatchingID || m.gistPassphrase != nil {
+		return plain, err
+	}
+	if k, err = m.keyring(true); err != nil {
+		return gist.Gist{}, err
+	}
+	plain, err = gist.Decrypt(g, k)
+	if errors.Cause(err) == crypt.ErrNoMatchingID {
+		// letting the user to try again.
+		m.gistPassphrase = nil
+	}
+	return plain, err
+}
+
+// prepareGist encrypts the gist if encrypted is true.
+func (m *MainWindow) prepareGist(g gist.Gist, encrypted bool) (gist.Gist, error) {
+	if !encrypted {
+		return g, nil
+	}
+	k, err := m.keyring(false)
+	if err != nil {
+		return gist.Gist{}, err
+	}
+	return gist.Encrypt(g, k)
+}
+
+// generateIdentity creates a new identity and writes it into the identity
+// file. If the file is not set, it is created next to the settings file. The
+// public key is copied to the clipboard.
+func (m *MainWindow) generateIdentity(t *conf.Tab) {
+	name := m.settings.IdentityFile
+	if name == "" {
+		name = path.Join(path.Dir(m.settings.FileName()), m.name+".key")
+	}
+	if _, err := os.Stat(name); err == nil {
+		b := m.logger.Critical("The identity file already exists. Do you want to replace it?")
+		if b != widgets.QMessageBox__Ok {
+			return
+		}
+	}
+	id, err := crypt.GenerateKey()
+	if err != nil {
+		m.logger.Error(err.Error())
+		return
+	}
+	if err := ioutil.WriteFile(name, []byte(id.String()+"\n"), 0600); err != nil {
+		msg := fmt.Sprintf("Could not write the identity: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	t.IdentityFileInput.SetText(name)
+	m.clipboard().SetText(id.Public().String(), gui.QClipboard__Clipboard)
+	m.showNotification("Your public key has been copied to clipboard")
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/crypt"
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/conf"
+)
+
+func TestEncryptGistPassphrase(t *testing.T) { tRunner.Run(func() { testEncryptGistPassphrase(t) }) }
+func testEncryptGistPassphrase(t *testing.T) {
+	var asked int
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.settings = &conf.Settings{}
+	window.passphrase = func(string) (string, bool) {
+		asked++
+		return "tJbN4u6r", true
+	}
+	g := gist.Gist{
+		Files: map[string]gist.File{
+			"file": gist.File{Content: "SaSK1UH1nXs"},
+		},
+	}
+	out, err := window.prepareGist(g, false)
+	if err != nil {
+		t.Errorf("window.prepareGist(): err = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(out, g) {
+		t.Errorf("window.prepareGist() = %v, want %v", out, g)
+	}
+	if asked != 0 {
+		t.Error("asked for passphrase for a plain gist")
+	}
+
+	out, err = window.prepareGist(g, true)
+	if err != nil {
+		t.Fatalf("window.prepareGist(): err = %v, want nil", err)
+	}
+	if !out.Encrypted() {
+		t.Error("gist is not encrypted")
+	}
+	plain, err := window.decryptGist(out)
+	if err != nil {
+		t.Errorf("window.decryptGist(): err = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(plain.Files, g.Files) {
+		t.Errorf("plain.Files = %v, want %v", plain.Files, g.Files)
+	}
+	if asked != 1 {
+		t.Errorf("asked = %d, want 1", asked)
+	}
+
+	window.gistPassphrase = nil
+	window.passphrase = func(string) (string, bool) { return "", false }
+	if _, err := window.decryptGist(out); err != ErrNoPassphrase {
+		t.Errorf("window.decryptGist(): err = %v, want %v", err, ErrNoPassphrase)
+	}
+}
+
+func TestEncryptGistIdentity(t *testing.T) { tRunner.Run(func() { testEncryptGistIdentity(t) }) }
+func testEncryptGistIdentity(t *testing.T) {
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	id, err := crypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "gistflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(id.String())
+	f.Close()
+
+	window.settings = &conf.Settings{IdentityFile: f.Name()}
+	window.passphrase = func(string) (string, bool) {
+		t.Error("asked for passphrase")
+		return "", false
+	}
+	g := gist.Gist{
+		Files: map[string]gist.File{
+			"file": gist.File{Content: "eHx9Xfs8"},
+		},
+	}
+	out, err := window.prepareGist(g, true)
+	if err != nil {
+		t.Fatalf("window.prepareGist(): err = %v, want nil", err)
+	}
+	plain, err := window.decryptGist(out)
+	if err != nil {
+		t.Errorf("window.decryptGist(): err = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(plain.Files, g.Files) {
+		t.Errorf("plain.Files = %v, want %v", plain.Files, g.Files)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/pkg/errors"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// These are the actions on the gists that can be used without opening them,
+// for example from the searchbox results.
+
+// plainGist returns the gist identified by id, decrypting it if needed. The
+// errors are shown to the user.
+func (m *MainWindow) plainGist(id string) (gist.Gist, bool) {
+	g, err := m.fetchPlain(id)
+	if err != nil {
+		msg := fmt.Sprintf("Could not %s", err)
+		m.logger.Error(msg)
+		return gist.Gist{}, false
+	}
+	return g, true
+}
+
+// fetchPlain returns the gist identified by id, decrypting it if needed.
+func (m *MainWindow) fetchPlain(id string) (gist.Gist, error) {
+	g, err := m.gistService.Get(id)
+	if err != nil {
+		return gist.Gist{}, errors.Wrap(err, "get the gist")
+	}
+	if g.Encrypted() {
+		if g, err = m.decryptGist(g); err != nil {
+			return gist.Gist{}, errors.Wrap(err, "decrypt the gist")
+		}
+	}
+	return g, nil
+}
+
+// copyGistContent copies the content of the first file of the gist.
+func (m *MainWindow) copyGistContent(id string) {
+	g, ok := m.plainGist(id)
+	if !ok {
+		return
+	}
+	names := make([]string, 0, len(g.Files))
+	for name := range g.Files {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		m.logger.Warning("The gist has no files")
+		return
+	}
+	sort.Strings(names)
+	m.clipboard().SetText(g.Files[names[0]].Content, gui.QClipboard__Clipboard)
+	m.showNotification(fmt.Sprintf("Content of %s has been copied to clipboard", names[0]))
+}
+
+// copyGistURL copies the URL of the html page of the gist.
+func (m *MainWindow) copyGistURL(id string) {
+	g, err := m.gistService.Get(id)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.clipboard().SetText(g.HTMLURL, gui.QClipboard__Clipboard)
+	m.showNotification("URL has been copied to clipboard")
+}
+
+// openGistInBrowser opens the html page of the gist.
+func (m *MainWindow) openGistInBrowser(id string) {
+	g, err := m.gistService.Get(id)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	gui.QDesktopServices_OpenUrl(core.NewQUrl3(g.HTMLURL, 0))
+}
+
+// starGist stars or unstars the gist.
+func (m *MainWindow) starGist(id string, star bool) {
+	var err error
+	msg := "Gist has been starred"
+	if star {
+		err = m.gistService.Star(id)
+	} else {
+		err = m.gistService.Unstar(id)
+		msg = "Gist has been unstarred"
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Could not change the star: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.searchbox.MarkStarred(id, star)
+	m.showNotification(msg)
+}
+
+// confirmDeleteGist deletes the gist after the user confirms.
+func (m *MainWindow) confirmDeleteGist(id string) {
+	msg := "Are you sure you want to delete this gist? This action is irreversible."
+	if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+		return
+	}
+	if m.removeGist(id) {
+		m.showNotification("Gist has been removed")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestGistActions(t *testing.T) { tRunner.Run(func() { testGistActions(t) }) }
+func testGistActions(t *testing.T) {
+	var (
+		id       = "Ty7mQ2"
+		starred  bool
+		deleted  bool
+		copied   string
+		htmlURL  = "https://gist.github.com/" + id
+		response = gist.Gist{
+			ID:      id,
+			HTMLURL: htmlURL,
+			Files: map[string]gist.File{
+				"b.txt": {Content: "second"},
+				"a.txt": {Content: "first"},
+			},
+		}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/star"):
+			starred = r.Method == http.MethodPut
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			json.NewEncoder(w).Encode(response)
+		}
+	}))
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.clipboard = func() clipboard {
+		return &fakeClipboard{textFunc: func(text string, mode gui.QClipboard__Mode) { copied = text }}
+	}
+	var confirm widgets.QMessageBox__StandardButton
+	window.logger = &logger{
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton { return confirm },
+		errorFunc:    func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc:  func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+	}
+	window.searchbox.Add(response)
+	window.gistList.Add(response)
+
+	window.searchbox.CopyContent(id)
+	if copied != "first" {
+		t.Errorf("copied = %q, want first", copied)
+	}
+	window.searchbox.CopyURL(id)
+	if copied != htmlURL {
+		t.Errorf("copied = %q, want %q", copied, htmlURL)
+	}
+
+	window.searchbox.StarGist(id, true)
+	if !starred {
+		t.Error("gist was not starred")
+	}
+	window.searchbox.StarGist(id, false)
+	if starred {
+		t.Error("gist was not unstarred")
+	}
+
+	confirm = widgets.QMessageBox__Cancel
+	window.searchbox.DeleteGist(id)
+	if deleted {
+		t.Error("deleted the gist without confirmation")
+	}
+	confirm = widgets.QMessageBox__Ok
+	window.searchbox.DeleteGist(id)
+	if !deleted {
+		t.Error("gist was not deleted")
+	}
+	if window.searchbox.HasID(id) || window.gistList.HasID(id) {
+		t.Error("gist is still in the lists")
+	}
+}
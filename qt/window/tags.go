@@ -0,0 +1,97 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+	"github.com/pkg/errors"
+	"github.com/therecipe/qt/widgets"
+)
+
+func (m *MainWindow) setupTags() {
+	m.tagBrowser.SetContainer(m.gistList)
+	m.gistList.ConnectAddTag(func(ids []string) {
+		if tag, ok := m.askTag("Add Tag", "Tag to add to the selected gists", ""); ok {
+			m.retag(ids, func(description string) string {
+				return search.AddTag(description, tag)
+			})
+		}
+	})
+	m.gistList.ConnectRemoveTag(func(ids []string) {
+		if tag, ok := m.askTag("Remove Tag", "Tag to remove from the selected gists", ""); ok {
+			m.retag(ids, func(description string) string {
+				return search.RemoveTag(description, tag)
+			})
+		}
+	})
+	m.tagBrowser.ConnectRenameTag(func(old string) {
+		if tag, ok := m.askTag("Rename Tag", fmt.Sprintf("New name for #%s", old), old); ok {
+			m.retag(m.gistList.IDsWithTag(old), func(description string) string {
+				return search.RenameTag(description, old, tag)
+			})
+		}
+	})
+	m.tagBrowser.ConnectRemoveTag(func(tag string) {
+		ids := m.gistList.IDsWithTag(tag)
+		msg := fmt.Sprintf("Are you sure you want to remove #%s from %d gists?", tag, len(ids))
+		if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+			return
+		}
+		m.retag(ids, func(description string) string {
+			return search.RemoveTag(description, tag)
+		})
+	})
+}
+
+// askTag asks the user for a tag, and reports an invalid one.
+func (m *MainWindow) askTag(title, label, text string) (string, bool) {
+	input, ok := m.askText(title, label, text)
+	if !ok {
+		return "", false
+	}
+	tag := search.NormalizeTag(input)
+	if tag == "" {
+		m.logger.Warningf("%q is not a valid tag", input)
+		return "", false
+	}
+	return tag, true
+}
+
+// retag rewrites the latest descriptions of the gists with fn and saves the
+// ones that changed, while showing the progress. Nothing is changed while any
+// of the gists have unsaved changes.
+func (m *MainWindow) retag(ids []string, fn func(description string) string) {
+	if !m.saved(ids...) {
+		return
+	}
+	m.bulk("Tagging", "tagged", ids, func(id string) error {
+		g, err := m.gistService.Fetch(id)
+		if err != nil {
+			return errors.Wrap(err, "getting the gist")
+		}
+		description := fn(g.Description)
+		if description == g.Description {
+			return nil
+		}
+		res, err := m.gistService.Update(gist.Gist{
+			ID:          g.ID,
+			URL:         g.URL,
+			Description: description,
+			Files:       map[string]gist.File{},
+		})
+		if err != nil {
+			return errors.Wrap(err, "updating the gist")
+		}
+		m.gistList.Add(res)
+		m.searchbox.Add(res)
+		if t, ok := m.tabGistList[id]; ok {
+			t.SetDescription(res.Description)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+// tagServer keeps the gists in memory and updates their descriptions.
+func tagServer(t *testing.T, gists map[string]gist.Gist) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/gists/")
+		g, ok := gists[id]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(g)
+			return
+		case r.Method != http.MethodPatch:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var in gist.Gist
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Error(err)
+		}
+		if len(in.Files) != 0 {
+			t.Errorf("files = %v, want only the description to be sent", in.Files)
+		}
+		g.Description = in.Description
+		gists[id] = g
+		json.NewEncoder(w).Encode(g)
+	}))
+}
+
+func TestRetag(t *testing.T) { tRunner.Run(func() { testRetag(t) }) }
+func testRetag(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Description: "deploy #k8s"},
+		"b2": {ID: "b2", Description: "pods #K8s #helm"},
+		"c3": {ID: "c3", Description: "notes"},
+	}
+	ts := tagServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.logger = &logger{
+		errorFunc:    func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc:  func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton { return widgets.QMessageBox__Ok },
+	}
+	for _, id := range []string{"a1", "b2", "c3"} {
+		g := gists[id]
+		g.URL = ts.URL + "/gists/" + id
+		gists[id] = g
+		window.gistList.Add(g)
+		window.searchbox.Add(g)
+	}
+
+	window.askText = func(title, label, text string) (string, bool) {
+		if text != "k8s" {
+			t.Errorf("text = %q, want the old name", text)
+		}
+		return "#Kubernetes", true
+	}
+	window.tagBrowser.RenameTag("k8s")
+	if gists["a1"].Description != "deploy #kubernetes" || gists["b2"].Description != "pods #kubernetes #helm" {
+		t.Errorf("descriptions = %q, %q, want the tag renamed", gists["a1"].Description, gists["b2"].Description)
+	}
+	if g, _ := window.gistList.Gist("a1"); g.Description != "deploy #kubernetes" {
+		t.Errorf("gist list description = %q, want it updated", g.Description)
+	}
+	if window.searchbox.Description(0) != "deploy #kubernetes" {
+		t.Errorf("searchbox description = %q, want it updated", window.searchbox.Description(0))
+	}
+
+	window.askText = func(title, label, text string) (string, bool) { return "go", true }
+	window.gistList.AddTag([]string{"a1", "c3"})
+	if gists["c3"].Description != "notes #go" {
+		t.Errorf("description = %q, want the tag added", gists["c3"].Description)
+	}
+	if n := window.gistList.Tags()["go"]; n != 2 {
+		t.Errorf("Tags()[go] = %d, want 2", n)
+	}
+
+	window.tagBrowser.RemoveTag("go")
+	if gists["a1"].Description != "deploy #kubernetes" || gists["c3"].Description != "notes" {
+		t.Errorf("descriptions = %q, %q, want the tag removed", gists["a1"].Description, gists["c3"].Description)
+	}
+
+	// the list has an old description of the gist.
+	g := gists["c3"]
+	g.Description = "notes #edited"
+	gists["c3"] = g
+	window.askText = func(title, label, text string) (string, bool) { return "go", true }
+	window.gistList.AddTag([]string{"c3"})
+	if gists["c3"].Description != "notes #edited #go" {
+		t.Errorf("description = %q, want the tag added to the latest description", gists["c3"].Description)
+	}
+
+	var warnings []string
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(msg string) { warnings = append(warnings, msg) },
+	}
+	window.askText = func(title, label, text string) (string, bool) { return "ops", true }
+	window.gistList.AddTag([]string{"a1", "zz9"})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "1 of 2 gists have been tagged. 1 failed.") || !strings.Contains(warnings[0], "zz9:") {
+		t.Errorf("warnings = %q, want one summary with the failed gist", warnings)
+	}
+	if gists["a1"].Description != "deploy #kubernetes #ops" {
+		t.Errorf("description = %q, want the tag added", gists["a1"].Description)
+	}
+
+	var warned bool
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(string) { warned = true },
+	}
+	window.askText = func(title, label, text string) (string, bool) { return "two words", true }
+	window.gistList.AddTag([]string{"a1"})
+	if !warned {
+		t.Error("an invalid tag was not reported")
+	}
+}
+
+func TestRetagDirty(t *testing.T) { tRunner.Run(func() { testRetagDirty(t) }) }
+func testRetagDirty(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"a1": {ID: "a1", Description: "deploy", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+	}
+	ts := tagServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var warnings []string
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(msg string) { warnings = append(warnings, msg) },
+	}
+	if err := window.openGist("a1"); err != nil {
+		t.Fatal(err)
+	}
+	window.gistList.Add(gists["a1"])
+	tab := window.tabGistList["a1"]
+	tab.Files()[0].Content().SetPlainText("package b")
+	if !tab.Dirty() {
+		t.Fatal("the tab is not dirty")
+	}
+
+	window.askText = func(title, label, text string) (string, bool) { return "go", true }
+	window.gistList.AddTag([]string{"a1"})
+	if len(warnings) != 1 || gists["a1"].Description != "deploy" {
+		t.Errorf("warnings = %q, description = %q, want the dirty gist not retagged", warnings, gists["a1"].Description)
+	}
+	if got := tab.Files()[0].Content().ToPlainText(); got != "package b" || !tab.Dirty() {
+		t.Errorf("content = %q, dirty = %t, want the changes kept", got, tab.Dirty())
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/tab"
+	"github.com/therecipe/qt/core"
+	"github.com/therecipe/qt/gui"
+	"github.com/therecipe/qt/widgets"
+)
+
+// transferFile moves or copies the file identified by name from the gist
+// identified by srcID to the one identified by dstID. The open tabs of both
+// gists are reloaded afterwards. The gists are fetched from the API, as the
+// cached ones might be older than the ones on github.
+func (m *MainWindow) transferFile(srcID, name, dstID string, copy bool) {
+	if srcID == dstID || dstID == "" || !m.saved(srcID, dstID) {
+		return
+	}
+	src, err := m.gistService.Fetch(srcID)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	dst, err := m.gistService.Fetch(dstID)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	if src.Encrypted() != dst.Encrypted() {
+		m.logger.Error("Files cannot be moved between encrypted and plain gists")
+		return
+	}
+	if _, ok := src.Files[name+gist.EncryptedExt]; ok {
+		name += gist.EncryptedExt
+	}
+
+	verb := "moved"
+	if copy {
+		verb = "copied"
+		_, err = m.gistService.CopyFile(src, name, dst)
+	} else {
+		_, _, err = m.gistService.MoveFile(src, name, dst)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Could not transfer the file: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.reloadGist(srcID)
+	m.reloadGist(dstID)
+	m.showNotification(fmt.Sprintf("File has been %s", verb))
+}
+
+// mergeGists creates a new gist with the files of the gists identified by ids.
+// The user is asked to remove the original gists afterwards.
+func (m *MainWindow) mergeGists(ids []string) {
+	if len(ids) < 2 || !m.saved(ids...) {
+		return
+	}
+	gists := make([]gist.Gist, 0, len(ids))
+	var description string
+	for _, id := range ids {
+		g, err := m.gistService.Fetch(id)
+		if err != nil {
+			msg := fmt.Sprintf("Could not get the gist: %s", err)
+			m.logger.Error(msg)
+			return
+		}
+		if len(gists) > 0 && g.Encrypted() != gists[0].Encrypted() {
+			m.logger.Error("Encrypted and plain gists cannot be merged")
+			return
+		}
+		if description == "" {
+			description = g.Description
+		}
+		gists = append(gists, g)
+	}
+	msg := fmt.Sprintf("A new gist will be created with the files of the %d selected gists.\n\nDo you want to continue?", len(gists))
+	if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+		return
+	}
+	merged, err := m.gistService.Merge(gists, description)
+	if err != nil {
+		msg := fmt.Sprintf("Could not merge the gists: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.searchbox.Add(merged)
+	m.gistList.Add(merged)
+
+	msg = "The new gist has been created and verified. Do you want to delete the original gists? This action is irreversible."
+	if m.logger.Critical(msg) == widgets.QMessageBox__Ok {
+		for _, id := range ids {
+			m.removeGist(id)
+		}
+	}
+	if err := m.openGist(merged.ID); err != nil {
+		msg := fmt.Sprintf("Opening gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.showNotification("Gists have been merged")
+}
+
+// splitGist creates a new gist for each file of the gist identified by id.
+// The user is asked to remove the original gist afterwards.
+func (m *MainWindow) splitGist(id string) {
+	if !m.saved(id) {
+		return
+	}
+	g, err := m.gistService.Fetch(id)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	msg := fmt.Sprintf("A new gist will be created for each of the %d files.\n\nDo you want to continue?", len(g.Files))
+	if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+		return
+	}
+	gists, err := m.gistService.Split(g)
+	if err != nil {
+		msg := fmt.Sprintf("Could not split the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	for _, g := range gists {
+		m.searchbox.Add(g)
+		m.gistList.Add(g)
+	}
+	msg = "The new gists have been created and verified. Do you want to delete the original gist? This action is irreversible."
+	if m.logger.Critical(msg) == widgets.QMessageBox__Ok {
+		m.removeGist(id)
+	}
+	m.showNotification(fmt.Sprintf("Gist has been split into %d gists", len(gists)))
+}
+
+// removeGist deletes the gist identified by id and removes it from the lists
+// and the tabs. It returns false if the gist could not be deleted.
+func (m *MainWindow) removeGist(id string) bool {
+	if err := m.deleteGist(id); err != nil {
+		msg := fmt.Sprintf("Could not delete gist: %s", err)
+		m.logger.Error(msg)
+		return false
+	}
+	return true
+}
+
+// deleteGist deletes the gist identified by id, and removes it from the lists
+// and the tabs.
+func (m *MainWindow) deleteGist(id string) error {
+	if err := m.gistService.DeleteGist(id); err != nil {
+		return err
+	}
+	m.searchbox.Remove(id)
+	m.gistList.Remove(id)
+	m.closeGistTab(id)
+	return nil
+}
+
+// reloadGist reopens the tab of the gist identified by id, if it is open, to
+// show its latest state.
+func (m *MainWindow) reloadGist(id string) {
+	t, ok := m.tabGistList[id]
+	if !ok {
+		return
+	}
+	index := m.tabsWidget.IndexOf(t)
+	m.closeGistTab(id)
+	if err := m.openGist(id); err != nil {
+		msg := fmt.Sprintf("Opening gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	newTab := m.tabGistList[id]
+	m.tabsWidget.TabBar().MoveTab(m.tabsWidget.IndexOf(newTab), index)
+}
+
+// closeGistTab closes the tab of the gist identified by id, if it is open.
+func (m *MainWindow) closeGistTab(id string) {
+	t, ok := m.tabGistList[id]
+	if !ok {
+		return
+	}
+	m.releaseTab(t)
+	m.tabsWidget.RemoveTab(m.tabsWidget.IndexOf(t))
+	delete(m.tabGistList, id)
+	m.updateTrayBadge()
+	// the tab might be handling the event that caused this.
+	t.DeleteLater()
+}
+
+// saved returns false and warns the user if any of the gists have unsaved
+// changes.
+func (m *MainWindow) saved(ids ...string) bool {
+	for _, id := range ids {
+		if t, ok := m.tabGistList[id]; ok && t.Dirty() {
+			m.logger.Warning("Please save your changes first.")
+			return false
+		}
+	}
+	return true
+}
+
+// setupTabDrops lets the user drop files on the tab bar. Hovering over a tab
+// while dragging a file shows that tab.
+func (m *MainWindow) setupTabDrops() {
+	bar := m.tabsWidget.TabBar()
+	bar.SetAcceptDrops(true)
+	bar.ConnectDragEnterEvent(func(event *gui.QDragEnterEvent) {
+		if _, _, ok := tab.FileFromMimeData(event.MimeData()); ok {
+			event.AcceptProposedAction()
+			return
+		}
+		event.Ignore()
+	})
+	bar.ConnectDragMoveEvent(func(event *gui.QDragMoveEvent) {
+		if index := bar.TabAt(event.Pos()); index >= 0 {
+			m.tabsWidget.SetCurrentIndex(index)
+		}
+		event.AcceptProposedAction()
+	})
+	bar.ConnectDropEvent(func(event *gui.QDropEvent) {
+		id, name, ok := tab.FileFromMimeData(event.MimeData())
+		dst := m.tabIDFromIndex(bar.TabAt(event.Pos()))
+		if !ok || dst == "" || dst == id {
+			event.Ignore()
+			return
+		}
+		event.AcceptProposedAction()
+		m.transferFile(id, name, dst, event.DropAction() == core.Qt__CopyAction)
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+// transferServer keeps the gists in memory and applies the updates on them.
+func transferServer(t *testing.T, gists map[string]gist.Gist) *httptest.Server {
+	var ts *httptest.Server
+	var created int
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/gists/")
+		switch r.Method {
+		case http.MethodPost:
+			var in gist.Gist
+			json.NewDecoder(r.Body).Decode(&in)
+			created++
+			in.ID = fmt.Sprintf("new%d", created)
+			in.URL = ts.URL + "/gists/" + in.ID
+			gists[in.ID] = in
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(in)
+		case http.MethodPatch:
+			var in gist.Gist
+			json.NewDecoder(r.Body).Decode(&in)
+			g := gists[id]
+			files := make(map[string]gist.File)
+			for name, f := range g.Files {
+				files[name] = f
+			}
+			for name, f := range in.Files {
+				if f.Content == "" {
+					delete(files, name)
+					continue
+				}
+				files[name] = f
+			}
+			g.Files = files
+			gists[id] = g
+			json.NewEncoder(w).Encode(g)
+		case http.MethodDelete:
+			delete(gists, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			g, ok := gists[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(g)
+		}
+	}))
+	for id, g := range gists {
+		g.URL = ts.URL + "/gists/" + id
+		gists[id] = g
+	}
+	return ts
+}
+
+func TestTransferFile(t *testing.T) { tRunner.Run(func() { testTransferFile(t) }) }
+func testTransferFile(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"src": {ID: "src", Files: map[string]gist.File{
+			"a.go": {Content: "package a"},
+			"b.go": {Content: "package b"},
+		}},
+		"dst": {ID: "dst", Files: map[string]gist.File{
+			"c.go": {Content: "package c"},
+		}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+	}
+	for _, id := range []string{"src", "dst"} {
+		if err := window.openGist(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// edited elsewhere after it was cached.
+	src := gists["src"]
+	src.Files["a.go"] = gist.File{Content: "package fresh"}
+	gists["src"] = src
+
+	window.transferFile("src", "a.go", "dst", true)
+	if _, ok := gists["src"].Files["a.go"]; !ok {
+		t.Error("copying removed the file from the source")
+	}
+	if got := gists["dst"].Files["a.go"].Content; got != "package fresh" {
+		t.Errorf("copied content = %q, want the latest version of the gist", got)
+	}
+	if l := len(window.tabGistList["dst"].Files()); l != 2 {
+		t.Errorf("len(files) = %d, want 2: the tab was not reloaded", l)
+	}
+
+	window.transferFile("src", "b.go", "dst", false)
+	if _, ok := gists["src"].Files["b.go"]; ok {
+		t.Error("file was not removed from the source")
+	}
+	if _, ok := gists["dst"].Files["b.go"]; !ok {
+		t.Error("file was not moved")
+	}
+	if l := len(window.tabGistList["src"].Files()); l != 1 {
+		t.Errorf("len(files) = %d, want 1: the tab was not reloaded", l)
+	}
+}
+
+func TestTransferFileUnsaved(t *testing.T) { tRunner.Run(func() { testTransferFileUnsaved(t) }) }
+func testTransferFileUnsaved(t *testing.T) {
+	var warned bool
+	gists := map[string]gist.Gist{
+		"src": {ID: "src", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+		"dst": {ID: "dst", Files: map[string]gist.File{"c.go": {Content: "package c"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.logger = &logger{
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(string) { warned = true },
+	}
+	if err := window.openGist("dst"); err != nil {
+		t.Fatal(err)
+	}
+	window.tabGistList["dst"].Files()[0].Content().SetPlainText("package d")
+	window.transferFile("src", "a.go", "dst", true)
+	if !warned {
+		t.Error("didn't warn about the unsaved changes")
+	}
+	if _, ok := gists["dst"].Files["a.go"]; ok {
+		t.Error("file was copied")
+	}
+}
+
+func TestMergeAndSplitGists(t *testing.T) { tRunner.Run(func() { testMergeAndSplitGists(t) }) }
+func testMergeAndSplitGists(t *testing.T) {
+	gists := map[string]gist.Gist{
+		"one": {ID: "one", Description: "first", Files: map[string]gist.File{"a.go": {Content: "package a"}}},
+		"two": {ID: "two", Files: map[string]gist.File{"b.go": {Content: "package b"}}},
+	}
+	ts := transferServer(t, gists)
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	window.logger = &logger{
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton { return widgets.QMessageBox__Ok },
+		errorFunc:    func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc:  func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+	}
+	window.gistList.Add(gists["one"])
+	window.gistList.Add(gists["two"])
+
+	window.mergeGists([]string{"one", "two"})
+	merged, ok := gists["new1"]
+	if !ok {
+		t.Fatal("merged gist was not created")
+	}
+	if len(merged.Files) != 2 || merged.Description != "first" {
+		t.Errorf("merged = %v", merged)
+	}
+	for _, id := range []string{"one", "two"} {
+		if _, ok := gists[id]; ok {
+			t.Errorf("%s was not removed", id)
+		}
+		if window.gistList.HasID(id) {
+			t.Errorf("%s is still in the list", id)
+		}
+	}
+	if _, ok := window.tabGistList["new1"]; !ok {
+		t.Error("merged gist was not opened")
+	}
+
+	window.splitGist("new1")
+	if _, ok := gists["new1"]; ok {
+		t.Error("the split gist was not removed")
+	}
+	for _, id := range []string{"new2", "new3"} {
+		if len(gists[id].Files) != 1 {
+			t.Errorf("gists[%s] = %v", id, gists[id])
+		}
+		if !window.gistList.HasID(id) {
+			t.Errorf("%s is not in the list", id)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+// changeVisibility moves the files of the remote gist to a new gist with the
+// opposite visibility, as the API cannot change it. The latest version of the
+// gist is copied, so the changes made elsewhere are not lost. The original gist
+// is only removed after the copy is verified and the user confirms. The tab of
+// the original gist is replaced with the new one.
+func (m *MainWindow) changeVisibility(remote gist.Gist) {
+	remote, err := m.gistService.Fetch(remote.ID)
+	if err != nil {
+		msg := fmt.Sprintf("Could not get the gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	visibility := "public"
+	if remote.Public {
+		visibility = "secret"
+	}
+	msg := fmt.Sprintf("A new %s gist will be created with the same files and description. The URL of the gist will change.\n\nDo you want to continue?", visibility)
+	if m.logger.Critical(msg) != widgets.QMessageBox__Ok {
+		return
+	}
+	var comments bool
+	if remote.Comments > 0 {
+		msg := fmt.Sprintf("Do you want to copy the %d comments as a note to the new gist?", remote.Comments)
+		comments = m.logger.Critical(msg) == widgets.QMessageBox__Ok
+	}
+
+	clone, err := m.gistService.Clone(remote, !remote.Public, comments)
+	if err != nil {
+		msg := fmt.Sprintf("Could not change the visibility: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.searchbox.Add(clone)
+	m.gistList.Add(clone)
+	cache := m.gistService.Cache()
+	if cache.Pinned(remote.ID) {
+		if err := cache.Pin(clone.ID); err != nil {
+			m.logger.Warningf("Could not change offline state: %s", err)
+		}
+	}
+
+	msg = "The new gist has been created and verified. Do you want to delete the original gist? This action is irreversible."
+	removed := m.logger.Critical(msg) == widgets.QMessageBox__Ok && m.removeGist(remote.ID)
+	m.closeGistTab(remote.ID)
+	if err := m.openGist(clone.ID); err != nil {
+		msg := fmt.Sprintf("Opening gist: %s", err)
+		m.logger.Error(msg)
+		return
+	}
+	m.showNotification(visibilityNotice(visibility, removed))
+}
+
+// visibilityNotice returns the message shown after a copy of a gist is created
+// with the visibility. The original gist is still there if it was not removed.
+func visibilityNotice(visibility string, removed bool) string {
+	if removed {
+		return fmt.Sprintf("Gist is now %s", visibility)
+	}
+	original := "secret"
+	if visibility == "secret" {
+		original = "public"
+	}
+	return fmt.Sprintf("A %s copy of the gist has been created. The %s original is kept", visibility, original)
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/therecipe/qt/widgets"
+)
+
+func TestChangeVisibility(t *testing.T) { tRunner.Run(func() { testChangeVisibility(t) }) }
+func testChangeVisibility(t *testing.T) {
+	var (
+		id      = "Ua0bP7sRw"
+		cloneID = "x2RcMn1qE"
+		deleted string
+		created gist.Gist
+	)
+	orig := gist.Gist{
+		ID:          id,
+		Description: "kV5bWn",
+		Files: map[string]gist.File{
+			"file": gist.File{Content: "nQ3pZ"},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&created)
+			created.ID = cloneID
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+		case r.Method == http.MethodDelete:
+			deleted = strings.TrimPrefix(r.URL.Path, "/gists/")
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, cloneID):
+			json.NewEncoder(w).Encode(created)
+		default:
+			json.NewEncoder(w).Encode(orig)
+		}
+	}))
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var asked int
+	window.logger = &logger{
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton {
+			asked++
+			return widgets.QMessageBox__Ok
+		},
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(msg string) { t.Errorf("unexpected warning: %s", msg) },
+	}
+
+	if err := window.openGist(id); err != nil {
+		t.Fatal(err)
+	}
+	window.gistList.Add(orig)
+	stale := orig
+	orig.Files = map[string]gist.File{"file": {Content: "edited elsewhere"}}
+	window.changeVisibility(stale)
+
+	if asked != 2 {
+		t.Errorf("asked = %d, want 2", asked)
+	}
+	if !created.Public {
+		t.Error("new gist is not public")
+	}
+	if created.Description != orig.Description {
+		t.Errorf("created.Description = %s, want %s", created.Description, orig.Description)
+	}
+	if got := created.Files["file"].Content; got != "edited elsewhere" {
+		t.Errorf("content = %q, want the latest content", got)
+	}
+	if deleted != id {
+		t.Errorf("deleted = %s, want %s", deleted, id)
+	}
+	if _, ok := window.tabGistList[id]; ok {
+		t.Error("the tab of the original gist is still open")
+	}
+	if _, ok := window.tabGistList[cloneID]; !ok {
+		t.Error("the new gist is not opened")
+	}
+	if window.gistList.HasID(id) {
+		t.Error("the original gist is still in the list")
+	}
+	if !window.gistList.HasID(cloneID) {
+		t.Error("the new gist is not in the list")
+	}
+	if !window.searchbox.HasID(cloneID) {
+		t.Error("the new gist is not in the searchbox")
+	}
+}
+
+func TestChangeVisibilityKeepOriginal(t *testing.T) {
+	tRunner.Run(func() { testChangeVisibilityKeepOriginal(t) })
+}
+func testChangeVisibilityKeepOriginal(t *testing.T) {
+	var deleted bool
+	orig := gist.Gist{
+		ID:     "Mk7aZ0",
+		Public: true,
+		Files: map[string]gist.File{
+			"file": gist.File{Content: "content"},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var g gist.Gist
+			json.NewDecoder(r.Body).Decode(&g)
+			w.WriteHeader(http.StatusCreated)
+			g.ID = "W9cQe"
+			json.NewEncoder(w).Encode(g)
+		case http.MethodDelete:
+			deleted = true
+		default:
+			g := orig
+			if strings.HasSuffix(r.URL.Path, "W9cQe") {
+				g.ID = "W9cQe"
+				g.Public = false
+			}
+			json.NewEncoder(w).Encode(g)
+		}
+	}))
+	defer ts.Close()
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.gistService.API = ts.URL
+	var asked int
+	window.logger = &logger{
+		criticalFunc: func(string) widgets.QMessageBox__StandardButton {
+			asked++
+			if asked == 1 {
+				return widgets.QMessageBox__Ok
+			}
+			return widgets.QMessageBox__Cancel
+		},
+		errorFunc:   func(msg string) { t.Errorf("unexpected error: %s", msg) },
+		warningFunc: func(string) {},
+	}
+	if err := window.openGist(orig.ID); err != nil {
+		t.Fatal(err)
+	}
+	window.changeVisibility(orig)
+	if deleted {
+		t.Error("deleted the original gist without confirmation")
+	}
+}
+
+func TestVisibilityNotice(t *testing.T) {
+	tcs := []struct {
+		visibility string
+		removed    bool
+		want       string
+	}{
+		{"public", true, "Gist is now public"},
+		{"secret", true, "Gist is now secret"},
+		{"public", false, "A public copy of the gist has been created. The secret original is kept"},
+		{"secret", false, "A secret copy of the gist has been created. The public original is kept"},
+	}
+	for _, tc := range tcs {
+		if got := visibilityNotice(tc.visibility, tc.removed); got != tc.want {
+			t.Errorf("visibilityNotice(%q, %t) = %q, want %q", tc.visibility, tc.removed, got, tc.want)
+		}
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"os"
 	"path"
 
+	"github.com/arsham/gistflow/crypt"
 	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/qt/command"
 	"github.com/arsham/gistflow/qt/conf"
 	"github.com/arsham/gistflow/qt/gistlist"
 	"github.com/arsham/gistflow/qt/menubar"
@@ -18,6 +20,8 @@ import (
 	"github.com/arsham/gistflow/qt/searchbox"
 	"github.com/arsham/gistflow/qt/tab"
 	"github.com/arsham/gistflow/qt/toolbar"
+	"github.com/arsham/gistflow/scan"
+	"github.com/arsham/gistflow/syntax"
 	"github.com/pkg/errors"
 	"github.com/therecipe/qt/core"
 	"github.com/therecipe/qt/gui"
@@ -26,6 +30,9 @@ import (
 
 const (
 	mainWindowGeometry = "mainWindowGeometry"
+	gistListSortOrder  = "gistListSortOrder"
+	gistListGrouping   = "gistListGrouping"
+	unlockAttempts     = 3
 )
 
 type clipboard interface {
@@ -45,18 +52,30 @@ type MainWindow struct {
 	gistService gist.Service
 
 	menubar    *menubar.MenuBar
+	commands   *command.Registry // actions listed in the command palette.
 	toolBar    *toolbar.Toolbar
 	sysTray    *widgets.QSystemTrayIcon
 	icon       *gui.QIcon
-	statusArea *widgets.QStatusBar // named this way to avoid collision
+	badgeIcon  *gui.QIcon              // icon of the tray when there are unsaved changes.
+	draftKeys  map[*tab.Tab]string     // keys of the drafts of the tabs.
+	autoSavers map[*tab.Tab]*autoSaver // auto-saving state of the tabs.
+	statusArea *widgets.QStatusBar     // named this way to avoid collision
 
 	searchbox  *searchbox.Dialog
 	gistList   *gistlist.Container
+	gistFilter *gistlist.FilterBox
+	tagBrowser *gistlist.TagBrowser
 	dockWidget *widgets.QDockWidget
 	tabsWidget *widgets.QTabWidget
 
 	tabGistList map[string]*tab.Tab // gist id to the tab
 	clipboard   func() clipboard
+	passphrase  func(msg string) (string, bool) // asks the user for a passphrase.
+	askText     func(title, label, text string) (string, bool)
+	askDir      func(title string) (string, bool) // asks the user for a directory.
+	askSave     func(msg string) widgets.QMessageBox__StandardButton
+
+	gistPassphrase []byte // passphrase of encrypted gists in this session.
 }
 
 func (m *MainWindow) setupUI() {
@@ -69,6 +88,12 @@ func (m *MainWindow) setupUI() {
 	if m.tabGistList == nil {
 		m.tabGistList = make(map[string]*tab.Tab, 0)
 	}
+	if m.draftKeys == nil {
+		m.draftKeys = make(map[*tab.Tab]string)
+	}
+	if m.autoSavers == nil {
+		m.autoSavers = make(map[*tab.Tab]*autoSaver)
+	}
 
 	centralWidget := widgets.NewQWidget(m, core.Qt__Widget)
 	centralWidget.SetObjectName("centralWidget")
@@ -106,7 +131,16 @@ func (m *MainWindow) setupUI() {
 	m.gistList = gistlist.NewContainer(dockWidgetContents)
 	m.gistList.SetObjectName("gistList")
 
-	verticalLayout2.AddWidget(m.gistList, 0, 0)
+	m.gistFilter = gistlist.NewFilterBox(dockWidgetContents)
+	m.gistFilter.SetObjectName("gistFilter")
+	m.gistFilter.SetContainer(m.gistList)
+
+	m.tagBrowser = gistlist.NewTagBrowser(dockWidgetContents)
+	m.tagBrowser.SetObjectName("tagBrowser")
+
+	verticalLayout2.AddWidget(m.gistFilter, 0, 0)
+	verticalLayout2.AddWidget(m.gistList, 3, 0)
+	verticalLayout2.AddWidget(m.tagBrowser, 1, 0)
 	m.dockWidget.SetWidget(dockWidgetContents)
 
 	m.dockWidget.SetWidget(dockWidgetContents)
@@ -120,7 +154,7 @@ func (m *MainWindow) setupUI() {
 	m.sysTray = widgets.NewQSystemTrayIcon(m)
 	m.sysTray.SetIcon(m.icon)
 	m.sysTray.SetVisible(true)
-	m.sysTray.SetToolTip("GistFlow")
+	m.sysTray.SetToolTip(trayToolTip)
 	m.sysTray.SetContextMenu(m.menubar.Options())
 
 	m.SetWindowIcon(m.icon)
@@ -132,16 +166,32 @@ func (m *MainWindow) setupUI() {
 
 	m.tabsWidget.InstallEventFilter(filter)
 	m.tabsWidget.ConnectTabCloseRequested(m.closeTab)
+	m.setupTabDrops()
+	m.gistList.ConnectFileDropped(m.transferFile)
+	m.gistList.ConnectMergeGists(m.mergeGists)
+	m.gistList.ConnectSplitGist(m.splitGist)
+	m.setupTags()
+	m.setupGistListActions()
 
 	m.menubar.ConnectCopyURLToClipboard(m.copyURLToClipboard)
 	m.menubar.ConnectOpenInBrowser(m.openInBrowser)
 	m.menubar.ConnectQuit(func() {
-		m.app.Quit()
+		if m.confirmQuit() {
+			m.app.Quit()
+		}
+	})
+	m.ConnectCloseEvent(func(event *gui.QCloseEvent) {
+		if !m.confirmQuit() {
+			event.Ignore()
+			return
+		}
+		m.CloseEventDefault(event)
 	})
 	m.menubar.ConnectOpenSettings(func(bool) {
 		m.showSettings(func() {
 			m.gistService.Username = m.settings.Username
 			m.gistService.Token = m.settings.Token
+			m.gistService.CacheSize = m.settings.CacheSize()
 			m.gistList.Clear()
 			m.searchbox.Clear()
 			go m.populate()
@@ -152,14 +202,53 @@ func (m *MainWindow) setupUI() {
 	m.clipboard = func() clipboard {
 		return m.app.Clipboard()
 	}
+	m.passphrase = func(msg string) (string, bool) {
+		var ok bool
+		text := widgets.QInputDialog_GetText(m, "Passphrase", msg, widgets.QLineEdit__Password, "", &ok, 0, 0)
+		return text, ok
+	}
+	m.askText = func(title, label, text string) (string, bool) {
+		var ok bool
+		text = widgets.QInputDialog_GetText(m, title, label, widgets.QLineEdit__Normal, text, &ok, 0, 0)
+		return text, ok
+	}
+	m.askDir = func(title string) (string, bool) {
+		dir := widgets.QFileDialog_GetExistingDirectory(m, title, "", widgets.QFileDialog__ShowDirsOnly)
+		return dir, dir != ""
+	}
+	m.askSave = func(msg string) widgets.QMessageBox__StandardButton {
+		buttons := widgets.QMessageBox__Save | widgets.QMessageBox__Discard | widgets.QMessageBox__Cancel
+		return widgets.QMessageBox_Question(m, "Unsaved Changes", msg, buttons, widgets.QMessageBox__Save)
+	}
 
 	m.searchbox = searchbox.NewDialog(m, 0)
+	m.commands = command.NewRegistry()
+	m.menubar.Register(m.commands)
+	m.commands.Register("Gist List", m.gistList.GistActions()...)
+	m.commands.Register("Gist List", m.gistList.ArrangeActions()...)
+	m.setupEditorActions()
+	m.searchbox.SetCommands(m.commands)
 	m.ConnectKeyPressEvent(func(event *gui.QKeyEvent) {
-		if event.Key() == int(core.Qt__Key_P) && event.Modifiers() == core.Qt__ControlModifier {
+		if event.Key() != int(core.Qt__Key_P) {
+			return
+		}
+		switch event.Modifiers() {
+		case core.Qt__ControlModifier:
 			m.searchbox.View(m.Geometry())
+		case core.Qt__ControlModifier | core.Qt__ShiftModifier:
+			m.searchbox.ViewCommands(m.Geometry())
 		}
 	})
 	m.searchbox.ConnectOpenGist(m.openGistByID)
+	m.searchbox.ConnectOpenFile(func(id, name string) {
+		m.openGistFile(id, name)
+		m.searchbox.Hide()
+	})
+	m.searchbox.ConnectCopyContent(m.copyGistContent)
+	m.searchbox.ConnectCopyURL(m.copyGistURL)
+	m.searchbox.ConnectOpenInBrowser(m.openGistInBrowser)
+	m.searchbox.ConnectStarGist(m.starGist)
+	m.searchbox.ConnectDeleteGist(m.confirmDeleteGist)
 	m.menubar.ConnectNewGist(m.newGist)
 
 	if m.gistService.Logger == nil {
@@ -195,13 +284,19 @@ func (m *MainWindow) Display(app *widgets.QApplication) error {
 	}
 	m.app = app
 	m.setStyleSheet(":/qml/stylesheet.qss")
+	m.loadGrammars()
 	m.Show()
 
 	populate := func() {
 		m.lastGeometry()
 		m.app.ConnectAboutToQuit(m.recordGeometry)
+		m.restoreArrangement()
 		m.gistService.Username = m.settings.Username
 		m.gistService.Token = m.settings.Token
+		m.gistService.CacheSize = m.settings.CacheSize()
+		m.unlockCache()
+		m.gistService.DraftDir = m.draftDir()
+		m.restoreDrafts()
 		go m.populate()
 	}
 	m.settings, err = conf.New(m.name)
@@ -228,6 +323,35 @@ func (m *MainWindow) setStyleSheet(name string) {
 func (m *MainWindow) showSettings(callback func()) {
 	t := conf.NewTab(m.tabsWidget)
 	t.SetSettings(m.settings)
+	m.updateCacheSize(t)
+	t.ConnectClearCache(func() {
+		if err := m.gistService.Cache().Clear(); err != nil {
+			m.logger.Warningf("Clearing cache: %s", err)
+		}
+		m.updateCacheSize(t)
+	})
+	t.ConnectCompactCache(func() {
+		m.gistService.CacheSize = m.settings.CacheSize()
+		if err := m.gistService.Cache().Compact(); err != nil {
+			m.logger.Warningf("Compacting cache: %s", err)
+		}
+		m.updateCacheSize(t)
+	})
+	t.ConnectGenerateIdentity(func() {
+		m.generateIdentity(t)
+	})
+	t.ConnectEncryptCache(func(encrypt bool) {
+		if encrypt {
+			m.unlockCache()
+			t.EncryptCacheInput.SetChecked(m.settings.EncryptCache)
+			return
+		}
+		c := m.gistService.Cache()
+		if err := c.Decrypt(); err != nil {
+			m.logger.Warningf("Decrypting cache: %s", err)
+		}
+		m.gistService.CacheKey = nil
+	})
 	m.tabsWidget.AddTab(t, "Settings")
 	m.tabsWidget.SetCurrentWidget(t)
 	tabIndex := m.tabsWidget.IndexOf(t)
@@ -238,6 +362,59 @@ func (m *MainWindow) showSettings(callback func()) {
 	})
 }
 
+// unlockCache reads the cache key from the key file, or asks the user for the
+// passphrase. If the cache is not encrypted yet, its entries will be encrypted,
+// and encrypting it is turned off if the user doesn't choose a passphrase. The
+// gists are fetched from the API while the cache is locked.
+func (m *MainWindow) unlockCache() {
+	if !m.settings.EncryptCache {
+		return
+	}
+	c := m.gistService.Cache()
+	if m.settings.CacheKeyFile != "" {
+		secret, err := crypt.ReadKeyFile(m.settings.CacheKeyFile)
+		if err == nil {
+			err = c.Unlock(secret)
+		}
+		if err != nil {
+			m.logger.Warningf("Unlocking cache: %s", err)
+			return
+		}
+		m.gistService.CacheKey = c.Key
+		return
+	}
+
+	msg := "Passphrase"
+	if !c.Encrypted() {
+		msg = "Choose a passphrase for encrypting the cache"
+	}
+	for i := 0; i < unlockAttempts; i++ {
+		text, ok := m.passphrase(msg)
+		if !ok && i == 0 && !c.Encrypted() {
+			m.settings.SetEncryptCache(false)
+			return
+		}
+		if !ok {
+			break
+		}
+		err := c.Unlock([]byte(text))
+		if err == nil {
+			m.gistService.CacheKey = c.Key
+			return
+		}
+		msg = fmt.Sprintf("%s, try again", err)
+	}
+	m.logger.Warning("The cache is locked, gists will be fetched from GitHub")
+}
+
+func (m *MainWindow) updateCacheSize(t *conf.Tab) {
+	size, err := m.gistService.Cache().Size()
+	if err != nil {
+		m.logger.Warningf("Reading cache size: %s", err)
+	}
+	t.SetCacheSize(size)
+}
+
 func (m *MainWindow) lastGeometry() {
 	tmp := widgets.NewQWidget(nil, 0)
 	tmp.SetGeometry2(100, 100, 600, 600)
@@ -253,39 +430,112 @@ func (m *MainWindow) recordGeometry() {
 	m.settings.Sync()
 }
 
+// restoreArrangement sorts and groups the gist list as the user left it, and
+// records the changes.
+func (m *MainWindow) restoreArrangement() {
+	order := m.settings.Value(gistListSortOrder, core.NewQVariant7(0)).ToInt(nil)
+	grouping := m.settings.Value(gistListGrouping, core.NewQVariant7(0)).ToInt(nil)
+	m.gistList.SetSortOrder(gistlist.SortOrder(order))
+	m.gistList.SetGrouping(gistlist.Grouping(grouping))
+	m.gistList.ConnectArranged(func(order, grouping int) {
+		m.settings.SetValue(gistListSortOrder, core.NewQVariant7(order))
+		m.settings.SetValue(gistListGrouping, core.NewQVariant7(grouping))
+		m.settings.Sync()
+	})
+}
+
 func (m *MainWindow) populate() {
-	var foundOne bool
-	for item := range m.gistService.Iter() {
-		foundOne = true
+	var (
+		ids  []string
+		page []gist.Gist
+	)
+	gists, errc := m.gistService.Iter()
+	for item := range gists {
+		ids = append(ids, item.ID)
+		page = append(page, item)
 		m.searchbox.Add(item)
-		m.gistList.Add(item)
+		// list responses have no contents, the cached ones are indexed instead.
+		if g, err := m.gistService.Cached(item.ID); err == nil && g.UpdatedAt == item.UpdatedAt {
+			m.searchbox.Index().Add(g)
+		}
+		// the list is arranged once for each page, as it gets slow with
+		// many gists.
+		if len(page) == gist.PageSize {
+			m.gistList.AddGists(page)
+			page = nil
+		}
+	}
+	if len(page) > 0 {
+		m.gistList.AddGists(page)
 	}
-	if !foundOne {
+	listErr := <-errc
+	if listErr != nil {
+		m.logger.Warningf("Listing gists: %s", listErr)
+	}
+	if len(ids) == 0 {
 		m.logger.Error("didn't find any gists")
+		return
+	}
+	if starred, err := m.gistService.Starred(); err == nil {
+		m.searchbox.SetStarred(starred)
+	}
+	if listErr != nil {
+		// the gists that were not listed are not known to be deleted.
+		return
+	}
+	// removing the cache of the gists that were deleted elsewhere.
+	if err := m.gistService.Cache().Prune(ids); err != nil {
+		m.logger.Warningf("Cleaning up cache: %s", err)
 	}
 }
 
 func (m *MainWindow) newGist(bool) {
+	m.newGistTab("")
+}
+
+// newGistTab opens a tab for a new gist, which keeps its draft under the key.
+// A new key is made if the key is empty.
+func (m *MainWindow) newGistTab(key string) *tab.Tab {
+	if key == "" {
+		key = newDraftKey()
+	}
 	id := nextUntitled(m.tabGistList)
 	t := tab.NewTab(m.tabsWidget)
+	t.SetAllowlist(m.allowlist())
+	m.setEditorOptions(t)
 	t.NewGist(m.tabsWidget, id)
+	t.SetTags(m.gistList.TagNames())
 	m.tabGistList[id] = t
+	m.watchDirty(t)
+	m.journal(t, key)
 
 	t.ConnectCopyToClipboard(func(text string) {
 		m.clipboard().SetText(text, gui.QClipboard__Clipboard)
 	})
+	t.ConnectPinGist(m.pinGist)
 	t.ConnectCreateGist(func(g *gist.Gist) {
-		newGist, err := m.gistService.Create(*g)
+		out, err := m.prepareGist(*g, t.Encrypted())
+		if err != nil {
+			msg := fmt.Sprintf("Could not encrypt the gist: %s", err)
+			m.logger.Error(msg)
+			return
+		}
+		newGist, err := m.gistService.Create(out)
 		if err != nil {
 			msg := fmt.Sprintf("Could not create new gist: %s", err)
 			m.logger.Error(msg)
 			return
 		}
 		m.showNotification("New gist has been created")
-		t.GistCreated(&newGist)
+		shown := newGist
+		if t.Encrypted() {
+			shown.Files = g.Files
+		}
+		t.GistCreated(&shown)
 		m.searchbox.Add(newGist)
 		m.gistList.Add(newGist)
 	})
+	return t
 }
 
 func (m *MainWindow) openGist(id string) error {
@@ -298,26 +548,78 @@ func (m *MainWindow) openGist(id string) error {
 		return errors.Wrapf(err, "id: %s", id)
 	}
 
+	// remote keeps the file names on the server, as the tab changes the
+	// files of the gist it is showing.
+	remote := rg
+	remote.Files = make(map[string]gist.File, len(rg.Files))
+	for name, f := range rg.Files {
+		remote.Files[name] = f
+	}
+	if rg.Encrypted() {
+		if rg, err = m.decryptGist(rg); err != nil {
+			return errors.Wrapf(err, "id: %s", id)
+		}
+	}
+
+	m.searchbox.Index().Add(remote)
+
 	t := tab.NewTab(m.tabsWidget)
+	t.SetAllowlist(m.allowlist())
+	m.setEditorOptions(t)
 	t.ShowGist(m.tabsWidget, &rg)
+	t.SetTags(m.gistList.TagNames())
+	t.SetEncrypted(remote.Encrypted())
+	t.SetPinned(m.gistService.Cache().Pinned(id))
 	m.tabGistList[id] = t
+	m.watchDirty(t)
+	m.journal(t, id)
+	t.ConnectPinGist(m.pinGist)
 
 	t.ConnectCopyToClipboard(func(text string) {
 		m.clipboard().SetText(text, gui.QClipboard__Clipboard)
 	})
 
-	t.ConnectUpdateGist(func(g *gist.Gist) {
-		_, err := m.gistService.Update(*g)
+	update := func(g *gist.Gist) error {
+		out := *g
+		if t.Encrypted() || remote.Encrypted() {
+			var err error
+			if out, err = m.prepareGist(*g, t.Encrypted()); err != nil {
+				return errors.Wrap(err, "encrypting the gist")
+			}
+			out = gist.Patch(out, remote)
+		}
+		res, err := m.gistService.Update(out)
 		if err != nil {
+			return err
+		}
+		remote = res
+		m.searchbox.Index().Add(res)
+		return nil
+	}
+	t.ConnectUpdateGist(func(g *gist.Gist) {
+		if err := update(g); err != nil {
 			msg := fmt.Sprintf("Could not update the gist: %s", err)
 			m.logger.Error(msg)
 			return
 		}
+		t.Saved()
 		m.showNotification("Gist has been updated")
 	})
+	m.autoSave(t, id, update)
+
+	t.ConnectChangeVisibility(func(*gist.Gist) {
+		m.changeVisibility(remote)
+	})
+	t.ConnectDropFile(func(srcID, name string, copy bool) {
+		m.transferFile(srcID, name, id, copy)
+	})
 
 	t.ConnectDeleteFile(func(g *gist.Gist, name string) {
-		_, err := m.gistService.DeleteFile(*g, name)
+		remoteName := name
+		if _, ok := remote.Files[name+gist.EncryptedExt]; ok {
+			remoteName += gist.EncryptedExt
+		}
+		_, err := m.gistService.DeleteFile(*g, remoteName)
 		if err != nil {
 			msg := fmt.Sprintf("Could not delete file: %s", err)
 			m.logger.Error(msg)
@@ -338,13 +640,51 @@ func (m *MainWindow) openGist(id string) error {
 		m.gistList.Remove(g.ID)
 		tab := m.tabGistList[g.ID]
 		delete(m.tabGistList, g.ID)
+		m.releaseTab(tab)
 		m.showNotification("Gist has been removed")
 		tab.DestroyQWidget()
+		m.updateTrayBadge()
 	})
 
 	return nil
 }
 
+// pinGist keeps the gist in the cache, so it will be available offline.
+func (m *MainWindow) pinGist(g *gist.Gist, pin bool) {
+	var err error
+	switch pin {
+	case true:
+		err = m.gistService.Cache().Pin(g.ID)
+	case false:
+		err = m.gistService.Cache().Unpin(g.ID)
+	}
+	if err != nil {
+		m.logger.Warningf("Could not change offline state: %s", err)
+	}
+}
+
+// allowlist returns the patterns ignored by the secret scanner. Invalid
+// patterns are reported and the scanning continues without an allowlist.
+func (m *MainWindow) allowlist() *scan.Allowlist {
+	if m.settings == nil {
+		return nil
+	}
+	a, err := scan.NewAllowlist(m.settings.AllowlistEntries())
+	if err != nil {
+		m.logger.Warningf("Invalid scan allowlist: %s", err)
+		return nil
+	}
+	return a
+}
+
+// setEditorOptions applies the editor settings to the files of the tab.
+func (m *MainWindow) setEditorOptions(t *tab.Tab) {
+	if m.settings == nil {
+		return
+	}
+	t.SetEditorOptions(m.settings.TabWidth, m.settings.IndentSpaces)
+}
+
 func (m *MainWindow) tabIDFromIndex(index int) string {
 	tab := m.tabsWidget.Widget(index)
 	if tab.Pointer() == nil {
@@ -359,10 +699,27 @@ func (m *MainWindow) tabIDFromIndex(index int) string {
 	return ""
 }
 
+// closeTab closes the tab at index, after asking the user what to do with its
+// unsaved changes.
 func (m *MainWindow) closeTab(index int) {
 	id := m.tabIDFromIndex(index)
+	if t, ok := m.tabGistList[id]; ok && !m.confirmClose(t) {
+		return
+	}
+	if t, ok := m.tabGistList[id]; ok {
+		m.releaseTab(t)
+	}
 	m.tabsWidget.RemoveTab(index)
 	delete(m.tabGistList, id)
+	m.updateTrayBadge()
+}
+
+// releaseTab stops journaling and auto-saving the tab, and removes its draft.
+// It is called when the tab is closed, after its changes are saved or
+// discarded.
+func (m *MainWindow) releaseTab(t *tab.Tab) {
+	m.dropDraft(t)
+	m.stopAutoSave(t)
 }
 
 func (m *MainWindow) cacheDir() string {
@@ -376,6 +733,17 @@ func (m *MainWindow) cacheDir() string {
 	return cacheDir
 }
 
+// loadGrammars adds the user's grammar files to the syntax highlighters. The
+// files are in the grammars directory of the configuration.
+func (m *MainWindow) loadGrammars() {
+	loc := core.QStandardPaths_StandardLocations(core.QStandardPaths__GenericConfigLocation)[0]
+	grammars, err := syntax.LoadDir(path.Join(loc, m.name, "grammars"))
+	if err != nil {
+		m.logger.Warning(err.Error())
+	}
+	syntax.Default.Add(grammars...)
+}
+
 func nextUntitled(tabGistList map[string]*tab.Tab) string {
 	id := "untitled"
 	if _, ok := tabGistList[id]; !ok {
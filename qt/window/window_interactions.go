@@ -38,8 +38,9 @@ func (m *MainWindow) openSelectedGist(event *gui.QKeyEvent) {
 	switch core.Qt__Key(event.Key()) {
 	case core.Qt__Key_Enter, core.Qt__Key_Return:
 		index := m.gistList.CurrentIndex()
-		id := gistlist.NewContainerFromPointer(index.Pointer()).IndexID(index)
-		m.openGistByID(id)
+		list := gistlist.NewContainerFromPointer(index.Pointer())
+		m.openGistFile(list.IndexID(index), list.IndexFile(index))
+		m.searchbox.Hide()
 		event.Accept()
 	}
 }
@@ -54,10 +55,22 @@ func (m *MainWindow) openGistByID(id string) {
 
 func (m *MainWindow) gistListDoubleClickEvent(*core.QModelIndex) {
 	index := m.gistList.CurrentIndex()
-	id := gistlist.NewContainerFromPointer(index.Pointer()).IndexID(index)
-	err := m.openGist(id)
-	if err != nil {
+	list := gistlist.NewContainerFromPointer(index.Pointer())
+	m.openGistFile(list.IndexID(index), list.IndexFile(index))
+}
+
+// openGistFile opens the gist and moves the focus to the file. The gist is
+// only opened if name is empty.
+func (m *MainWindow) openGistFile(id, name string) {
+	if err := m.openGist(id); err != nil {
 		m.logger.Error(err.Error())
+		return
+	}
+	if name == "" {
+		return
+	}
+	if !m.tabGistList[id].FocusFile(name) {
+		m.logger.Warningf("file %s is not in the gist", name)
 	}
 }
 
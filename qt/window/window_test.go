@@ -221,6 +221,71 @@ func testLoadingGeometry(t *testing.T) {
 	check("to make sure: geometry.Height()", window.Geometry().Height(), h)
 }
 
+func TestRestoreArrangement(t *testing.T) { tRunner.Run(func() { testRestoreArrangement(t) }) }
+func testRestoreArrangement(t *testing.T) {
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+
+	settings, cleanup2 := testSettings(appName)
+	defer cleanup2()
+	settings.SetValue(gistListSortOrder, core.NewQVariant7(int(gistlist.SortName)))
+	settings.SetValue(gistListGrouping, core.NewQVariant7(int(gistlist.GroupTag)))
+	settings.Sync()
+	if window.settings, err = conf.New(appName); err != nil && window.settings == nil {
+		t.Fatalf("getting settings: %v", err)
+	}
+	window.restoreArrangement()
+	window.gistList.Add(gist.Gist{ID: "b2", Description: "beta #go"})
+	window.gistList.Add(gist.Gist{ID: "a1", Description: "alpha #go"})
+	if window.gistList.ID(1) != "a1" || window.gistList.ID(2) != "b2" {
+		t.Errorf("IDs = %s, %s, want the list sorted by name under a group", window.gistList.ID(1), window.gistList.ID(2))
+	}
+
+	window.gistList.SetSortOrder(gistlist.SortSize)
+	settings.Sync()
+	if v := settings.Value(gistListSortOrder, core.NewQVariant7(0)).ToInt(nil); v != int(gistlist.SortSize) {
+		t.Errorf("saved sort order = %d, want %d", v, gistlist.SortSize)
+	}
+}
+
+func TestGistFilter(t *testing.T) { tRunner.Run(func() { testGistFilter(t) }) }
+func testGistFilter(t *testing.T) {
+	gists := []gist.Gist{
+		{ID: "a1", Description: "alpha"},
+		{ID: "b2", Description: "beta"},
+	}
+	_, window, cleanup, err := setup(t, appName, gists, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+
+	window.gistFilter.SetText("bet")
+	window.populate()
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			window.gistList.Clear()
+			for _, g := range gists {
+				window.gistList.Add(g)
+			}
+		}
+		if window.gistList.Count() != len(gists) {
+			t.Fatalf("Count() = %d, want %d", window.gistList.Count(), len(gists))
+		}
+		for row := 0; row < window.gistList.Count(); row++ {
+			hidden := window.gistList.Item(row).IsHidden()
+			if id := window.gistList.ID(row); hidden != (id == "a1") {
+				t.Errorf("refresh %d: %s hidden = %t", i, id, hidden)
+			}
+		}
+	}
+}
+
 func TestViewGist(t *testing.T) { tRunner.Run(func() { testViewGist(t) }) }
 func testViewGist(t *testing.T) {
 	var (
@@ -234,6 +299,7 @@ func testViewGist(t *testing.T) {
 		fileName: gist.File{Content: content},
 	}
 	gres := gist.Gist{
+		ID:    id,
 		Files: files,
 	}
 	gistTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -294,6 +360,9 @@ func testViewGist(t *testing.T) {
 	if window.tabsWidget.TabText(index) != fileName {
 		t.Errorf("TabText(%d) = %s, want %s", index, window.tabsWidget.TabText(index), fileName)
 	}
+	if res := window.searchbox.Index().Search("asuonvyd"); len(res) != 1 || res[0].ID != id {
+		t.Errorf("the content was not indexed: %v", res)
+	}
 }
 
 func TestClickViewGist(t *testing.T) { tRunner.Run(func() { testClickViewGist(t) }) }
@@ -571,6 +640,36 @@ func testOpenSearchBox(t *testing.T) {
 	}
 }
 
+func TestOpenCommandPalette(t *testing.T) { tRunner.Run(func() { testOpenCommandPalette(t) }) }
+func testOpenCommandPalette(t *testing.T) {
+	_, window, cleanup, err := setup(t, appName, nil, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+
+	app.SetActiveWindow(window)
+	window.Show()
+	event := testlib.NewQTestEventList()
+	event.AddKeyPress(core.Qt__Key_P, core.Qt__ControlModifier|core.Qt__ShiftModifier, -1)
+	event.Simulate(window)
+	defer window.searchbox.Hide()
+	if !window.searchbox.IsVisible() {
+		t.Fatal("SearchBox is not shown")
+	}
+
+	var found bool
+	for _, c := range window.commands.Commands() {
+		if c.Name() == "Options: Quit" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("the menubar actions are not registered")
+	}
+}
+
 func TestClickOpenGist(t *testing.T) { tRunner.Run(func() { testClickOpenGist(t) }) }
 func testClickOpenGist(t *testing.T) {
 	var called bool
@@ -638,6 +737,54 @@ func testClickOpenGist(t *testing.T) {
 	}
 }
 
+func TestOpenGistFile(t *testing.T) { tRunner.Run(func() { testOpenGistFile(t) }) }
+func testOpenGistFile(t *testing.T) {
+	gres := gist.Gist{
+		ID:          "vP2sWn8qLd",
+		Description: "files",
+		Files: map[string]gist.File{
+			"one.go": {Content: "one"},
+			"two.go": {Content: "two"},
+		},
+	}
+	gistTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(gres)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Write(b)
+	}))
+	defer gistTs.Close()
+
+	_, window, cleanup, err := setup(t, appName, []gist.Gist{gres}, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer cleanup()
+	window.populate()
+	window.gistService.API = gistTs.URL
+
+	var warned bool
+	window.logger = &logger{
+		errorFunc:   func(str string) { t.Errorf("unexpected error: %s", str) },
+		warningFunc: func(str string) { warned = true },
+	}
+
+	window.openGistFile(gres.ID, "two.go")
+	if _, ok := window.tabGistList[gres.ID]; !ok {
+		t.Fatal("the gist was not opened")
+	}
+	if warned {
+		t.Error("warned about an existing file")
+	}
+	window.openGistFile(gres.ID, "three.go")
+	if !warned {
+		t.Error("no warnings about a missing file")
+	}
+}
+
 func TestUpdateGistError(t *testing.T) { tRunner.Run(func() { testUpdateGistError(t) }) }
 func testUpdateGistError(t *testing.T) {
 	var (
@@ -697,7 +844,8 @@ func testUpdateGistError(t *testing.T) {
 	file := tabWidget.Files()[0]
 	// hijacking the url to the new place
 	tabWidget.Gist().URL = updateTS.URL
-	file.Content().SetText(newContent)
+	file.Content().SetPlainText(newContent)
+	tabWidget.SetReview(func() bool { return true })
 	tabWidget.SaveButton().Click()
 	if !called {
 		t.Error("didn't call the server")
@@ -759,7 +907,8 @@ func testUpdateGist(t *testing.T) {
 	file := tabWidget.Files()[0]
 	// hijacking the url to the new place
 	tabWidget.Gist().URL = updateTS.URL
-	file.Content().SetText(newContent)
+	file.Content().SetPlainText(newContent)
+	tabWidget.SetReview(func() bool { return true })
 	tabWidget.SaveButton().Click()
 	if !called {
 		t.Error("didn't call the server")
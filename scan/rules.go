@@ -0,0 +1,30 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package scan
+
+import "regexp"
+
+// Rule detects one kind of secret.
+type Rule struct {
+	Name     string
+	Severity Severity
+	Pattern  *regexp.Regexp
+}
+
+// Rules are the rules applied on every line.
+var Rules = []Rule{
+	{"AWS access key", High, regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"AWS secret key", High, regexp.MustCompile(`(?i)aws.{0,20}(secret|key).{0,20}['"=:\s][A-Za-z0-9/+]{40}\b`)},
+	{"GitHub token", High, regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"GitHub fine-grained token", High, regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{60,}\b`)},
+	{"Slack token", High, regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"Slack webhook", High, regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`)},
+	{"Google API key", High, regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Stripe key", High, regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"Private key", High, regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY( BLOCK)?-----`)},
+	{"JSON web token", Medium, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`)},
+	{"Password assignment", Medium, regexp.MustCompile(`(?i)\b(password|passwd|secret|token|api_?key)\b\s*[:=]\s*['"][^'"\s]{8,}['"]`)},
+	{"Credentials in URL", Medium, regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s:@]+@[^\s]+`)},
+}
@@ -0,0 +1,230 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package scan finds credentials and other secrets in texts before they are
+// published.
+package scan
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severity shows how likely a finding is a real secret.
+type Severity int
+
+// Severities of findings.
+const (
+	Medium Severity = iota // might be a secret.
+	High                   // almost certainly a secret.
+)
+
+func (s Severity) String() string {
+	if s == High {
+		return "high"
+	}
+	return "medium"
+}
+
+// Verdict is the decision about publishing a text.
+type Verdict int
+
+// Verdicts for publishing.
+const (
+	Allow Verdict = iota
+	Warn
+	Block
+)
+
+// AllowMarker can be put on a line to skip it.
+const AllowMarker = "gistflow:allow"
+
+const (
+	minEntropyLen = 20  // shorter words are not checked for entropy.
+	base64Entropy = 4.5 // bits per character.
+	hexEntropy    = 3.0 // bits per character.
+)
+
+var (
+	entropyWord = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+	hexWord     = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	hashWord    = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`) // git commits and sha256 sums.
+)
+
+// Finding is one suspected secret.
+type Finding struct {
+	File     string
+	Line     int // starting from 1.
+	Rule     string
+	Severity Severity
+	Match    string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Rule)
+}
+
+// Allowlist holds regular expressions for the texts that should not be
+// reported.
+type Allowlist struct {
+	patterns []*regexp.Regexp
+}
+
+// NewAllowlist compiles the entries. Empty entries are ignored.
+func NewAllowlist(entries []string) (*Allowlist, error) {
+	a := &Allowlist{}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		r, err := regexp.Compile(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "allowlist entry %q", e)
+		}
+		a.patterns = append(a.patterns, r)
+	}
+	return a, nil
+}
+
+// Allowed returns true if the match is in the allowlist.
+func (a *Allowlist) Allowed(match string) bool {
+	if a == nil {
+		return false
+	}
+	for _, p := range a.patterns {
+		if p.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the findings in the contents of the file. Lines containing
+// AllowMarker and matches in the allowlist are skipped.
+func Text(file, contents string, allow *Allowlist) []Finding {
+	var findings []Finding
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	scanner.Buffer(make([]byte, 64*1024), len(contents)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.Contains(text, AllowMarker) {
+			continue
+		}
+		found := false
+		for _, r := range Rules {
+			for _, m := range r.Pattern.FindAllString(text, -1) {
+				if allow.Allowed(m) {
+					continue
+				}
+				found = true
+				findings = append(findings, Finding{
+					File:     file,
+					Line:     line,
+					Rule:     r.Name,
+					Severity: r.Severity,
+					Match:    m,
+				})
+			}
+		}
+		if found {
+			// the line is already reported.
+			continue
+		}
+		for _, w := range entropyWord.FindAllString(text, -1) {
+			if !highEntropy(w) || allow.Allowed(w) {
+				continue
+			}
+			findings = append(findings, Finding{
+				File:     file,
+				Line:     line,
+				Rule:     "High entropy string",
+				Severity: Medium,
+				Match:    w,
+			})
+		}
+	}
+	return findings
+}
+
+// Files scans all files, which are keyed by their names. The findings are
+// sorted by the file names and the lines.
+func Files(files map[string]string, allow *Allowlist) []Finding {
+	var findings []Finding
+	for name, contents := range files {
+		findings = append(findings, Text(name, contents, allow)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// Decide returns the verdict for publishing a text with the findings. Public
+// texts are blocked when they contain definite secrets and come with a warning
+// for possible ones. Secret texts only come with a warning for definite
+// secrets.
+func Decide(findings []Finding, public bool) Verdict {
+	v := Allow
+	for _, f := range findings {
+		switch {
+		case public && f.Severity == High:
+			return Block
+		case public || f.Severity == High:
+			v = Warn
+		}
+	}
+	return v
+}
+
+// Summary returns a human readable list of the findings.
+func Summary(findings []Finding) string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = f.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func highEntropy(word string) bool {
+	if len(word) < minEntropyLen {
+		return false
+	}
+	if hashWord.MatchString(word) {
+		// the hashes are all over the docs and are not secrets.
+		return false
+	}
+	if hexWord.MatchString(word) {
+		return entropy(word) > hexEntropy
+	}
+	// identifiers like some_long_function_name are not random.
+	if strings.Count(word, "_")+strings.Count(word, "-") > len(word)/8 {
+		return false
+	}
+	return entropy(word) > base64Entropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var e float64
+	l := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / l
+		e -= p * math.Log2(p)
+	}
+	return e
+}
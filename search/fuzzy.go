@@ -0,0 +1,204 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search
+
+import (
+	"bytes"
+	"html"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Scores of the fuzzy matcher. A match on a word boundary or a camelCase hump
+// is worth more than one in the middle of a word, and consecutive matches are
+// worth more than scattered ones.
+const (
+	scoreMatch       = 16
+	scoreGapStart    = -3
+	scoreGapExtend   = -1
+	bonusBoundary    = 8
+	bonusFirst       = 10 // matching the first character of the text.
+	bonusCamel       = 7
+	bonusConsecutive = 6
+	bonusCase        = 1 // matching the exact case.
+	maxFuzzyLen      = 512
+)
+
+const minScore = math.MinInt32 / 2
+
+// Fuzzy finds the characters of pattern in text in order, ignoring the case.
+// It returns the best score of all possible alignments and the positions of the
+// matched runes in text. The match fails if any character is not found.
+// Spaces in the pattern are ignored.
+func Fuzzy(pattern, text string) (score int, positions []int, ok bool) {
+	p := []rune(strings.Replace(pattern, " ", "", -1))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(t) > maxFuzzyLen {
+		t = t[:maxFuzzyLen]
+	}
+	if len(p) > len(t) {
+		return 0, nil, false
+	}
+	lp, lt := lower(p), lower(t)
+
+	// best[i][j] is the best score of matching p[:i+1] with p[i] on t[j], and
+	// from[i][j] is where p[i-1] was matched for that score.
+	best := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		best[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		// the gap before p[i], carried over from the previous columns.
+		gap, gapFrom := minScore, -1
+		for j := range t {
+			if i > 0 && j > 1 {
+				gap += scoreGapExtend
+				if prev := best[i-1][j-2]; prev > minScore && prev+scoreGapStart >= gap {
+					gap, gapFrom = prev+scoreGapStart, j-2
+				}
+			}
+			best[i][j] = minScore
+			if lp[i] != lt[j] {
+				continue
+			}
+			s := scoreMatch + bonus(t, j)
+			if p[i] == t[j] {
+				s += bonusCase
+			}
+			switch {
+			case i == 0:
+				best[i][j] = s
+			case j == 0:
+			default:
+				prev, k := minScore, -1
+				if best[i-1][j-1] > minScore {
+					prev, k = best[i-1][j-1]+bonusConsecutive, j-1
+				}
+				if gapFrom >= 0 && gap > prev {
+					prev, k = gap, gapFrom
+				}
+				if k >= 0 {
+					best[i][j], from[i][j] = prev+s, k
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	score = minScore
+	for j, s := range best[last] {
+		if s > score {
+			score, end = s, j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	positions = make([]int, len(p))
+	for i := last; i >= 0; i-- {
+		positions[i] = end
+		end = from[i][end]
+	}
+	return score, positions, true
+}
+
+func lower(runes []rune) []rune {
+	res := make([]rune, len(runes))
+	for i, r := range runes {
+		res[i] = unicode.ToLower(r)
+	}
+	return res
+}
+
+// bonus returns the bonus of matching the rune at position j of t.
+func bonus(t []rune, j int) int {
+	if j == 0 {
+		return bonusFirst
+	}
+	prev, cur := t[j-1], t[j]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case unicode.IsLetter(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+// Recency returns a bonus for recently updated gists. updatedAt is in the
+// RFC3339 format, as the API returns it.
+func Recency(updatedAt string, now time.Time) int {
+	t, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return 0
+	}
+	switch age := now.Sub(t); {
+	case age < 24*time.Hour:
+		return 10
+	case age < 7*24*time.Hour:
+		return 6
+	case age < 30*24*time.Hour:
+		return 3
+	case age < 365*24*time.Hour:
+		return 1
+	}
+	return 0
+}
+
+// Highlight returns text as HTML, with the runes at positions wrapped in the
+// tag.
+func Highlight(text string, positions []int, tag string) string {
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	buf := &bytes.Buffer{}
+	open := false
+	for i, r := range []rune(text) {
+		if marked[i] != open {
+			if open {
+				buf.WriteString("</" + tag + ">")
+			} else {
+				buf.WriteString("<" + tag + ">")
+			}
+			open = !open
+		}
+		buf.WriteString(html.EscapeString(string(r)))
+	}
+	if open {
+		buf.WriteString("</" + tag + ">")
+	}
+	return buf.String()
+}
+
+// HighlightWords returns text as HTML, with the occurrences of the words
+// wrapped in the tag. The words are matched ignoring the case.
+func HighlightWords(text string, words []string, tag string) string {
+	runes := []rune(text)
+	lowered := lower(runes)
+	var positions []int
+	for _, word := range words {
+		w := lower([]rune(word))
+		if len(w) == 0 {
+			continue
+		}
+		for i := 0; i+len(w) <= len(lowered); i++ {
+			if string(lowered[i:i+len(w)]) == string(w) {
+				for j := range w {
+					positions = append(positions, i+j)
+				}
+			}
+		}
+	}
+	return Highlight(text, positions, tag)
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/search"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tcs := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"ABC", "abc", true, []int{0, 1, 2}},
+		{"abc", "ab", false, nil},
+		{"acb", "abc", false, nil},
+		{"a*c", "abc", false, nil},
+		{"a?c", "a?c", true, []int{0, 1, 2}},
+		{"hs", "http server", true, []int{0, 5}},
+		{"gs", "getStatus", true, []int{0, 3}},
+		{"k8", "config k8s", true, []int{7, 8}},
+		{"file go", "main file.go", true, []int{5, 6, 7, 8, 10, 11}},
+		{"ée", "éclair de", true, []int{0, 8}},
+	}
+	for _, tc := range tcs {
+		_, positions, ok := search.Fuzzy(tc.pattern, tc.text)
+		if ok != tc.ok {
+			t.Errorf("Fuzzy(%q, %q): ok = %t, want %t", tc.pattern, tc.text, ok, tc.ok)
+			continue
+		}
+		if !reflect.DeepEqual(positions, tc.positions) {
+			t.Errorf("Fuzzy(%q, %q): positions = %v, want %v", tc.pattern, tc.text, positions, tc.positions)
+		}
+	}
+}
+
+func TestFuzzyRanking(t *testing.T) {
+	tcs := []struct {
+		pattern       string
+		better, worse string
+	}{
+		{"ser", "server", "user"},
+		{"ser", "http server", "laser"},
+		{"gs", "getStatus", "bugs"},
+		{"main", "main.go", "m_a_i_n.go"},
+		{"abc", "abc", "aXbXc"},
+		{"ab", "Ab", "xab"},
+	}
+	for _, tc := range tcs {
+		s1, _, ok1 := search.Fuzzy(tc.pattern, tc.better)
+		s2, _, ok2 := search.Fuzzy(tc.pattern, tc.worse)
+		if !ok1 || !ok2 {
+			t.Errorf("%q: expected both %q and %q to match", tc.pattern, tc.better, tc.worse)
+			continue
+		}
+		if s1 <= s2 {
+			t.Errorf("%q: score(%q) = %d, want more than score(%q) = %d", tc.pattern, tc.better, s1, tc.worse, s2)
+		}
+	}
+}
+
+func TestRecency(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		updated string
+		want    int
+	}{
+		{"", 0},
+		{"yesterday", 0},
+		{"2018-06-01T10:00:00Z", 10},
+		{"2018-05-28T10:00:00Z", 6},
+		{"2018-05-10T10:00:00Z", 3},
+		{"2017-10-10T10:00:00Z", 1},
+		{"2015-10-10T10:00:00Z", 0},
+	}
+	for _, tc := range tcs {
+		if got := search.Recency(tc.updated, now); got != tc.want {
+			t.Errorf("Recency(%q) = %d, want %d", tc.updated, got, tc.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tcs := []struct {
+		text      string
+		positions []int
+		want      string
+	}{
+		{"abc", nil, "abc"},
+		{"abc", []int{0, 1, 2}, "<b>abc</b>"},
+		{"abcd", []int{1, 3}, "a<b>b</b>c<b>d</b>"},
+		{"a<b>", []int{1}, "a<b>&lt;</b>b&gt;"},
+		{"\U0001F512 café", []int{5}, "\U0001F512 caf<b>é</b>"},
+	}
+	for _, tc := range tcs {
+		if got := search.Highlight(tc.text, tc.positions, "b"); got != tc.want {
+			t.Errorf("Highlight(%q, %v) = %q, want %q", tc.text, tc.positions, got, tc.want)
+		}
+	}
+}
+
+func TestHighlightWords(t *testing.T) {
+	tcs := []struct {
+		text  string
+		words []string
+		want  string
+	}{
+		{"abc", nil, "abc"},
+		{"select * FROM users", []string{"from"}, "select * <b>FROM</b> users"},
+		{"users & user", []string{"user"}, "<b>user</b>s &amp; <b>user</b>"},
+		{"aaa", []string{"aa"}, "<b>aaa</b>"},
+		{"go is good", []string{"go", ""}, "<b>go</b> is <b>go</b>od"},
+	}
+	for _, tc := range tcs {
+		if got := search.HighlightWords(tc.text, tc.words, "b"); got != tc.want {
+			t.Errorf("HighlightWords(%q, %v) = %q, want %q", tc.text, tc.words, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package search finds gists by their descriptions, file names and contents.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+// Weights of the terms based on where they appear in a gist.
+const (
+	contentWeight     = 1.0
+	descriptionWeight = 2.0
+	nameWeight        = 3.0
+	prefixWeight      = 0.5 // applied when a term only starts with the query.
+	minTokenLen       = 2
+	maxContentLen     = 1 << 20 // bigger contents are truncated.
+)
+
+// Result is a gist that matched a query. Files holds the names of the files
+// that matched.
+type Result struct {
+	ID    string
+	Score float64
+	Files []string
+}
+
+// posting is the occurrences of a term in a gist.
+type posting struct {
+	weight float64
+	files  map[string]struct{}
+}
+
+// Index is an inverted index of the terms in the descriptions, file names and
+// contents of gists. Contents of encrypted files are never indexed. It is safe
+// for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	docs  map[string]gist.Gist
+	terms map[string]map[string]*posting // term to gist ID.
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:  make(map[string]gist.Gist),
+		terms: make(map[string]map[string]*posting),
+	}
+}
+
+// Len returns the number of gists in the index.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Contents returns the indexed contents of the files of the gist identified
+// by id. Encrypted files are left out.
+func (i *Index) Contents(id string) []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var res []string
+	for name, f := range i.docs[id].Files {
+		if f.Content != "" && !strings.HasSuffix(name, gist.EncryptedExt) {
+			res = append(res, f.Content)
+		}
+	}
+	return res
+}
+
+// Files returns the indexed contents of the gist identified by id, keyed by
+// the file names. Encrypted files are left out.
+func (i *Index) Files(id string) map[string]string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	res := make(map[string]string)
+	for name, f := range i.docs[id].Files {
+		if f.Content != "" && !strings.HasSuffix(name, gist.EncryptedExt) {
+			res[name] = f.Content
+		}
+	}
+	return res
+}
+
+// Add indexes g, replacing its previous state. Gists from a list response come
+// without contents, therefore a file with no content keeps the content it was
+// indexed with before.
+func (i *Index) Add(g gist.Gist) {
+	if g.ID == "" {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	doc := gist.Gist{
+		ID:          g.ID,
+		Description: g.Description,
+		Files:       make(map[string]gist.File, len(g.Files)),
+	}
+	old, ok := i.docs[g.ID]
+	for name, f := range g.Files {
+		if f.Content == "" && ok {
+			f = old.Files[name]
+		}
+		doc.Files[name] = gist.File{Content: f.Content}
+	}
+	i.remove(g.ID)
+	i.docs[g.ID] = doc
+
+	for _, term := range Tokens(doc.Description) {
+		i.post(term, g.ID, descriptionWeight, "")
+	}
+	for name, f := range doc.Files {
+		plain := strings.TrimSuffix(name, gist.EncryptedExt)
+		for _, term := range Tokens(plain) {
+			i.post(term, g.ID, nameWeight, name)
+		}
+		if plain != name {
+			continue
+		}
+		content := f.Content
+		if len(content) > maxContentLen {
+			content = content[:maxContentLen]
+		}
+		for _, term := range Tokens(content) {
+			i.post(term, g.ID, contentWeight, name)
+		}
+	}
+}
+
+// Remove removes the gist identified by id from the index.
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id string) {
+	if _, ok := i.docs[id]; !ok {
+		return
+	}
+	delete(i.docs, id)
+	for term, ids := range i.terms {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(i.terms, term)
+		}
+	}
+}
+
+func (i *Index) post(term, id string, weight float64, file string) {
+	ids, ok := i.terms[term]
+	if !ok {
+		ids = make(map[string]*posting)
+		i.terms[term] = ids
+	}
+	p, ok := ids[id]
+	if !ok {
+		p = &posting{files: make(map[string]struct{})}
+		ids[id] = p
+	}
+	p.weight += weight
+	if file != "" {
+		p.files[file] = struct{}{}
+	}
+}
+
+// Search returns the gists that contain all terms of the query, the best
+// matches first. A term also matches the words it is a prefix of, with a lower
+// score.
+func (i *Index) Search(query string) []Result {
+	words := Tokens(query)
+	if len(words) == 0 {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var matches map[string]*Result
+	for _, word := range words {
+		found := i.match(word)
+		if matches == nil {
+			matches = found
+			continue
+		}
+		for id, r := range matches {
+			f, ok := found[id]
+			if !ok {
+				delete(matches, id)
+				continue
+			}
+			r.Score += f.Score
+			r.Files = append(r.Files, f.Files...)
+		}
+	}
+
+	res := make([]Result, 0, len(matches))
+	for _, r := range matches {
+		r.Files = unique(r.Files)
+		res = append(res, *r)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if res[a].Score != res[b].Score {
+			return res[a].Score > res[b].Score
+		}
+		return res[a].ID < res[b].ID
+	})
+	return res
+}
+
+// match returns the gists with any terms that start with word.
+func (i *Index) match(word string) map[string]*Result {
+	total := float64(len(i.docs))
+	found := make(map[string]*Result)
+	for term, ids := range i.terms {
+		if !strings.HasPrefix(term, word) {
+			continue
+		}
+		weight := 1.0
+		if term != word {
+			weight = prefixWeight
+		}
+		idf := math.Log(1 + total/float64(len(ids)))
+		for id, p := range ids {
+			r, ok := found[id]
+			if !ok {
+				r = &Result{ID: id}
+				found[id] = r
+			}
+			// the weight is saturated so repeated terms don't dominate.
+			r.Score += weight * idf * p.weight / (p.weight + 1)
+			for name := range p.files {
+				r.Files = append(r.Files, name)
+			}
+		}
+	}
+	return found
+}
+
+func unique(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	res := names[:1]
+	for _, name := range names[1:] {
+		if name != res[len(res)-1] {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// Tokens returns the lower-cased words of text. Identifiers in camelCase are
+// also broken into their parts, so "parseHTTPRequest" matches "request".
+func Tokens(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	res := make([]string, 0, len(words))
+	for _, word := range words {
+		parts := camelParts(word)
+		if len(parts) > 1 {
+			res = appendToken(res, word)
+		}
+		for _, part := range parts {
+			res = appendToken(res, part)
+		}
+	}
+	return res
+}
+
+func appendToken(tokens []string, word string) []string {
+	if len([]rune(word)) < minTokenLen {
+		return tokens
+	}
+	return append(tokens, strings.ToLower(word))
+}
+
+// camelParts splits word on its lower to upper case changes. An upper case
+// letter followed by a lower case one starts a new part after an acronym.
+func camelParts(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for j := 1; j < len(runes); j++ {
+		prev, cur := runes[j-1], runes[j]
+		next := j+1 < len(runes) && unicode.IsLower(runes[j+1])
+		if unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && next) {
+			parts = append(parts, string(runes[start:j]))
+			start = j
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
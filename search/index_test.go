@@ -0,0 +1,178 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+)
+
+func TestTokens(t *testing.T) {
+	tcs := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"a b", []string{}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"parseHTTPRequest", []string{"parsehttprequest", "parse", "http", "request"}},
+		{"snake_case-name", []string{"snake", "case", "name"}},
+		{"v2Client", []string{"v2client", "v2", "client"}},
+		{"ÜberCafé", []string{"übercafé", "über", "café"}},
+	}
+	for _, tc := range tcs {
+		if got := search.Tokens(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Tokens(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func testIndex() *search.Index {
+	idx := search.NewIndex()
+	idx.Add(gist.Gist{
+		ID:          "server",
+		Description: "HTTP server",
+		Files: map[string]gist.File{
+			"main.go": {Content: "func main() { http.ListenAndServe(addr, nil) }"},
+		},
+	})
+	idx.Add(gist.Gist{
+		ID:          "query",
+		Description: "Postgres snippets",
+		Files: map[string]gist.File{
+			"users.sql":   {Content: "SELECT * FROM users WHERE active"},
+			"servers.sql": {Content: "SELECT name FROM servers"},
+		},
+	})
+	idx.Add(gist.Gist{
+		ID: "secret",
+		Files: map[string]gist.File{
+			"token.txt" + gist.EncryptedExt: {Content: "ListenAndServe"},
+		},
+	})
+	return idx
+}
+
+func ids(res []search.Result) []string {
+	var ids []string
+	for _, r := range res {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	if idx.Len() != 3 {
+		t.Errorf("idx.Len() = %d, want 3", idx.Len())
+	}
+	tcs := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"listen", []string{"server"}},
+		{"ListenAndServe", []string{"server"}},
+		{"select users", []string{"query"}},
+		{"select nothing", nil},
+		{"postgres", []string{"query"}},
+		{"token", []string{"secret"}},
+		{"enc", nil},
+		{"server", []string{"server", "query"}},
+	}
+	for _, tc := range tcs {
+		if got := ids(idx.Search(tc.query)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	idx := testIndex()
+	res := idx.Search("users")
+	if len(res) != 1 {
+		t.Fatalf("len(res) = %d, want 1", len(res))
+	}
+	if want := []string{"users.sql"}; !reflect.DeepEqual(res[0].Files, want) {
+		t.Errorf("res[0].Files = %v, want %v", res[0].Files, want)
+	}
+	res = idx.Search("select")
+	if want := []string{"servers.sql", "users.sql"}; !reflect.DeepEqual(res[0].Files, want) {
+		t.Errorf("res[0].Files = %v, want %v", res[0].Files, want)
+	}
+}
+
+func TestIndexAddKeepsContent(t *testing.T) {
+	idx := testIndex()
+	// as it comes from a list response.
+	idx.Add(gist.Gist{
+		ID:          "server",
+		Description: "HTTP server",
+		Files: map[string]gist.File{
+			"main.go":  {},
+			"other.go": {},
+		},
+	})
+	if got := ids(idx.Search("listenandserve")); !reflect.DeepEqual(got, []string{"server"}) {
+		t.Errorf("content was dropped: got %v", got)
+	}
+	if got := ids(idx.Search("other")); !reflect.DeepEqual(got, []string{"server"}) {
+		t.Errorf("new file was not indexed: got %v", got)
+	}
+
+	idx.Add(gist.Gist{
+		ID:    "server",
+		Files: map[string]gist.File{"main.go": {Content: "package main"}},
+	})
+	if got := idx.Search("listen"); len(got) != 0 {
+		t.Errorf("old content is still indexed: got %v", ids(got))
+	}
+	if got := idx.Search("other"); len(got) != 0 {
+		t.Errorf("removed file is still indexed: got %v", ids(got))
+	}
+}
+
+func TestIndexRemove(t *testing.T) {
+	idx := testIndex()
+	idx.Remove("server")
+	idx.Remove("not found")
+	if idx.Len() != 2 {
+		t.Errorf("idx.Len() = %d, want 2", idx.Len())
+	}
+	if got := ids(idx.Search("server")); !reflect.DeepEqual(got, []string{"query"}) {
+		t.Errorf("Search(server) = %v, want [query]", got)
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := search.NewIndex()
+	idx.Add(gist.Gist{ID: "content", Files: map[string]gist.File{"a.txt": {Content: "docker"}}})
+	idx.Add(gist.Gist{ID: "name", Files: map[string]gist.File{"docker.txt": {Content: "x"}}})
+	idx.Add(gist.Gist{ID: "prefix", Files: map[string]gist.File{"b.txt": {Content: "dockerized"}}})
+	want := []string{"name", "content", "prefix"}
+	if got := ids(idx.Search("docker")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(docker) = %v, want %v", got, want)
+	}
+}
+
+func TestIndexFiles(t *testing.T) {
+	idx := testIndex()
+	want := map[string]string{"main.go": "func main() { http.ListenAndServe(addr, nil) }"}
+	if got := idx.Files("server"); !reflect.DeepEqual(got, want) {
+		t.Errorf("idx.Files(server) = %v, want %v", got, want)
+	}
+	if got := idx.Files("secret"); len(got) != 0 {
+		t.Errorf("encrypted contents are returned: %v", got)
+	}
+	if got := idx.Contents("secret"); len(got) != 0 {
+		t.Errorf("encrypted contents are returned: %v", got)
+	}
+	if got := idx.Files("not found"); len(got) != 0 {
+		t.Errorf("idx.Files(not found) = %v, want empty", got)
+	}
+}
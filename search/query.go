@@ -0,0 +1,415 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arsham/gistflow/gist"
+)
+
+const dateLayout = "2006-01-02"
+
+// Meta is the information about a gist that queries are evaluated against.
+// Contents are only available for the gists that are in the index.
+type Meta struct {
+	Description string
+	Files       []string
+	Languages   []string
+	Contents    []string
+	Public      bool
+	Starred     bool
+	CreatedAt   string
+	UpdatedAt   string
+	Size        int
+	Tags        []string
+}
+
+// NewMeta returns the metadata of g.
+func NewMeta(g gist.Gist) Meta {
+	m := Meta{
+		Description: g.Description,
+		Public:      g.Public,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+		Tags:        Tags(g.Description),
+	}
+	for name, f := range g.Files {
+		m.Files = append(m.Files, name)
+		if lang := Language(name, f.Language); lang != "" {
+			m.Languages = append(m.Languages, lang)
+		}
+		if f.Size > 0 {
+			m.Size += f.Size
+		} else {
+			m.Size += len(f.Content)
+		}
+		if f.Content != "" {
+			m.Contents = append(m.Contents, f.Content)
+		}
+	}
+	return m
+}
+
+var extLanguages = map[string]string{
+	".c":    "c",
+	".cpp":  "c++",
+	".css":  "css",
+	".go":   "go",
+	".html": "html",
+	".java": "java",
+	".js":   "javascript",
+	".json": "json",
+	".md":   "markdown",
+	".php":  "php",
+	".py":   "python",
+	".rb":   "ruby",
+	".rs":   "rust",
+	".sh":   "shell",
+	".sql":  "sql",
+	".ts":   "typescript",
+	".yaml": "yaml",
+	".yml":  "yaml",
+}
+
+// Language returns the lower-cased language of the file. The language the API
+// reports is preferred over guessing from the extension.
+func Language(name, reported string) string {
+	if reported != "" {
+		return strings.ToLower(reported)
+	}
+	name = strings.TrimSuffix(name, gist.EncryptedExt)
+	return extLanguages[strings.ToLower(path.Ext(name))]
+}
+
+// SyntaxError is returned when a query cannot be parsed. Pos is the byte
+// offset of the problem in the query.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// Term is one part of a query. Terms without a Field match the free text.
+type Term struct {
+	Negate bool
+	Field  string
+	Op     string // one of "", ">", ">=", "<" and "<=".
+	Value  string
+	Phrase bool // the value was quoted.
+
+	num int
+}
+
+// Query is a parsed search query. All terms must match a gist.
+type Query struct {
+	Terms []Term
+}
+
+// qualifiers maps the accepted field names to their canonical names.
+var qualifiers = map[string]string{
+	"lang":     "lang",
+	"language": "lang",
+	"file":     "file",
+	"filename": "file",
+	"public":   "public",
+	"starred":  "starred",
+	"tag":      "tag",
+	"updated":  "updated",
+	"created":  "created",
+	"size":     "size",
+}
+
+// ParseQuery parses the query. Words are matched against the descriptions and
+// the files, and qualifiers like lang:go, file:*.sql, public:false,
+// starred:true, tag:k8s, updated:>2018-01-01 and size:>10k narrow them down. A
+// leading minus negates a term and double quotes keep the words of a phrase
+// together. Words like TODO: or http://x that don't start with a known
+// qualifier are searched as text.
+func ParseQuery(query string) (*Query, error) {
+	q := &Query{}
+	for pos := 0; pos < len(query); {
+		if query[pos] == ' ' || query[pos] == '\t' {
+			pos++
+			continue
+		}
+		var t Term
+		if query[pos] == '-' && pos+1 < len(query) && query[pos+1] != ' ' {
+			t.Negate = true
+			pos++
+		}
+		word, next, err := readWord(query, pos)
+		if err != nil {
+			return nil, err
+		}
+		raw := query[pos:next]
+		if field, i, ok := qualifier(raw); ok {
+			t.Field = field
+			valuePos := pos + i + 1
+			if word, _, err = readWord(query, valuePos); err != nil {
+				return nil, err
+			}
+			t.Phrase = strings.HasPrefix(query[valuePos:], `"`)
+			if err := t.parseValue(word, valuePos); err != nil {
+				return nil, err
+			}
+		} else {
+			t.Value = word
+			t.Phrase = strings.HasPrefix(raw, `"`)
+		}
+		if t.Value == "" && t.Field == "" {
+			pos = next
+			continue
+		}
+		q.Terms = append(q.Terms, t)
+		pos = next
+	}
+	return q, nil
+}
+
+// qualifier returns the field of the qualifier the word starts with, and the
+// position of its colon. Words like TODO: or http://x that don't start with a
+// known qualifier are searched as they are.
+func qualifier(word string) (field string, colon int, ok bool) {
+	colon = strings.IndexByte(word, ':')
+	if colon <= 0 || strings.ContainsRune(word[:colon], '"') {
+		return "", 0, false
+	}
+	field, ok = qualifiers[strings.ToLower(word[:colon])]
+	return field, colon, ok
+}
+
+// readWord reads until the next space that is not in quotes. The quotes are
+// removed from the returned word.
+func readWord(query string, pos int) (word string, next int, err error) {
+	buf := &bytes.Buffer{}
+	quoted, quotePos := false, 0
+	for next = pos; next < len(query); next++ {
+		c := query[next]
+		switch {
+		case c == '"':
+			quoted, quotePos = !quoted, next
+			continue
+		case !quoted && (c == ' ' || c == '\t'):
+			return buf.String(), next, nil
+		}
+		buf.WriteByte(c)
+	}
+	if quoted {
+		return "", next, &SyntaxError{Pos: quotePos, Msg: "unterminated quote"}
+	}
+	return buf.String(), next, nil
+}
+
+func (t *Term) parseValue(value string, pos int) error {
+	if !t.Phrase {
+		for _, op := range []string{">=", "<=", ">", "<"} {
+			if strings.HasPrefix(value, op) {
+				t.Op, value = op, value[len(op):]
+				break
+			}
+		}
+	}
+	t.Value = strings.ToLower(value)
+	if t.Value == "" {
+		return &SyntaxError{Pos: pos, Msg: fmt.Sprintf("%s needs a value", t.Field)}
+	}
+	fail := func(format string, a ...interface{}) error {
+		return &SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+	}
+	switch t.Field {
+	case "public", "starred":
+		if t.Op != "" {
+			return fail("%s cannot be compared", t.Field)
+		}
+		switch t.Value {
+		case "true", "yes":
+			t.Value = "true"
+		case "false", "no":
+			t.Value = "false"
+		default:
+			return fail("%s should be true or false, got %q", t.Field, value)
+		}
+	case "updated", "created":
+		if _, err := time.Parse(dateLayout, t.Value); err != nil {
+			return fail("%s should be a date like 2018-01-31, got %q", t.Field, value)
+		}
+	case "size":
+		n, err := parseSize(t.Value)
+		if err != nil {
+			return fail("size should be a number like 10k, got %q", value)
+		}
+		t.num = n
+	default:
+		if t.Op != "" {
+			return fail("%s cannot be compared", t.Field)
+		}
+	}
+	return nil
+}
+
+// parseSize parses sizes like 100, 10k and 2m in bytes.
+func parseSize(s string) (int, error) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "kb"):
+		unit, s = 1<<10, strings.TrimRight(s, "kb")
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "mb"):
+		unit, s = 1<<20, strings.TrimRight(s, "mb")
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("bad size: %s", s)
+	}
+	return int(f * float64(unit)), nil
+}
+
+// Text returns the words of the query that are not negated or qualified,
+// which are used for ranking the results.
+func (q *Query) Text() string {
+	var words []string
+	for _, t := range q.Terms {
+		if t.Field == "" && !t.Negate {
+			words = append(words, t.Value)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Filters reports whether the query has terms that are not used for ranking,
+// therefore it should be evaluated with Match.
+func (q *Query) Filters() bool {
+	for _, t := range q.Terms {
+		if t.Field != "" || t.Negate || t.Phrase {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the gist with the metadata satisfies all the terms
+// that are not used for ranking. Free text words are left to the ranking.
+func (q *Query) Match(m Meta) bool {
+	for _, t := range q.Terms {
+		if t.Field == "" && !t.Negate && !t.Phrase {
+			continue
+		}
+		if t.match(m) == t.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Term) match(m Meta) bool {
+	switch t.Field {
+	case "":
+		return containsText(m, t.Value)
+	case "lang":
+		return containsFold(m.Languages, t.Value, equalFold)
+	case "file":
+		return containsFold(m.Files, t.Value, matchName)
+	case "tag":
+		return containsFold(m.Tags, strings.TrimPrefix(t.Value, "#"), equalFold)
+	case "public":
+		return m.Public == (t.Value == "true")
+	case "starred":
+		return m.Starred == (t.Value == "true")
+	case "updated":
+		return compare(t.Op, day(m.UpdatedAt), t.Value)
+	case "created":
+		return compare(t.Op, day(m.CreatedAt), t.Value)
+	case "size":
+		return compareInt(t.Op, m.Size, t.num)
+	}
+	return false
+}
+
+// containsText reports whether the description, the file names or the
+// contents contain text.
+func containsText(m Meta, text string) bool {
+	text = strings.ToLower(text)
+	if strings.Contains(strings.ToLower(m.Description), text) {
+		return true
+	}
+	for _, list := range [][]string{m.Files, m.Contents} {
+		for _, s := range list {
+			if strings.Contains(strings.ToLower(s), text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, value string, fn func(s, value string) bool) bool {
+	for _, s := range list {
+		if fn(strings.ToLower(s), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalFold(s, value string) bool { return s == value }
+
+// matchName matches the name against the glob pattern, or checks if it
+// contains the value if it is not a pattern.
+func matchName(name, value string) bool {
+	if !strings.ContainsAny(value, "*?[") {
+		return strings.Contains(name, value)
+	}
+	ok, err := path.Match(value, name)
+	return err == nil && ok
+}
+
+// day returns the date of the RFC3339 timestamp in UTC.
+func day(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(dateLayout)
+}
+
+// compare compares dates in the 2006-01-02 format, which sort as strings.
+func compare(op, a, b string) bool {
+	if a == "" {
+		return false
+	}
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return a == b
+}
+
+func compareInt(op string, a, b int) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return a == b
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/gist"
+	"github.com/arsham/gistflow/search"
+)
+
+func TestLanguage(t *testing.T) {
+	tcs := []struct{ name, reported, want string }{
+		{"main.go", "", "go"},
+		{"main.go", "Go", "go"},
+		{"Query.SQL", "", "sql"},
+		{"secret.py" + gist.EncryptedExt, "", "python"},
+		{"README", "", ""},
+		{"notes", "Markdown", "markdown"},
+	}
+	for _, tc := range tcs {
+		if got := search.Language(tc.name, tc.reported); got != tc.want {
+			t.Errorf("Language(%q, %q) = %q, want %q", tc.name, tc.reported, got, tc.want)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tcs := []struct {
+		query string
+		want  []search.Term
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"hello world", []search.Term{{Value: "hello"}, {Value: "world"}}},
+		{`"hello world"`, []search.Term{{Value: "hello world", Phrase: true}}},
+		{`-"hello world" -bye`, []search.Term{
+			{Value: "hello world", Phrase: true, Negate: true},
+			{Value: "bye", Negate: true},
+		}},
+		{"lang:Go", []search.Term{{Field: "lang", Value: "go"}}},
+		{"-language:go", []search.Term{{Field: "lang", Value: "go", Negate: true}}},
+		{"file:*.sql", []search.Term{{Field: "file", Value: "*.sql"}}},
+		{`file:"my file.go"`, []search.Term{{Field: "file", Value: "my file.go", Phrase: true}}},
+		{"public:no", []search.Term{{Field: "public", Value: "false"}}},
+		{"updated:>=2018-01-01", []search.Term{{Field: "updated", Op: ">=", Value: "2018-01-01"}}},
+		{`"a:b"`, []search.Term{{Value: "a:b", Phrase: true}}},
+		{"- x", []search.Term{{Value: "-"}, {Value: "x"}}},
+		{"TODO: fix", []search.Term{{Value: "TODO:"}, {Value: "fix"}}},
+		{"http://x std::vector note:", []search.Term{{Value: "http://x"}, {Value: "std::vector"}, {Value: "note:"}}},
+		{"-owner:me", []search.Term{{Value: "owner:me", Negate: true}}},
+	}
+	for _, tc := range tcs {
+		q, err := search.ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): err = %v, want nil", tc.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Terms, tc.want) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tc.query, q.Terms, tc.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tcs := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`hello "world`, 6, "unterminated quote"},
+		{"lang:", 5, "needs a value"},
+		{"public:maybe", 7, "true or false"},
+		{"starred:>true", 8, "cannot be compared"},
+		{"lang:>go", 5, "cannot be compared"},
+		{"updated:>yesterday", 8, "date"},
+		{"size:>lots", 5, "number"},
+	}
+	for _, tc := range tcs {
+		_, err := search.ParseQuery(tc.query)
+		e, ok := err.(*search.SyntaxError)
+		if !ok {
+			t.Errorf("ParseQuery(%q): err = %v, want *SyntaxError", tc.query, err)
+			continue
+		}
+		if e.Pos != tc.pos || !strings.Contains(e.Msg, tc.msg) {
+			t.Errorf("ParseQuery(%q): err = %v, want %q at %d", tc.query, e, tc.msg, tc.pos)
+		}
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	q, err := search.ParseQuery(`deploy -old lang:go "blue green"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Text(), "deploy blue green"; got != want {
+		t.Errorf("q.Text() = %q, want %q", got, want)
+	}
+	if !q.Filters() {
+		t.Error("q.Filters() = false, want true")
+	}
+	q, _ = search.ParseQuery("deploy")
+	if q.Filters() {
+		t.Error("q.Filters() = true, want false")
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	m := search.NewMeta(gist.Gist{
+		Description: "Kubernetes deploy #k8s",
+		Public:      true,
+		CreatedAt:   "2017-05-01T10:00:00Z",
+		UpdatedAt:   "2018-03-15T23:30:00Z",
+		Files: map[string]gist.File{
+			"deploy.yaml": {Size: 8 << 10, Language: "YAML"},
+			"init.sql":    {Content: "CREATE TABLE users"},
+		},
+	})
+	m.Starred = true
+	tcs := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"anything", true}, // free text is left to ranking.
+		{"lang:yaml", true},
+		{"lang:sql", true},
+		{"lang:go", false},
+		{"-lang:go", true},
+		{"file:*.sql", true},
+		{"file:*.go", false},
+		{"file:deploy", true},
+		{"public:true", true},
+		{"public:false", false},
+		{"starred:true", true},
+		{"tag:k8s", true},
+		{"tag:#K8S", true},
+		{"tag:helm", false},
+		{"updated:2018-03-15", true},
+		{"updated:>2018-03-15", false},
+		{"updated:>=2018-03-15", true},
+		{"updated:<2019-01-01", true},
+		{"created:<2018-01-01", true},
+		{"size:>8k", true},
+		{"size:>9k", false},
+		{"size:<=1m", true},
+		{`"create table"`, true},
+		{`"table create"`, false},
+		{`-"create table"`, false},
+		{"-kubernetes", false},
+		{"-helm", true},
+		{"lang:yaml public:false", false},
+	}
+	for _, tc := range tcs {
+		q, err := search.ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): err = %v", tc.query, err)
+			continue
+		}
+		if got := q.Match(m); got != tc.want {
+			t.Errorf("%q: q.Match() = %t, want %t", tc.query, got, tc.want)
+		}
+	}
+}
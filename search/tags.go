@@ -0,0 +1,99 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var tagRe = regexp.MustCompile(`(?:^|\s)#([\pL\pN_\-./]*[\pL\pN_])`)
+
+// Tags returns the #tags in the description, lower-cased and without the hash.
+func Tags(description string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, m := range tagRe.FindAllStringSubmatch(description, -1) {
+		tag := strings.ToLower(m[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// NormalizeTag returns the tag lower-cased and without the hash. It returns an
+// empty string if tag cannot be written in a description as a whole.
+func NormalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	if tags := Tags("#" + tag); len(tags) == 1 && tags[0] == strings.ToLower(tag) {
+		return tags[0]
+	}
+	return ""
+}
+
+// tagSpans returns the byte offsets of the hash and the end of each occurrence
+// of tag in the description.
+func tagSpans(description, tag string) [][2]int {
+	var spans [][2]int
+	for _, m := range tagRe.FindAllStringSubmatchIndex(description, -1) {
+		if strings.ToLower(description[m[2]:m[3]]) == tag {
+			spans = append(spans, [2]int{m[2] - 1, m[3]})
+		}
+	}
+	return spans
+}
+
+// AddTag appends #tag to the description, unless it already has the tag.
+func AddTag(description, tag string) string {
+	tag = NormalizeTag(tag)
+	if tag == "" || len(tagSpans(description, tag)) > 0 {
+		return description
+	}
+	description = strings.TrimRightFunc(description, unicode.IsSpace)
+	if description == "" {
+		return "#" + tag
+	}
+	return description + " #" + tag
+}
+
+// RemoveTag removes every occurrence of #tag from the description, with the
+// space that separated it from the rest.
+func RemoveTag(description, tag string) string {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return description
+	}
+	spans := tagSpans(description, tag)
+	for i := len(spans) - 1; i >= 0; i-- {
+		start, end := spans[i][0], spans[i][1]
+		before := strings.TrimRightFunc(description[:start], unicode.IsSpace)
+		after := description[end:]
+		if before == "" {
+			after = strings.TrimLeftFunc(after, unicode.IsSpace)
+		}
+		description = before + after
+	}
+	return description
+}
+
+// RenameTag replaces #old with #new in the description. If the description
+// already has the new tag, the old one is removed.
+func RenameTag(description, old, new string) string {
+	old, new = NormalizeTag(old), NormalizeTag(new)
+	if old == "" || new == "" || old == new {
+		return description
+	}
+	if len(tagSpans(description, new)) > 0 {
+		return RemoveTag(description, old)
+	}
+	spans := tagSpans(description, old)
+	for i := len(spans) - 1; i >= 0; i-- {
+		description = description[:spans[i][0]] + "#" + new + description[spans[i][1]:]
+	}
+	return description
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/search"
+)
+
+func TestTags(t *testing.T) {
+	tcs := []struct {
+		description string
+		want        []string
+	}{
+		{"", nil},
+		{"no tags", nil},
+		{"#k8s deployment", []string{"k8s"}},
+		{"deploy #K8s #helm #k8s", []string{"k8s", "helm"}},
+		{"issue#12 and # alone", nil},
+		{"#c++ #go-lang #v1.2.", []string{"c", "go-lang", "v1.2"}},
+	}
+	for _, tc := range tcs {
+		if got := search.Tags(tc.description); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Tags(%q) = %v, want %v", tc.description, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tcs := []struct{ tag, want string }{
+		{"k8s", "k8s"},
+		{" #K8s ", "k8s"},
+		{"go-lang", "go-lang"},
+		{"", ""},
+		{"#", ""},
+		{"two words", ""},
+		{"c++", ""},
+		{"v1.", ""},
+	}
+	for _, tc := range tcs {
+		if got := search.NormalizeTag(tc.tag); got != tc.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestAddTag(t *testing.T) {
+	tcs := []struct{ description, tag, want string }{
+		{"", "k8s", "#k8s"},
+		{"deployment ", "#K8s", "deployment #k8s"},
+		{"deployment #K8S", "k8s", "deployment #K8S"},
+		{"deployment #k8s-prod", "k8s", "deployment #k8s-prod #k8s"},
+		{"deployment", "not valid", "deployment"},
+	}
+	for _, tc := range tcs {
+		if got := search.AddTag(tc.description, tc.tag); got != tc.want {
+			t.Errorf("AddTag(%q, %q) = %q, want %q", tc.description, tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	tcs := []struct{ description, tag, want string }{
+		{"#k8s", "k8s", ""},
+		{"#k8s deployment", "k8s", "deployment"},
+		{"deployment #K8s", "k8s", "deployment"},
+		{"a #k8s b #k8s", "k8s", "a b"},
+		{"a #k8s, b", "k8s", "a, b"},
+		{"a #k8s-prod", "k8s", "a #k8s-prod"},
+		{"issue#k8s", "k8s", "issue#k8s"},
+	}
+	for _, tc := range tcs {
+		if got := search.RemoveTag(tc.description, tc.tag); got != tc.want {
+			t.Errorf("RemoveTag(%q, %q) = %q, want %q", tc.description, tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tcs := []struct{ description, old, new, want string }{
+		{"deploy #k8s", "k8s", "kubernetes", "deploy #kubernetes"},
+		{"#K8s and #k8s", "#k8s", "#kube", "#kube and #kube"},
+		{"#k8s #kube", "k8s", "kube", "#kube"},
+		{"deploy #helm", "k8s", "kube", "deploy #helm"},
+		{"deploy #k8s", "k8s", "not valid", "deploy #k8s"},
+	}
+	for _, tc := range tcs {
+		if got := search.RenameTag(tc.description, tc.old, tc.new); got != tc.want {
+			t.Errorf("RenameTag(%q, %q, %q) = %q, want %q", tc.description, tc.old, tc.new, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax
+
+import "errors"
+
+// Errors for grammar files.
+var (
+	ErrNoName       = errors.New("grammar has no name")
+	ErrNoFiles      = errors.New("grammar has no extensions or file names")
+	ErrNoRules      = errors.New("grammar has no rules or regions")
+	ErrUnknownKind  = errors.New("unknown token kind")
+	ErrEmptyPattern = errors.New("empty pattern")
+	ErrEmptyStart   = errors.New("region start matches empty text")
+)
@@ -0,0 +1,283 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Patterns shared by the grammars.
+const (
+	doubleQuoted = `"(?:\\.|[^"\\])*"`
+	singleQuoted = `'(?:\\.|[^'\\])*'`
+	number       = `\b(?:0[xXbBoO][0-9a-fA-F_]+|\d[\d_]*(?:\.\d+)?(?:[eE][+-]?\d+)?)\b`
+	call         = `\b([A-Za-z_]\w*)\s*\(`
+	hashComment  = `(?:^|\s)(#.*)`
+)
+
+func rule(kind Kind, pattern string) Rule {
+	return Rule{Kind: kind, Pattern: regexp.MustCompile(pattern)}
+}
+
+// words returns a rule matching any of the whole words.
+func words(kind Kind, list string) Rule {
+	return rule(kind, `\b(?:`+strings.Join(strings.Fields(list), "|")+`)\b`)
+}
+
+// foldWords is like words, but ignores the case.
+func foldWords(kind Kind, list string) Rule {
+	return rule(kind, `(?i)\b(?:`+strings.Join(strings.Fields(list), "|")+`)\b`)
+}
+
+func region(kind Kind, start, end string) Region {
+	return Region{Kind: kind, Start: regexp.MustCompile(start), End: regexp.MustCompile(end)}
+}
+
+var blockComment = region(Comment, `/\*`, `\*/`)
+
+// Grammars returns the grammars shipped with the application.
+func Grammars() []*Grammar {
+	return []*Grammar{
+		goGrammar(), pythonGrammar(), shellGrammar(), javaScriptGrammar(),
+		typeScriptGrammar(), jsonGrammar(), yamlGrammar(), sqlGrammar(),
+		markdownGrammar(), dockerfileGrammar(),
+	}
+}
+
+func goGrammar() *Grammar {
+	return &Grammar{
+		Name:       "Go",
+		Aliases:    []string{"golang"},
+		Extensions: []string{".go"},
+		FileNames:  []string{"go.mod"},
+		Rules: []Rule{
+			rule(Comment, `//.*`),
+			rule(String, doubleQuoted+`|'(?:\\.|[^'\\])+'`),
+			words(Keyword, `break case chan const continue default defer else
+				fallthrough for func go goto if import interface map package range
+				return select struct switch type var`),
+			words(Type, `any bool byte complex64 complex128 error float32 float64
+				int int8 int16 int32 int64 rune string uint uint8 uint16 uint32
+				uint64 uintptr`),
+			words(Builtin, `append cap close complex copy delete imag len make new
+				panic print println real recover true false nil iota`),
+			rule(Number, number),
+			rule(Function, call),
+		},
+		Regions: []Region{
+			blockComment,
+			region(String, "`", "`"),
+		},
+	}
+}
+
+func pythonGrammar() *Grammar {
+	return &Grammar{
+		Name:       "Python",
+		Aliases:    []string{"py", "python3"},
+		Extensions: []string{".py", ".pyw", ".pyi"},
+		Rules: []Rule{
+			rule(Comment, `#.*`),
+			rule(String, doubleQuoted+`|`+singleQuoted),
+			rule(Builtin, `@[\w.]+`),
+			words(Keyword, `and as assert async await break class continue def del
+				elif else except finally for from global if import in is lambda
+				nonlocal not or pass raise return try while with yield`),
+			words(Builtin, `True False None self cls print len range open int str
+				float list dict set tuple bool bytes isinstance super type
+				enumerate zip map filter sorted min max sum abs any all`),
+			rule(Number, number),
+			rule(Function, call),
+		},
+		Regions: []Region{
+			region(String, `"""`, `"""`),
+			region(String, `'''`, `'''`),
+		},
+	}
+}
+
+func shellGrammar() *Grammar {
+	return &Grammar{
+		Name:       "Shell",
+		Aliases:    []string{"sh", "bash", "zsh"},
+		Extensions: []string{".sh", ".bash", ".zsh"},
+		FileNames:  []string{".bashrc", ".bash_profile", ".profile", ".zshrc"},
+		Rules: []Rule{
+			rule(Comment, hashComment),
+			rule(Variable, `\$(?:\{[^}]*\}|\w+|[@*#?$!-])`),
+			words(Keyword, `if then else elif fi for while until do done case esac
+				in function select return break continue local export readonly
+				declare unset shift exit`),
+			words(Builtin, `echo printf cd pwd source read test set eval exec trap
+				true false`),
+			rule(Number, `\b\d+\b`),
+		},
+		Regions: []Region{
+			region(String, `"`, `\A(?:\\.|[^"\\])*"`),
+			region(String, `'`, `'`),
+		},
+	}
+}
+
+var (
+	jsKeywords = `break case catch class const continue debugger default delete
+		do else export extends finally for function if import in instanceof let
+		new return super switch this throw try typeof var void while with yield
+		async await of static get set from as`
+	jsBuiltins = `true false null undefined NaN Infinity console window document
+		Math JSON Object Array String Number Boolean Promise Symbol Map Set Error
+		RegExp Date`
+)
+
+func javaScriptGrammar() *Grammar {
+	return &Grammar{
+		Name:       "JavaScript",
+		Aliases:    []string{"js", "jsx"},
+		Extensions: []string{".js", ".mjs", ".cjs", ".jsx"},
+		Rules:      jsRules(jsKeywords, ""),
+		Regions:    jsRegions(),
+	}
+}
+
+func typeScriptGrammar() *Grammar {
+	return &Grammar{
+		Name:       "TypeScript",
+		Aliases:    []string{"ts", "tsx"},
+		Extensions: []string{".ts", ".mts", ".cts", ".tsx"},
+		Rules: jsRules(jsKeywords+` interface type enum implements private public
+			protected readonly declare namespace abstract keyof is`,
+			`string number boolean any unknown never void object bigint symbol`),
+		Regions: jsRegions(),
+	}
+}
+
+func jsRules(keywords, types string) []Rule {
+	rules := []Rule{
+		rule(Comment, `//.*`),
+		rule(String, doubleQuoted+`|`+singleQuoted),
+		words(Keyword, keywords),
+	}
+	if types != "" {
+		rules = append(rules, words(Type, types))
+	}
+	return append(rules,
+		words(Builtin, jsBuiltins),
+		rule(Number, number),
+		rule(Function, call),
+	)
+}
+
+func jsRegions() []Region {
+	return []Region{
+		blockComment,
+		region(String, "`", "\\A(?:\\\\.|[^`\\\\])*`"),
+	}
+}
+
+func jsonGrammar() *Grammar {
+	return &Grammar{
+		Name:       "JSON",
+		Extensions: []string{".json", ".geojson", ".jsonc"},
+		FileNames:  []string{".babelrc", ".eslintrc"},
+		Rules: []Rule{
+			rule(Comment, `//.*`),
+			rule(Key, `(`+doubleQuoted+`)\s*:`),
+			rule(String, doubleQuoted),
+			words(Builtin, `true false null`),
+			rule(Number, `-?\b\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b`),
+		},
+		Regions: []Region{blockComment},
+	}
+}
+
+func yamlGrammar() *Grammar {
+	return &Grammar{
+		Name:       "YAML",
+		Aliases:    []string{"yml"},
+		Extensions: []string{".yml", ".yaml"},
+		Rules: []Rule{
+			rule(Comment, hashComment),
+			rule(Keyword, `^(?:---|\.\.\.)\s*$`),
+			rule(Key, `^\s*(?:-\s+)?([\w.-]+|"[^"]*"|'[^']*')\s*:(?:\s|$)`),
+			rule(String, doubleQuoted+`|'(?:''|[^'])*'`),
+			rule(Variable, `[&*][\w-]+`),
+			rule(Type, `!!?[\w-]+`),
+			rule(Builtin, `(?i)\b(?:true|false|null|yes|no|on|off)\b|~`),
+			rule(Number, `-?\b\d+(?:\.\d+)?\b`),
+		},
+	}
+}
+
+func sqlGrammar() *Grammar {
+	return &Grammar{
+		Name:       "SQL",
+		Aliases:    []string{"plsql", "postgresql", "mysql"},
+		Extensions: []string{".sql", ".psql"},
+		Rules: []Rule{
+			rule(Comment, `--.*`),
+			rule(String, `'(?:''|[^'])*'`),
+			rule(Variable, `"[^"]*"`),
+			foldWords(Keyword, `select from where and or not insert into values
+				update set delete create table drop alter index view join left right
+				inner outer full cross on as group by order having limit offset union
+				all distinct case when then else end is null like ilike in between
+				exists primary key foreign references default constraint unique begin
+				commit rollback transaction with returning cascade if asc desc`),
+			foldWords(Type, `int integer bigint smallint serial bigserial text
+				varchar char boolean bool date time timestamp timestamptz interval
+				numeric decimal float real double json jsonb uuid bytea`),
+			foldWords(Builtin, `count sum avg min max coalesce nullif now cast
+				lower upper length substring true false`),
+			rule(Number, `\b\d+(?:\.\d+)?\b`),
+			rule(Function, call),
+		},
+		Regions: []Region{blockComment},
+	}
+}
+
+func markdownGrammar() *Grammar {
+	return &Grammar{
+		Name:       "Markdown",
+		Aliases:    []string{"md"},
+		Extensions: []string{".md", ".markdown", ".mdown"},
+		Rules: []Rule{
+			rule(Heading, `^#{1,6}\s.*`),
+			rule(Comment, `^\s*>.*`),
+			rule(Operator, `^\s*(?:[-*_]\s*){3,}$`),
+			rule(Keyword, `^\s*(?:[-*+]|\d+[.)])\s(?:\[[ xX]\]\s)?`),
+			rule(Code, "`[^`]+`"),
+			rule(Link, `!?\[[^\]]*\]\([^)]*\)|<https?://[^>]+>`),
+			rule(Emphasis, `\*\*[^*]+\*\*|__[^_]+__|\*[^*\s][^*]*\*|\b_[^_]+_\b`),
+		},
+		Regions: []Region{
+			region(Code, "^\\s*```", "^\\s*```"),
+			region(Code, `^\s*~~~`, `^\s*~~~`),
+			region(Comment, `<!--`, `-->`),
+		},
+	}
+}
+
+// instructions are the instructions of Dockerfiles, in any case.
+const instructions = `(?i:FROM|RUN|CMD|LABEL|MAINTAINER|EXPOSE|ENV|ADD|COPY|` +
+	`ENTRYPOINT|VOLUME|USER|WORKDIR|ARG|ONBUILD|STOPSIGNAL|HEALTHCHECK|SHELL)`
+
+func dockerfileGrammar() *Grammar {
+	return &Grammar{
+		Name:       "Dockerfile",
+		Aliases:    []string{"docker", "containerfile"},
+		Extensions: []string{".dockerfile"},
+		FileNames:  []string{"Dockerfile", "Dockerfile.*", "Containerfile"},
+		Rules: []Rule{
+			rule(Comment, `^\s*#.*`),
+			rule(Key, `^\s*(`+instructions+`)\b`),
+			rule(String, doubleQuoted+`|`+singleQuoted),
+			rule(Variable, `\$(?:\{[^}]*\}|\w+)`),
+			rule(Operator, `(?:^|\s)(--?[\w-]+)`),
+			foldWords(Keyword, `as`),
+			rule(Number, `\b\d+\b`),
+		},
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arsham/gistflow/syntax"
+)
+
+func TestGrammars(t *testing.T) {
+	tcs := []struct {
+		file  string
+		lines []string
+		want  []string
+	}{
+		{"main.go", []string{"func main() { // start", "\ts := `raw", "line` + len(x)"}, []string{
+			"keyword:func", "function:main", "comment:// start", "string:`raw", "string:line`", "builtin:len",
+		}},
+		{"main.go", []string{"var n int = 0x1F"}, []string{"keyword:var", "type:int", "number:0x1F"}},
+		{"app.py", []string{`@app.route("/")`, `def f(x): """doc`, `"""  # done`}, []string{
+			"builtin:@app.route", `string:"/"`, "keyword:def", "function:f", `string:"""doc`, `string:"""`, "comment:# done",
+		}},
+		{"run.sh", []string{`echo "$HOME \"x" # it's`, `for f in $#; do`}, []string{
+			"builtin:echo", `string:"$HOME \"x"`, "comment:# it's", "keyword:for", "keyword:in", "variable:$#", "keyword:do",
+		}},
+		{"app.js", []string{"const s = `a ${b}", "` // x"}, []string{
+			"keyword:const", "string:`a ${b}", "string:`", "comment:// x",
+		}},
+		{"app.ts", []string{"let n: number = null"}, []string{"keyword:let", "type:number", "builtin:null"}},
+		{"data.json", []string{`{"a": "b", "n": -1.5, "t": true}`}, []string{
+			`key:"a"`, `string:"b"`, `key:"n"`, "number:-1.5", `key:"t"`, "builtin:true",
+		}},
+		{"ci.yml", []string{"---", "- name: 'it''s' # x", "  on: yes"}, []string{
+			"keyword:---", "key:name", "string:'it''s'", "comment:# x", "key:on", "builtin:yes",
+		}},
+		{"q.sql", []string{"SELECT count(*) FROM t -- all", "WHERE name = 'o''k'"}, []string{
+			"keyword:SELECT", "builtin:count", "keyword:FROM", "comment:-- all", "keyword:WHERE", "string:'o''k'",
+		}},
+		{"README.md", []string{"# Title", "- [x] **done** `code`", "```go", "# not a title", "```"}, []string{
+			"heading:# Title", "keyword:- [x] ", "emphasis:**done**", "code:`code`", "code:```go", "code:# not a title", "code:```",
+		}},
+		{"Dockerfile.dev", []string{"FROM golang AS build", "run go build -o $OUT"}, []string{
+			"key:FROM", "keyword:AS", "key:run", "operator:-o", "variable:$OUT",
+		}},
+	}
+	for _, tc := range tcs {
+		g := syntax.ForFile(tc.file)
+		if g == nil {
+			t.Errorf("ForFile(%q) = nil", tc.file)
+			continue
+		}
+		got, _ := tokens(g, tc.lines...)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: tokens = %q\nwant %q", tc.file, got, tc.want)
+		}
+	}
+}
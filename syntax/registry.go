@@ -0,0 +1,233 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Registry finds the grammars of files by their names, or by the names of
+// their languages.
+type Registry struct {
+	mu       sync.RWMutex
+	grammars []*Grammar
+}
+
+// Default holds the built-in grammars, and the grammars the user adds.
+var Default = NewRegistry(Grammars()...)
+
+// NewRegistry returns a Registry with the grammars.
+func NewRegistry(grammars ...*Grammar) *Registry {
+	r := &Registry{}
+	r.Add(grammars...)
+	return r
+}
+
+// Add adds the grammars. They take precedence over the grammars added before
+// them for the same files or languages.
+func (r *Registry) Add(grammars ...*Grammar) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.grammars = append(r.grammars, grammars...)
+}
+
+// ForFile returns the grammar of the file. The file names are matched before
+// the extensions. It returns nil if there is no grammar for the file.
+func (r *Registry) ForFile(name string) *Grammar {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	base := path.Base(strings.TrimSuffix(name, ".enc"))
+	for i := len(r.grammars) - 1; i >= 0; i-- {
+		for _, pattern := range r.grammars[i].FileNames {
+			if ok, _ := path.Match(pattern, base); ok {
+				return r.grammars[i]
+			}
+		}
+	}
+	ext := strings.ToLower(path.Ext(base))
+	if ext == "" {
+		return nil
+	}
+	for i := len(r.grammars) - 1; i >= 0; i-- {
+		for _, e := range r.grammars[i].Extensions {
+			if strings.ToLower(e) == ext {
+				return r.grammars[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ForLanguage returns the grammar with the name or the alias, in any case. It
+// returns nil if there is no grammar for the language.
+func (r *Registry) ForLanguage(lang string) *Grammar {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.grammars) - 1; i >= 0; i-- {
+		g := r.grammars[i]
+		if strings.EqualFold(g.Name, lang) {
+			return g
+		}
+		for _, alias := range g.Aliases {
+			if strings.EqualFold(alias, lang) {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+// ForFile returns the grammar of the file from the Default registry.
+func ForFile(name string) *Grammar { return Default.ForFile(name) }
+
+// ForLanguage returns the grammar of the language from the Default registry.
+func ForLanguage(lang string) *Grammar { return Default.ForLanguage(lang) }
+
+// grammarFile is the JSON form of a Grammar. Kinds are named as returned by
+// Kind.String.
+type grammarFile struct {
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+	Extensions []string `json:"extensions"`
+	FileNames  []string `json:"filenames"`
+	Rules      []struct {
+		Kind    string `json:"kind"`
+		Pattern string `json:"pattern"`
+	} `json:"rules"`
+	Regions []struct {
+		Kind  string `json:"kind"`
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"regions"`
+}
+
+// Load reads a grammar in JSON, for example:
+//
+//	{
+//	    "name": "TOML",
+//	    "extensions": [".toml"],
+//	    "rules": [
+//	        {"kind": "comment", "pattern": "#.*"},
+//	        {"kind": "key", "pattern": "^\\s*([\\w.-]+)\\s*="}
+//	    ],
+//	    "regions": [
+//	        {"kind": "string", "start": "\"\"\"", "end": "\"\"\""}
+//	    ]
+//	}
+//
+// The patterns are regular expressions with the syntax of the regexp package.
+func Load(r io.Reader) (*Grammar, error) {
+	var f grammarFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, errors.Wrap(err, "decoding grammar")
+	}
+	switch {
+	case strings.TrimSpace(f.Name) == "":
+		return nil, ErrNoName
+	case len(f.Extensions) == 0 && len(f.FileNames) == 0:
+		return nil, ErrNoFiles
+	case len(f.Rules) == 0 && len(f.Regions) == 0:
+		return nil, ErrNoRules
+	}
+	g := &Grammar{
+		Name:      f.Name,
+		Aliases:   f.Aliases,
+		FileNames: f.FileNames,
+	}
+	for _, ext := range f.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		g.Extensions = append(g.Extensions, ext)
+	}
+	for i, r := range f.Rules {
+		kind, re, err := compile(r.Kind, r.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %d", i+1)
+		}
+		g.Rules = append(g.Rules, Rule{Kind: kind, Pattern: re})
+	}
+	for i, r := range f.Regions {
+		kind, start, err := compile(r.Kind, r.Start)
+		if err != nil {
+			return nil, errors.Wrapf(err, "region %d start", i+1)
+		}
+		if start.MatchString("") {
+			return nil, errors.Wrapf(ErrEmptyStart, "region %d", i+1)
+		}
+		_, end, err := compile(r.Kind, r.End)
+		if err != nil {
+			return nil, errors.Wrapf(err, "region %d end", i+1)
+		}
+		g.Regions = append(g.Regions, Region{Kind: kind, Start: start, End: end})
+	}
+	return g, nil
+}
+
+func compile(kind, pattern string) (Kind, *regexp.Regexp, error) {
+	k, ok := ParseKind(kind)
+	if !ok {
+		return Plain, nil, errors.Wrap(ErrUnknownKind, kind)
+	}
+	if pattern == "" {
+		return Plain, nil, ErrEmptyPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Plain, nil, err
+	}
+	return k, re, nil
+}
+
+// LoadDir reads the grammars of all .json files in the directory. Files that
+// can't be loaded are reported in the error, and the rest are returned. A
+// directory that does not exist has no grammars.
+func LoadDir(dir string) ([]*Grammar, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading grammars")
+	}
+	var (
+		grammars []*Grammar
+		failed   []string
+	)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		g, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			failed = append(failed, e.Name()+": "+err.Error())
+			continue
+		}
+		grammars = append(grammars, g)
+	}
+	if len(failed) > 0 {
+		return grammars, errors.Errorf("loading grammars: %s", strings.Join(failed, "; "))
+	}
+	return grammars, nil
+}
+
+func loadFile(name string) (*Grammar, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arsham/gistflow/syntax"
+	"github.com/pkg/errors"
+)
+
+func TestRegistry(t *testing.T) {
+	custom := &syntax.Grammar{Name: "Custom Go", Extensions: []string{".go"}}
+	r := syntax.NewRegistry(syntax.Grammars()...)
+	tcs := []struct{ file, want string }{
+		{"main.go", "Go"},
+		{"MAIN.GO", "Go"},
+		{"secret.go.enc", "Go"},
+		{"dir/Dockerfile", "Dockerfile"},
+		{"Dockerfile.prod", "Dockerfile"},
+		{".bashrc", "Shell"},
+		{"README", ""},
+		{"archive.tar.gz", ""},
+	}
+	for _, tc := range tcs {
+		got := ""
+		if g := r.ForFile(tc.file); g != nil {
+			got = g.Name
+		}
+		if got != tc.want {
+			t.Errorf("ForFile(%q) = %q, want %q", tc.file, got, tc.want)
+		}
+	}
+	for lang, want := range map[string]string{"go": "Go", "BASH": "Shell", "yml": "YAML", "cobol": ""} {
+		got := ""
+		if g := r.ForLanguage(lang); g != nil {
+			got = g.Name
+		}
+		if got != want {
+			t.Errorf("ForLanguage(%q) = %q, want %q", lang, got, want)
+		}
+	}
+
+	r.Add(custom)
+	if g := r.ForFile("main.go"); g != custom {
+		t.Errorf("ForFile(main.go) = %v, want the grammar added last", g)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	g, err := syntax.Load(strings.NewReader(`{
+		"name": "TOML",
+		"extensions": ["toml"],
+		"rules": [{"kind": "comment", "pattern": "#.*"}],
+		"regions": [{"kind": "string", "start": "\"\"\"", "end": "\"\"\""}]
+	}`))
+	if err != nil {
+		t.Fatalf("Load(): err = %v, want nil", err)
+	}
+	if g.Name != "TOML" || len(g.Extensions) != 1 || g.Extensions[0] != ".toml" {
+		t.Errorf("Load() = %+v, want TOML with .toml", g)
+	}
+	got, _ := tokens(g, `a = """x # y""" # z`)
+	if want := []string{`string:"""x # y"""`, "comment:# z"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("tokens = %q, want %q", got, want)
+	}
+
+	tcs := []struct {
+		name, input string
+		want        error
+	}{
+		{"no name", `{"extensions": [".x"], "rules": [{"kind": "comment", "pattern": "#"}]}`, syntax.ErrNoName},
+		{"no files", `{"name": "x", "rules": [{"kind": "comment", "pattern": "#"}]}`, syntax.ErrNoFiles},
+		{"no rules", `{"name": "x", "extensions": [".x"]}`, syntax.ErrNoRules},
+		{"kind", `{"name": "x", "extensions": [".x"], "rules": [{"kind": "colour", "pattern": "#"}]}`, syntax.ErrUnknownKind},
+		{"empty", `{"name": "x", "extensions": [".x"], "regions": [{"kind": "string", "start": "'"}]}`, syntax.ErrEmptyPattern},
+		{"empty start", `{"name": "x", "extensions": [".x"], "regions": [{"kind": "string", "start": "a*", "end": "b*"}]}`, syntax.ErrEmptyStart},
+	}
+	for _, tc := range tcs {
+		if _, err := syntax.Load(strings.NewReader(tc.input)); errors.Cause(err) != tc.want {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := syntax.Load(strings.NewReader(`{"name": "x", "extensions": [".x"], "rules": [{"kind": "string", "pattern": "("}]}`)); err == nil {
+		t.Error("Load(): err = nil, want an error for a bad pattern")
+	}
+	if _, err := syntax.Load(strings.NewReader(`{`)); err == nil {
+		t.Error("Load(): err = nil, want an error for bad JSON")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grammars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"toml.json":  `{"name": "TOML", "extensions": [".toml"], "rules": [{"kind": "comment", "pattern": "#.*"}]}`,
+		"bad.json":   `{"name": "Bad"}`,
+		"notes.txt":  `not a grammar`,
+		"ini.json":   `{"name": "INI", "extensions": [".ini"], "rules": [{"kind": "key", "pattern": "^\\[.*\\]"}]}`,
+		"empty.json": ``,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	grammars, err := syntax.LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "bad.json") || !strings.Contains(err.Error(), "empty.json") {
+		t.Errorf("LoadDir(): err = %v, want the bad files reported", err)
+	}
+	if len(grammars) != 2 {
+		t.Errorf("len(grammars) = %d, want 2", len(grammars))
+	}
+
+	grammars, err = syntax.LoadDir(filepath.Join(dir, "missing"))
+	if err != nil || grammars != nil {
+		t.Errorf("LoadDir(missing) = (%v, %v), want (nil, nil)", grammars, err)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+// Package syntax splits lines of source code into tokens for highlighting.
+// Lines are tokenized one at a time, with a state carried from each line to
+// the next one, so only the lines that change need to be tokenized again.
+package syntax
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind is the class of a token, which decides its colour.
+type Kind int
+
+// Kinds of tokens.
+const (
+	Plain Kind = iota
+	Keyword
+	Type
+	Builtin
+	Function
+	String
+	Number
+	Comment
+	Operator
+	Variable
+	Key      // keys of JSON and YAML, and instructions of Dockerfiles.
+	Heading  // headings of Markdown.
+	Emphasis // emphasised texts of Markdown.
+	Link     // links of Markdown.
+	Code     // code spans and blocks of Markdown.
+)
+
+var kindNames = []string{
+	"plain", "keyword", "type", "builtin", "function", "string", "number",
+	"comment", "operator", "variable", "key", "heading", "emphasis", "link", "code",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// ParseKind returns the Kind named s, as returned by its String method.
+func ParseKind(s string) (Kind, bool) {
+	for i, name := range kindNames {
+		if strings.EqualFold(name, s) {
+			return Kind(i), true
+		}
+	}
+	return Plain, false
+}
+
+// MaxLineLength is the length of the longest line that is tokenized. Longer
+// lines, like minified files, are left plain to keep the editor responsive.
+const MaxLineLength = 4000
+
+// Token is a part of a line from the Start byte up to the End byte.
+type Token struct {
+	Start, End int
+	Kind       Kind
+}
+
+// Rule marks the texts matching its Pattern. If the pattern has a group, only
+// the first group is marked.
+type Rule struct {
+	Kind    Kind
+	Pattern *regexp.Regexp
+}
+
+// Region is a token that can span lines, like block comments and multi-line
+// strings. It starts with a text matching Start and ends after the text
+// matching End. An End starting with \A has to match right after the start,
+// or at the start of the next lines, which helps skipping escaped quotes.
+type Region struct {
+	Kind       Kind
+	Start, End *regexp.Regexp
+}
+
+// Grammar is a set of rules for a language. The files it applies to are found
+// by their extensions or their names.
+type Grammar struct {
+	Name       string
+	Aliases    []string // other names of the language, like sh for Shell.
+	Extensions []string // with the leading dot.
+	FileNames  []string // patterns of whole names, like Dockerfile.*.
+	Rules      []Rule
+	Regions    []Region
+}
+
+// Highlight returns the tokens of the line. The state is zero, or the state
+// returned for the previous line when it ended inside a region. Regions take
+// precedence over rules starting at the same position, and earlier rules over
+// later ones.
+func (g *Grammar) Highlight(line string, state int) ([]Token, int) {
+	if len(line) > MaxLineLength {
+		return nil, state
+	}
+	var (
+		tokens []Token
+		pos    int
+	)
+	if state > 0 && state <= len(g.Regions) {
+		r := g.Regions[state-1]
+		end, ok := find(r.End, line, 0)
+		if !ok {
+			return []Token{{0, len(line), r.Kind}}, state
+		}
+		tokens = append(tokens, Token{0, end[1], r.Kind})
+		pos = end[1]
+	}
+
+	// matches keeps the next match of each rule, so rules are only searched
+	// again when the position passes their match.
+	matches := make([][]int, len(g.Rules))
+	for pos < len(line) {
+		best, bestLoc := -1, []int(nil)
+		for i, r := range g.Regions {
+			if loc, ok := find(r.Start, line, pos); ok && (bestLoc == nil || loc[0] < bestLoc[0]) {
+				best, bestLoc = i, loc
+			}
+		}
+		region := best >= 0
+		for i, r := range g.Rules {
+			loc := matches[i]
+			if loc == nil || (loc[0] >= 0 && loc[0] < pos) {
+				var ok bool
+				if loc, ok = find(r.Pattern, line, pos); !ok {
+					loc = []int{-1, -1}
+				}
+				matches[i] = loc
+			}
+			if loc[0] >= 0 && (bestLoc == nil || loc[0] < bestLoc[0]) {
+				best, bestLoc, region = i, loc, false
+			}
+		}
+		if bestLoc == nil {
+			break
+		}
+
+		if region {
+			r := g.Regions[best]
+			end, ok := find(r.End, line, bestLoc[1])
+			if !ok {
+				tokens = append(tokens, Token{bestLoc[0], len(line), r.Kind})
+				return tokens, best + 1
+			}
+			if end[1] > bestLoc[0] {
+				tokens = append(tokens, Token{bestLoc[0], end[1], r.Kind})
+			}
+			pos = end[1]
+			if end[1] == bestLoc[0] {
+				pos++ // empty regions would stop here forever.
+			}
+			continue
+		}
+		start, end := bestLoc[0], bestLoc[1]
+		if len(bestLoc) > 2 && bestLoc[2] >= 0 {
+			start, end = bestLoc[2], bestLoc[3]
+		}
+		if end > start {
+			tokens = append(tokens, Token{start, end, g.Rules[best].Kind})
+		}
+		switch {
+		case end > pos:
+			pos = end
+		case bestLoc[1] > pos:
+			pos = bestLoc[1]
+		default:
+			pos++ // empty matches would stop here forever.
+		}
+	}
+	return tokens, 0
+}
+
+// find returns the location of the first match of re in the line at or after
+// pos, with the locations of its groups. Patterns starting with ^ only match
+// at the start of the line.
+func find(re *regexp.Regexp, line string, pos int) ([]int, bool) {
+	if pos > 0 && strings.HasPrefix(re.String(), "^") {
+		return nil, false
+	}
+	loc := re.FindStringSubmatchIndex(line[pos:])
+	if loc == nil {
+		return nil, false
+	}
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += pos
+		}
+	}
+	return loc, true
+}
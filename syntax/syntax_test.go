@@ -0,0 +1,120 @@
+// Copyright 2018 Arsham Shirvani <arshamshirvani@gmail.com>. All rights
+// reserved. Use of this source code is governed by the LGPL-v3 License that can
+// be found in the LICENSE file.
+
+package syntax_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arsham/gistflow/syntax"
+)
+
+// tokens returns the tokens of the lines as kind:text, with the states at the
+// end of the lines.
+func tokens(g *syntax.Grammar, lines ...string) ([]string, []int) {
+	var (
+		got    []string
+		states []int
+		state  int
+	)
+	for _, line := range lines {
+		var ts []syntax.Token
+		ts, state = g.Highlight(line, state)
+		for _, t := range ts {
+			got = append(got, fmt.Sprintf("%s:%s", t.Kind, line[t.Start:t.End]))
+		}
+		states = append(states, state)
+	}
+	return got, states
+}
+
+func TestKind(t *testing.T) {
+	for k := syntax.Plain; k <= syntax.Code; k++ {
+		got, ok := syntax.ParseKind(strings.ToUpper(k.String()))
+		if !ok || got != k {
+			t.Errorf("ParseKind(%q) = (%v, %t), want (%v, true)", k.String(), got, ok, k)
+		}
+	}
+	if _, ok := syntax.ParseKind("colour"); ok {
+		t.Error("ParseKind(colour) = true, want false")
+	}
+	if got := syntax.Kind(100).String(); got != "unknown" {
+		t.Errorf("String() = %q, want unknown", got)
+	}
+}
+
+func TestHighlightEmptyRegion(t *testing.T) {
+	g := &syntax.Grammar{
+		Regions: []syntax.Region{
+			{Kind: syntax.String, Start: regexp.MustCompile(`\b`), End: regexp.MustCompile(`b*`)},
+		},
+	}
+	done := make(chan []syntax.Token)
+	go func() {
+		tokens, _ := g.Highlight("hello", 0)
+		done <- tokens
+	}()
+	select {
+	case tokens := <-done:
+		if len(tokens) != 0 {
+			t.Errorf("tokens = %v, want none for empty regions", tokens)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Highlight() did not finish with an empty region")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	g := &syntax.Grammar{
+		Rules: []syntax.Rule{
+			{Kind: syntax.Comment, Pattern: regexp.MustCompile(`#.*`)},
+			{Kind: syntax.String, Pattern: regexp.MustCompile(`"[^"]*"`)},
+			{Kind: syntax.Keyword, Pattern: regexp.MustCompile(`\b(?:if|in)\b`)},
+			{Kind: syntax.Function, Pattern: regexp.MustCompile(`(\w+)\(`)},
+			{Kind: syntax.Heading, Pattern: regexp.MustCompile(`^=.*`)},
+			{Kind: syntax.Operator, Pattern: regexp.MustCompile(`x*`)},
+		},
+		Regions: []syntax.Region{
+			{Kind: syntax.Comment, Start: regexp.MustCompile(`/\*`), End: regexp.MustCompile(`\*/`)},
+		},
+	}
+	tcs := []struct {
+		name   string
+		lines  []string
+		want   []string
+		states []int
+	}{
+		{"empty", []string{""}, nil, []int{0}},
+		{"plain", []string{"nothing here"}, nil, []int{0}},
+		{"order", []string{`if "a # b" in c # if`}, []string{
+			"keyword:if", `string:"a # b"`, "keyword:in", "comment:# if",
+		}, []int{0}},
+		{"group", []string{"print(if)"}, []string{"function:print", "keyword:if"}, []int{0}},
+		{"anchored", []string{"=title", "a =b"}, []string{"heading:=title"}, []int{0, 0}},
+		{"empty matches", []string{"a xx b"}, []string{"operator:xx"}, []int{0}},
+		{"one line region", []string{`if /* "in" */ in`}, []string{
+			"keyword:if", `comment:/* "in" */`, "keyword:in",
+		}, []int{0}},
+		{"region", []string{`if /* one`, `if "two"`, `three */ in`}, []string{
+			"keyword:if", "comment:/* one", `comment:if "two"`, "comment:three */", "keyword:in",
+		}, []int{1, 1, 0}},
+		{"long line", []string{"/*", strings.Repeat("a", syntax.MaxLineLength+1)}, []string{"comment:/*"}, []int{1, 1}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, states := tokens(g, tc.lines...)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("tokens = %q, want %q", got, tc.want)
+			}
+			if !reflect.DeepEqual(states, tc.states) {
+				t.Errorf("states = %v, want %v", states, tc.states)
+			}
+		})
+	}
+}